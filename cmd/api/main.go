@@ -0,0 +1,55 @@
+// Command api serves the DONELOG REST API backed by the local file store.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/taketosaeki/donelog/internal/bootstrap"
+	"github.com/taketosaeki/donelog/internal/infrastructure/oidc"
+	"github.com/taketosaeki/donelog/internal/infrastructure/persistence/filestore"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	storePath := flag.String("store", os.Getenv("DONELOG_STORE"), "path of the store file (empty keeps data in memory)")
+	requireAuth := flag.Bool("require-auth", true, "reject requests without a session cookie or API token; disable only behind a proxy that sets X-Actor")
+	trashRetention := flag.String("trash-retention", os.Getenv("DONELOG_TRASH_RETENTION"), "how long trashed DONELOGs are kept before a purge, e.g. 30d or 36h (empty means 30d)")
+	oidcIssuer := flag.String("oidc-issuer", os.Getenv("DONELOG_OIDC_ISSUER"), "OpenID Connect issuer URL; enables single sign-on")
+	oidcClientID := flag.String("oidc-client-id", os.Getenv("DONELOG_OIDC_CLIENT_ID"), "OpenID Connect client ID")
+	oidcClientSecret := flag.String("oidc-client-secret", os.Getenv("DONELOG_OIDC_CLIENT_SECRET"), "OpenID Connect client secret (empty for a public client)")
+	oidcRedirectURL := flag.String("oidc-redirect-url", os.Getenv("DONELOG_OIDC_REDIRECT_URL"), "callback URL registered at the provider, ending in /api/auth/sso/callback")
+	flag.Parse()
+
+	store, err := filestore.Open(*storePath)
+	if err != nil {
+		log.Fatalf("open store: %v", err)
+	}
+	app := bootstrap.New(store)
+	app.PurgeTrash.Policy, err = bootstrap.ParseTrashRetention(*trashRetention)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *oidcIssuer != "" {
+		provider, err := oidc.Discover(context.Background(), oidc.Config{
+			Issuer:       *oidcIssuer,
+			ClientID:     *oidcClientID,
+			ClientSecret: *oidcClientSecret,
+			RedirectURL:  *oidcRedirectURL,
+		})
+		if err != nil {
+			log.Fatalf("single sign-on: %v", err)
+		}
+		app.Auth.Provider = provider
+	}
+	handler := app.HTTPHandler()
+	handler.RequireAuth = *requireAuth
+
+	log.Printf("listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, handler.Routes()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/taketosaeki/donelog/internal/bootstrap"
+)
+
+const accountsUsage = `usage: donelog accounts add <username>     (password from DONELOG_PASSWORD or the first line of stdin)
+       donelog accounts passwd <username>  (current password from DONELOG_PASSWORD, new one from DONELOG_NEW_PASSWORD,
+                                            or the first two lines of stdin)`
+
+// runAccounts manages sign-in accounts of the local store, e.g. the one an API server is started on.
+func runAccounts(ctx context.Context, e *env, args []string) error {
+	if len(args) != 2 {
+		return errors.New(accountsUsage)
+	}
+	switch args[0] {
+	case "add":
+		passwords, err := e.readPasswords(password{env: "DONELOG_PASSWORD", prompt: "password"})
+		if err != nil {
+			return err
+		}
+		store, err := e.openStore()
+		if err != nil {
+			return err
+		}
+		if err := bootstrap.New(store).Auth.CreateAccount(ctx, args[1], passwords[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "created account %s\n", args[1])
+		return nil
+	case "passwd":
+		passwords, err := e.readPasswords(
+			password{env: "DONELOG_PASSWORD", prompt: "current password"},
+			password{env: "DONELOG_NEW_PASSWORD", prompt: "new password"},
+		)
+		if err != nil {
+			return err
+		}
+		store, err := e.openStore()
+		if err != nil {
+			return err
+		}
+		if err := bootstrap.New(store).Auth.ChangePassword(ctx, args[1], passwords[0], passwords[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "changed the password of %s; its sessions and API tokens were revoked\n", args[1])
+		return nil
+	default:
+		return errors.New(accountsUsage)
+	}
+}
+
+// password names where readPasswords takes one password from.
+type password struct {
+	env    string
+	prompt string
+}
+
+// readPasswords takes each password from its environment variable when set, so scripts need not pipe it,
+// and otherwise from the next line of stdin.
+func (e *env) readPasswords(sources ...password) ([]string, error) {
+	stdin := bufio.NewReader(e.stdin)
+	values := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if value := os.Getenv(source.env); value != "" {
+			values = append(values, value)
+			continue
+		}
+		fmt.Fprintf(e.stderr, "%s: ", source.prompt)
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("read %s: %w", source.prompt, err)
+		}
+		values = append(values, strings.TrimRight(line, "\r\n"))
+	}
+	return values, nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/bootstrap"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+// backend is what the DONELOG subcommands need. *httpapi.Client implements it for
+// remote mode; localBackend calls the application handlers against the local store.
+type backend interface {
+	CreateDoneLog(ctx context.Context, body httpapi.CreateDoneLogRequest, idempotencyKey string) (string, error)
+	UpdateDoneLog(ctx context.Context, id string, body httpapi.UpdateDoneLogRequest) error
+	DeleteDoneLog(ctx context.Context, id string) error
+	Undo(ctx context.Context) (httpapi.UndoResponse, error)
+	PurgeTrash(ctx context.Context) (httpapi.PurgeResponse, error)
+	GetDoneLog(ctx context.Context, id string) (query.DoneLogItem, error)
+	ListDoneLogs(ctx context.Context, q query.ListDoneLogsQuery) (query.DoneLogPage, error)
+	SummarizeByDay(ctx context.Context, q query.SummarizeByDayQuery) (query.Summary, error)
+	SummarizeByWeek(ctx context.Context, q query.SummarizeByWeekQuery) (query.Summary, error)
+	SummarizeByMonth(ctx context.Context, q query.SummarizeByMonthQuery) (query.Summary, error)
+	SummarizeByQuarter(ctx context.Context, q query.SummarizeByQuarterQuery) (query.Summary, error)
+	SummarizeByYear(ctx context.Context, q query.SummarizeByYearQuery) (query.Summary, error)
+	ListTracks(ctx context.Context, q query.ListTracksQuery) ([]query.TrackItem, error)
+	CreateTrack(ctx context.Context, body httpapi.CreateTrackRequest) error
+	ArchiveTrack(ctx context.Context, id string) error
+	ListCategories(ctx context.Context, q query.ListCategoriesQuery) ([]query.CategoryItem, error)
+	CreateCategory(ctx context.Context, body httpapi.CreateCategoryRequest) error
+	ArchiveCategory(ctx context.Context, id string) error
+	GetSettings(ctx context.Context) (query.SettingsItem, error)
+	UpdateSettings(ctx context.Context, body httpapi.UpdateSettingsRequest) error
+}
+
+// localBackend runs each command in its own store transaction.
+type localBackend struct {
+	app bootstrap.App
+}
+
+func (b localBackend) CreateDoneLog(ctx context.Context, body httpapi.CreateDoneLogRequest, idempotencyKey string) (string, error) {
+	var id string
+	err := b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := b.app.CreateDoneLog.Handle(ctx, command.CreateDoneLogCommand{
+			Title:          body.Title,
+			TrackID:        body.TrackID,
+			CategoryID:     body.CategoryID,
+			Count:          body.Count,
+			OccurredOn:     body.OccurredOn,
+			Tags:           body.Tags,
+			IdempotencyKey: idempotencyKey,
+		})
+		id = created.String()
+		return err
+	})
+	return id, err
+}
+
+func (b localBackend) UpdateDoneLog(ctx context.Context, id string, body httpapi.UpdateDoneLogRequest) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.UpdateDoneLog.Handle(ctx, command.UpdateDoneLogCommand{
+			ID:         id,
+			Title:      body.Title,
+			CategoryID: body.CategoryID,
+			Count:      body.Count,
+			OccurredOn: body.OccurredOn,
+			Tags:       body.Tags,
+			Note:       body.Note,
+			Links:      body.Links,
+		})
+	})
+}
+
+func (b localBackend) DeleteDoneLog(ctx context.Context, id string) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.DeleteDoneLog.Handle(ctx, command.DeleteDoneLogCommand{ID: id})
+	})
+}
+
+func (b localBackend) Undo(ctx context.Context) (httpapi.UndoResponse, error) {
+	var result command.UndoResult
+	err := b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = b.app.UndoLastChange.Handle(ctx, command.UndoLastChangeCommand{})
+		return err
+	})
+	return httpapi.UndoResponse{ID: result.DoneLogID.String(), Action: string(result.Action)}, err
+}
+
+func (b localBackend) PurgeTrash(ctx context.Context) (httpapi.PurgeResponse, error) {
+	res := httpapi.PurgeResponse{Purged: []string{}}
+	err := b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		ids, err := b.app.PurgeTrash.Handle(ctx, command.PurgeTrashedDoneLogsCommand{})
+		for _, id := range ids {
+			res.Purged = append(res.Purged, id.String())
+		}
+		return err
+	})
+	return res, err
+}
+
+func (b localBackend) GetDoneLog(ctx context.Context, id string) (query.DoneLogItem, error) {
+	return b.app.GetDoneLog.Handle(ctx, query.GetDoneLogQuery{ID: id})
+}
+
+func (b localBackend) ListDoneLogs(ctx context.Context, q query.ListDoneLogsQuery) (query.DoneLogPage, error) {
+	return b.app.ListDoneLogs.Handle(ctx, q)
+}
+
+func (b localBackend) SummarizeByDay(ctx context.Context, q query.SummarizeByDayQuery) (query.Summary, error) {
+	return b.app.SummarizeByDay.Handle(ctx, q)
+}
+
+func (b localBackend) SummarizeByWeek(ctx context.Context, q query.SummarizeByWeekQuery) (query.Summary, error) {
+	return b.app.SummarizeByWeek.Handle(ctx, q)
+}
+
+func (b localBackend) SummarizeByMonth(ctx context.Context, q query.SummarizeByMonthQuery) (query.Summary, error) {
+	return b.app.SummarizeByMonth.Handle(ctx, q)
+}
+
+func (b localBackend) SummarizeByQuarter(ctx context.Context, q query.SummarizeByQuarterQuery) (query.Summary, error) {
+	return b.app.SummarizeByQuarter.Handle(ctx, q)
+}
+
+func (b localBackend) SummarizeByYear(ctx context.Context, q query.SummarizeByYearQuery) (query.Summary, error) {
+	return b.app.SummarizeByYear.Handle(ctx, q)
+}
+
+func (b localBackend) ListTracks(ctx context.Context, q query.ListTracksQuery) ([]query.TrackItem, error) {
+	return b.app.ListTracks.Handle(ctx, q)
+}
+
+func (b localBackend) CreateTrack(ctx context.Context, body httpapi.CreateTrackRequest) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.CreateTrack.Handle(ctx, command.CreateTrackCommand{
+			ID:                body.ID,
+			Name:              body.Name,
+			DefaultCategoryID: body.DefaultCategoryID,
+			SortOrder:         body.SortOrder,
+		})
+	})
+}
+
+func (b localBackend) ArchiveTrack(ctx context.Context, id string) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.ArchiveTrack.Handle(ctx, command.ArchiveTrackCommand{ID: id})
+	})
+}
+
+func (b localBackend) ListCategories(ctx context.Context, q query.ListCategoriesQuery) ([]query.CategoryItem, error) {
+	return b.app.ListCategories.Handle(ctx, q)
+}
+
+func (b localBackend) CreateCategory(ctx context.Context, body httpapi.CreateCategoryRequest) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.CreateCategory.Handle(ctx, command.CreateCategoryCommand{ID: body.ID, Name: body.Name, SortOrder: body.SortOrder})
+	})
+}
+
+func (b localBackend) ArchiveCategory(ctx context.Context, id string) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.ArchiveCategory.Handle(ctx, command.ArchiveCategoryCommand{ID: id})
+	})
+}
+
+func (b localBackend) GetSettings(ctx context.Context) (query.SettingsItem, error) {
+	return b.app.GetSettings.Handle(ctx, query.GetSettingsQuery{})
+}
+
+func (b localBackend) UpdateSettings(ctx context.Context, body httpapi.UpdateSettingsRequest) error {
+	return b.app.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return b.app.UpdateSettings.Handle(ctx, command.UpdateSettingsCommand{WeekStart: body.WeekStart})
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/taketosaeki/donelog/internal/app/backup"
+	"github.com/taketosaeki/donelog/internal/infrastructure/clock"
+)
+
+func runBackup(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	output := fs.String("o", "", "archive file to write (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return errors.New("-o is required")
+	}
+
+	store, err := e.openStore()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	summary, err := backup.Service{Store: store, Time: clock.SystemClock{}}.Backup(ctx, f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	printBackupSummary(e.stdout, "backed up", summary)
+	return nil
+}
+
+func runRestore(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dryRun := fs.Bool("dry-run", false, "only validate the archive")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: donelog restore [--dry-run] <archive>")
+	}
+
+	archive, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	store, err := e.openStore()
+	if err != nil {
+		return err
+	}
+	service := backup.Service{Store: store, Time: clock.SystemClock{}}
+
+	if *dryRun {
+		summary, err := service.Verify(ctx, archive)
+		if err != nil {
+			return err
+		}
+		printBackupSummary(e.stdout, "archive is valid", summary)
+		return nil
+	}
+	summary, err := service.Restore(ctx, archive)
+	if err != nil {
+		return err
+	}
+	printBackupSummary(e.stdout, "restored", summary)
+	return nil
+}
+
+func printBackupSummary(w io.Writer, verb string, s backup.Summary) {
+	fmt.Fprintf(w, "%s: schema v%d, %d doneLogs, %d tracks, %d categories, %d goals, %d teams, %d audit entries, %d accounts, %d identities\n",
+		verb, s.SchemaVersion, s.DoneLogs, s.Tracks, s.Categories, s.Goals, s.Teams, s.AuditEntries, s.Accounts, s.Identities)
+}
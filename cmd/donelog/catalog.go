@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+func runTracks(ctx context.Context, e *env, args []string) error {
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "add":
+			fs := flag.NewFlagSet("tracks add", flag.ContinueOnError)
+			fs.SetOutput(e.stderr)
+			category := fs.String("default-category", "", "CategoryID used when add omits --category")
+			sortOrder := fs.Int("sort", 0, "display order")
+			if err := fs.Parse(args[1:]); err != nil {
+				return err
+			}
+			if fs.NArg() < 2 {
+				return errors.New("usage: donelog tracks add [--default-category c] [--sort n] <id> <name>")
+			}
+			return b.CreateTrack(ctx, httpapi.CreateTrackRequest{
+				ID:                fs.Arg(0),
+				Name:              strings.Join(fs.Args()[1:], " "),
+				DefaultCategoryID: *category,
+				SortOrder:         *sortOrder,
+			})
+		case "archive":
+			if len(args) != 2 {
+				return errors.New("usage: donelog tracks archive <id>")
+			}
+			return b.ArchiveTrack(ctx, args[1])
+		}
+	}
+
+	fs := flag.NewFlagSet("tracks", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	all := fs.Bool("all", false, "include archived Tracks")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unknown tracks command %q", fs.Arg(0))
+	}
+	tracks, err := b.ListTracks(ctx, query.ListTracksQuery{IncludeArchived: *all})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, tracks)
+	}
+	tw := newTable(e.stdout)
+	fmt.Fprintln(tw, "ID\tNAME\tDEFAULT CATEGORY\tACTIVE")
+	for _, t := range tracks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", t.ID, t.Name, t.DefaultCategoryID, t.Active)
+	}
+	return tw.Flush()
+}
+
+func runCategories(ctx context.Context, e *env, args []string) error {
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "add":
+			fs := flag.NewFlagSet("categories add", flag.ContinueOnError)
+			fs.SetOutput(e.stderr)
+			sortOrder := fs.Int("sort", 0, "display order")
+			if err := fs.Parse(args[1:]); err != nil {
+				return err
+			}
+			if fs.NArg() < 2 {
+				return errors.New("usage: donelog categories add [--sort n] <id> <name>")
+			}
+			return b.CreateCategory(ctx, httpapi.CreateCategoryRequest{
+				ID:        fs.Arg(0),
+				Name:      strings.Join(fs.Args()[1:], " "),
+				SortOrder: *sortOrder,
+			})
+		case "archive":
+			if len(args) != 2 {
+				return errors.New("usage: donelog categories archive <id>")
+			}
+			return b.ArchiveCategory(ctx, args[1])
+		}
+	}
+
+	fs := flag.NewFlagSet("categories", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	all := fs.Bool("all", false, "include archived Categories")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unknown categories command %q", fs.Arg(0))
+	}
+	categories, err := b.ListCategories(ctx, query.ListCategoriesQuery{IncludeArchived: *all})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, categories)
+	}
+	tw := newTable(e.stdout)
+	fmt.Fprintln(tw, "ID\tNAME\tACTIVE")
+	for _, c := range categories {
+		fmt.Fprintf(tw, "%s\t%s\t%t\n", c.ID, c.Name, c.Active)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/app/donelog/quickadd"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+const dateLayout = "2006-01-02"
+
+func runAdd(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	track := fs.String("track", "", "TrackID or exact Track name (like @track, but not matched fuzzily)")
+	category := fs.String("category", "", "CategoryID or exact Category name (default: the Track's default category)")
+	count := fs.String("count", "", "how many were done (default 1)")
+	date := fs.String("date", "", "day it happened: YYYY-MM-DD, today, yesterday, -3d, friday... (default today)")
+	tags := fs.String("tags", "", "comma-separated tags, e.g. go,performance")
+	key := fs.String("key", "", "idempotency key; repeating the same add is then a no-op")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New(`usage: donelog add [flags] <title> [count] @track [#category] [date]`)
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	tracks, err := b.ListTracks(ctx, query.ListTracksQuery{})
+	if err != nil {
+		return err
+	}
+	categories, err := b.ListCategories(ctx, query.ListCategoriesQuery{})
+	if err != nil {
+		return err
+	}
+	// Flags are checked strictly; only the free-text tokens are matched fuzzily.
+	parser := quickadd.Parser{
+		Tracks:     tracks,
+		Categories: categories,
+		Today:      e.today(),
+		Flags:      quickadd.Flags{Track: *track, Category: *category, Count: *count, Date: *date},
+	}
+	cmd, err := parser.Parse(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	id, err := b.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title:      cmd.Title,
+		TrackID:    cmd.TrackID,
+		CategoryID: cmd.CategoryID,
+		Count:      cmd.Count,
+		OccurredOn: cmd.OccurredOn,
+		Tags:       splitTags(*tags),
+	}, *key)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, httpapi.CreateDoneLogResponse{ID: id})
+	}
+	fmt.Fprintln(e.stdout, id)
+	return nil
+}
+
+func runEdit(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	title := fs.String("title", "", "new title")
+	category := fs.String("category", "", "new CategoryID")
+	count := fs.Int("count", 0, "new count")
+	date := fs.String("date", "", "new day, YYYY-MM-DD")
+	tags := fs.String("tags", "", `new comma-separated tags; "" clears them`)
+	note := fs.String("note", "", `new Markdown note; "" clears it`)
+	var links []string
+	fs.Func("link", `URL to attach, replacing the current links; repeat for several, "" clears them`, func(value string) error {
+		if value != "" {
+			links = append(links, value)
+		}
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: donelog edit [--title t] [--category c] [--count n] [--date d] [--tags t1,t2] [--note md] [--link url]... <id>")
+	}
+	id := fs.Arg(0)
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	// Updates replace every field, so start from the current values and apply the flags given.
+	current, err := b.GetDoneLog(ctx, id)
+	if err != nil {
+		return err
+	}
+	body := httpapi.UpdateDoneLogRequest{
+		Title:      current.Title,
+		CategoryID: current.CategoryID,
+		Count:      current.Count,
+		OccurredOn: current.OccurredOn,
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			body.Title = *title
+		case "category":
+			body.CategoryID = *category
+		case "count":
+			body.Count = *count
+		case "date":
+			body.OccurredOn = *date
+		case "tags":
+			body.Tags = splitTags(*tags)
+			if body.Tags == nil {
+				body.Tags = []string{}
+			}
+		case "note":
+			body.Note = note
+		case "link":
+			body.Links = append([]string{}, links...)
+		}
+	})
+	return b.UpdateDoneLog(ctx, id, body)
+}
+
+func runRemove(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: donelog rm <id>...")
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	for _, id := range fs.Args() {
+		if err := b.DeleteDoneLog(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func runList(ctx context.Context, e *env, args []string) error {
+	today := e.today()
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	from := fs.String("from", today.AddDate(0, 0, -6).Format(dateLayout), "first day (YYYY-MM-DD)")
+	to := fs.String("to", today.Format(dateLayout), "last day (YYYY-MM-DD)")
+	track := fs.String("track", "", "only this TrackID")
+	category := fs.String("category", "", "only this CategoryID")
+	tag := fs.String("tag", "", "only DONELOGs with this tag")
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 20, "entries per page")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	result, err := b.ListDoneLogs(ctx, query.ListDoneLogsQuery{
+		TrackID:    *track,
+		CategoryID: *category,
+		Tag:        *tag,
+		StartDate:  *from,
+		EndDate:    *to,
+		Page:       *page,
+		Limit:      *limit,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, result)
+	}
+
+	tw := newTable(e.stdout)
+	fmt.Fprintln(tw, "DATE\tTRACK\tCATEGORY\tCOUNT\tTITLE\tID")
+	for _, item := range result.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			item.OccurredOn, nameOr(item.TrackName, item.TrackID), nameOr(item.CategoryName, item.CategoryID), item.Count, item.Title, item.ID)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "page %d, %d of %d entries\n", result.Page, len(result.Items), result.TotalCount)
+	return nil
+}
+
+func runUndo(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("undo", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	result, err := b.Undo(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, result)
+	}
+	fmt.Fprintf(e.stdout, "reverted %s of %s\n", result.Action, result.ID)
+	return nil
+}
+
+func runPurge(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	result, err := b.PurgeTrash(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, result)
+	}
+	fmt.Fprintf(e.stdout, "purged %d DoneLogs\n", len(result.Purged))
+	return nil
+}
+
+// splitTags parses a comma-separated --tags value; blank entries are dropped.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// nameOr falls back to the ID when a referenced Track or Category has no name.
+func nameOr(name, id string) string {
+	if name == "" {
+		return id
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/export"
+)
+
+func runExport(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	from := fs.String("from", "", "first day to export (YYYY-MM-DD)")
+	to := fs.String("to", "", "last day to export (YYYY-MM-DD)")
+	track := fs.String("track", "", "only this TrackID")
+	category := fs.String("category", "", "only this CategoryID")
+	format := fs.String("format", string(export.FormatCSV), "csv, jsonl or xlsx")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req, err := export.ParseRequest(*from, *to, *track, *category, *format)
+	if err != nil {
+		return err
+	}
+
+	store, err := e.openStore()
+	if err != nil {
+		return err
+	}
+	exporter := export.Exporter{DoneLogs: store.DoneLogs(), Tracks: store.Tracks(), Categories: store.Categories()}
+
+	if *output == "" {
+		return exporter.Export(ctx, req, e.stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Export(ctx, req, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
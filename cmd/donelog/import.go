@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/importer"
+	"github.com/taketosaeki/donelog/internal/bootstrap"
+)
+
+// runImport loads historic DoneLogs from a CSV file into the local store. The import is atomic:
+// when any row fails, nothing is created and every failing line is reported.
+func runImport(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dryRun := fs.Bool("dry-run", false, "validate every row, including Track and Category references, without creating anything")
+	var pairs, dateFormats []string
+	fs.Func("map", "map a CSV column to a field (title, track, category, count or date), e.g. Name=title; repeat for several", func(value string) error {
+		pairs = append(pairs, value)
+		return nil
+	})
+	fs.Func("date-format", "Go time layout of the date column, e.g. 2006/01/02; repeat to try several (default 2006-01-02)", func(value string) error {
+		dateFormats = append(dateFormats, value)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: donelog import [--dry-run] [--map column=field]... [--date-format layout]... file.csv")
+	}
+	mapping, err := importer.ParseMapping(pairs)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	store, err := e.openStore()
+	if err != nil {
+		return err
+	}
+	report, err := bootstrap.New(store).Import.Import(ctx, f, importer.Options{Mapping: mapping, DateFormats: dateFormats, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	// A row can fail on several fields, so failed rows are counted by line.
+	failed := map[int]bool{}
+	for _, lineErr := range report.Errors {
+		fmt.Fprintln(e.stderr, lineErr)
+		failed[lineErr.Line] = true
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d rows failed; nothing was imported", len(failed), report.Rows)
+	}
+	if report.DryRun {
+		fmt.Fprintf(e.stdout, "dry run: %d rows are valid; nothing was imported\n", report.Rows)
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "imported %d DoneLogs\n", len(report.Created))
+	return nil
+}
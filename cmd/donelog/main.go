@@ -0,0 +1,178 @@
+// Command donelog records and reviews DONELOGs from the terminal.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/bootstrap"
+	"github.com/taketosaeki/donelog/internal/infrastructure/persistence/filestore"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+// subcommand is one `donelog <name>` entry point.
+type subcommand struct {
+	summary string
+	run     func(ctx context.Context, env *env, args []string) error
+}
+
+var subcommands = map[string]subcommand{
+	"add":        {summary: "record a DoneLog", run: runAdd},
+	"edit":       {summary: "change fields of a DoneLog", run: runEdit},
+	"rm":         {summary: "move DoneLogs to the trash", run: runRemove},
+	"ls":         {summary: "list DoneLogs, newest first", run: runList},
+	"undo":       {summary: "undo your last add, edit or rm", run: runUndo},
+	"purge":      {summary: "permanently delete DoneLogs trashed more than {retention} ago", run: runPurge},
+	"summary":    {summary: "show totals per day, week, month, quarter or year", run: runSummary},
+	"tracks":     {summary: "list, add or archive Tracks", run: runTracks},
+	"categories": {summary: "list, add or archive Categories", run: runCategories},
+	"settings":   {summary: "show or change your settings, e.g. the first day of the week", run: runSettings},
+	"tui":        {summary: "full-screen view of today with quick add, edit and delete", run: runTUI},
+	"export":     {summary: "export DoneLogs as csv, jsonl or xlsx", run: runExport},
+	"import":     {summary: "import DoneLogs from a CSV file", run: runImport},
+	"backup":     {summary: "write a full backup archive", run: runBackup},
+	"restore":    {summary: "validate and restore a backup archive", run: runRestore},
+	"accounts":   {summary: "add a sign-in account to the local store or change its password", run: runAccounts},
+}
+
+// env carries shared state for subcommands.
+type env struct {
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	storePath string
+	store     *filestore.Store
+	// server switches DoneLog subcommands to remote mode when set.
+	server string
+	actor  string
+	// token authenticates remote requests.
+	token string
+	// trashRetention is the --trash-retention of the local store, checked by run; a server applies its own.
+	trashRetention string
+	now            func() time.Time
+}
+
+// backend returns the remote client when --server is set, otherwise the local store.
+// Export, import, backup and restore always work on the local store.
+func (e *env) backend() (backend, error) {
+	if e.server != "" {
+		return &httpapi.Client{BaseURL: e.server, Actor: e.actor, Token: e.token}, nil
+	}
+	store, err := e.openStore()
+	if err != nil {
+		return nil, err
+	}
+	app := bootstrap.New(store)
+	if app.PurgeTrash.Policy, err = bootstrap.ParseTrashRetention(e.trashRetention); err != nil {
+		return nil, err
+	}
+	return localBackend{app: app}, nil
+}
+
+// today is the default date for new DoneLogs and listings.
+func (e *env) today() time.Time {
+	return e.now()
+}
+
+// openStore opens the local store on first use.
+func (e *env) openStore() (*filestore.Store, error) {
+	if e.store != nil {
+		return e.store, nil
+	}
+	store, err := filestore.Open(e.storePath)
+	if err != nil {
+		return nil, err
+	}
+	e.store = store
+	return store, nil
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, time.Now))
+}
+
+// run executes one CLI invocation; now is the clock behind relative dates such as "today".
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, now func() time.Time) int {
+	global := flag.NewFlagSet("donelog", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	storePath := global.String("store", defaultStorePath(), "path of the local store file")
+	server := global.String("server", os.Getenv("DONELOG_SERVER"), "base URL of a donelog API server (remote mode)")
+	actor := global.String("actor", os.Getenv("USER"), "who is making the change (recorded in the audit log)")
+	token := global.String("token", os.Getenv("DONELOG_TOKEN"), "personal API token for --server")
+	trashRetention := global.String("trash-retention", os.Getenv("DONELOG_TRASH_RETENTION"), "how long trashed DoneLogs are kept before purge in the local store, e.g. 30d or 36h (empty means 30d)")
+	global.Usage = func() {
+		// An invalid value is reported after parsing; help falls back to the default meanwhile.
+		policy, _ := bootstrap.ParseTrashRetention(*trashRetention)
+		usage(stderr, bootstrap.FormatTrashRetention(policy))
+	}
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	purgePolicy, err := bootstrap.ParseTrashRetention(*trashRetention)
+	if err != nil {
+		fmt.Fprintf(stderr, "donelog: --trash-retention: %v\n", err)
+		return 2
+	}
+	retention := bootstrap.FormatTrashRetention(purgePolicy)
+
+	rest := global.Args()
+	if len(rest) == 0 {
+		usage(stderr, retention)
+		return 2
+	}
+	cmd, ok := subcommands[rest[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "donelog: unknown command %q\n", rest[0])
+		usage(stderr, retention)
+		return 2
+	}
+
+	e := &env{stdin: os.Stdin, stdout: stdout, stderr: stderr, storePath: *storePath, server: *server, actor: *actor, token: *token, trashRetention: *trashRetention, now: now}
+	if *actor != "" {
+		ctx = appctx.WithActor(ctx, *actor)
+	}
+	if err := cmd.run(ctx, e, rest[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "donelog %s: %v\n", rest[0], err)
+		return 1
+	}
+	return 0
+}
+
+// usage lists the commands; summaries name the configured trash retention in place of {retention}.
+func usage(w io.Writer, retention string) {
+	fmt.Fprintln(w, "usage: donelog [--store path | --server url [--token t]] [--actor name] [--trash-retention 30d] <command> [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		summary := strings.ReplaceAll(subcommands[name].summary, "{retention}", retention)
+		fmt.Fprintf(w, "  %-12s %s\n", name, summary)
+	}
+}
+
+// defaultStorePath honours DONELOG_STORE and falls back to ~/.donelog/donelog.json.
+func defaultStorePath() string {
+	if path := os.Getenv("DONELOG_STORE"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "donelog.json"
+	}
+	return filepath.Join(home, ".donelog", "donelog.json")
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+// testNow is the CLI clock in tests, a day after every fixed date and quarter used below.
+func testNow() time.Time { return time.Date(2024, 7, 15, 9, 0, 0, 0, time.Local) }
+
+// donelog runs the CLI against a store file and returns stdout, failing on a non-zero exit.
+func donelog(t *testing.T, store string, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append([]string{"--store", store, "--actor", "taketo"}, args...), &stdout, &stderr, testNow)
+	if code != 0 {
+		t.Fatalf("donelog %v exited %d: %s", args, code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestLocalWorkflow(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "donelog.json")
+
+	donelog(t, store, "categories", "add", "pages", "Pages")
+	donelog(t, store, "tracks", "add", "--default-category", "pages", "reading", "Reading", "club")
+	id := strings.TrimSpace(donelog(t, store, "add", "--track", "reading", "--count", "12", "--date", "2024-05-01", "--tags", "books, go", "ch.1"))
+	donelog(t, store, "edit", "--count", "20", "--note", "Read **twice**", "--link", "https://example.com/ch1", "--link", "https://example.com/errata", id)
+	quick := strings.TrimSpace(donelog(t, store, "add", "ch.2 and ch.3", "8", "@read", "-1d"))
+
+	var page query.DoneLogPage
+	out := donelog(t, store, "ls", "--from", "2024-05-01", "--to", "2024-05-31", "--json")
+	if err := json.Unmarshal([]byte(out), &page); err != nil {
+		t.Fatalf("ls --json: %v\n%s", err, out)
+	}
+	if page.TotalCount != 1 || page.Items[0].Count != 20 || page.Items[0].CategoryID != "pages" || page.Items[0].Title != "ch.1" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	// edit without --tags keeps them; ls --tag filters on them.
+	if out := donelog(t, store, "ls", "--from", "2024-05-01", "--to", "2024-05-31", "--tag", "go"); !strings.Contains(out, "1 of 1 entries") || strings.Join(page.Items[0].Tags, ",") != "books,go" {
+		t.Fatalf("unexpected tag-filtered list (tags %v):\n%s", page.Items[0].Tags, out)
+	}
+	if page.Items[0].Note != "Read **twice**" || len(page.Items[0].Links) != 2 {
+		t.Fatalf("unexpected note and links: %+v", page.Items[0])
+	}
+	yesterday := testNow().AddDate(0, 0, -1).Format(dateLayout)
+	out = donelog(t, store, "ls", "--from", yesterday, "--to", yesterday, "--json")
+	if err := json.Unmarshal([]byte(out), &page); err != nil || page.TotalCount != 1 || page.Items[0].ID != quick || page.Items[0].Count != 8 {
+		t.Fatalf("expected quick-added DoneLog yesterday, got %+v (%v)", page, err)
+	}
+
+	table := donelog(t, store, "summary", "day", "--from", "2024-05-01", "--to", "2024-05-02")
+	if !strings.Contains(table, "2024-05-01  20") || !strings.Contains(table, "total       20") {
+		t.Fatalf("unexpected summary table:\n%s", table)
+	}
+	quarterly := donelog(t, store, "summary", "quarter", "--from", "2024-Q1", "--to", "2024-Q2", "--json")
+	if !strings.Contains(quarterly, `"label": "2024-Q2",
+      "count": 20`) {
+		t.Fatalf("unexpected quarterly summary:\n%s", quarterly)
+	}
+	if out := donelog(t, store, "settings", "--week-start", "sunday"); !strings.Contains(out, "week-start  sunday") {
+		t.Fatalf("unexpected settings output: %s", out)
+	}
+	weekly := donelog(t, store, "summary", "week", "--from", "2024-04-28", "--to", "2024-05-04")
+	if !strings.Contains(weekly, "2024-W18  20") || strings.Contains(weekly, "2024-W17") {
+		t.Fatalf("unexpected weekly table:\n%s", weekly)
+	}
+
+	if tracks := donelog(t, store, "tracks"); !strings.Contains(tracks, "Reading club") {
+		t.Fatalf("unexpected tracks table:\n%s", tracks)
+	}
+
+	donelog(t, store, "rm", id)
+	if out := donelog(t, store, "ls", "--from", "2024-05-01", "--to", "2024-05-31"); !strings.Contains(out, "0 of 0 entries") {
+		t.Fatalf("expected trashed DoneLog to be hidden:\n%s", out)
+	}
+	if out := donelog(t, store, "undo"); !strings.Contains(out, "reverted deleted of "+id) {
+		t.Fatalf("unexpected undo output: %s", out)
+	}
+	donelog(t, store, "rm", quick)
+	if out := donelog(t, store, "purge"); !strings.Contains(out, "purged 0 DoneLogs") {
+		t.Fatalf("recently trashed DoneLogs must survive a purge: %s", out)
+	}
+	if out := donelog(t, store, "--trash-retention", "1ns", "purge"); !strings.Contains(out, "purged 1 DoneLogs") {
+		t.Fatalf("a shorter --trash-retention must purge the trashed DoneLog: %s", out)
+	}
+}
+
+func TestTrashRetentionFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      string
+		wantCode int
+		wantErr  string
+	}{
+		{"OK: help names the default retention", nil, "", 2, "trashed more than 30 days ago"},
+		{"OK: help names the configured retention", []string{"--trash-retention", "7d"}, "", 2, "trashed more than 7 days ago"},
+		{"OK: DONELOG_TRASH_RETENTION sets the retention", nil, "14d", 2, "trashed more than 14 days ago"},
+		{"NG: zero retention", []string{"--trash-retention", "0d", "purge"}, "", 2, "trash retention must be > 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DONELOG_TRASH_RETENTION", tt.env)
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args, &stdout, &stderr, testNow)
+			if code != tt.wantCode || !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Fatalf("code = %d, stderr = %q", code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	store := filepath.Join(dir, "donelog.json")
+	donelog(t, store, "categories", "add", "pages", "Pages")
+	donelog(t, store, "tracks", "add", "--default-category", "pages", "reading", "Reading")
+	writeCSV := func(name, body string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	good := writeCSV("good.csv", "Name,Book,Kind,Pages,Day\nch.1,reading,pages,12,2024/05/01\nch.2,reading,pages,8,2024/05/02\n")
+	bad := writeCSV("bad.csv", "Name,Book,Kind,Pages,Day\nch.3,reading,pages,0,2024/05/03\nch.4,ghost,pages,4,2024/05/04\nch.5,reading,pages,6,2024/05/05\n")
+	mapping := []string{"--map", "Name=title", "--map", "Book=track", "--map", "Kind=category", "--map", "Pages=count", "--map", "Day=date", "--date-format", "2006/01/02"}
+	listed := func() int {
+		t.Helper()
+		var page query.DoneLogPage
+		out := donelog(t, store, "ls", "--from", "2024-05-01", "--to", "2024-05-31", "--json")
+		if err := json.Unmarshal([]byte(out), &page); err != nil {
+			t.Fatalf("ls --json: %v\n%s", err, out)
+		}
+		return page.TotalCount
+	}
+
+	if out := donelog(t, store, append(append([]string{"import", "--dry-run"}, mapping...), good)...); !strings.Contains(out, "dry run: 2 rows are valid") || listed() != 0 {
+		t.Fatalf("dry run must validate without importing: %s", out)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr []string
+	}{
+		{"NG: failing rows import nothing", append(append([]string{"import"}, mapping...), bad), []string{"line 2: count", "line 3: track ghost", "2 of 3 rows failed; nothing was imported"}},
+		{"NG: default columns are missing", []string{"import", good}, []string{`missing column "title"`}},
+		{"NG: unknown field", []string{"import", "--map", "Name=name", good}, []string{`unknown field "name"`}},
+		{"NG: no file", []string{"import"}, []string{"usage: donelog import"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), append([]string{"--store", store}, tt.args...), &stdout, &stderr, testNow)
+			for _, want := range tt.wantErr {
+				if code != 1 || !strings.Contains(stderr.String(), want) {
+					t.Fatalf("code = %d, stderr = %q, want %q", code, stderr.String(), want)
+				}
+			}
+			if n := listed(); n != 0 {
+				t.Fatalf("a failed import created %d DoneLogs", n)
+			}
+		})
+	}
+
+	if out := donelog(t, store, append(append([]string{"import"}, mapping...), good)...); !strings.Contains(out, "imported 2 DoneLogs") || listed() != 2 {
+		t.Fatalf("unexpected import: %s", out)
+	}
+	if table := donelog(t, store, "summary", "day", "--from", "2024-05-01", "--to", "2024-05-02"); !strings.Contains(table, "total       20") {
+		t.Fatalf("imported counts are missing from the summary:\n%s", table)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "donelog.json")
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{"NG: unknown command", []string{"nope"}, 2, "unknown command"},
+		{"NG: add without arguments", []string{"add"}, 1, "usage: donelog add"},
+		{"NG: add without track", []string{"add", "title"}, 1, "track is required"},
+		{"NG: unknown track", []string{"add", "--track", "ghost", "title"}, 1, `no track matches "ghost"`},
+		{"NG: --count is not part of the title", []string{"add", "--track", "ghost", "--count", "ten", "title"}, 1, `--count must be a positive number, got "ten"`},
+		{"NG: --date is not part of the title", []string{"add", "--track", "ghost", "--date", "2024-13-01", "title"}, 1, `--date "2024-13-01" is not a date`},
+		{"NG: summary needs a unit", []string{"summary"}, 1, "day|week|month|quarter|year"},
+		{"NG: accounts needs a username", []string{"accounts", "add"}, 1, "usage: donelog accounts add"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), append([]string{"--store", store}, tt.args...), &stdout, &stderr, testNow)
+			if code != tt.wantCode || !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Fatalf("code = %d, stderr = %q", code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "donelog.json")
+	t.Setenv("DONELOG_PASSWORD", "correct horse")
+	if out := donelog(t, store, "accounts", "add", "alice"); !strings.Contains(out, "created account alice") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"--store", store, "accounts", "add", "alice"}, &stdout, &stderr, testNow)
+	if code != 1 || !strings.Contains(stderr.String(), "conflict") {
+		t.Fatalf("code = %d, stderr = %q", code, stderr.String())
+	}
+
+	// passwd needs the current password and takes the new one from DONELOG_NEW_PASSWORD.
+	t.Setenv("DONELOG_NEW_PASSWORD", "battery staple")
+	if out := donelog(t, store, "accounts", "passwd", "alice"); !strings.Contains(out, "changed the password of alice") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	code = run(context.Background(), []string{"--store", store, "accounts", "passwd", "alice"}, &stdout, &stderr, testNow)
+	if code != 1 || !strings.Contains(stderr.String(), "invalid username or password") {
+		t.Fatalf("passwd with a stale password: code = %d, stderr = %q", code, stderr.String())
+	}
+	t.Setenv("DONELOG_PASSWORD", "battery staple")
+	t.Setenv("DONELOG_NEW_PASSWORD", "correct horse")
+	donelog(t, store, "accounts", "passwd", "alice")
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"text/tabwriter"
+)
+
+// printJSON writes v as indented JSON for --json output.
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// newTable returns a tabwriter for human-readable tables; callers must Flush it.
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
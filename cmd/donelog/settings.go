@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+func runSettings(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("settings", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	weekStart := fs.String("week-start", "", "set the default first day of summary weeks: monday or sunday")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	if *weekStart != "" {
+		if err := b.UpdateSettings(ctx, httpapi.UpdateSettingsRequest{WeekStart: *weekStart}); err != nil {
+			return err
+		}
+	}
+	settings, err := b.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, settings)
+	}
+	fmt.Fprintf(e.stdout, "week-start  %s\n", settings.WeekStart)
+	return nil
+}
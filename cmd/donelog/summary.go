@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+// maxBarWidth is the width of the longest bar in summary tables.
+const maxBarWidth = 40
+
+func runSummary(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 || !slices.Contains([]string{"day", "week", "month", "quarter", "year"}, args[0]) {
+		return errors.New("usage: donelog summary day|week|month|quarter|year [flags]")
+	}
+	unit := args[0]
+	today := e.today()
+
+	fs := flag.NewFlagSet("summary "+unit, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	var from, to, weekStart *string
+	switch unit {
+	case "day":
+		from = fs.String("from", today.AddDate(0, 0, -13).Format(dateLayout), "first day (YYYY-MM-DD)")
+		to = fs.String("to", today.Format(dateLayout), "last day (YYYY-MM-DD)")
+	case "week":
+		from = fs.String("from", today.AddDate(0, 0, -7*7).Format(dateLayout), "first day (YYYY-MM-DD)")
+		to = fs.String("to", today.Format(dateLayout), "last day (YYYY-MM-DD)")
+		weekStart = fs.String("week-start", "", "monday or sunday (default: week_start setting, else monday)")
+	case "quarter":
+		from = fs.String("from", quarterOf(today.AddDate(0, -9, 1-today.Day())), "first quarter (YYYY-Qn)")
+		to = fs.String("to", quarterOf(today), "last quarter (YYYY-Qn)")
+	case "year":
+		from = fs.String("from", today.AddDate(-4, 0, 0).Format("2006"), "first year (YYYY)")
+		to = fs.String("to", today.Format("2006"), "last year (YYYY)")
+	default:
+		from = fs.String("from", today.AddDate(0, -5, 1-today.Day()).Format("2006-01"), "first month (YYYY-MM)")
+		to = fs.String("to", today.Format("2006-01"), "last month (YYYY-MM)")
+	}
+	category := fs.String("category", "", "only this CategoryID")
+	tag := fs.String("tag", "", "only DONELOGs with this tag")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	var summary query.Summary
+	switch unit {
+	case "day":
+		summary, err = b.SummarizeByDay(ctx, query.SummarizeByDayQuery{CategoryID: *category, Tag: *tag, StartDate: *from, EndDate: *to})
+	case "week":
+		summary, err = b.SummarizeByWeek(ctx, query.SummarizeByWeekQuery{CategoryID: *category, Tag: *tag, StartDate: *from, EndDate: *to, WeekStart: *weekStart})
+	case "quarter":
+		summary, err = b.SummarizeByQuarter(ctx, query.SummarizeByQuarterQuery{CategoryID: *category, Tag: *tag, StartQuarter: *from, EndQuarter: *to})
+	case "year":
+		summary, err = b.SummarizeByYear(ctx, query.SummarizeByYearQuery{CategoryID: *category, Tag: *tag, StartYear: *from, EndYear: *to})
+	default:
+		summary, err = b.SummarizeByMonth(ctx, query.SummarizeByMonthQuery{CategoryID: *category, Tag: *tag, StartMonth: *from, EndMonth: *to})
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(e.stdout, summary)
+	}
+
+	peak := 0
+	for _, p := range summary.Points {
+		peak = max(peak, p.Count)
+	}
+	tw := newTable(e.stdout)
+	fmt.Fprintln(tw, strings.ToUpper(unit)+"\tCOUNT\t")
+	for _, p := range summary.Points {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", p.Label, p.Count, bar(p.Count, peak))
+	}
+	fmt.Fprintf(tw, "total\t%d\t\n", summary.TotalCount)
+	return tw.Flush()
+}
+
+// quarterOf formats the quarter containing t as YYYY-Qn.
+func quarterOf(t time.Time) string {
+	return fmt.Sprintf("%04d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// bar scales count against peak into a row of block characters.
+func bar(count, peak int) string {
+	if peak == 0 || count == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, count*maxBarWidth/peak))
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/taketosaeki/donelog/internal/interface/tui"
+)
+
+func runTUI(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: donelog tui")
+	}
+
+	b, err := e.backend()
+	if err != nil {
+		return err
+	}
+	return tui.Run(ctx, tui.NewApp(b, e.now), os.Stdin, e.stdout)
+}
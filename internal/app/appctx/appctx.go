@@ -0,0 +1,81 @@
+// Package appctx carries request-scoped metadata (actor, owner, principal, request ID) through context.Context.
+package appctx
+
+import (
+	"context"
+
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+type ctxKey int
+
+const (
+	actorKey ctxKey = iota
+	requestIDKey
+	ownerKey
+	principalKey
+)
+
+// WithActor returns a context that carries the acting user.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the acting user, or an empty string when unknown.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// WithOwner returns a context whose reads and writes are scoped to owner's data.
+func WithOwner(ctx context.Context, owner donelog.OwnerID) context.Context {
+	return context.WithValue(ctx, ownerKey, owner)
+}
+
+// Owner returns the data owner, or the zero OwnerID (the single-user owner) when unset.
+func Owner(ctx context.Context) donelog.OwnerID {
+	owner, _ := ctx.Value(ownerKey).(donelog.OwnerID)
+	return owner
+}
+
+// AuthMethod tells how a Principal proved its identity.
+type AuthMethod string
+
+const (
+	AuthMethodSession AuthMethod = "session"
+	AuthMethodToken   AuthMethod = "token"
+)
+
+// Principal is an authenticated account and what its credential allows.
+type Principal struct {
+	Owner  donelog.OwnerID
+	Scopes account.Scopes
+	Method AuthMethod
+	// TokenID is set when Method is AuthMethodToken.
+	TokenID string
+}
+
+// WithPrincipal returns a context authenticated as p. The principal also becomes
+// the actor and the data owner, so command handlers audit and scope changes to it.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	ctx = WithOwner(WithActor(ctx, p.Owner.String()), p.Owner)
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// Authenticated returns the principal, and false when the request is not authenticated.
+func Authenticated(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey).(Principal)
+	return p, ok
+}
+
+// WithRequestID returns a context that carries the request identifier.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request identifier, or an empty string when unknown.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,29 @@
+// Package apperr defines error kinds shared by the command and query sides.
+// Adapters map them to transport-level codes (e.g. HTTP 400/401/403/404/409).
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalid is wrapped when input fails validation.
+	ErrInvalid = errors.New("invalid input")
+	// ErrNotFound is wrapped when a referenced aggregate does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is wrapped when the request clashes with the current state.
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized is wrapped when the caller could not be authenticated.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is wrapped when the authenticated caller lacks permission.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Invalid marks err as a validation failure. A nil err stays nil.
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalid, err)
+}
@@ -0,0 +1,477 @@
+// Package auth signs users in with local accounts or OpenID Connect single sign-on
+// and authenticates their web sessions and API tokens.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// DefaultSessionTTL is how long a web session lasts when Service.SessionTTL is zero.
+const DefaultSessionTTL = 14 * 24 * time.Hour
+
+const (
+	saltBytes    = 16
+	secretBytes  = 32
+	tokenIDBytes = 8
+)
+
+// AccountRepository stores Accounts by username.
+type AccountRepository interface {
+	Save(ctx context.Context, acct *account.Account) error
+	// FindByUsername returns nil when the account does not exist.
+	FindByUsername(ctx context.Context, username donelog.OwnerID) (*account.RawAccount, error)
+}
+
+// SessionRepository stores web sessions by key.
+type SessionRepository interface {
+	Save(ctx context.Context, session *account.Session) error
+	// FindByKey returns nil when the session does not exist.
+	FindByKey(ctx context.Context, key string) (*account.RawSession, error)
+	Delete(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+	// DeleteByOwner ends every session of owner.
+	DeleteByOwner(ctx context.Context, owner donelog.OwnerID) error
+}
+
+// TokenRepository stores personal API tokens.
+type TokenRepository interface {
+	Save(ctx context.Context, token *account.Token) error
+	// FindByID returns nil when the token does not exist.
+	FindByID(ctx context.Context, id account.TokenID) (*account.RawToken, error)
+	// ListByOwner returns the owner's tokens, oldest first.
+	ListByOwner(ctx context.Context, owner donelog.OwnerID) ([]account.RawToken, error)
+	Delete(ctx context.Context, id account.TokenID) error
+}
+
+// TimeSource provides wall-clock timestamps.
+type TimeSource interface {
+	Now() time.Time
+}
+
+// Service manages accounts and authenticates credentials.
+type Service struct {
+	Accounts AccountRepository
+	Sessions SessionRepository
+	Tokens   TokenRepository
+	Time     TimeSource
+	// Random defaults to crypto/rand.Reader.
+	Random io.Reader
+	// SessionTTL defaults to DefaultSessionTTL.
+	SessionTTL time.Duration
+	// Iterations is the PBKDF2 work factor for new passwords; zero means account.DefaultIterations.
+	Iterations int
+
+	// Provider enables single sign-on when set; Identities, PendingLogins and Owners are required with it.
+	Provider      IdentityProvider
+	Identities    IdentityRepository
+	PendingLogins PendingLoginRepository
+	Owners        DataOwners
+}
+
+// Login is a started web session. Secret goes into the session cookie and is not stored.
+type Login struct {
+	Username  string    `json:"username"`
+	Secret    string    `json:"-"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TokenInfo describes a personal API token without its secret.
+type TokenInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IssuedToken is a newly issued token. Token is the plaintext, shown only this once.
+type IssuedToken struct {
+	TokenInfo
+	Token string `json:"token"`
+}
+
+var errBadCredentials = fmt.Errorf("invalid username or password: %w", apperr.ErrUnauthorized)
+
+// CreateAccount registers username with password. It fails with apperr.ErrConflict when the name is taken.
+func (s Service) CreateAccount(ctx context.Context, username, password string) error {
+	owner, err := donelog.NewOwnerID(username)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	existing, err := s.Accounts.FindByUsername(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("account %s: %w", username, apperr.ErrConflict)
+	}
+	if s.Identities != nil {
+		taken, err := s.Identities.UsernameTaken(ctx, owner)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("account %s is used by single sign-on: %w", username, apperr.ErrConflict)
+		}
+	}
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+	acct, err := account.NewAccount(owner, hash, s.Time.Now())
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	return s.Accounts.Save(ctx, acct)
+}
+
+// ChangePassword replaces the password of username after checking the current one.
+// Every session and API token of the account is revoked, so old credentials stop working.
+func (s Service) ChangePassword(ctx context.Context, username, current, next string) error {
+	change, err := s.verifyPasswordChange(ctx, username, current, next)
+	if err != nil {
+		return err
+	}
+	return s.ApplyPasswordChange(ctx, change)
+}
+
+// PasswordChange is a password change whose current password was checked and whose new one is hashed.
+// It stays valid only while the password is unchanged.
+type PasswordChange struct {
+	username     donelog.OwnerID
+	passwordHash string
+	next         account.PasswordHash
+}
+
+// VerifyPasswordChange checks the current password of the principal in ctx and hashes the new one
+// without writing anything, so callers can keep the slow key derivations out of their write transaction.
+func (s Service) VerifyPasswordChange(ctx context.Context, current, next string) (PasswordChange, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return PasswordChange{}, err
+	}
+	return s.verifyPasswordChange(ctx, p.Owner.String(), current, next)
+}
+
+func (s Service) verifyPasswordChange(ctx context.Context, username, current, next string) (PasswordChange, error) {
+	acct, err := s.verifyPassword(ctx, username, current)
+	if err != nil {
+		return PasswordChange{}, err
+	}
+	hash, err := s.hashPassword(next)
+	if err != nil {
+		return PasswordChange{}, err
+	}
+	return PasswordChange{username: acct.Username(), passwordHash: acct.Raw().PasswordHash, next: hash}, nil
+}
+
+// ApplyPasswordChange stores the new password and revokes every session and API token of the account.
+// It fails with apperr.ErrUnauthorized when the password changed, or the account went away, since the check.
+func (s Service) ApplyPasswordChange(ctx context.Context, c PasswordChange) error {
+	raw, err := s.Accounts.FindByUsername(ctx, c.username)
+	if err != nil {
+		return err
+	}
+	if raw == nil || raw.PasswordHash != c.passwordHash {
+		return errBadCredentials
+	}
+	acct, err := account.RehydrateAccount(*raw)
+	if err != nil {
+		return err
+	}
+	if err := acct.ChangePassword(c.next); err != nil {
+		return apperr.Invalid(err)
+	}
+	if err := s.Accounts.Save(ctx, acct); err != nil {
+		return err
+	}
+	if err := s.Sessions.DeleteByOwner(ctx, acct.Username()); err != nil {
+		return err
+	}
+	tokens, err := s.Tokens.ListByOwner(ctx, acct.Username())
+	if err != nil {
+		return err
+	}
+	for _, raw := range tokens {
+		id, err := account.NewTokenID(raw.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.Tokens.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Login checks the password and starts a web session.
+// Unknown usernames and wrong passwords fail alike, with apperr.ErrUnauthorized.
+func (s Service) Login(ctx context.Context, username, password string) (Login, error) {
+	verified, err := s.VerifyLogin(ctx, username, password)
+	if err != nil {
+		return Login{}, err
+	}
+	return s.StartSession(ctx, verified)
+}
+
+// VerifiedLogin is a password check that passed. It stays valid only while the password is unchanged.
+type VerifiedLogin struct {
+	username     donelog.OwnerID
+	passwordHash string
+}
+
+// VerifyLogin checks the password without writing anything, so callers can keep the slow
+// key derivation out of their write transaction and start the session afterwards with StartSession.
+func (s Service) VerifyLogin(ctx context.Context, username, password string) (VerifiedLogin, error) {
+	acct, err := s.verifyPassword(ctx, username, password)
+	if err != nil {
+		return VerifiedLogin{}, err
+	}
+	return VerifiedLogin{username: acct.Username(), passwordHash: acct.Raw().PasswordHash}, nil
+}
+
+// StartSession starts a web session for a verified login. It fails with apperr.ErrUnauthorized
+// when the account's password changed, or the account went away, since the check.
+func (s Service) StartSession(ctx context.Context, v VerifiedLogin) (Login, error) {
+	raw, err := s.Accounts.FindByUsername(ctx, v.username)
+	if err != nil {
+		return Login{}, err
+	}
+	if raw == nil || raw.PasswordHash != v.passwordHash {
+		return Login{}, errBadCredentials
+	}
+	return s.startSession(ctx, v.username)
+}
+
+// startSession creates a web session for username, dropping expired ones on the way.
+func (s Service) startSession(ctx context.Context, username donelog.OwnerID) (Login, error) {
+	now := s.Time.Now()
+	if err := s.Sessions.DeleteExpired(ctx, now); err != nil {
+		return Login{}, err
+	}
+	secret, err := s.randomHex(secretBytes)
+	if err != nil {
+		return Login{}, err
+	}
+	session, err := account.NewSession(secret, username, now, s.sessionTTL())
+	if err != nil {
+		return Login{}, err
+	}
+	if err := s.Sessions.Save(ctx, session); err != nil {
+		return Login{}, err
+	}
+	return Login{Username: username.String(), Secret: secret, ExpiresAt: session.ExpiresAt()}, nil
+}
+
+// Logout ends the session whose cookie holds secret. Unknown sessions are ignored.
+func (s Service) Logout(ctx context.Context, secret string) error {
+	return s.Sessions.Delete(ctx, account.SessionKey(secret))
+}
+
+// AuthenticateSession resolves a session cookie to a principal with every scope.
+func (s Service) AuthenticateSession(ctx context.Context, secret string) (appctx.Principal, error) {
+	key := account.SessionKey(secret)
+	raw, err := s.Sessions.FindByKey(ctx, key)
+	if err != nil {
+		return appctx.Principal{}, err
+	}
+	if raw == nil {
+		return appctx.Principal{}, fmt.Errorf("session is invalid or expired: %w", apperr.ErrUnauthorized)
+	}
+	session, err := account.RehydrateSession(*raw)
+	if err != nil {
+		return appctx.Principal{}, err
+	}
+	if session.Expired(s.Time.Now()) {
+		if err := s.Sessions.Delete(ctx, key); err != nil {
+			return appctx.Principal{}, err
+		}
+		return appctx.Principal{}, fmt.Errorf("session is invalid or expired: %w", apperr.ErrUnauthorized)
+	}
+	return appctx.Principal{Owner: session.Owner(), Scopes: account.AllScopes(), Method: appctx.AuthMethodSession}, nil
+}
+
+// AuthenticateToken resolves a plaintext API token to a principal limited to the token's scopes.
+func (s Service) AuthenticateToken(ctx context.Context, plaintext string) (appctx.Principal, error) {
+	invalid := fmt.Errorf("API token is invalid or revoked: %w", apperr.ErrUnauthorized)
+	id, secret, err := account.ParseToken(plaintext)
+	if err != nil {
+		return appctx.Principal{}, invalid
+	}
+	raw, err := s.Tokens.FindByID(ctx, id)
+	if err != nil {
+		return appctx.Principal{}, err
+	}
+	if raw == nil {
+		return appctx.Principal{}, invalid
+	}
+	token, err := account.RehydrateToken(*raw)
+	if err != nil {
+		return appctx.Principal{}, err
+	}
+	if !token.Verify(secret) {
+		return appctx.Principal{}, invalid
+	}
+	return appctx.Principal{Owner: token.Owner(), Scopes: token.Scopes(), Method: appctx.AuthMethodToken, TokenID: id.String()}, nil
+}
+
+// IssueToken creates a personal API token for the principal in ctx.
+// The scopes must be ones the caller holds, so a read-only token cannot mint a writable one.
+func (s Service) IssueToken(ctx context.Context, name string, scopes []string) (IssuedToken, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	requested, err := account.ParseScopes(scopes)
+	if err != nil {
+		return IssuedToken{}, apperr.Invalid(err)
+	}
+	if !p.Scopes.Covers(requested) {
+		return IssuedToken{}, fmt.Errorf("cannot grant scopes %v beyond your own %v: %w", requested.Strings(), p.Scopes.Strings(), apperr.ErrForbidden)
+	}
+	rawID, err := s.randomHex(tokenIDBytes)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	id, err := account.NewTokenID(rawID)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	secret, err := s.randomHex(secretBytes)
+	if err != nil {
+		return IssuedToken{}, err
+	}
+	token, err := account.NewToken(id, p.Owner, name, requested, secret, s.Time.Now())
+	if err != nil {
+		return IssuedToken{}, apperr.Invalid(err)
+	}
+	if err := s.Tokens.Save(ctx, token); err != nil {
+		return IssuedToken{}, err
+	}
+	return IssuedToken{TokenInfo: newTokenInfo(token.Raw()), Token: account.FormatToken(id, secret)}, nil
+}
+
+// ListTokens returns the tokens of the principal in ctx, oldest first.
+func (s Service) ListTokens(ctx context.Context) ([]TokenInfo, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raws, err := s.Tokens.ListByOwner(ctx, p.Owner)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]TokenInfo, 0, len(raws))
+	for _, raw := range raws {
+		items = append(items, newTokenInfo(raw))
+	}
+	return items, nil
+}
+
+// RevokeToken deletes one of the principal's tokens. Tokens of other accounts are reported as not found.
+func (s Service) RevokeToken(ctx context.Context, tokenID string) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := account.NewTokenID(tokenID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	raw, err := s.Tokens.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if raw == nil || raw.Owner != p.Owner.String() {
+		return fmt.Errorf("token %s: %w", tokenID, apperr.ErrNotFound)
+	}
+	return s.Tokens.Delete(ctx, id)
+}
+
+// verifyPassword loads username and checks password, failing alike for unknown users and wrong passwords.
+func (s Service) verifyPassword(ctx context.Context, username, password string) (*account.Account, error) {
+	owner, err := donelog.NewOwnerID(username)
+	if err != nil {
+		account.MatchNothing(password, s.iterations())
+		return nil, errBadCredentials
+	}
+	raw, err := s.Accounts.FindByUsername(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		account.MatchNothing(password, s.iterations())
+		return nil, errBadCredentials
+	}
+	acct, err := account.RehydrateAccount(*raw)
+	if err != nil {
+		return nil, err
+	}
+	if !acct.Authenticate(password) {
+		return nil, errBadCredentials
+	}
+	return acct, nil
+}
+
+func (s Service) hashPassword(password string) (account.PasswordHash, error) {
+	salt := make([]byte, saltBytes)
+	if _, err := io.ReadFull(s.random(), salt); err != nil {
+		return account.PasswordHash{}, err
+	}
+	hash, err := account.HashPassword(password, salt, s.iterations())
+	if err != nil {
+		return account.PasswordHash{}, apperr.Invalid(err)
+	}
+	return hash, nil
+}
+
+func (s Service) randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(s.random(), b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s Service) random() io.Reader {
+	if s.Random == nil {
+		return rand.Reader
+	}
+	return s.Random
+}
+
+func (s Service) iterations() int {
+	if s.Iterations == 0 {
+		return account.DefaultIterations
+	}
+	return s.Iterations
+}
+
+func (s Service) sessionTTL() time.Duration {
+	if s.SessionTTL == 0 {
+		return DefaultSessionTTL
+	}
+	return s.SessionTTL
+}
+
+// principal returns the authenticated principal in ctx, or apperr.ErrUnauthorized.
+func principal(ctx context.Context) (appctx.Principal, error) {
+	p, ok := appctx.Authenticated(ctx)
+	if !ok {
+		return appctx.Principal{}, fmt.Errorf("sign in first: %w", apperr.ErrUnauthorized)
+	}
+	return p, nil
+}
+
+func newTokenInfo(raw account.RawToken) TokenInfo {
+	return TokenInfo{ID: raw.ID, Name: raw.Name, Scopes: raw.Scopes, CreatedAt: raw.CreatedAt}
+}
@@ -0,0 +1,419 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+type memAccounts map[string]account.RawAccount
+
+func (m memAccounts) Save(ctx context.Context, acct *account.Account) error {
+	m[acct.Username().String()] = acct.Raw()
+	return nil
+}
+func (m memAccounts) FindByUsername(ctx context.Context, username donelog.OwnerID) (*account.RawAccount, error) {
+	if raw, ok := m[username.String()]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+
+type memSessions map[string]account.RawSession
+
+func (m memSessions) Save(ctx context.Context, session *account.Session) error {
+	m[session.Key()] = session.Raw()
+	return nil
+}
+func (m memSessions) FindByKey(ctx context.Context, key string) (*account.RawSession, error) {
+	if raw, ok := m[key]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+func (m memSessions) Delete(ctx context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+func (m memSessions) DeleteExpired(ctx context.Context, now time.Time) error {
+	for key, raw := range m {
+		if !now.Before(raw.ExpiresAt) {
+			delete(m, key)
+		}
+	}
+	return nil
+}
+
+func (m memSessions) DeleteByOwner(ctx context.Context, owner donelog.OwnerID) error {
+	for key, raw := range m {
+		if raw.Owner == owner.String() {
+			delete(m, key)
+		}
+	}
+	return nil
+}
+
+type memTokens map[string]account.RawToken
+
+func (m memTokens) Save(ctx context.Context, token *account.Token) error {
+	m[token.ID().String()] = token.Raw()
+	return nil
+}
+func (m memTokens) FindByID(ctx context.Context, id account.TokenID) (*account.RawToken, error) {
+	if raw, ok := m[id.String()]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+func (m memTokens) ListByOwner(ctx context.Context, owner donelog.OwnerID) ([]account.RawToken, error) {
+	var tokens []account.RawToken
+	for _, raw := range m {
+		if raw.Owner == owner.String() {
+			tokens = append(tokens, raw)
+		}
+	}
+	return tokens, nil
+}
+func (m memTokens) Delete(ctx context.Context, id account.TokenID) error {
+	delete(m, id.String())
+	return nil
+}
+
+// memOwners lists the owners that already have data.
+type memOwners map[string]bool
+
+func (m memOwners) HasData(ctx context.Context, owner donelog.OwnerID) (bool, error) {
+	return m[owner.String()], nil
+}
+
+type memIdentities map[string]account.RawIdentity
+
+func (m memIdentities) Save(ctx context.Context, identity *account.Identity) error {
+	raw := identity.Raw()
+	m[account.IdentityKey(raw.Issuer, raw.Subject)] = raw
+	return nil
+}
+func (m memIdentities) FindBySubject(ctx context.Context, issuer, subject string) (*account.RawIdentity, error) {
+	if raw, ok := m[account.IdentityKey(issuer, subject)]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+func (m memIdentities) UsernameTaken(ctx context.Context, username donelog.OwnerID) (bool, error) {
+	for _, raw := range m {
+		if raw.Username == username.String() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type memPendingLogins map[string]account.RawPendingLogin
+
+func (m memPendingLogins) Save(ctx context.Context, login *account.PendingLogin) error {
+	raw := login.Raw()
+	m[raw.Key] = raw
+	return nil
+}
+func (m memPendingLogins) Take(ctx context.Context, key string) (*account.RawPendingLogin, error) {
+	raw, ok := m[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(m, key)
+	return &raw, nil
+}
+func (m memPendingLogins) DeleteExpired(ctx context.Context, now time.Time) error {
+	for key, raw := range m {
+		if !now.Before(raw.ExpiresAt) {
+			delete(m, key)
+		}
+	}
+	return nil
+}
+
+// fakeProvider answers every code with claims, echoing the nonce of the last authorization request
+// unless nonce is set.
+type fakeProvider struct {
+	claims    IDClaims
+	nonce     string
+	lastNonce string
+	challenge string
+	err       error
+}
+
+func (p *fakeProvider) Issuer() string { return "https://idp.example" }
+func (p *fakeProvider) AuthCodeURL(state, nonce, challenge string) string {
+	p.lastNonce, p.challenge = nonce, challenge
+	return "https://idp.example/authorize?state=" + state
+}
+func (p *fakeProvider) Exchange(ctx context.Context, code, verifier string) (IDClaims, error) {
+	if p.err != nil {
+		return IDClaims{}, p.err
+	}
+	if codeChallenge(verifier) != p.challenge {
+		return IDClaims{}, errors.New("PKCE verification failed")
+	}
+	claims := p.claims
+	claims.Issuer, claims.Nonce = p.Issuer(), p.lastNonce
+	if p.nonce != "" {
+		claims.Nonce = p.nonce
+	}
+	return claims, nil
+}
+
+type movableClock struct{ now time.Time }
+
+func (c *movableClock) Now() time.Time { return c.now }
+
+func newTestService() (Service, *movableClock) {
+	clock := &movableClock{now: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}
+	return Service{
+		Accounts:      memAccounts{},
+		Sessions:      memSessions{},
+		Tokens:        memTokens{},
+		Time:          clock,
+		SessionTTL:    time.Hour,
+		Iterations:    10,
+		Identities:    memIdentities{},
+		PendingLogins: memPendingLogins{},
+		Owners:        memOwners{},
+	}, clock
+}
+
+func TestAccountsAndSessions(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestService()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{"OK: new account", "alice", "correct horse", nil},
+		{"NG: taken", "alice", "another horse", apperr.ErrConflict},
+		{"NG: weak password", "bob", "short", apperr.ErrInvalid},
+		{"NG: bad username", "bob smith", "correct horse", apperr.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.CreateAccount(ctx, tt.username, tt.password)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, bad := range [][2]string{{"alice", "wrong horse"}, {"nobody", "correct horse"}, {"", ""}} {
+		if _, err := s.Login(ctx, bad[0], bad[1]); !errors.Is(err, apperr.ErrUnauthorized) || err.Error() != errBadCredentials.Error() {
+			t.Fatalf("Login(%q) = %v, want the same unauthorized error", bad[0], err)
+		}
+	}
+
+	login, err := s.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	p, err := s.AuthenticateSession(ctx, login.Secret)
+	if err != nil || p.Owner.String() != "alice" || p.Method != appctx.AuthMethodSession || !p.Scopes.Covers(account.AllScopes()) {
+		t.Fatalf("AuthenticateSession = %+v, %v", p, err)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	if _, err := s.AuthenticateSession(ctx, login.Secret); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("expired session accepted: %v", err)
+	}
+	if len(s.Sessions.(memSessions)) != 0 {
+		t.Fatal("expired session should be deleted")
+	}
+
+	login, _ = s.Login(ctx, "alice", "correct horse")
+	if err := s.Logout(ctx, login.Secret); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := s.AuthenticateSession(ctx, login.Secret); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("session survived logout: %v", err)
+	}
+
+	// Changing the password ends every session and token, and voids checks made before it.
+	login, _ = s.Login(ctx, "alice", "correct horse")
+	alice, _ := donelog.NewOwnerID("alice")
+	issued, err := s.IssueToken(appctx.WithPrincipal(ctx, appctx.Principal{Owner: alice, Scopes: account.AllScopes()}), "cron", []string{"read"})
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	stale, err := s.VerifyLogin(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("VerifyLogin: %v", err)
+	}
+	if err := s.ChangePassword(ctx, "alice", "correct horse", "battery staple"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if _, err := s.AuthenticateSession(ctx, login.Secret); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("session survived a password change: %v", err)
+	}
+	if _, err := s.AuthenticateToken(ctx, issued.Token); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("token survived a password change: %v", err)
+	}
+	if _, err := s.StartSession(ctx, stale); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("session started from a check made before the password change: %v", err)
+	}
+	if _, err := s.Login(ctx, "alice", "battery staple"); err != nil {
+		t.Fatalf("Login with new password: %v", err)
+	}
+
+	// Signed-in callers change their own password, and only with the current one.
+	asAlice := appctx.WithPrincipal(ctx, appctx.Principal{Owner: alice, Scopes: account.AllScopes()})
+	if _, err := s.VerifyPasswordChange(ctx, "battery staple", "hunter2hunter2"); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("anonymous password change: %v", err)
+	}
+	if _, err := s.VerifyPasswordChange(asAlice, "correct horse", "hunter2hunter2"); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("password change with a wrong current password: %v", err)
+	}
+	if _, err := s.VerifyPasswordChange(asAlice, "battery staple", "short"); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("password change to a weak password: %v", err)
+	}
+	change, err := s.VerifyPasswordChange(asAlice, "battery staple", "hunter2hunter2")
+	if err != nil {
+		t.Fatalf("VerifyPasswordChange: %v", err)
+	}
+	staleChange, _ := s.VerifyPasswordChange(asAlice, "battery staple", "other password")
+	if err := s.ApplyPasswordChange(asAlice, change); err != nil {
+		t.Fatalf("ApplyPasswordChange: %v", err)
+	}
+	if err := s.ApplyPasswordChange(asAlice, staleChange); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("change applied after another one: %v", err)
+	}
+	if _, err := s.Login(ctx, "alice", "hunter2hunter2"); err != nil {
+		t.Fatalf("Login with changed password: %v", err)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	s, _ := newTestService()
+	alice, _ := donelog.NewOwnerID("alice")
+	bob, _ := donelog.NewOwnerID("bob")
+	asAlice := appctx.WithPrincipal(context.Background(), appctx.Principal{Owner: alice, Scopes: account.AllScopes()})
+	asBob := appctx.WithPrincipal(context.Background(), appctx.Principal{Owner: bob, Scopes: account.AllScopes()})
+
+	if _, err := s.IssueToken(context.Background(), "cron", []string{"read"}); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("anonymous issue: %v", err)
+	}
+	if _, err := s.IssueToken(asAlice, "cron", []string{"admin"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("unknown scope: %v", err)
+	}
+
+	issued, err := s.IssueToken(asAlice, "cron", []string{"read"})
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	p, err := s.AuthenticateToken(context.Background(), issued.Token)
+	if err != nil || p.Owner != alice || p.Method != appctx.AuthMethodToken || p.Scopes.Has(account.ScopeWrite) || p.TokenID != issued.ID {
+		t.Fatalf("AuthenticateToken = %+v, %v", p, err)
+	}
+	tampered := []byte(issued.Token)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := s.AuthenticateToken(context.Background(), string(tampered)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("tampered token accepted: %v", err)
+	}
+
+	// A read-only token cannot mint a writable one.
+	asToken := appctx.WithPrincipal(context.Background(), p)
+	if _, err := s.IssueToken(asToken, "escalate", []string{"read", "write"}); !errors.Is(err, apperr.ErrForbidden) {
+		t.Fatalf("escalation: %v", err)
+	}
+
+	if items, err := s.ListTokens(asBob); err != nil || len(items) != 0 {
+		t.Fatalf("bob's tokens = %+v, %v", items, err)
+	}
+	if err := s.RevokeToken(asBob, issued.ID); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("bob revoked alice's token: %v", err)
+	}
+	if err := s.RevokeToken(asAlice, issued.ID); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if _, err := s.AuthenticateToken(context.Background(), issued.Token); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("revoked token accepted: %v", err)
+	}
+}
+
+func TestSingleSignOn(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestService()
+	if _, err := s.BeginSSO(ctx); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("BeginSSO without a provider: %v", err)
+	}
+	provider := &fakeProvider{}
+	s.Provider = provider
+	if err := s.CreateAccount(ctx, "bob", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	s.Owners = memOwners{"carol": true}
+
+	tests := []struct {
+		name    string
+		claims  IDClaims
+		prepare func(state string) string
+		nonce   string
+		err     error
+		want    string
+		wantErr error
+	}{
+		{name: "OK: preferred_username", claims: IDClaims{Subject: "1", PreferredUsername: "alice"}, want: "alice"},
+		{name: "OK: known subject", claims: IDClaims{Subject: "1", PreferredUsername: "other"}, want: "alice"},
+		{name: "OK: email when the name is taken", claims: IDClaims{Subject: "2", PreferredUsername: "bob", Email: "bob@example.com"}, want: "bob@example.com"},
+		{name: "OK: email when the name already owns data", claims: IDClaims{Subject: "4", PreferredUsername: "carol", Email: "carol@example.com"}, want: "carol@example.com"},
+		{name: "NG: unknown state", claims: IDClaims{Subject: "3"}, prepare: func(string) string { return "forged" }, wantErr: apperr.ErrUnauthorized},
+		{
+			name:   "NG: expired state",
+			claims: IDClaims{Subject: "3"},
+			prepare: func(state string) string {
+				clock.now = clock.now.Add(PendingLoginTTL)
+				return state
+			},
+			wantErr: apperr.ErrUnauthorized,
+		},
+		{name: "NG: nonce mismatch", claims: IDClaims{Subject: "3"}, nonce: "replayed", wantErr: apperr.ErrUnauthorized},
+		{name: "NG: provider rejects the code", claims: IDClaims{Subject: "3"}, err: errors.New("invalid_grant"), wantErr: apperr.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.claims, provider.nonce, provider.err = tt.claims, tt.nonce, tt.err
+			redirect, err := s.BeginSSO(ctx)
+			if err != nil {
+				t.Fatalf("BeginSSO: %v", err)
+			}
+			state := redirect.State
+			if tt.prepare != nil {
+				state = tt.prepare(state)
+			}
+			login, err := s.CompleteSSO(ctx, state, "code")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompleteSSO: %v", err)
+			}
+			if login.Username != tt.want {
+				t.Fatalf("username = %q, want %q", login.Username, tt.want)
+			}
+			if _, err := s.CompleteSSO(ctx, redirect.State, "code"); !errors.Is(err, apperr.ErrUnauthorized) {
+				t.Fatalf("a state must complete only once, got %v", err)
+			}
+		})
+	}
+
+	if err := s.CreateAccount(ctx, "alice", "correct horse"); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("a local account must not reuse an SSO username, got %v", err)
+	}
+}
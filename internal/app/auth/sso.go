@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// PendingLoginTTL is how long the user has to finish signing in at the identity provider.
+const PendingLoginTTL = 10 * time.Minute
+
+// IdentityProvider is an OpenID Connect provider using the authorization code flow with PKCE (S256).
+type IdentityProvider interface {
+	// Issuer returns the provider's issuer identifier.
+	Issuer() string
+	// AuthCodeURL returns the authorization endpoint URL the browser is sent to.
+	AuthCodeURL(state, nonce, codeChallenge string) string
+	// Exchange redeems code with the PKCE verifier and returns the claims of the verified ID token.
+	Exchange(ctx context.Context, code, codeVerifier string) (IDClaims, error)
+}
+
+// IDClaims are the ID token claims single sign-on uses. The provider has already checked
+// the signature, issuer, audience and expiry; the nonce is checked against the pending login.
+type IDClaims struct {
+	Issuer            string
+	Subject           string
+	Nonce             string
+	PreferredUsername string
+	Email             string
+}
+
+// IdentityRepository stores links from provider subjects to usernames.
+type IdentityRepository interface {
+	Save(ctx context.Context, identity *account.Identity) error
+	// FindBySubject returns nil when the subject has not signed in before.
+	FindBySubject(ctx context.Context, issuer, subject string) (*account.RawIdentity, error)
+	// UsernameTaken reports whether any identity already maps to username.
+	UsernameTaken(ctx context.Context, username donelog.OwnerID) (bool, error)
+}
+
+// DataOwners tells which usernames already own data, for example DONELOGs recorded under
+// X-Actor before that user could sign in. Single sign-on never hands such a name to a new subject.
+type DataOwners interface {
+	HasData(ctx context.Context, owner donelog.OwnerID) (bool, error)
+}
+
+// PendingLoginRepository stores authorization code flows until the callback arrives.
+type PendingLoginRepository interface {
+	Save(ctx context.Context, login *account.PendingLogin) error
+	// Take returns and deletes the flow, or nil when it does not exist.
+	Take(ctx context.Context, key string) (*account.RawPendingLogin, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// SSORedirect starts single sign-on: the browser is sent to URL, and State is bound to it by a cookie.
+type SSORedirect struct {
+	URL   string
+	State string
+}
+
+var errSSONotConfigured = fmt.Errorf("single sign-on is not configured: %w", apperr.ErrNotFound)
+
+// BeginSSO starts an authorization code flow with a fresh state, nonce and PKCE verifier.
+func (s Service) BeginSSO(ctx context.Context) (SSORedirect, error) {
+	if s.Provider == nil {
+		return SSORedirect{}, errSSONotConfigured
+	}
+	now := s.Time.Now()
+	if err := s.PendingLogins.DeleteExpired(ctx, now); err != nil {
+		return SSORedirect{}, err
+	}
+	var values [3]string
+	for i := range values {
+		v, err := s.randomHex(secretBytes)
+		if err != nil {
+			return SSORedirect{}, err
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+	pending, err := account.NewPendingLogin(state, verifier, nonce, now, PendingLoginTTL)
+	if err != nil {
+		return SSORedirect{}, err
+	}
+	if err := s.PendingLogins.Save(ctx, pending); err != nil {
+		return SSORedirect{}, err
+	}
+	return SSORedirect{URL: s.Provider.AuthCodeURL(state, nonce, codeChallenge(verifier)), State: state}, nil
+}
+
+// CompleteSSO finishes the flow started with state, links the subject to a username on first sign-in
+// and starts a web session. Unknown, reused or expired states fail with apperr.ErrUnauthorized.
+func (s Service) CompleteSSO(ctx context.Context, state, code string) (Login, error) {
+	verified, err := s.VerifySSO(ctx, state, code)
+	if err != nil {
+		return Login{}, err
+	}
+	return s.FinishSSO(ctx, verified)
+}
+
+// VerifiedSSO holds ID token claims that passed VerifySSO.
+type VerifiedSSO struct {
+	claims IDClaims
+}
+
+// VerifySSO consumes the flow started with state and redeems code at the identity provider.
+// It waits on the provider over the network, so callers run it outside their write transaction
+// and pass the result to FinishSSO inside one.
+func (s Service) VerifySSO(ctx context.Context, state, code string) (VerifiedSSO, error) {
+	if s.Provider == nil {
+		return VerifiedSSO{}, errSSONotConfigured
+	}
+	invalid := fmt.Errorf("sign-in request is invalid or expired: %w", apperr.ErrUnauthorized)
+	raw, err := s.PendingLogins.Take(ctx, account.PendingLoginKey(state))
+	if err != nil {
+		return VerifiedSSO{}, err
+	}
+	if raw == nil {
+		return VerifiedSSO{}, invalid
+	}
+	pending, err := account.RehydratePendingLogin(*raw)
+	if err != nil {
+		return VerifiedSSO{}, err
+	}
+	if pending.Expired(s.Time.Now()) {
+		return VerifiedSSO{}, invalid
+	}
+
+	claims, err := s.Provider.Exchange(ctx, code, pending.CodeVerifier())
+	if err != nil {
+		return VerifiedSSO{}, fmt.Errorf("identity provider: %w: %w", apperr.ErrUnauthorized, err)
+	}
+	if !pending.MatchesNonce(claims.Nonce) {
+		return VerifiedSSO{}, fmt.Errorf("ID token nonce does not match: %w", apperr.ErrUnauthorized)
+	}
+	return VerifiedSSO{claims: claims}, nil
+}
+
+// FinishSSO links a verified subject to a username on first sign-in and starts a web session.
+func (s Service) FinishSSO(ctx context.Context, v VerifiedSSO) (Login, error) {
+	if s.Provider == nil {
+		return Login{}, errSSONotConfigured
+	}
+	username, err := s.linkIdentity(ctx, v.claims)
+	if err != nil {
+		return Login{}, err
+	}
+	return s.startSession(ctx, username)
+}
+
+// linkIdentity returns the username of a known subject, or maps a new one to the first free
+// candidate: preferred_username, then email, then a name derived from the subject.
+// A name already used by a local account, another subject or existing data is never taken over.
+func (s Service) linkIdentity(ctx context.Context, claims IDClaims) (donelog.OwnerID, error) {
+	issuer := s.Provider.Issuer()
+	raw, err := s.Identities.FindBySubject(ctx, issuer, claims.Subject)
+	if err != nil {
+		return donelog.OwnerID{}, err
+	}
+	if raw != nil {
+		identity, err := account.RehydrateIdentity(*raw)
+		if err != nil {
+			return donelog.OwnerID{}, err
+		}
+		return identity.Username(), nil
+	}
+
+	sum := sha256.Sum256([]byte(account.IdentityKey(issuer, claims.Subject)))
+	for _, candidate := range []string{claims.PreferredUsername, claims.Email, "sso-" + hex.EncodeToString(sum[:6])} {
+		username, err := donelog.NewOwnerID(candidate)
+		if err != nil {
+			continue
+		}
+		free, err := s.usernameFree(ctx, username)
+		if err != nil {
+			return donelog.OwnerID{}, err
+		}
+		if !free {
+			continue
+		}
+		identity, err := account.NewIdentity(issuer, claims.Subject, username, s.Time.Now())
+		if err != nil {
+			return donelog.OwnerID{}, apperr.Invalid(err)
+		}
+		if err := s.Identities.Save(ctx, identity); err != nil {
+			return donelog.OwnerID{}, err
+		}
+		return username, nil
+	}
+	return donelog.OwnerID{}, fmt.Errorf("no free username for subject %s: %w", claims.Subject, apperr.ErrConflict)
+}
+
+func (s Service) usernameFree(ctx context.Context, username donelog.OwnerID) (bool, error) {
+	acct, err := s.Accounts.FindByUsername(ctx, username)
+	if err != nil || acct != nil {
+		return false, err
+	}
+	taken, err := s.Identities.UsernameTaken(ctx, username)
+	if err != nil || taken {
+		return false, err
+	}
+	owns, err := s.Owners.HasData(ctx, username)
+	return !owns, err
+}
+
+// codeChallenge derives the PKCE S256 challenge from verifier (RFC 7636).
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
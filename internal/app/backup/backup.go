@@ -0,0 +1,492 @@
+// Package backup writes and restores the whole DONELOG dataset as one versioned archive.
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Dataset is everything that survives a backup: aggregates, history, settings and the sign-ins that own them.
+// Undo journals, idempotency keys, sessions and API tokens are not included.
+type Dataset struct {
+	DoneLogs   []donelog.RawDoneLog
+	Tracks     []donelog.RawTrack
+	Categories []donelog.RawCategory
+	Goals      []donelog.RawGoal
+	Teams      []donelog.RawTeam
+	Audit      []donelog.AuditEntry
+	Settings   map[string]string
+	Accounts   []account.RawAccount
+	Identities []account.RawIdentity
+	// SignIns is false for archives older than version 9, which carry no accounts or identities;
+	// Replace then keeps the stored ones.
+	SignIns bool
+}
+
+// DatasetStore is implemented by storage backends that can be backed up.
+type DatasetStore interface {
+	Snapshot(ctx context.Context) (Dataset, error)
+	// Replace swaps the whole dataset in one step.
+	Replace(ctx context.Context, data Dataset) error
+}
+
+// TimeSource provides the archive timestamp.
+type TimeSource interface {
+	Now() time.Time
+}
+
+// Summary reports what an archive contains.
+type Summary struct {
+	SchemaVersion int
+	CreatedAt     time.Time
+	DoneLogs      int
+	Tracks        int
+	Categories    int
+	Goals         int
+	Teams         int
+	AuditEntries  int
+	Accounts      int
+	Identities    int
+}
+
+// Service creates and restores backups.
+type Service struct {
+	Store DatasetStore
+	Time  TimeSource
+}
+
+// Backup writes a zip archive of the current dataset to w.
+func (s Service) Backup(ctx context.Context, w io.Writer) (Summary, error) {
+	data, err := s.Store.Snapshot(ctx)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	doneLogs := make([]doneLogRecord, 0, len(data.DoneLogs))
+	for _, raw := range data.DoneLogs {
+		doneLogs = append(doneLogs, toDoneLogRecord(raw))
+	}
+	tracks := make([]trackRecord, 0, len(data.Tracks))
+	for _, raw := range data.Tracks {
+		tracks = append(tracks, toTrackRecord(raw))
+	}
+	categories := make([]categoryRecord, 0, len(data.Categories))
+	for _, raw := range data.Categories {
+		categories = append(categories, toCategoryRecord(raw))
+	}
+	goals := make([]goalRecord, 0, len(data.Goals))
+	for _, raw := range data.Goals {
+		goals = append(goals, toGoalRecord(raw))
+	}
+	teams := make([]teamRecord, 0, len(data.Teams))
+	for _, raw := range data.Teams {
+		teams = append(teams, toTeamRecord(raw))
+	}
+	accounts := make([]accountRecord, 0, len(data.Accounts))
+	for _, raw := range data.Accounts {
+		accounts = append(accounts, toAccountRecord(raw))
+	}
+	identities := make([]identityRecord, 0, len(data.Identities))
+	for _, raw := range data.Identities {
+		identities = append(identities, toIdentityRecord(raw))
+	}
+	audit := make([]auditRecord, 0, len(data.Audit))
+	for _, entry := range data.Audit {
+		audit = append(audit, toAuditRecord(entry))
+	}
+	settings := data.Settings
+	if settings == nil {
+		settings = map[string]string{}
+	}
+
+	m := manifest{SchemaVersion: SchemaVersion, CreatedAt: s.Time.Now().UTC()}
+	zw := zip.NewWriter(w)
+	for _, part := range []struct {
+		name  string
+		body  any
+		count int
+	}{
+		{doneLogsFile, doneLogs, len(doneLogs)},
+		{tracksFile, tracks, len(tracks)},
+		{categoriesFile, categories, len(categories)},
+		{goalsFile, goals, len(goals)},
+		{teamsFile, teams, len(teams)},
+		{accountsFile, accounts, len(accounts)},
+		{identitiesFile, identities, len(identities)},
+		{auditFile, audit, len(audit)},
+		{settingsFile, settings, len(settings)},
+	} {
+		b, err := json.MarshalIndent(part.body, "", "  ")
+		if err != nil {
+			return Summary{}, err
+		}
+		if err := writeZipFile(zw, part.name, b); err != nil {
+			return Summary{}, err
+		}
+		m.Files = append(m.Files, manifestEntry{Name: part.name, SHA256: checksum(b), Count: part.count})
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Summary{}, err
+	}
+	if err := writeZipFile(zw, manifestFile, b); err != nil {
+		return Summary{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return Summary{}, err
+	}
+	return summarize(m, data), nil
+}
+
+// Verify decodes and validates an archive without touching the store.
+func (s Service) Verify(ctx context.Context, archive []byte) (Summary, error) {
+	m, data, err := decode(archive)
+	if err != nil {
+		return Summary{}, err
+	}
+	return summarize(m, data), nil
+}
+
+// Restore validates the archive completely and only then replaces the stored dataset.
+func (s Service) Restore(ctx context.Context, archive []byte) (Summary, error) {
+	m, data, err := decode(archive)
+	if err != nil {
+		return Summary{}, err
+	}
+	if err := s.Store.Replace(ctx, data); err != nil {
+		return Summary{}, err
+	}
+	return summarize(m, data), nil
+}
+
+func summarize(m manifest, data Dataset) Summary {
+	return Summary{
+		SchemaVersion: m.SchemaVersion,
+		CreatedAt:     m.CreatedAt,
+		DoneLogs:      len(data.DoneLogs),
+		Tracks:        len(data.Tracks),
+		Categories:    len(data.Categories),
+		Goals:         len(data.Goals),
+		Teams:         len(data.Teams),
+		AuditEntries:  len(data.Audit),
+		Accounts:      len(data.Accounts),
+		Identities:    len(data.Identities),
+	}
+}
+
+// decode reads the archive, checks checksums and validates every aggregate.
+func decode(archive []byte) (manifest, Dataset, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return manifest{}, Dataset{}, fmt.Errorf("open archive: %w", err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		b, err := readZipFile(f)
+		if err != nil {
+			return manifest{}, Dataset{}, err
+		}
+		files[f.Name] = b
+	}
+
+	var m manifest
+	if err := unmarshalFile(files, manifestFile, &m); err != nil {
+		return manifest{}, Dataset{}, err
+	}
+	if m.SchemaVersion < minSchemaVersion || m.SchemaVersion > SchemaVersion {
+		return manifest{}, Dataset{}, fmt.Errorf("unsupported schema version %d (expected %d to %d)", m.SchemaVersion, minSchemaVersion, SchemaVersion)
+	}
+	listed := map[string]bool{}
+	for _, entry := range m.Files {
+		b, ok := files[entry.Name]
+		if !ok {
+			return manifest{}, Dataset{}, fmt.Errorf("%s is listed in the manifest but missing", entry.Name)
+		}
+		if checksum(b) != entry.SHA256 {
+			return manifest{}, Dataset{}, fmt.Errorf("%s: checksum mismatch", entry.Name)
+		}
+		listed[entry.Name] = true
+	}
+	required := []string{doneLogsFile, tracksFile, categoriesFile, auditFile, settingsFile}
+	if m.SchemaVersion >= 2 {
+		required = append(required, goalsFile)
+	}
+	if m.SchemaVersion >= 4 {
+		required = append(required, teamsFile)
+	}
+	if m.SchemaVersion >= 9 {
+		required = append(required, accountsFile, identitiesFile)
+	}
+	for _, name := range required {
+		if !listed[name] {
+			return manifest{}, Dataset{}, fmt.Errorf("%s is missing from the manifest", name)
+		}
+	}
+
+	var (
+		doneLogs   []doneLogRecord
+		tracks     []trackRecord
+		categories []categoryRecord
+		goals      []goalRecord
+		teams      []teamRecord
+		accounts   []accountRecord
+		identities []identityRecord
+		audit      []auditRecord
+		data       Dataset
+	)
+	for name, dst := range map[string]any{
+		doneLogsFile:   &doneLogs,
+		tracksFile:     &tracks,
+		categoriesFile: &categories,
+		auditFile:      &audit,
+		settingsFile:   &data.Settings,
+	} {
+		if err := unmarshalFile(files, name, dst); err != nil {
+			return manifest{}, Dataset{}, err
+		}
+	}
+	if listed[goalsFile] {
+		if err := unmarshalFile(files, goalsFile, &goals); err != nil {
+			return manifest{}, Dataset{}, err
+		}
+	}
+	if listed[teamsFile] {
+		if err := unmarshalFile(files, teamsFile, &teams); err != nil {
+			return manifest{}, Dataset{}, err
+		}
+	}
+
+	if listed[accountsFile] && listed[identitiesFile] {
+		if err := unmarshalFile(files, accountsFile, &accounts); err != nil {
+			return manifest{}, Dataset{}, err
+		}
+		if err := unmarshalFile(files, identitiesFile, &identities); err != nil {
+			return manifest{}, Dataset{}, err
+		}
+		data.SignIns = true
+	}
+
+	var errs []error
+	usernames := map[string]bool{}
+	for _, r := range accounts {
+		if _, err := account.RehydrateAccount(r.raw()); err != nil {
+			errs = append(errs, fmt.Errorf("account %s: %w", r.Username, err))
+			continue
+		}
+		if usernames[r.Username] {
+			errs = append(errs, fmt.Errorf("account %s: duplicate username", r.Username))
+		}
+		usernames[r.Username] = true
+		data.Accounts = append(data.Accounts, r.raw())
+	}
+	identityKeys := map[string]bool{}
+	for _, r := range identities {
+		if _, err := account.RehydrateIdentity(r.raw()); err != nil {
+			errs = append(errs, fmt.Errorf("identity %s: %w", account.IdentityKey(r.Issuer, r.Subject), err))
+			continue
+		}
+		key := account.IdentityKey(r.Issuer, r.Subject)
+		if identityKeys[key] {
+			errs = append(errs, fmt.Errorf("identity %s: duplicate subject", key))
+		}
+		identityKeys[key] = true
+		data.Identities = append(data.Identities, r.raw())
+	}
+	// memberOf lists the teams of each member, so references to team Tracks can be resolved.
+	teamIDs := map[string]bool{}
+	memberOf := map[string][]string{}
+	for _, r := range teams {
+		if _, err := donelog.RehydrateTeam(r.raw()); err != nil {
+			errs = append(errs, fmt.Errorf("team %s: %w", r.ID, err))
+			continue
+		}
+		if teamIDs[r.ID] {
+			errs = append(errs, fmt.Errorf("team %s: duplicate id", r.ID))
+		}
+		teamIDs[r.ID] = true
+		for member := range r.Members {
+			memberOf[member] = append(memberOf[member], r.ID)
+		}
+		data.Teams = append(data.Teams, r.raw())
+	}
+	trackIDs := map[string]bool{}
+	for _, r := range tracks {
+		_, err := donelog.RehydrateTrack(r.raw())
+		if err == nil {
+			err = checkOwner(r.Owner)
+		}
+		if err == nil && r.Team != "" && (r.Owner != "" || !teamIDs[r.Team]) {
+			err = fmt.Errorf("unknown team %s or team track with an owner", r.Team)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("track %s: %w", r.ID, err))
+			continue
+		}
+		key := ownerRef(r.Owner, r.ID)
+		if r.Team != "" {
+			key = teamRef(r.Team, r.ID)
+		}
+		if trackIDs[key] {
+			errs = append(errs, fmt.Errorf("track %s: duplicate id", r.ID))
+		}
+		trackIDs[key] = true
+		data.Tracks = append(data.Tracks, r.raw())
+	}
+	// knownTrack resolves a reference the way the store does: the owner's own Track or a Track of one of their teams.
+	knownTrack := func(owner, id string) bool {
+		if trackIDs[ownerRef(owner, id)] {
+			return true
+		}
+		for _, team := range memberOf[owner] {
+			if trackIDs[teamRef(team, id)] {
+				return true
+			}
+		}
+		return false
+	}
+	categoryIDs := map[string]bool{}
+	for _, r := range categories {
+		_, err := donelog.RehydrateCategory(r.raw())
+		if err == nil {
+			err = checkOwner(r.Owner)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("category %s: %w", r.ID, err))
+			continue
+		}
+		if categoryIDs[ownerRef(r.Owner, r.ID)] {
+			errs = append(errs, fmt.Errorf("category %s: duplicate id", r.ID))
+		}
+		categoryIDs[ownerRef(r.Owner, r.ID)] = true
+		data.Categories = append(data.Categories, r.raw())
+	}
+	for _, r := range tracks {
+		if r.DefaultCategoryID != "" && !categoryIDs[ownerRef(r.Owner, r.DefaultCategoryID)] {
+			errs = append(errs, fmt.Errorf("track %s: unknown default category %s", r.ID, r.DefaultCategoryID))
+		}
+	}
+	doneLogIDs := map[string]bool{}
+	for _, r := range doneLogs {
+		raw, err := r.raw()
+		if err == nil {
+			_, err = donelog.RehydrateDoneLog(raw)
+		}
+		if err == nil {
+			err = checkOwner(r.Owner)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("doneLog %s: %w", r.ID, err))
+			continue
+		}
+		if doneLogIDs[r.ID] {
+			errs = append(errs, fmt.Errorf("doneLog %s: duplicate id", r.ID))
+		}
+		if !knownTrack(r.Owner, r.TrackID) {
+			errs = append(errs, fmt.Errorf("doneLog %s: unknown track %s", r.ID, r.TrackID))
+		}
+		if !categoryIDs[ownerRef(r.Owner, r.CategoryID)] {
+			errs = append(errs, fmt.Errorf("doneLog %s: unknown category %s", r.ID, r.CategoryID))
+		}
+		doneLogIDs[r.ID] = true
+		data.DoneLogs = append(data.DoneLogs, raw)
+	}
+	goalIDs := map[string]bool{}
+	for _, r := range goals {
+		raw, err := r.raw()
+		if err == nil {
+			_, err = donelog.RehydrateGoal(raw)
+		}
+		if err == nil {
+			err = checkOwner(r.Owner)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("goal %s: %w", r.ID, err))
+			continue
+		}
+		if goalIDs[r.ID] {
+			errs = append(errs, fmt.Errorf("goal %s: duplicate id", r.ID))
+		}
+		if r.TrackID != "" && !knownTrack(r.Owner, r.TrackID) {
+			errs = append(errs, fmt.Errorf("goal %s: unknown track %s", r.ID, r.TrackID))
+		}
+		if r.CategoryID != "" && !categoryIDs[ownerRef(r.Owner, r.CategoryID)] {
+			errs = append(errs, fmt.Errorf("goal %s: unknown category %s", r.ID, r.CategoryID))
+		}
+		goalIDs[r.ID] = true
+		data.Goals = append(data.Goals, raw)
+	}
+	for _, r := range audit {
+		data.Audit = append(data.Audit, r.entry())
+	}
+	if len(errs) > 0 {
+		return manifest{}, Dataset{}, fmt.Errorf("invalid backup: %w", errors.Join(errs...))
+	}
+	return m, data, nil
+}
+
+// ownerRef keys the reference checks of decode: it scopes a Track or Category ID to its owner, so
+// references to personal records never cross owners. It is not a store key; the store keys restored
+// records itself when it replaces the dataset.
+func ownerRef(owner, id string) string {
+	return owner + "/" + id
+}
+
+// teamRef keys a team Track ID for the same reference checks.
+func teamRef(team, id string) string {
+	return "team:" + team + "/" + id
+}
+
+// checkOwner accepts the single-user owner ("") and valid OwnerIDs.
+func checkOwner(owner string) error {
+	if owner == "" {
+		return nil
+	}
+	_, err := donelog.NewOwnerID(owner)
+	return err
+}
+
+func writeZipFile(zw *zip.Writer, name string, body []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(body)
+	return err
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func unmarshalFile(files map[string][]byte, name string, dst any) error {
+	b, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%s is missing", name)
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("decode %s: %w", name, err)
+	}
+	return nil
+}
+
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,375 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"encoding/json"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+type memoryStore struct {
+	data     Dataset
+	replaced bool
+}
+
+func (m *memoryStore) Snapshot(ctx context.Context) (Dataset, error) {
+	return m.data, nil
+}
+
+func (m *memoryStore) Replace(ctx context.Context, data Dataset) error {
+	m.data = data
+	m.replaced = true
+	return nil
+}
+
+type fixedTime struct{}
+
+func (fixedTime) Now() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+func sampleDataset() Dataset {
+	trashedAt := time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)
+	hash, _ := account.HashPassword("correct horse", []byte("0123456789abcdef"), 1)
+	return Dataset{
+		DoneLogs: []donelog.RawDoneLog{
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Title: "ch.1", TrackID: "track_book", CategoryID: "cat_reading", Count: 12, OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"architecture", "go"}, Note: "## Notes\n- SRP", Links: []string{"https://example.com/clean-architecture"}},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH2", Title: "ch.2", TrackID: "track_book", CategoryID: "cat_reading", Count: 8, OccurredOn: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), TrashedAt: &trashedAt},
+		},
+		Tracks:     []donelog.RawTrack{{ID: "track_book", Name: "Clean Architecture", DefaultCategoryID: "cat_reading", SortOrder: 1, Active: true}},
+		Categories: []donelog.RawCategory{{ID: "cat_reading", Name: "読書", Active: true}},
+		Goals:      []donelog.RawGoal{{ID: "01HYR1X5C9XM9P6H7K71M9QAG1", Name: "May", TrackID: "track_book", StartDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), Target: 100}},
+		Audit:      []donelog.AuditEntry{{DoneLogID: "01HYR1X5C9XM9P6H7K71M9QAH1", Action: donelog.AuditActionCreated, Actor: "taketo", RecordedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}},
+		Settings:   map[string]string{"week_start": "monday"},
+		Accounts:   []account.RawAccount{{Username: "alice", PasswordHash: hash.String(), CreatedAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}},
+		Identities: []account.RawIdentity{{Issuer: "https://idp.example.com", Subject: "sub-bob", Username: "bob", CreatedAt: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)}},
+		SignIns:    true,
+	}
+}
+
+// rewrite copies an archive, replacing the named file's body.
+func rewrite(t *testing.T, archive []byte, name, body string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == name {
+			b = []byte(body)
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(b)
+	}
+	zw.Close()
+	return out.Bytes()
+}
+
+// rewriteWithManifest replaces the named file's body and updates its checksum in the manifest.
+func rewriteWithManifest(t *testing.T, archive []byte, name, body string) []byte {
+	t.Helper()
+	zr, _ := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	var m manifest
+	for _, f := range zr.File {
+		if f.Name == manifestFile {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			_ = json.Unmarshal(b, &m)
+		}
+	}
+	for i, entry := range m.Files {
+		if entry.Name == name {
+			m.Files[i].SHA256 = checksum([]byte(body))
+		}
+	}
+	b, _ := json.Marshal(m)
+	return rewrite(t, rewrite(t, archive, name, body), manifestFile, string(b))
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	source := &memoryStore{data: sampleDataset()}
+
+	var archive bytes.Buffer
+	summary, err := Service{Store: source, Time: fixedTime{}}.Backup(ctx, &archive)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if summary.DoneLogs != 2 || summary.Tracks != 1 || summary.Categories != 1 || summary.Goals != 1 || summary.AuditEntries != 1 || summary.Accounts != 1 || summary.Identities != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr string
+	}{
+		{
+			name:    "OK: round trip",
+			archive: archive.Bytes(),
+		},
+		{
+			name:    "NG: tampered file fails checksum",
+			archive: rewrite(t, archive.Bytes(), tracksFile, `[{"id":"track_book","name":"Hacked","active":true}]`),
+			wantErr: "checksum mismatch",
+		},
+		{
+			name:    "NG: account with a malformed password hash",
+			archive: rewriteWithManifest(t, archive.Bytes(), accountsFile, `[{"username":"alice","passwordHash":"plain","createdAt":"2024-04-01T00:00:00Z"}]`),
+			wantErr: "account alice:",
+		},
+		{
+			name:    "NG: unsupported schema version",
+			archive: rewrite(t, archive.Bytes(), manifestFile, `{"schemaVersion":99}`),
+			wantErr: "unsupported schema version",
+		},
+		{
+			name:    "NG: not a zip",
+			archive: []byte("plain text"),
+			wantErr: "open archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &memoryStore{}
+			_, err := Service{Store: target, Time: fixedTime{}}.Restore(ctx, tt.archive)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if target.replaced {
+					t.Fatalf("store must not be touched when validation fails")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			want := sampleDataset()
+			if len(target.data.DoneLogs) != 2 || target.data.DoneLogs[1].TrashedAt == nil || !target.data.DoneLogs[1].TrashedAt.Equal(*want.DoneLogs[1].TrashedAt) {
+				t.Fatalf("unexpected doneLogs: %+v", target.data.DoneLogs)
+			}
+			if !slices.Equal(target.data.DoneLogs[0].Tags, want.DoneLogs[0].Tags) || target.data.DoneLogs[1].Tags != nil {
+				t.Fatalf("unexpected tags: %+v", target.data.DoneLogs)
+			}
+			if got := target.data.DoneLogs[0]; got.Note != want.DoneLogs[0].Note || !slices.Equal(got.Links, want.DoneLogs[0].Links) {
+				t.Fatalf("unexpected note and links: %+v", got)
+			}
+			if !target.data.DoneLogs[0].OccurredOn.Equal(want.DoneLogs[0].OccurredOn) {
+				t.Fatalf("unexpected occurredOn: %v", target.data.DoneLogs[0].OccurredOn)
+			}
+			if target.data.Tracks[0] != want.Tracks[0] || target.data.Categories[0] != want.Categories[0] {
+				t.Fatalf("unexpected catalog: %+v %+v", target.data.Tracks, target.data.Categories)
+			}
+			if len(target.data.Goals) != 1 || target.data.Goals[0] != want.Goals[0] {
+				t.Fatalf("unexpected goals: %+v", target.data.Goals)
+			}
+			if target.data.Settings["week_start"] != "monday" || target.data.Audit[0].Actor != "taketo" {
+				t.Fatalf("unexpected settings/audit: %+v %+v", target.data.Settings, target.data.Audit)
+			}
+			if !target.data.SignIns || len(target.data.Accounts) != 1 || target.data.Accounts[0] != want.Accounts[0] || len(target.data.Identities) != 1 || target.data.Identities[0] != want.Identities[0] {
+				t.Fatalf("unexpected sign-ins: %+v %+v", target.data.Accounts, target.data.Identities)
+			}
+		})
+	}
+}
+
+func TestRestoreRejectsInvalidAggregates(t *testing.T) {
+	ctx := context.Background()
+	data := sampleDataset()
+	data.DoneLogs[0].Count = 0
+	data.DoneLogs[1].TrackID = "track_missing"
+	data.Categories[0].Name = ""
+	data.Goals[0].TrackID = "track_gone"
+
+	var archive bytes.Buffer
+	if _, err := (Service{Store: &memoryStore{data: data}, Time: fixedTime{}}).Backup(ctx, &archive); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	target := &memoryStore{}
+	_, err := Service{Store: target, Time: fixedTime{}}.Restore(ctx, archive.Bytes())
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	for _, want := range []string{"count must be", "unknown track track_missing", "category cat_reading: category name must not be empty", "goal 01HYR1X5C9XM9P6H7K71M9QAG1: unknown track track_gone"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+	if target.replaced {
+		t.Fatalf("store must not be touched when validation fails")
+	}
+}
+
+func TestRestoreVersion1Archive(t *testing.T) {
+	ctx := context.Background()
+	data := sampleDataset()
+	data.Goals = nil
+
+	var archive bytes.Buffer
+	if _, err := (Service{Store: &memoryStore{data: data}, Time: fixedTime{}}).Backup(ctx, &archive); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	// Rebuild the archive the way version 1 wrote it: no goals.json, teams.json or sign-ins, none listed in the manifest.
+	zr, _ := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		if f.Name == goalsFile || f.Name == teamsFile || f.Name == accountsFile || f.Name == identitiesFile {
+			continue
+		}
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == manifestFile {
+			var m manifest
+			_ = json.Unmarshal(b, &m)
+			m.SchemaVersion = 1
+			kept := m.Files[:0]
+			for _, entry := range m.Files {
+				if entry.Name != goalsFile && entry.Name != teamsFile && entry.Name != accountsFile && entry.Name != identitiesFile {
+					kept = append(kept, entry)
+				}
+			}
+			m.Files = kept
+			b, _ = json.Marshal(m)
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(b)
+	}
+	zw.Close()
+
+	target := &memoryStore{}
+	summary, err := Service{Store: target, Time: fixedTime{}}.Restore(ctx, out.Bytes())
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if summary.SchemaVersion != 1 || summary.DoneLogs != 2 || len(target.data.Goals) != 0 || target.data.SignIns {
+		t.Fatalf("unexpected restore of v1 archive: %+v", summary)
+	}
+}
+
+func TestRestoreKeepsOwners(t *testing.T) {
+	ctx := context.Background()
+	data := sampleDataset()
+	// bob owns his own track_book; alice's DONELOG must still resolve to alice's track.
+	data.Tracks = append(data.Tracks,
+		donelog.RawTrack{ID: "track_book", Name: "Bob's book", Active: true, Owner: "bob"},
+		donelog.RawTrack{ID: "track_book", Name: "Alice's book", Active: true, Owner: "alice"},
+	)
+	data.Categories = append(data.Categories, donelog.RawCategory{ID: "cat_reading", Name: "Reading", Active: true, Owner: "alice"})
+	data.DoneLogs = append(data.DoneLogs, donelog.RawDoneLog{
+		ID: "01HYR1X5C9XM9P6H7K71M9QAH3", Title: "a", TrackID: "track_book", CategoryID: "cat_reading", Count: 1,
+		OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Owner: "alice",
+	})
+
+	var archive bytes.Buffer
+	if _, err := (Service{Store: &memoryStore{data: data}, Time: fixedTime{}}).Backup(ctx, &archive); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	target := &memoryStore{}
+	if _, err := (Service{Store: target, Time: fixedTime{}}).Restore(ctx, archive.Bytes()); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if len(target.data.Tracks) != 3 || target.data.Tracks[1].Owner != "bob" || target.data.DoneLogs[2].Owner != "alice" {
+		t.Fatalf("owners were not restored: %+v %+v", target.data.Tracks, target.data.DoneLogs)
+	}
+
+	// A reference to another owner's Category is rejected.
+	data.DoneLogs[2].Owner = "bob"
+	archive.Reset()
+	if _, err := (Service{Store: &memoryStore{data: data}, Time: fixedTime{}}).Backup(ctx, &archive); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	_, err := Service{Store: &memoryStore{}, Time: fixedTime{}}.Restore(ctx, archive.Bytes())
+	if err == nil || !strings.Contains(err.Error(), "doneLog 01HYR1X5C9XM9P6H7K71M9QAH3: unknown category cat_reading") {
+		t.Fatalf("expected a cross-owner reference to fail, got %v", err)
+	}
+}
+
+func TestRestoreTeams(t *testing.T) {
+	ctx := context.Background()
+	data := sampleDataset()
+	data.Teams = []donelog.RawTeam{{ID: "club", Name: "Club", Members: map[string]string{"alice": "owner", "bob": "viewer"}, LeaderboardOptOut: []string{"bob"}}}
+	data.Tracks = append(data.Tracks, donelog.RawTrack{ID: "track_run", Name: "Run", Active: true, Team: "club"})
+	// bob logs against the team track through his membership.
+	data.DoneLogs = append(data.DoneLogs, donelog.RawDoneLog{
+		ID: "01HYR1X5C9XM9P6H7K71M9QAH3", Title: "5k", TrackID: "track_run", CategoryID: "cat_run", Count: 5,
+		OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Owner: "bob",
+	})
+	data.Categories = append(data.Categories, donelog.RawCategory{ID: "cat_run", Name: "Run", Active: true, Owner: "bob"})
+
+	var archive bytes.Buffer
+	summary, err := Service{Store: &memoryStore{data: data}, Time: fixedTime{}}.Backup(ctx, &archive)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if summary.Teams != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	target := &memoryStore{}
+	if _, err := (Service{Store: target, Time: fixedTime{}}).Restore(ctx, archive.Bytes()); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if len(target.data.Teams) != 1 || target.data.Teams[0].Members["bob"] != "viewer" || len(target.data.Teams[0].LeaderboardOptOut) != 1 || target.data.Tracks[1].Team != "club" {
+		t.Fatalf("teams were not restored: %+v %+v", target.data.Teams, target.data.Tracks)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*Dataset)
+		wantErr string
+	}{
+		{
+			name:    "NG: team without owner",
+			mutate:  func(d *Dataset) { d.Teams[0].Members = map[string]string{"bob": "viewer"} },
+			wantErr: "team club: team club has no owner",
+		},
+		{
+			name:    "NG: opt-out of a non-member",
+			mutate:  func(d *Dataset) { d.Teams[0].LeaderboardOptOut = []string{"carol"} },
+			wantErr: "carol is not a member of team club",
+		},
+		{
+			name:    "NG: track of an unknown team",
+			mutate:  func(d *Dataset) { d.Tracks[1].Team = "gone" },
+			wantErr: "unknown team gone",
+		},
+		{
+			name:    "NG: DONELOG of a non-member",
+			mutate:  func(d *Dataset) { d.Teams[0].Members = map[string]string{"alice": "owner"} },
+			wantErr: "doneLog 01HYR1X5C9XM9P6H7K71M9QAH3: unknown track track_run",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := data
+			broken.Teams = []donelog.RawTeam{{ID: "club", Name: "Club", Members: map[string]string{"alice": "owner", "bob": "viewer"}}}
+			broken.Tracks = append([]donelog.RawTrack(nil), data.Tracks...)
+			tt.mutate(&broken)
+			var archive bytes.Buffer
+			if _, err := (Service{Store: &memoryStore{data: broken}, Time: fixedTime{}}).Backup(ctx, &archive); err != nil {
+				t.Fatalf("backup failed: %v", err)
+			}
+			_, err := Service{Store: &memoryStore{}, Time: fixedTime{}}.Restore(ctx, archive.Bytes())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
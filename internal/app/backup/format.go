@@ -0,0 +1,279 @@
+package backup
+
+import (
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// SchemaVersion is written to every archive. Bump it whenever a record layout changes
+// and teach decode how to read the previous versions.
+// Version 2 added goals.json; version 1 archives restore without Goals.
+// Version 3 added the owner field; older records restore into the single-user owner.
+// Version 4 added teams.json and the team field of Tracks; older archives restore without Teams.
+// Version 5 added leaderboardOptOut to teams; older teams restore with every member visible.
+// Version 6 added the tags field of DoneLogs; older DoneLogs restore untagged.
+// Version 7 added the note and links fields of DoneLogs; older DoneLogs restore without them.
+// Version 8 added the team field of DoneLogs and invites to teams; the store attributes older team DoneLogs on restore.
+// Version 9 added accounts.json and identities.json; older archives restore without touching the stored sign-ins.
+const SchemaVersion = 9
+
+// minSchemaVersion is the oldest archive layout decode still reads.
+const minSchemaVersion = 1
+
+const (
+	manifestFile   = "manifest.json"
+	doneLogsFile   = "donelogs.json"
+	tracksFile     = "tracks.json"
+	categoriesFile = "categories.json"
+	auditFile      = "audit.json"
+	settingsFile   = "settings.json"
+	goalsFile      = "goals.json"
+	teamsFile      = "teams.json"
+	accountsFile   = "accounts.json"
+	identitiesFile = "identities.json"
+)
+
+// manifest describes the archive contents.
+type manifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Files         []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Count  int    `json:"count"`
+}
+
+// The record types below pin the on-disk layout independently of the domain structs.
+
+type doneLogRecord struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	TrackID    string     `json:"trackId"`
+	CategoryID string     `json:"categoryId"`
+	Count      int        `json:"count"`
+	OccurredOn string     `json:"occurredOn"`
+	TrashedAt  *time.Time `json:"trashedAt,omitempty"`
+	Owner      string     `json:"owner,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	Links      []string   `json:"links,omitempty"`
+	Team       string     `json:"team,omitempty"`
+}
+
+type trackRecord struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	DefaultCategoryID string `json:"defaultCategoryId,omitempty"`
+	SortOrder         int    `json:"sortOrder"`
+	Active            bool   `json:"active"`
+	Owner             string `json:"owner,omitempty"`
+	Team              string `json:"team,omitempty"`
+}
+
+type categoryRecord struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sortOrder"`
+	Active    bool   `json:"active"`
+	Owner     string `json:"owner,omitempty"`
+}
+
+type teamRecord struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Members map[string]string `json:"members"`
+	// LeaderboardOptOut lists the members hidden from leaderboards.
+	LeaderboardOptOut []string `json:"leaderboardOptOut,omitempty"`
+	// Invites maps invited users to the role they will get once they accept.
+	Invites map[string]string `json:"invites,omitempty"`
+}
+
+// accountRecord keeps the password hash, never the password itself.
+type accountRecord struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type identityRecord struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type goalRecord struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	TrackID    string `json:"trackId,omitempty"`
+	CategoryID string `json:"categoryId,omitempty"`
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+	Target     int    `json:"target"`
+	Owner      string `json:"owner,omitempty"`
+}
+
+type auditRecord struct {
+	DoneLogID  string              `json:"doneLogId"`
+	Action     string              `json:"action"`
+	Actor      string              `json:"actor"`
+	RequestID  string              `json:"requestId"`
+	RecordedAt time.Time           `json:"recordedAt"`
+	Changes    []fieldChangeRecord `json:"changes"`
+	Owner      string              `json:"owner,omitempty"`
+}
+
+type fieldChangeRecord struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+func toDoneLogRecord(raw donelog.RawDoneLog) doneLogRecord {
+	return doneLogRecord{
+		ID:         raw.ID,
+		Title:      raw.Title,
+		TrackID:    raw.TrackID,
+		CategoryID: raw.CategoryID,
+		Count:      raw.Count,
+		OccurredOn: donelog.OccurredOnFromTime(raw.OccurredOn).String(),
+		TrashedAt:  raw.TrashedAt,
+		Owner:      raw.Owner,
+		Tags:       raw.Tags,
+		Note:       raw.Note,
+		Links:      raw.Links,
+		Team:       raw.Team,
+	}
+}
+
+func (r doneLogRecord) raw() (donelog.RawDoneLog, error) {
+	occurredOn, err := donelog.NewOccurredOn(r.OccurredOn)
+	if err != nil {
+		return donelog.RawDoneLog{}, err
+	}
+	return donelog.RawDoneLog{
+		ID:         r.ID,
+		Title:      r.Title,
+		TrackID:    r.TrackID,
+		CategoryID: r.CategoryID,
+		Count:      r.Count,
+		OccurredOn: occurredOn.Time(),
+		TrashedAt:  r.TrashedAt,
+		Owner:      r.Owner,
+		Tags:       r.Tags,
+		Note:       r.Note,
+		Links:      r.Links,
+		Team:       r.Team,
+	}, nil
+}
+
+func toTrackRecord(raw donelog.RawTrack) trackRecord {
+	return trackRecord(raw)
+}
+
+func (r trackRecord) raw() donelog.RawTrack {
+	return donelog.RawTrack(r)
+}
+
+func toCategoryRecord(raw donelog.RawCategory) categoryRecord {
+	return categoryRecord(raw)
+}
+
+func (r categoryRecord) raw() donelog.RawCategory {
+	return donelog.RawCategory(r)
+}
+
+func toTeamRecord(raw donelog.RawTeam) teamRecord {
+	return teamRecord(raw)
+}
+
+func (r teamRecord) raw() donelog.RawTeam {
+	return donelog.RawTeam(r)
+}
+
+func toAccountRecord(raw account.RawAccount) accountRecord {
+	return accountRecord(raw)
+}
+
+func (r accountRecord) raw() account.RawAccount {
+	return account.RawAccount(r)
+}
+
+func toIdentityRecord(raw account.RawIdentity) identityRecord {
+	return identityRecord(raw)
+}
+
+func (r identityRecord) raw() account.RawIdentity {
+	return account.RawIdentity(r)
+}
+
+func toGoalRecord(raw donelog.RawGoal) goalRecord {
+	return goalRecord{
+		ID:         raw.ID,
+		Name:       raw.Name,
+		TrackID:    raw.TrackID,
+		CategoryID: raw.CategoryID,
+		StartDate:  donelog.OccurredOnFromTime(raw.StartDate).String(),
+		EndDate:    donelog.OccurredOnFromTime(raw.EndDate).String(),
+		Target:     raw.Target,
+		Owner:      raw.Owner,
+	}
+}
+
+func (r goalRecord) raw() (donelog.RawGoal, error) {
+	start, err := donelog.NewOccurredOn(r.StartDate)
+	if err != nil {
+		return donelog.RawGoal{}, err
+	}
+	end, err := donelog.NewOccurredOn(r.EndDate)
+	if err != nil {
+		return donelog.RawGoal{}, err
+	}
+	return donelog.RawGoal{
+		ID:         r.ID,
+		Name:       r.Name,
+		TrackID:    r.TrackID,
+		CategoryID: r.CategoryID,
+		StartDate:  start.Time(),
+		EndDate:    end.Time(),
+		Target:     r.Target,
+		Owner:      r.Owner,
+	}, nil
+}
+
+func toAuditRecord(entry donelog.AuditEntry) auditRecord {
+	changes := make([]fieldChangeRecord, 0, len(entry.Changes))
+	for _, c := range entry.Changes {
+		changes = append(changes, fieldChangeRecord(c))
+	}
+	return auditRecord{
+		DoneLogID:  entry.DoneLogID,
+		Action:     string(entry.Action),
+		Actor:      entry.Actor,
+		RequestID:  entry.RequestID,
+		RecordedAt: entry.RecordedAt,
+		Changes:    changes,
+		Owner:      entry.Owner,
+	}
+}
+
+func (r auditRecord) entry() donelog.AuditEntry {
+	changes := make([]donelog.FieldChange, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		changes = append(changes, donelog.FieldChange(c))
+	}
+	return donelog.AuditEntry{
+		DoneLogID:  r.DoneLogID,
+		Action:     donelog.AuditAction(r.Action),
+		Actor:      r.Actor,
+		RequestID:  r.RequestID,
+		RecordedAt: r.RecordedAt,
+		Changes:    changes,
+		Owner:      r.Owner,
+	}
+}
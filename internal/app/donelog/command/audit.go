@@ -0,0 +1,27 @@
+package command
+
+import (
+	"context"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// recordAudit appends an audit entry describing the transition from before to after.
+func recordAudit(
+	ctx context.Context,
+	audit AuditLog,
+	clock TimeSource,
+	action donelog.AuditAction,
+	id donelog.DoneLogID,
+	before, after *donelog.RawDoneLog,
+) error {
+	return audit.Append(ctx, donelog.AuditEntry{
+		DoneLogID:  id.String(),
+		Action:     action,
+		Actor:      appctx.Actor(ctx),
+		RequestID:  appctx.RequestID(ctx),
+		RecordedAt: clock.Now(),
+		Changes:    donelog.DiffRawDoneLog(before, after),
+	})
+}
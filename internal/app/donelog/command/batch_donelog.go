@@ -0,0 +1,220 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// BatchMode controls how failures inside a batch are handled.
+type BatchMode string
+
+const (
+	// BatchModeAtomic persists every item or none of them.
+	BatchModeAtomic BatchMode = "atomic"
+	// BatchModeBestEffort persists each item independently.
+	BatchModeBestEffort BatchMode = "best_effort"
+)
+
+// ErrBatchRolledBack marks an item that succeeded but was discarded because another item failed.
+var ErrBatchRolledBack = errors.New("rolled back because another item in the batch failed")
+
+// BatchItem holds exactly one of Create, Update or Delete.
+type BatchItem struct {
+	Create *CreateDoneLogCommand
+	Update *UpdateDoneLogCommand
+	Delete *DeleteDoneLogCommand
+}
+
+func (i BatchItem) validate() error {
+	n := 0
+	for _, set := range []bool{i.Create != nil, i.Update != nil, i.Delete != nil} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("exactly one of create, update or delete is required")
+	}
+	return nil
+}
+
+// BatchDoneLogCommand creates, updates and deletes many DONELOGs in one call.
+type BatchDoneLogCommand struct {
+	Mode  BatchMode
+	Items []BatchItem
+	// DryRun validates every item inside a transaction and always rolls it back.
+	DryRun bool
+}
+
+func (c BatchDoneLogCommand) Validate() error {
+	if c.Mode != BatchModeAtomic && c.Mode != BatchModeBestEffort {
+		return fmt.Errorf("invalid batch mode: %s", c.Mode)
+	}
+	if len(c.Items) == 0 {
+		return fmt.Errorf("items are required")
+	}
+	return nil
+}
+
+// BatchItemResult reports the outcome of one item, in the order it was submitted.
+type BatchItemResult struct {
+	Index int
+	ID    donelog.DoneLogID
+	Err   error
+}
+
+// BatchResult is the outcome of a BatchDoneLogCommand.
+type BatchResult struct {
+	Items []BatchItemResult
+	// RolledBack is true when atomic mode discarded all changes because an item failed.
+	RolledBack bool
+}
+
+// Failed returns the number of items that did not persist.
+func (r BatchResult) Failed() int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// BatchDoneLogHandler handles BatchDoneLogCommand.
+// Track/Category references are resolved once per distinct ID for the whole batch.
+// Batch changes are not pushed to the undo journal.
+type BatchDoneLogHandler struct {
+	DoneLogs   DoneLogRepository
+	Tracks     TrackRepository
+	Categories CategoryRepository
+	IDs        IDGenerator
+	Audit      AuditLog
+	Time       TimeSource
+	Tx         Transactor
+	// Idempotency is passed through to create items that carry an IdempotencyKey.
+	Idempotency IdempotencyStore
+}
+
+func (h BatchDoneLogHandler) Handle(ctx context.Context, cmd BatchDoneLogCommand) (BatchResult, error) {
+	if err := cmd.Validate(); err != nil {
+		return BatchResult{}, apperr.Invalid(err)
+	}
+
+	tracks := &cachingTrackRepository{next: h.Tracks}
+	categories := &cachingCategoryRepository{next: h.Categories}
+	create := CreateDoneLogHandler{DoneLogs: h.DoneLogs, Tracks: tracks, Categories: categories, IDs: h.IDs, Audit: h.Audit, Time: h.Time, Idempotency: h.Idempotency}
+	update := UpdateDoneLogHandler{DoneLogs: h.DoneLogs, Categories: categories, Audit: h.Audit, Time: h.Time}
+	remove := DeleteDoneLogHandler{DoneLogs: h.DoneLogs, Audit: h.Audit, Time: h.Time}
+
+	apply := func(ctx context.Context, index int, item BatchItem) BatchItemResult {
+		result := BatchItemResult{Index: index}
+		if result.Err = item.validate(); result.Err != nil {
+			return result
+		}
+		switch {
+		case item.Create != nil:
+			result.ID, result.Err = create.Handle(ctx, *item.Create)
+		case item.Update != nil:
+			result.ID, _ = donelog.NewDoneLogID(item.Update.ID)
+			result.Err = update.Handle(ctx, *item.Update)
+		case item.Delete != nil:
+			result.ID, _ = donelog.NewDoneLogID(item.Delete.ID)
+			result.Err = remove.Handle(ctx, *item.Delete)
+		}
+		return result
+	}
+
+	results := make([]BatchItemResult, len(cmd.Items))
+
+	if cmd.Mode == BatchModeBestEffort && !cmd.DryRun {
+		for i, item := range cmd.Items {
+			err := h.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				results[i] = apply(ctx, i, item)
+				return results[i].Err
+			})
+			if err != nil && results[i].Err == nil {
+				results[i].Err = err
+			}
+		}
+		return BatchResult{Items: results}, nil
+	}
+
+	errItemsFailed := errors.New("batch items failed")
+	err := h.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		failed := false
+		for i, item := range cmd.Items {
+			results[i] = apply(ctx, i, item)
+			failed = failed || results[i].Err != nil
+		}
+		if failed || cmd.DryRun {
+			return errItemsFailed
+		}
+		return nil
+	})
+	if err == nil {
+		return BatchResult{Items: results}, nil
+	}
+	if !errors.Is(err, errItemsFailed) {
+		return BatchResult{}, err
+	}
+	if cmd.DryRun {
+		return BatchResult{Items: results, RolledBack: true}, nil
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = ErrBatchRolledBack
+		}
+	}
+	return BatchResult{Items: results, RolledBack: true}, nil
+}
+
+type trackLookup struct {
+	track *Track
+	err   error
+}
+
+// cachingTrackRepository memoizes FindActiveByID for the lifetime of one batch.
+type cachingTrackRepository struct {
+	next  TrackRepository
+	found map[string]trackLookup
+}
+
+func (c *cachingTrackRepository) FindActiveByID(ctx context.Context, id donelog.TrackID) (*Track, error) {
+	if hit, ok := c.found[id.String()]; ok {
+		return hit.track, hit.err
+	}
+	track, err := c.next.FindActiveByID(ctx, id)
+	if c.found == nil {
+		c.found = map[string]trackLookup{}
+	}
+	c.found[id.String()] = trackLookup{track: track, err: err}
+	return track, err
+}
+
+type categoryLookup struct {
+	category *Category
+	err      error
+}
+
+// cachingCategoryRepository memoizes FindActiveByID for the lifetime of one batch.
+type cachingCategoryRepository struct {
+	next  CategoryRepository
+	found map[string]categoryLookup
+}
+
+func (c *cachingCategoryRepository) FindActiveByID(ctx context.Context, id donelog.CategoryID) (*Category, error) {
+	if hit, ok := c.found[id.String()]; ok {
+		return hit.category, hit.err
+	}
+	category, err := c.next.FindActiveByID(ctx, id)
+	if c.found == nil {
+		c.found = map[string]categoryLookup{}
+	}
+	c.found[id.String()] = categoryLookup{category: category, err: err}
+	return category, err
+}
@@ -0,0 +1,255 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// CreateTrackCommand registers a new Track. IDs are caller-chosen slugs such as "reading".
+// With TeamID the Track is shared by that Team; only its owners may create one.
+type CreateTrackCommand struct {
+	ID                string
+	Name              string
+	DefaultCategoryID string
+	SortOrder         int
+	TeamID            string
+}
+
+func (c CreateTrackCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.TeamID != "" && c.DefaultCategoryID != "" {
+		return fmt.Errorf("team tracks have no default category because categories are personal")
+	}
+	return nil
+}
+
+// CreateTrackHandler handles CreateTrackCommand.
+type CreateTrackHandler struct {
+	Tracks     TrackStore
+	Categories CategoryRepository
+	// Teams and TeamTracks are required only for team Tracks.
+	Teams      TeamStore
+	TeamTracks TeamTrackStore
+}
+
+func (h CreateTrackHandler) Handle(ctx context.Context, cmd CreateTrackCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+
+	id, err := donelog.NewTrackID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	var team *donelog.Team
+	if cmd.TeamID != "" {
+		if h.Teams == nil || h.TeamTracks == nil {
+			return fmt.Errorf("team tracks are not supported")
+		}
+		var role donelog.Role
+		team, role, err = loadTeam(ctx, h.Teams, cmd.TeamID)
+		if err != nil {
+			return err
+		}
+		if !role.CanManage() {
+			return fmt.Errorf("only owners add tracks to team %s: %w", cmd.TeamID, apperr.ErrForbidden)
+		}
+	}
+	var defaultCategory *donelog.CategoryID
+	if cmd.DefaultCategoryID != "" {
+		categoryID, err := donelog.NewCategoryID(cmd.DefaultCategoryID)
+		if err != nil {
+			return apperr.Invalid(err)
+		}
+		category, err := h.Categories.FindActiveByID(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return fmt.Errorf("category %s: %w", categoryID.String(), apperr.ErrNotFound)
+		}
+		if !category.Active {
+			return apperr.Invalid(fmt.Errorf("category %s not active", categoryID.String()))
+		}
+		defaultCategory = &categoryID
+	}
+
+	// FindByID also sees the Tracks of the caller's Teams, so a new Track never hides one of them.
+	existing, err := h.Tracks.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("track %s already exists: %w", id.String(), apperr.ErrConflict)
+	}
+	if team != nil {
+		for _, m := range team.Members() {
+			if err := checkTeamTrackFree(ctx, h.TeamTracks, m.Member, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	var track *donelog.Track
+	if team != nil {
+		track, err = donelog.NewTeamTrack(id, cmd.Name, team.ID(), cmd.SortOrder)
+	} else {
+		track, err = donelog.NewTrack(id, cmd.Name, defaultCategory, cmd.SortOrder)
+	}
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Tracks.Save(ctx, track)
+}
+
+// ArchiveTrackCommand hides a Track from new DONELOGs. Past DONELOGs keep referring to it.
+type ArchiveTrackCommand struct {
+	ID string
+}
+
+func (c ArchiveTrackCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// ArchiveTrackHandler handles ArchiveTrackCommand. Team Tracks may be archived by the team's owners only.
+type ArchiveTrackHandler struct {
+	Tracks TrackStore
+	// Teams is required only for team Tracks.
+	Teams TeamStore
+}
+
+func (h ArchiveTrackHandler) Handle(ctx context.Context, cmd ArchiveTrackCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+
+	id, err := donelog.NewTrackID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	raw, err := h.Tracks.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		return fmt.Errorf("track %s: %w", id.String(), apperr.ErrNotFound)
+	}
+
+	track, err := donelog.RehydrateTrack(*raw)
+	if err != nil {
+		return err
+	}
+	if team := track.Team(); team != nil {
+		if h.Teams == nil {
+			return fmt.Errorf("team tracks are not supported")
+		}
+		_, role, err := loadTeam(ctx, h.Teams, team.String())
+		if err != nil {
+			return err
+		}
+		if !role.CanManage() {
+			return fmt.Errorf("only owners archive tracks of team %s: %w", team.String(), apperr.ErrForbidden)
+		}
+	}
+	track.Archive()
+	return h.Tracks.Save(ctx, track)
+}
+
+// CreateCategoryCommand registers a new Category. IDs are caller-chosen slugs such as "pages".
+type CreateCategoryCommand struct {
+	ID        string
+	Name      string
+	SortOrder int
+}
+
+func (c CreateCategoryCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// CreateCategoryHandler handles CreateCategoryCommand.
+type CreateCategoryHandler struct {
+	Categories CategoryStore
+}
+
+func (h CreateCategoryHandler) Handle(ctx context.Context, cmd CreateCategoryCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+
+	id, err := donelog.NewCategoryID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	existing, err := h.Categories.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("category %s already exists: %w", id.String(), apperr.ErrConflict)
+	}
+
+	category, err := donelog.NewCategory(id, cmd.Name, cmd.SortOrder)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Categories.Save(ctx, category)
+}
+
+// ArchiveCategoryCommand hides a Category from new DONELOGs.
+type ArchiveCategoryCommand struct {
+	ID string
+}
+
+func (c ArchiveCategoryCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// ArchiveCategoryHandler handles ArchiveCategoryCommand.
+type ArchiveCategoryHandler struct {
+	Categories CategoryStore
+}
+
+func (h ArchiveCategoryHandler) Handle(ctx context.Context, cmd ArchiveCategoryCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+
+	id, err := donelog.NewCategoryID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	raw, err := h.Categories.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		return fmt.Errorf("category %s: %w", id.String(), apperr.ErrNotFound)
+	}
+
+	category, err := donelog.RehydrateCategory(*raw)
+	if err != nil {
+		return err
+	}
+	category.Archive()
+	return h.Categories.Save(ctx, category)
+}
@@ -2,9 +2,15 @@ package command
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
 	"github.com/taketosaeki/donelog/internal/domain/donelog"
 )
 
@@ -43,6 +49,24 @@ func (m mockCategoryRepo) FindActiveByID(ctx context.Context, id donelog.Categor
 	return m.category, m.err
 }
 
+type mockAuditLog struct {
+	entries []donelog.AuditEntry
+	err     error
+}
+
+func (m *mockAuditLog) Append(ctx context.Context, entry donelog.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return m.err
+}
+
+type fixedTime struct {
+	value time.Time
+}
+
+func (f fixedTime) Now() time.Time {
+	return f.value
+}
+
 type mockIDGenerator struct {
 	id  donelog.DoneLogID
 	err error
@@ -88,6 +112,19 @@ func TestCreateDoneLog(t *testing.T) {
 			category: &Category{Active: true},
 			wantErr:  true,
 		},
+		{
+			name: "NG: team track of a viewer",
+			cmd: CreateDoneLogCommand{
+				Title:      "Test",
+				TrackID:    "track_sample",
+				CategoryID: "cat_sample",
+				Count:      2,
+				OccurredOn: "2024-05-01",
+			},
+			track:    &Track{Active: true, ReadOnly: true},
+			category: &Category{Active: true},
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -98,6 +135,8 @@ func TestCreateDoneLog(t *testing.T) {
 				Tracks:     mockTrackRepo{track: tt.track},
 				Categories: mockCategoryRepo{category: tt.category},
 				IDs:        mockIDGenerator{id: mustDoneLogID(t, "01HYR1X5C9XM9P6H7K71M9QAHX"), err: tt.idGenErr},
+				Audit:      &mockAuditLog{},
+				Time:       fixedTime{},
 			}
 
 			id, err := handler.Handle(context.Background(), tt.cmd)
@@ -147,6 +186,8 @@ func TestUpdateDoneLog_NotFound(t *testing.T) {
 				Categories: mockCategoryRepo{
 					category: tt.category,
 				},
+				Audit: &mockAuditLog{},
+				Time:  fixedTime{},
 			}
 
 			cmd := UpdateDoneLogCommand{
@@ -165,6 +206,52 @@ func TestUpdateDoneLog_NotFound(t *testing.T) {
 	}
 }
 
+func TestUpdateDoneLog_NoteAndLinks(t *testing.T) {
+	annotated := sampleRawDoneLog()
+	annotated.Note = "keep me"
+	annotated.Links = []string{"https://example.com/kept"}
+	empty, html := "", "<script>x</script>see **this**"
+
+	tests := []struct {
+		name      string
+		note      *string
+		links     []string
+		wantNote  string
+		wantLinks []string
+		wantErr   error
+	}{
+		{"OK: nil keeps both", nil, nil, "keep me", []string{"https://example.com/kept"}, nil},
+		{"OK: empty clears both", &empty, []string{}, "", nil, nil},
+		{"OK: note is sanitized", &html, nil, "xsee **this**", []string{"https://example.com/kept"}, nil},
+		{"NG: invalid link", nil, []string{"ftp://example.com"}, "", nil, apperr.ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := *annotated
+			repo := &mockDoneLogRepo{found: &found}
+			handler := UpdateDoneLogHandler{DoneLogs: repo, Categories: mockCategoryRepo{category: &Category{Active: true}}, Audit: &mockAuditLog{}, Time: fixedTime{}}
+			err := handler.Handle(context.Background(), UpdateDoneLogCommand{
+				ID: found.ID, Title: found.Title, CategoryID: found.CategoryID, Count: found.Count, OccurredOn: "2024-05-01",
+				Note: tt.note, Links: tt.links,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := repo.saved.Raw()
+			if got.Note != tt.wantNote || !reflect.DeepEqual(got.Links, tt.wantLinks) {
+				t.Fatalf("note = %q, links = %v", got.Note, got.Links)
+			}
+		})
+	}
+}
+
 func TestDeleteDoneLog(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -177,8 +264,8 @@ func TestDeleteDoneLog(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repo := &mockDoneLogRepo{err: tt.repoErr}
-			handler := DeleteDoneLogHandler{DoneLogs: repo}
+			repo := &mockDoneLogRepo{found: sampleRawDoneLog(), err: tt.repoErr}
+			handler := DeleteDoneLogHandler{DoneLogs: repo, Audit: &mockAuditLog{}, Time: fixedTime{value: time.Now()}}
 
 			cmd := DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"}
 
@@ -190,6 +277,698 @@ func TestDeleteDoneLog(t *testing.T) {
 	}
 }
 
+func TestDoneLogAudit(t *testing.T) {
+	recordedAt := time.Date(2024, 5, 2, 9, 30, 0, 0, time.UTC)
+	ctx := appctx.WithRequestID(appctx.WithActor(context.Background(), "taketo"), "req-1")
+
+	tests := []struct {
+		name        string
+		run         func(audit *mockAuditLog) error
+		wantAction  donelog.AuditAction
+		wantChanges []donelog.FieldChange
+	}{
+		{
+			name: "create records every field",
+			run: func(audit *mockAuditLog) error {
+				handler := CreateDoneLogHandler{
+					DoneLogs:   &mockDoneLogRepo{},
+					Tracks:     mockTrackRepo{track: &Track{Active: true}},
+					Categories: mockCategoryRepo{category: &Category{Active: true}},
+					IDs:        mockIDGenerator{id: mustDoneLogID(t, "01HYR1X5C9XM9P6H7K71M9QAHX")},
+					Audit:      audit,
+					Time:       fixedTime{value: recordedAt},
+				}
+				_, err := handler.Handle(ctx, CreateDoneLogCommand{
+					Title:      "Existing",
+					TrackID:    "track_sample",
+					CategoryID: "cat_old",
+					Count:      50,
+					OccurredOn: "2024-05-01",
+				})
+				return err
+			},
+			wantAction: donelog.AuditActionCreated,
+			wantChanges: []donelog.FieldChange{
+				{Field: "title", After: "Existing"},
+				{Field: "trackId", After: "track_sample"},
+				{Field: "categoryId", After: "cat_old"},
+				{Field: "count", After: "50"},
+				{Field: "occurredOn", After: "2024-05-01"},
+			},
+		},
+		{
+			name: "update records only changed fields",
+			run: func(audit *mockAuditLog) error {
+				handler := UpdateDoneLogHandler{
+					DoneLogs:   &mockDoneLogRepo{found: sampleRawDoneLog()},
+					Categories: mockCategoryRepo{category: &Category{Active: true}},
+					Audit:      audit,
+					Time:       fixedTime{value: recordedAt},
+				}
+				return handler.Handle(ctx, UpdateDoneLogCommand{
+					ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+					Title:      "Existing",
+					CategoryID: "cat_old",
+					Count:      5,
+					OccurredOn: "2024-05-01",
+				})
+			},
+			wantAction: donelog.AuditActionUpdated,
+			wantChanges: []donelog.FieldChange{
+				{Field: "count", Before: "50", After: "5"},
+			},
+		},
+		{
+			name: "update records note and links",
+			run: func(audit *mockAuditLog) error {
+				handler := UpdateDoneLogHandler{
+					DoneLogs:   &mockDoneLogRepo{found: sampleRawDoneLog()},
+					Categories: mockCategoryRepo{category: &Category{Active: true}},
+					Audit:      audit,
+					Time:       fixedTime{value: recordedAt},
+				}
+				note := "Learned *a lot*"
+				return handler.Handle(ctx, UpdateDoneLogCommand{
+					ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+					Title:      "Existing",
+					CategoryID: "cat_old",
+					Note:       &note,
+					Links:      []string{"https://example.com/a", "https://example.com/b"},
+					Count:      50,
+					OccurredOn: "2024-05-01",
+				})
+			},
+			wantAction: donelog.AuditActionUpdated,
+			wantChanges: []donelog.FieldChange{
+				{Field: "note", After: "Learned *a lot*"},
+				{Field: "links", After: "https://example.com/a https://example.com/b"},
+			},
+		},
+		{
+			name: "delete records the removed state",
+			run: func(audit *mockAuditLog) error {
+				handler := DeleteDoneLogHandler{
+					DoneLogs: &mockDoneLogRepo{found: sampleRawDoneLog()},
+					Audit:    audit,
+					Time:     fixedTime{value: recordedAt},
+				}
+				return handler.Handle(ctx, DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"})
+			},
+			wantAction: donelog.AuditActionDeleted,
+			wantChanges: []donelog.FieldChange{
+				{Field: "trashedAt", After: "2024-05-02T09:30:00Z"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &mockAuditLog{}
+			if err := tt.run(audit); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(audit.entries) != 1 {
+				t.Fatalf("expected 1 audit entry, got %d", len(audit.entries))
+			}
+			entry := audit.entries[0]
+			if entry.Action != tt.wantAction {
+				t.Fatalf("expected action %s, got %s", tt.wantAction, entry.Action)
+			}
+			if entry.Actor != "taketo" || entry.RequestID != "req-1" {
+				t.Fatalf("unexpected actor/request: %s/%s", entry.Actor, entry.RequestID)
+			}
+			if !entry.RecordedAt.Equal(recordedAt) {
+				t.Fatalf("unexpected recordedAt: %v", entry.RecordedAt)
+			}
+			if !reflect.DeepEqual(entry.Changes, tt.wantChanges) {
+				t.Fatalf("unexpected changes: %+v", entry.Changes)
+			}
+		})
+	}
+}
+
+func TestRestoreDoneLog(t *testing.T) {
+	trashedAt := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		trashedAt *time.Time
+		wantErr   bool
+	}{
+		{"OK: restores trashed log", &trashedAt, false},
+		{"NG: not in trash", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := sampleRawDoneLog()
+			found.TrashedAt = tt.trashedAt
+			repo := &mockDoneLogRepo{found: found}
+			handler := RestoreDoneLogHandler{DoneLogs: repo, Audit: &mockAuditLog{}, Time: fixedTime{}}
+
+			err := handler.Handle(context.Background(), RestoreDoneLogCommand{ID: found.ID})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err == nil && repo.saved.IsTrashed() {
+				t.Fatalf("expected restored log to leave the trash")
+			}
+		})
+	}
+}
+
+type mockTrashRepo struct {
+	trashed []donelog.RawDoneLog
+	cutoff  time.Time
+}
+
+func (m *mockTrashRepo) ListTrashedBefore(ctx context.Context, cutoff time.Time) ([]donelog.RawDoneLog, error) {
+	m.cutoff = cutoff
+	return m.trashed, nil
+}
+
+type recordingDeleteRepo struct {
+	mockDoneLogRepo
+	deleted []string
+}
+
+func (m *recordingDeleteRepo) Delete(ctx context.Context, id donelog.DoneLogID) error {
+	m.deleted = append(m.deleted, id.String())
+	return nil
+}
+
+func TestPurgeTrashedDoneLogs(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-31 * 24 * time.Hour)
+	recent := now.Add(-24 * time.Hour)
+
+	old := *sampleRawDoneLog()
+	old.TrashedAt = &expired
+	fresh := *sampleRawDoneLog()
+	fresh.ID = "01HYR1X5C9XM9P6H7K71M9QAHY"
+	fresh.TrashedAt = &recent
+
+	policy, err := donelog.NewPurgePolicy(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo := &recordingDeleteRepo{}
+	trash := &mockTrashRepo{trashed: []donelog.RawDoneLog{old, fresh}}
+	audit := &mockAuditLog{}
+	handler := PurgeTrashedDoneLogsHandler{
+		DoneLogs: repo,
+		Trash:    trash,
+		Policy:   policy,
+		Audit:    audit,
+		Time:     fixedTime{value: now},
+	}
+
+	purged, err := handler.Handle(context.Background(), PurgeTrashedDoneLogsCommand{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !trash.cutoff.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Fatalf("unexpected cutoff: %v", trash.cutoff)
+	}
+	if len(purged) != 1 || purged[0].String() != old.ID {
+		t.Fatalf("unexpected purged ids: %v", purged)
+	}
+	if !reflect.DeepEqual(repo.deleted, []string{old.ID}) {
+		t.Fatalf("unexpected deletes: %v", repo.deleted)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != donelog.AuditActionPurged {
+		t.Fatalf("expected one purge audit entry, got %+v", audit.entries)
+	}
+}
+
+type memoryUndoJournal struct {
+	entries map[string][]UndoEntry
+}
+
+func (m *memoryUndoJournal) Push(ctx context.Context, entry UndoEntry) error {
+	if m.entries == nil {
+		m.entries = map[string][]UndoEntry{}
+	}
+	m.entries[entry.Actor] = append(m.entries[entry.Actor], entry)
+	return nil
+}
+
+func (m *memoryUndoJournal) Latest(ctx context.Context, actor string) (*UndoEntry, error) {
+	entries := m.entries[actor]
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	latest := entries[len(entries)-1]
+	return &latest, nil
+}
+
+func (m *memoryUndoJournal) DropLatest(ctx context.Context, actor string) error {
+	entries := m.entries[actor]
+	if len(entries) > 0 {
+		m.entries[actor] = entries[:len(entries)-1]
+	}
+	return nil
+}
+
+// statefulDoneLogRepo keeps the latest saved state so undo can be observed end to end.
+type statefulDoneLogRepo struct {
+	current *donelog.RawDoneLog
+}
+
+func (m *statefulDoneLogRepo) Save(ctx context.Context, log *donelog.DoneLog) error {
+	raw := log.Raw()
+	m.current = &raw
+	return nil
+}
+func (m *statefulDoneLogRepo) FindByID(ctx context.Context, id donelog.DoneLogID) (*donelog.RawDoneLog, error) {
+	return m.current, nil
+}
+func (m *statefulDoneLogRepo) Delete(ctx context.Context, id donelog.DoneLogID) error {
+	m.current = nil
+	return nil
+}
+
+func TestUndoLastChange(t *testing.T) {
+	changedAt := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	ctx := appctx.WithActor(context.Background(), "taketo")
+
+	tests := []struct {
+		name       string
+		change     func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error
+		tamper     bool
+		undoAt     time.Time
+		wantErr    error
+		wantAction donelog.AuditAction
+		wantCount  int
+		wantGone   bool
+	}{
+		{
+			name: "OK: reverts fat-fingered count",
+			change: func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error {
+				repo.current = sampleRawDoneLog()
+				return UpdateDoneLogHandler{
+					DoneLogs:   repo,
+					Categories: mockCategoryRepo{category: &Category{Active: true}},
+					Audit:      &mockAuditLog{},
+					Time:       fixedTime{value: changedAt},
+					Undo:       journal,
+				}.Handle(ctx, UpdateDoneLogCommand{
+					ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+					Title:      "Existing",
+					CategoryID: "cat_old",
+					Count:      5,
+					OccurredOn: "2024-05-01",
+				})
+			},
+			undoAt:     changedAt.Add(3 * time.Minute),
+			wantAction: donelog.AuditActionUpdated,
+			wantCount:  50,
+		},
+		{
+			name: "OK: reverts create by removing the log",
+			change: func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error {
+				_, err := CreateDoneLogHandler{
+					DoneLogs:   repo,
+					Tracks:     mockTrackRepo{track: &Track{Active: true}},
+					Categories: mockCategoryRepo{category: &Category{Active: true}},
+					IDs:        mockIDGenerator{id: mustDoneLogID(t, "01HYR1X5C9XM9P6H7K71M9QAHX")},
+					Audit:      &mockAuditLog{},
+					Time:       fixedTime{value: changedAt},
+					Undo:       journal,
+				}.Handle(ctx, CreateDoneLogCommand{
+					Title:      "Oops",
+					TrackID:    "track_sample",
+					CategoryID: "cat_sample",
+					Count:      1,
+					OccurredOn: "2024-05-02",
+				})
+				return err
+			},
+			undoAt:     changedAt.Add(time.Minute),
+			wantAction: donelog.AuditActionCreated,
+			wantGone:   true,
+		},
+		{
+			name: "OK: reverts delete by leaving the trash",
+			change: func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error {
+				repo.current = sampleRawDoneLog()
+				return DeleteDoneLogHandler{
+					DoneLogs: repo,
+					Audit:    &mockAuditLog{},
+					Time:     fixedTime{value: changedAt},
+					Undo:     journal,
+				}.Handle(ctx, DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"})
+			},
+			undoAt:     changedAt.Add(time.Minute),
+			wantAction: donelog.AuditActionDeleted,
+			wantCount:  50,
+		},
+		{
+			name: "NG: outside the window",
+			change: func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error {
+				repo.current = sampleRawDoneLog()
+				return DeleteDoneLogHandler{
+					DoneLogs: repo,
+					Audit:    &mockAuditLog{},
+					Time:     fixedTime{value: changedAt},
+					Undo:     journal,
+				}.Handle(ctx, DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"})
+			},
+			undoAt:  changedAt.Add(6 * time.Minute),
+			wantErr: apperr.ErrConflict,
+		},
+		{
+			name: "NG: changed by someone else since",
+			change: func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error {
+				repo.current = sampleRawDoneLog()
+				return DeleteDoneLogHandler{
+					DoneLogs: repo,
+					Audit:    &mockAuditLog{},
+					Time:     fixedTime{value: changedAt},
+					Undo:     journal,
+				}.Handle(ctx, DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"})
+			},
+			tamper:  true,
+			undoAt:  changedAt.Add(time.Minute),
+			wantErr: apperr.ErrConflict,
+		},
+		{
+			name:    "NG: nothing to undo",
+			change:  func(repo *statefulDoneLogRepo, journal *memoryUndoJournal) error { return nil },
+			undoAt:  changedAt,
+			wantErr: apperr.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &statefulDoneLogRepo{}
+			journal := &memoryUndoJournal{}
+			if err := tt.change(repo, journal); err != nil {
+				t.Fatalf("change failed: %v", err)
+			}
+			if tt.tamper {
+				repo.current.Count = 7
+			}
+
+			audit := &mockAuditLog{}
+			handler := UndoLastChangeHandler{
+				DoneLogs: repo,
+				Undo:     journal,
+				Audit:    audit,
+				Time:     fixedTime{value: tt.undoAt},
+				Window:   5 * time.Minute,
+			}
+
+			result, err := handler.Handle(ctx, UndoLastChangeCommand{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Fatalf("expected action %s, got %s", tt.wantAction, result.Action)
+			}
+			if tt.wantGone {
+				if repo.current != nil {
+					t.Fatalf("expected created log to be removed")
+				}
+			} else {
+				if repo.current.Count != tt.wantCount || repo.current.TrashedAt != nil {
+					t.Fatalf("unexpected state after undo: %+v", repo.current)
+				}
+			}
+			if len(audit.entries) != 1 || audit.entries[0].Action != donelog.AuditActionReverted {
+				t.Fatalf("expected a reverted audit entry, got %+v", audit.entries)
+			}
+			if latest, _ := journal.Latest(ctx, "taketo"); latest != nil {
+				t.Fatalf("expected journal to be drained")
+			}
+		})
+	}
+
+	// Anonymous callers share no undo stack: nothing is recorded and undo is refused.
+	anonymous := context.Background()
+	repo := &statefulDoneLogRepo{current: sampleRawDoneLog()}
+	journal := &memoryUndoJournal{}
+	if err := (DeleteDoneLogHandler{DoneLogs: repo, Audit: &mockAuditLog{}, Time: fixedTime{value: changedAt}, Undo: journal}).Handle(anonymous, DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"}); err != nil {
+		t.Fatalf("anonymous delete: %v", err)
+	}
+	if len(journal.entries) != 0 {
+		t.Fatalf("anonymous changes must not be journaled: %+v", journal.entries)
+	}
+	handler := UndoLastChangeHandler{DoneLogs: repo, Undo: journal, Audit: &mockAuditLog{}, Time: fixedTime{value: changedAt}, Window: 5 * time.Minute}
+	if _, err := handler.Handle(anonymous, UndoLastChangeCommand{}); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("anonymous undo = %v", err)
+	}
+
+	// A caller that merely names itself alice does not reach the signed-in alice's stack.
+	aliceID, _ := donelog.NewOwnerID("alice")
+	signedIn := appctx.WithPrincipal(context.Background(), appctx.Principal{Owner: aliceID})
+	named := appctx.WithOwner(appctx.WithActor(context.Background(), "alice"), aliceID)
+	repo.current = sampleRawDoneLog()
+	if err := (DeleteDoneLogHandler{DoneLogs: repo, Audit: &mockAuditLog{}, Time: fixedTime{value: changedAt}, Undo: journal}).Handle(signedIn, DeleteDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"}); err != nil {
+		t.Fatalf("signed-in delete: %v", err)
+	}
+	if _, err := handler.Handle(named, UndoLastChangeCommand{}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("a named caller must not undo the signed-in user's change, got %v", err)
+	}
+	if _, err := handler.Handle(signedIn, UndoLastChangeCommand{}); err != nil {
+		t.Fatalf("signed-in undo: %v", err)
+	}
+}
+
+type countingTrackRepo struct {
+	calls int
+}
+
+func (m *countingTrackRepo) FindActiveByID(ctx context.Context, id donelog.TrackID) (*Track, error) {
+	m.calls++
+	return &Track{ID: id, Active: id.String() != "track_archived"}, nil
+}
+
+type sequentialIDGenerator struct {
+	ids []string
+}
+
+func (m *sequentialIDGenerator) NewDoneLogID(ctx context.Context) (donelog.DoneLogID, error) {
+	id := m.ids[0]
+	m.ids = m.ids[1:]
+	return donelog.NewDoneLogID(id)
+}
+
+// rollbackTx keeps saved logs only when fn succeeds, imitating a real transaction.
+type rollbackTx struct {
+	repo      *recordingSaveRepo
+	rollbacks int
+}
+
+func (m *rollbackTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	committed := len(m.repo.saved)
+	if err := fn(ctx); err != nil {
+		m.repo.saved = m.repo.saved[:committed]
+		m.rollbacks++
+		return err
+	}
+	return nil
+}
+
+type recordingSaveRepo struct {
+	mockDoneLogRepo
+	saved []string
+}
+
+func (m *recordingSaveRepo) Save(ctx context.Context, log *donelog.DoneLog) error {
+	m.saved = append(m.saved, log.ID().String())
+	return nil
+}
+
+func TestBatchDoneLog(t *testing.T) {
+	create := func(track string) BatchItem {
+		return BatchItem{Create: &CreateDoneLogCommand{
+			Title:      "Chapter",
+			TrackID:    track,
+			CategoryID: "cat_reading",
+			Count:      10,
+			OccurredOn: "2024-05-01",
+		}}
+	}
+
+	tests := []struct {
+		name           string
+		cmd            BatchDoneLogCommand
+		wantErr        bool
+		wantSaved      int
+		wantFailed     int
+		wantRolledBack bool
+		wantItemErrs   []error
+	}{
+		{
+			name:       "OK: atomic batch persists every item",
+			cmd:        BatchDoneLogCommand{Mode: BatchModeAtomic, Items: []BatchItem{create("track_book"), create("track_book"), create("track_exam")}},
+			wantSaved:  3,
+			wantFailed: 0,
+		},
+		{
+			name:           "NG: atomic batch rolls back on one failure",
+			cmd:            BatchDoneLogCommand{Mode: BatchModeAtomic, Items: []BatchItem{create("track_book"), create("track_archived"), create("track_book")}},
+			wantSaved:      0,
+			wantFailed:     3,
+			wantRolledBack: true,
+			wantItemErrs:   []error{ErrBatchRolledBack, nil, ErrBatchRolledBack},
+		},
+		{
+			name:       "OK: best effort keeps successful items",
+			cmd:        BatchDoneLogCommand{Mode: BatchModeBestEffort, Items: []BatchItem{create("track_book"), create("track_archived"), {}}},
+			wantSaved:  1,
+			wantFailed: 2,
+		},
+		{
+			name:    "NG: unknown mode",
+			cmd:     BatchDoneLogCommand{Mode: "maybe", Items: []BatchItem{create("track_book")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingSaveRepo{}
+			tracks := &countingTrackRepo{}
+			handler := BatchDoneLogHandler{
+				DoneLogs:   repo,
+				Tracks:     tracks,
+				Categories: mockCategoryRepo{category: &Category{Active: true}},
+				IDs: &sequentialIDGenerator{ids: []string{
+					"01HYR1X5C9XM9P6H7K71M9QAH1", "01HYR1X5C9XM9P6H7K71M9QAH2", "01HYR1X5C9XM9P6H7K71M9QAH3",
+				}},
+				Audit: &mockAuditLog{},
+				Time:  fixedTime{value: time.Now()},
+				Tx:    &rollbackTx{repo: repo},
+			}
+
+			result, err := handler.Handle(context.Background(), tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(repo.saved) != tt.wantSaved {
+				t.Fatalf("expected %d saved, got %d", tt.wantSaved, len(repo.saved))
+			}
+			if result.Failed() != tt.wantFailed {
+				t.Fatalf("expected %d failed, got %d: %+v", tt.wantFailed, result.Failed(), result.Items)
+			}
+			if result.RolledBack != tt.wantRolledBack {
+				t.Fatalf("RolledBack = %v, want %v", result.RolledBack, tt.wantRolledBack)
+			}
+			if tracks.calls > 2 {
+				t.Fatalf("expected at most one lookup per distinct track, got %d", tracks.calls)
+			}
+			for i, want := range tt.wantItemErrs {
+				if want != nil && !errors.Is(result.Items[i].Err, want) {
+					t.Fatalf("item %d error = %v, want %v", i, result.Items[i].Err, want)
+				}
+			}
+		})
+	}
+}
+
+type memoryIdempotencyStore struct {
+	records map[string]IdempotencyRecord
+}
+
+func (m *memoryIdempotencyStore) Find(ctx context.Context, actor, key string) (*IdempotencyRecord, error) {
+	record, ok := m.records[actor+"/"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (m *memoryIdempotencyStore) Save(ctx context.Context, record IdempotencyRecord) error {
+	if m.records == nil {
+		m.records = map[string]IdempotencyRecord{}
+	}
+	m.records[record.Actor+"/"+record.Key] = record
+	return nil
+}
+
+func TestCreateDoneLog_Idempotency(t *testing.T) {
+	original := CreateDoneLogCommand{
+		Title:          "Test",
+		TrackID:        "track_sample",
+		CategoryID:     "cat_sample",
+		Count:          2,
+		OccurredOn:     "2024-05-01",
+		IdempotencyKey: "retry-1",
+	}
+	changed := original
+	changed.Count = 3
+	otherKey := original
+	otherKey.IdempotencyKey = "retry-2"
+
+	tests := []struct {
+		name      string
+		replay    CreateDoneLogCommand
+		wantErr   error
+		wantID    string
+		wantSaves int
+	}{
+		{"OK: same key and payload returns original id", original, nil, "01HYR1X5C9XM9P6H7K71M9QAH1", 1},
+		{"OK: new key creates another log", otherKey, nil, "01HYR1X5C9XM9P6H7K71M9QAH2", 2},
+		{"NG: same key with different payload", changed, apperr.ErrConflict, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingSaveRepo{}
+			handler := CreateDoneLogHandler{
+				DoneLogs:   repo,
+				Tracks:     mockTrackRepo{track: &Track{Active: true}},
+				Categories: mockCategoryRepo{category: &Category{Active: true}},
+				IDs: &sequentialIDGenerator{ids: []string{
+					"01HYR1X5C9XM9P6H7K71M9QAH1", "01HYR1X5C9XM9P6H7K71M9QAH2",
+				}},
+				Audit:       &mockAuditLog{},
+				Time:        fixedTime{value: time.Now()},
+				Idempotency: &memoryIdempotencyStore{},
+			}
+			ctx := appctx.WithActor(context.Background(), "taketo")
+
+			if _, err := handler.Handle(ctx, original); err != nil {
+				t.Fatalf("first create failed: %v", err)
+			}
+
+			id, err := handler.Handle(ctx, tt.replay)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if id.String() != tt.wantID {
+				t.Fatalf("expected id %s, got %s", tt.wantID, id.String())
+			}
+			if len(repo.saved) != tt.wantSaves {
+				t.Fatalf("expected %d saves, got %d", tt.wantSaves, len(repo.saved))
+			}
+		})
+	}
+
+	t.Run("NG: key without store", func(t *testing.T) {
+		handler := CreateDoneLogHandler{}
+		if _, err := handler.Handle(context.Background(), original); err == nil {
+			t.Fatalf("expected error when no idempotency store is configured")
+		}
+	})
+}
+
 // helper
 type mockClock struct {
 	value donelog.OccurredOn
@@ -200,6 +979,17 @@ type assertErr string
 
 func (e assertErr) Error() string { return string(e) }
 
+func sampleRawDoneLog() *donelog.RawDoneLog {
+	return &donelog.RawDoneLog{
+		ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Title:      "Existing",
+		TrackID:    "track_sample",
+		CategoryID: "cat_old",
+		Count:      50,
+		OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
 func mustDoneLogID(t *testing.T, value string) donelog.DoneLogID {
 	t.Helper()
 	id, err := donelog.NewDoneLogID(value)
@@ -208,3 +998,523 @@ func mustDoneLogID(t *testing.T, value string) donelog.DoneLogID {
 	}
 	return id
 }
+
+type memoryTrackStore struct {
+	tracks map[string]donelog.RawTrack
+}
+
+func (m *memoryTrackStore) FindByID(ctx context.Context, id donelog.TrackID) (*donelog.RawTrack, error) {
+	if raw, ok := m.tracks[id.String()]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryTrackStore) Save(ctx context.Context, track *donelog.Track) error {
+	m.tracks[track.ID().String()] = track.Raw()
+	return nil
+}
+
+func TestTrackCatalog(t *testing.T) {
+	activeCategory := mockCategoryRepo{category: &Category{Active: true}}
+
+	tests := []struct {
+		name       string
+		existing   map[string]donelog.RawTrack
+		categories mockCategoryRepo
+		cmd        CreateTrackCommand
+		wantErr    error
+	}{
+		{
+			name:       "OK: with default category",
+			categories: activeCategory,
+			cmd:        CreateTrackCommand{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"},
+		},
+		{
+			name:     "NG: duplicate id",
+			existing: map[string]donelog.RawTrack{"reading": {ID: "reading", Name: "Reading", Active: true}},
+			cmd:      CreateTrackCommand{ID: "reading", Name: "Reading"},
+			wantErr:  apperr.ErrConflict,
+		},
+		{
+			name:    "NG: id is not a slug",
+			cmd:     CreateTrackCommand{ID: "Reading Club", Name: "Reading"},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name:       "NG: unknown default category",
+			categories: mockCategoryRepo{},
+			cmd:        CreateTrackCommand{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"},
+			wantErr:    apperr.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryTrackStore{tracks: map[string]donelog.RawTrack{}}
+			for id, raw := range tt.existing {
+				store.tracks[id] = raw
+			}
+			err := CreateTrackHandler{Tracks: store, Categories: tt.categories}.Handle(context.Background(), tt.cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := (ArchiveTrackHandler{Tracks: store}).Handle(context.Background(), ArchiveTrackCommand{ID: tt.cmd.ID}); err != nil {
+				t.Fatalf("archive: %v", err)
+			}
+			raw := store.tracks[tt.cmd.ID]
+			if raw.Active || raw.DefaultCategoryID != tt.cmd.DefaultCategoryID {
+				t.Fatalf("unexpected stored track: %+v", raw)
+			}
+		})
+	}
+}
+
+type memoryTeamStore struct {
+	teams map[string]donelog.RawTeam
+}
+
+func (m *memoryTeamStore) FindByID(ctx context.Context, id donelog.TeamID) (*donelog.RawTeam, error) {
+	if raw, ok := m.teams[id.String()]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryTeamStore) Save(ctx context.Context, team *donelog.Team) error {
+	m.teams[team.ID().String()] = team.Raw()
+	return nil
+}
+
+// memoryTeamTracks finds the team Tracks of a memoryTrackStore, which keys every Track by ID alone.
+type memoryTeamTracks struct {
+	tracks *memoryTrackStore
+	teams  *memoryTeamStore
+}
+
+func (m memoryTeamTracks) ListTeamTracks(ctx context.Context, team donelog.TeamID) ([]donelog.RawTrack, error) {
+	var tracks []donelog.RawTrack
+	for _, raw := range m.tracks.tracks {
+		if raw.Team == team.String() {
+			tracks = append(tracks, raw)
+		}
+	}
+	return tracks, nil
+}
+
+func (m memoryTeamTracks) FindMemberTeamTrack(ctx context.Context, member donelog.OwnerID, id donelog.TrackID) (*donelog.RawTrack, error) {
+	raw, ok := m.tracks.tracks[id.String()]
+	if !ok || raw.Team == "" {
+		return nil, nil
+	}
+	if _, isMember := m.teams.teams[raw.Team].Members[member.String()]; !isMember {
+		return nil, nil
+	}
+	return &raw, nil
+}
+
+func TestTeams(t *testing.T) {
+	as := func(user string) context.Context {
+		owner, _ := donelog.NewOwnerID(user)
+		return appctx.WithOwner(context.Background(), owner)
+	}
+	teams := &memoryTeamStore{teams: map[string]donelog.RawTeam{}}
+	tracks := &memoryTrackStore{tracks: map[string]donelog.RawTrack{}}
+	teamTracks := memoryTeamTracks{tracks: tracks, teams: teams}
+	createTrack := CreateTrackHandler{Tracks: tracks, Categories: mockCategoryRepo{category: &Category{Active: true}}, Teams: teams, TeamTracks: teamTracks}
+	accept := AcceptTeamInviteHandler{Teams: teams, TeamTracks: teamTracks}
+	archiveTrack := ArchiveTrackHandler{Tracks: tracks, Teams: teams}
+
+	if err := (CreateTeamHandler{Teams: teams}).Handle(context.Background(), CreateTeamCommand{ID: "club", Name: "Club"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("the single-user owner must not create teams, got %v", err)
+	}
+	if err := (CreateTeamHandler{Teams: teams}).Handle(as("alice"), CreateTeamCommand{ID: "club", Name: "Club"}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{
+			name: "NG: team id taken",
+			run: func() error {
+				return CreateTeamHandler{Teams: teams}.Handle(as("bob"), CreateTeamCommand{ID: "club", Name: "Mine"})
+			},
+			wantErr: apperr.ErrConflict,
+		},
+		{
+			name: "NG: non-members do not see the team",
+			run: func() error {
+				return SetTeamMemberHandler{Teams: teams}.Handle(as("bob"), SetTeamMemberCommand{TeamID: "club", Member: "bob", Role: "owner"})
+			},
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name: "OK: owner invites an editor",
+			run: func() error {
+				return SetTeamMemberHandler{Teams: teams}.Handle(as("alice"), SetTeamMemberCommand{TeamID: "club", Member: "bob", Role: "editor"})
+			},
+		},
+		{
+			name: "NG: invitees are not members until they accept",
+			run: func() error {
+				return SetLeaderboardOptOutHandler{Teams: teams}.Handle(as("bob"), SetLeaderboardOptOutCommand{TeamID: "club", OptOut: true})
+			},
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name:    "NG: accept without an invite",
+			run:     func() error { return accept.Handle(as("carol"), AcceptTeamInviteCommand{TeamID: "club"}) },
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name: "OK: the invitee accepts",
+			run:  func() error { return accept.Handle(as("bob"), AcceptTeamInviteCommand{TeamID: "club"}) },
+		},
+		{
+			name: "NG: unknown role",
+			run: func() error {
+				return SetTeamMemberHandler{Teams: teams}.Handle(as("alice"), SetTeamMemberCommand{TeamID: "club", Member: "carol", Role: "admin"})
+			},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name: "NG: editors do not manage members",
+			run: func() error {
+				return SetTeamMemberHandler{Teams: teams}.Handle(as("bob"), SetTeamMemberCommand{TeamID: "club", Member: "carol", Role: "viewer"})
+			},
+			wantErr: apperr.ErrForbidden,
+		},
+		{
+			name: "NG: editors do not add team tracks",
+			run: func() error {
+				return createTrack.Handle(as("bob"), CreateTrackCommand{ID: "dune", Name: "Dune", TeamID: "club"})
+			},
+			wantErr: apperr.ErrForbidden,
+		},
+		{
+			name: "NG: team tracks have no default category",
+			run: func() error {
+				return createTrack.Handle(as("alice"), CreateTrackCommand{ID: "dune", Name: "Dune", TeamID: "club", DefaultCategoryID: "pages"})
+			},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name: "OK: owner adds a team track",
+			run: func() error {
+				return createTrack.Handle(as("alice"), CreateTrackCommand{ID: "dune", Name: "Dune", TeamID: "club"})
+			},
+		},
+		{
+			name:    "NG: editors do not archive team tracks",
+			run:     func() error { return archiveTrack.Handle(as("bob"), ArchiveTrackCommand{ID: "dune"}) },
+			wantErr: apperr.ErrForbidden,
+		},
+		{
+			name: "NG: the last owner cannot leave",
+			run: func() error {
+				return RemoveTeamMemberHandler{Teams: teams}.Handle(as("alice"), RemoveTeamMemberCommand{TeamID: "club", Member: "alice"})
+			},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name: "OK: members opt out of leaderboards themselves",
+			run: func() error {
+				if err := (SetLeaderboardOptOutHandler{Teams: teams}).Handle(as("alice"), SetLeaderboardOptOutCommand{TeamID: "club", OptOut: true}); err != nil {
+					return err
+				}
+				return SetLeaderboardOptOutHandler{Teams: teams}.Handle(as("bob"), SetLeaderboardOptOutCommand{TeamID: "club", OptOut: true})
+			},
+		},
+		{
+			name: "NG: non-members cannot opt out",
+			run: func() error {
+				return SetLeaderboardOptOutHandler{Teams: teams}.Handle(as("carol"), SetLeaderboardOptOutCommand{TeamID: "club", OptOut: true})
+			},
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name: "OK: a member leaves",
+			run: func() error {
+				return RemoveTeamMemberHandler{Teams: teams}.Handle(as("bob"), RemoveTeamMemberCommand{TeamID: "club", Member: "bob"})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	if raw := tracks.tracks["dune"]; raw.Team != "club" || !raw.Active {
+		t.Fatalf("unexpected team track: %+v", raw)
+	}
+	if raw := teams.teams["club"]; len(raw.Members) != 1 || raw.Members["alice"] != "owner" || len(raw.LeaderboardOptOut) != 1 {
+		t.Fatalf("unexpected members: %+v", raw)
+	}
+}
+
+type memoryGoalRepo struct {
+	goals map[string]donelog.RawGoal
+}
+
+func (m *memoryGoalRepo) Save(ctx context.Context, goal *donelog.Goal) error {
+	m.goals[goal.ID().String()] = goal.Raw()
+	return nil
+}
+
+func (m *memoryGoalRepo) FindByID(ctx context.Context, id donelog.GoalID) (*donelog.RawGoal, error) {
+	if raw, ok := m.goals[id.String()]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryGoalRepo) Delete(ctx context.Context, id donelog.GoalID) error {
+	delete(m.goals, id.String())
+	return nil
+}
+
+type fixedGoalID string
+
+func (f fixedGoalID) NewGoalID(ctx context.Context) (donelog.GoalID, error) {
+	return donelog.NewGoalID(string(f))
+}
+
+func TestGoals(t *testing.T) {
+	const goalID = "01HYR1X5C9XM9P6H7K71M9QAHZ"
+	active := mockTrackRepo{track: &Track{Active: true}}
+	activeCategory := mockCategoryRepo{category: &Category{Active: true}}
+
+	tests := []struct {
+		name       string
+		tracks     mockTrackRepo
+		categories mockCategoryRepo
+		cmd        CreateGoalCommand
+		wantErr    error
+	}{
+		{
+			name:   "OK: track goal",
+			tracks: active,
+			cmd:    CreateGoalCommand{Name: "May", TrackID: "reading", StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 300},
+		},
+		{
+			name:       "OK: category goal",
+			categories: activeCategory,
+			cmd:        CreateGoalCommand{Name: "May", CategoryID: "pages", StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 300},
+		},
+		{
+			name:    "NG: both track and category",
+			tracks:  active,
+			cmd:     CreateGoalCommand{Name: "May", TrackID: "reading", CategoryID: "pages", StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 1},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name:    "NG: end before start",
+			tracks:  active,
+			cmd:     CreateGoalCommand{Name: "May", TrackID: "reading", StartDate: "2024-05-31", EndDate: "2024-05-01", Target: 1},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name:    "NG: archived track",
+			tracks:  mockTrackRepo{track: &Track{}},
+			cmd:     CreateGoalCommand{Name: "May", TrackID: "reading", StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 1},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name:       "NG: unknown category",
+			categories: mockCategoryRepo{},
+			cmd:        CreateGoalCommand{Name: "May", CategoryID: "pages", StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 1},
+			wantErr:    apperr.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryGoalRepo{goals: map[string]donelog.RawGoal{}}
+			h := CreateGoalHandler{Goals: repo, Tracks: tt.tracks, Categories: tt.categories, IDs: fixedGoalID(goalID)}
+			id, err := h.Handle(context.Background(), tt.cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			raw := repo.goals[id.String()]
+			if raw.TrackID != tt.cmd.TrackID || raw.CategoryID != tt.cmd.CategoryID || raw.Target != tt.cmd.Target {
+				t.Fatalf("unexpected stored goal: %+v", raw)
+			}
+
+			del := DeleteGoalHandler{Goals: repo}
+			if err := del.Handle(context.Background(), DeleteGoalCommand{ID: goalID}); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if err := del.Handle(context.Background(), DeleteGoalCommand{ID: goalID}); !errors.Is(err, apperr.ErrNotFound) {
+				t.Fatalf("second delete error = %v, want not found", err)
+			}
+		})
+	}
+}
+
+// memoryTaggedRepo keeps DONELOGs by ID and lists them by Tag.
+type memoryTaggedRepo struct {
+	logs map[string]donelog.RawDoneLog
+}
+
+func (m *memoryTaggedRepo) Save(ctx context.Context, log *donelog.DoneLog) error {
+	m.logs[log.ID().String()] = log.Raw()
+	return nil
+}
+func (m *memoryTaggedRepo) FindByID(ctx context.Context, id donelog.DoneLogID) (*donelog.RawDoneLog, error) {
+	if raw, ok := m.logs[id.String()]; ok {
+		return &raw, nil
+	}
+	return nil, nil
+}
+func (m *memoryTaggedRepo) Delete(ctx context.Context, id donelog.DoneLogID) error {
+	delete(m.logs, id.String())
+	return nil
+}
+func (m *memoryTaggedRepo) ListByTag(ctx context.Context, tag donelog.Tag) ([]donelog.RawDoneLog, error) {
+	var logs []donelog.RawDoneLog
+	for _, raw := range m.logs {
+		for _, t := range raw.Tags {
+			if t == tag.String() {
+				logs = append(logs, raw)
+			}
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].ID < logs[j].ID })
+	return logs, nil
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	trashedAt := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	seed := func(id string, trashed bool, tags ...string) donelog.RawDoneLog {
+		raw := donelog.RawDoneLog{ID: id, Title: "Article", TrackID: "reading", CategoryID: "cat_tech", Tags: tags, Count: 1, OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+		if trashed {
+			raw.TrashedAt = &trashedAt
+		}
+		return raw
+	}
+	repo := &memoryTaggedRepo{logs: map[string]donelog.RawDoneLog{
+		"01HYR1X5C9XM9P6H7K71M9QAH1": seed("01HYR1X5C9XM9P6H7K71M9QAH1", false, "go"),
+		"01HYR1X5C9XM9P6H7K71M9QAH2": seed("01HYR1X5C9XM9P6H7K71M9QAH2", true, "go", "golang"),
+	}}
+	audit := &mockAuditLog{}
+	tag := TagDoneLogHandler{DoneLogs: repo, Audit: audit, Time: fixedTime{}}
+	update := UpdateDoneLogHandler{DoneLogs: repo, Categories: mockCategoryRepo{category: &Category{Active: true}}, Audit: audit, Time: fixedTime{}}
+	updateCmd := UpdateDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Title: "Article", CategoryID: "cat_tech", Count: 2, OccurredOn: "2024-05-01"}
+
+	tests := []struct {
+		name     string
+		run      func() error
+		wantErr  error
+		wantTags string
+	}{
+		{
+			name: "OK: add and remove tags",
+			run: func() error {
+				return tag.Handle(ctx, TagDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Add: []string{"performance", "go"}, Remove: []string{"rust"}})
+			},
+			wantTags: "go,performance",
+		},
+		{
+			name: "NG: invalid tag",
+			run: func() error {
+				return tag.Handle(ctx, TagDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Add: []string{"Go Lang"}})
+			},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name: "NG: trashed DONELOG",
+			run: func() error {
+				return tag.Handle(ctx, TagDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAH2", Add: []string{"perf"}})
+			},
+			wantErr: apperr.ErrConflict,
+		},
+		{
+			name: "NG: unknown DONELOG",
+			run: func() error {
+				return tag.Handle(ctx, TagDoneLogCommand{ID: "01HYR1X5C9XM9P6H7K71M9QAH9", Add: []string{"perf"}})
+			},
+			wantErr: apperr.ErrNotFound,
+		},
+		{
+			name:     "OK: update without tags keeps them",
+			run:      func() error { return update.Handle(ctx, updateCmd) },
+			wantTags: "go,performance",
+		},
+		{
+			name: "OK: update replaces tags",
+			run: func() error {
+				cmd := updateCmd
+				cmd.Tags = []string{"go", "testing"}
+				return update.Handle(ctx, cmd)
+			},
+			wantTags: "go,testing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Join(repo.logs["01HYR1X5C9XM9P6H7K71M9QAH1"].Tags, ","); got != tt.wantTags {
+				t.Fatalf("tags = %s, want %s", got, tt.wantTags)
+			}
+		})
+	}
+
+	// Renaming reaches trashed DONELOGs too and merges into an existing tag.
+	renamed, err := RenameTagHandler{DoneLogs: repo, Tagged: repo, Audit: audit, Time: fixedTime{}}.Handle(ctx, RenameTagCommand{From: "go", To: "golang"})
+	if err != nil || renamed != 2 {
+		t.Fatalf("renamed = %d, %v", renamed, err)
+	}
+	if got := repo.logs["01HYR1X5C9XM9P6H7K71M9QAH2"]; strings.Join(got.Tags, ",") != "golang" || got.TrashedAt == nil {
+		t.Fatalf("unexpected trashed DONELOG after rename: %+v", got)
+	}
+	deleted, err := DeleteTagHandler{DoneLogs: repo, Tagged: repo, Audit: audit, Time: fixedTime{}}.Handle(ctx, DeleteTagCommand{Tag: "golang"})
+	if err != nil || deleted != 2 {
+		t.Fatalf("deleted = %d, %v", deleted, err)
+	}
+	if got := strings.Join(repo.logs["01HYR1X5C9XM9P6H7K71M9QAH1"].Tags, ","); got != "testing" {
+		t.Fatalf("tags after delete = %s", got)
+	}
+	last := audit.entries[len(audit.entries)-1]
+	if len(last.Changes) != 1 || last.Changes[0].Field != "tags" || last.Changes[0].Before != "golang" {
+		t.Fatalf("tag changes must be audited: %+v", last)
+	}
+	if _, err := (RenameTagHandler{DoneLogs: repo, Tagged: repo, Audit: audit, Time: fixedTime{}}).Handle(ctx, RenameTagCommand{From: "go"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("rename without target = %v", err)
+	}
+}
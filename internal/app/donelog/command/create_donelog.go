@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
 	"github.com/taketosaeki/donelog/internal/domain/donelog"
 )
 
@@ -12,8 +14,12 @@ type CreateDoneLogCommand struct {
 	Title      string
 	TrackID    string
 	CategoryID string
+	// Tags is optional; duplicates are dropped.
+	Tags       []string
 	Count      int
 	OccurredOn string
+	// IdempotencyKey is optional. Replays with the same key and payload return the original ID.
+	IdempotencyKey string
 }
 
 // Validate performs basic checks before constructing VO.
@@ -33,6 +39,9 @@ func (c CreateDoneLogCommand) Validate() error {
 	if c.OccurredOn == "" {
 		return fmt.Errorf("occurredOn is required")
 	}
+	if len(c.IdempotencyKey) > maxIdempotencyKeyLength {
+		return fmt.Errorf("idempotencyKey must be <= %d characters", maxIdempotencyKeyLength)
+	}
 	return nil
 }
 
@@ -42,45 +51,77 @@ type CreateDoneLogHandler struct {
 	Tracks     TrackRepository
 	Categories CategoryRepository
 	IDs        IDGenerator
+	Audit      AuditLog
+	Time       TimeSource
+	Undo       UndoJournal
+	// Idempotency is required only when commands carry an IdempotencyKey.
+	Idempotency IdempotencyStore
 }
 
 // Handle executes the command and returns the new DoneLogID.
 func (h CreateDoneLogHandler) Handle(ctx context.Context, cmd CreateDoneLogCommand) (donelog.DoneLogID, error) {
 	if err := cmd.Validate(); err != nil {
-		return donelog.DoneLogID{}, err
+		return donelog.DoneLogID{}, apperr.Invalid(err)
+	}
+
+	actor := appctx.Actor(ctx)
+	if cmd.IdempotencyKey != "" {
+		if h.Idempotency == nil {
+			return donelog.DoneLogID{}, fmt.Errorf("idempotency keys are not supported")
+		}
+		id, replayed, err := replayedDoneLogID(ctx, h.Idempotency, actor, cmd)
+		if err != nil {
+			return donelog.DoneLogID{}, err
+		}
+		if replayed {
+			return id, nil
+		}
 	}
 
 	title, err := donelog.NewTitle(cmd.Title)
 	if err != nil {
-		return donelog.DoneLogID{}, err
+		return donelog.DoneLogID{}, apperr.Invalid(err)
 	}
 	trackID, err := donelog.NewTrackID(cmd.TrackID)
 	if err != nil {
-		return donelog.DoneLogID{}, err
+		return donelog.DoneLogID{}, apperr.Invalid(err)
 	}
 	categoryID, err := donelog.NewCategoryID(cmd.CategoryID)
 	if err != nil {
-		return donelog.DoneLogID{}, err
+		return donelog.DoneLogID{}, apperr.Invalid(err)
+	}
+	tags, err := donelog.ParseTagSet(cmd.Tags)
+	if err != nil {
+		return donelog.DoneLogID{}, apperr.Invalid(err)
 	}
 	count, err := donelog.NewCount(cmd.Count)
 	if err != nil {
-		return donelog.DoneLogID{}, err
+		return donelog.DoneLogID{}, apperr.Invalid(err)
 	}
 
 	track, err := h.Tracks.FindActiveByID(ctx, trackID)
 	if err != nil {
 		return donelog.DoneLogID{}, err
 	}
-	if track == nil || !track.Active {
-		return donelog.DoneLogID{}, fmt.Errorf("track %s not active", trackID.String())
+	if track == nil {
+		return donelog.DoneLogID{}, fmt.Errorf("track %s: %w", trackID.String(), apperr.ErrNotFound)
+	}
+	if !track.Active {
+		return donelog.DoneLogID{}, apperr.Invalid(fmt.Errorf("track %s not active", trackID.String()))
+	}
+	if track.ReadOnly {
+		return donelog.DoneLogID{}, fmt.Errorf("track %s is read-only for viewers: %w", trackID.String(), apperr.ErrForbidden)
 	}
 
 	category, err := h.Categories.FindActiveByID(ctx, categoryID)
 	if err != nil {
 		return donelog.DoneLogID{}, err
 	}
-	if category == nil || !category.Active {
-		return donelog.DoneLogID{}, fmt.Errorf("category %s not active", categoryID.String())
+	if category == nil {
+		return donelog.DoneLogID{}, fmt.Errorf("category %s: %w", categoryID.String(), apperr.ErrNotFound)
+	}
+	if !category.Active {
+		return donelog.DoneLogID{}, apperr.Invalid(fmt.Errorf("category %s not active", categoryID.String()))
 	}
 
 	id, err := h.IDs.NewDoneLogID(ctx)
@@ -90,17 +131,41 @@ func (h CreateDoneLogHandler) Handle(ctx context.Context, cmd CreateDoneLogComma
 
 	occurredOn, err := donelog.NewOccurredOn(cmd.OccurredOn)
 	if err != nil {
-		return donelog.DoneLogID{}, err
+		return donelog.DoneLogID{}, apperr.Invalid(err)
 	}
 
-	log, err := donelog.NewDoneLog(id, title, trackID, categoryID, count, occurredOn)
+	log, err := donelog.NewDoneLog(id, title, trackID, categoryID, tags, count, occurredOn)
 	if err != nil {
 		return donelog.DoneLogID{}, err
 	}
+	if track.Team != nil {
+		log.RecordFor(*track.Team)
+	}
 
 	if err := h.DoneLogs.Save(ctx, log); err != nil {
 		return donelog.DoneLogID{}, err
 	}
 
+	after := log.Raw()
+	if err := recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionCreated, id, nil, &after); err != nil {
+		return donelog.DoneLogID{}, err
+	}
+	if err := recordUndo(ctx, h.Undo, h.Time, donelog.AuditActionCreated, id, nil, &after); err != nil {
+		return donelog.DoneLogID{}, err
+	}
+
+	if cmd.IdempotencyKey != "" {
+		record := IdempotencyRecord{
+			Actor:       actor,
+			Key:         cmd.IdempotencyKey,
+			Fingerprint: cmd.fingerprint(),
+			DoneLogID:   id.String(),
+			CreatedAt:   h.Time.Now(),
+		}
+		if err := h.Idempotency.Save(ctx, record); err != nil {
+			return donelog.DoneLogID{}, err
+		}
+	}
+
 	return id, nil
 }
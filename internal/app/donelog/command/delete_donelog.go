@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/taketosaeki/donelog/internal/app/apperr"
 	"github.com/taketosaeki/donelog/internal/domain/donelog"
 )
 
-// DeleteDoneLogCommand removes a DONELOG entry.
+// DeleteDoneLogCommand moves a DONELOG entry into the trash.
 type DeleteDoneLogCommand struct {
 	ID string
 }
@@ -20,19 +21,47 @@ func (c DeleteDoneLogCommand) Validate() error {
 }
 
 // DeleteDoneLogHandler handles DeleteDoneLogCommand.
+// The DONELOG is soft-deleted; PurgeTrashedDoneLogsHandler removes it permanently later.
 type DeleteDoneLogHandler struct {
 	DoneLogs DoneLogRepository
+	Audit    AuditLog
+	Time     TimeSource
+	Undo     UndoJournal
 }
 
 func (h DeleteDoneLogHandler) Handle(ctx context.Context, cmd DeleteDoneLogCommand) error {
 	if err := cmd.Validate(); err != nil {
-		return err
+		return apperr.Invalid(err)
 	}
 
 	id, err := donelog.NewDoneLogID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+
+	before, err := h.DoneLogs.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if before == nil {
+		return fmt.Errorf("doneLog %s: %w", id.String(), apperr.ErrNotFound)
+	}
 
-	return h.DoneLogs.Delete(ctx, id)
+	log, err := donelog.RehydrateDoneLog(*before)
+	if err != nil {
+		return err
+	}
+	if err := log.Trash(h.Time.Now()); err != nil {
+		return fmt.Errorf("%w: %w", apperr.ErrConflict, err)
+	}
+
+	if err := h.DoneLogs.Save(ctx, log); err != nil {
+		return err
+	}
+
+	after := log.Raw()
+	if err := recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionDeleted, id, before, &after); err != nil {
+		return err
+	}
+	return recordUndo(ctx, h.Undo, h.Time, donelog.AuditActionDeleted, id, before, &after)
 }
@@ -0,0 +1,11 @@
+package command
+
+import "github.com/taketosaeki/donelog/internal/app/apperr"
+
+// The command package's error kinds are the shared apperr ones, so callers may match either.
+var (
+	// ErrNotFound is wrapped when a referenced aggregate does not exist.
+	ErrNotFound = apperr.ErrNotFound
+	// ErrConflict is wrapped when the request clashes with the current state.
+	ErrConflict = apperr.ErrConflict
+)
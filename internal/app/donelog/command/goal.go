@@ -0,0 +1,153 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// CreateGoalCommand sets a target Count for one Track or one Category over a period.
+type CreateGoalCommand struct {
+	Name       string
+	TrackID    string
+	CategoryID string
+	StartDate  string
+	EndDate    string
+	Target     int
+}
+
+func (c CreateGoalCommand) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if (c.TrackID == "") == (c.CategoryID == "") {
+		return fmt.Errorf("exactly one of trackId and categoryId is required")
+	}
+	if c.StartDate == "" || c.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	if c.Target <= 0 {
+		return fmt.Errorf("target must be > 0")
+	}
+	return nil
+}
+
+// CreateGoalHandler handles CreateGoalCommand.
+type CreateGoalHandler struct {
+	Goals      GoalRepository
+	Tracks     TrackRepository
+	Categories CategoryRepository
+	IDs        GoalIDGenerator
+}
+
+// Handle creates the Goal and returns its ID. The targeted Track or Category must be active.
+func (h CreateGoalHandler) Handle(ctx context.Context, cmd CreateGoalCommand) (donelog.GoalID, error) {
+	if err := cmd.Validate(); err != nil {
+		return donelog.GoalID{}, apperr.Invalid(err)
+	}
+
+	var (
+		trackID    *donelog.TrackID
+		categoryID *donelog.CategoryID
+	)
+	if cmd.TrackID != "" {
+		id, err := donelog.NewTrackID(cmd.TrackID)
+		if err != nil {
+			return donelog.GoalID{}, apperr.Invalid(err)
+		}
+		track, err := h.Tracks.FindActiveByID(ctx, id)
+		if err != nil {
+			return donelog.GoalID{}, err
+		}
+		if track == nil {
+			return donelog.GoalID{}, fmt.Errorf("track %s: %w", id.String(), apperr.ErrNotFound)
+		}
+		if !track.Active {
+			return donelog.GoalID{}, apperr.Invalid(fmt.Errorf("track %s not active", id.String()))
+		}
+		trackID = &id
+	} else {
+		id, err := donelog.NewCategoryID(cmd.CategoryID)
+		if err != nil {
+			return donelog.GoalID{}, apperr.Invalid(err)
+		}
+		category, err := h.Categories.FindActiveByID(ctx, id)
+		if err != nil {
+			return donelog.GoalID{}, err
+		}
+		if category == nil {
+			return donelog.GoalID{}, fmt.Errorf("category %s: %w", id.String(), apperr.ErrNotFound)
+		}
+		if !category.Active {
+			return donelog.GoalID{}, apperr.Invalid(fmt.Errorf("category %s not active", id.String()))
+		}
+		categoryID = &id
+	}
+
+	start, err := donelog.NewOccurredOn(cmd.StartDate)
+	if err != nil {
+		return donelog.GoalID{}, apperr.Invalid(err)
+	}
+	end, err := donelog.NewOccurredOn(cmd.EndDate)
+	if err != nil {
+		return donelog.GoalID{}, apperr.Invalid(err)
+	}
+	period, err := donelog.NewPeriod(start, end)
+	if err != nil {
+		return donelog.GoalID{}, apperr.Invalid(err)
+	}
+	target, err := donelog.NewCount(cmd.Target)
+	if err != nil {
+		return donelog.GoalID{}, apperr.Invalid(err)
+	}
+
+	id, err := h.IDs.NewGoalID(ctx)
+	if err != nil {
+		return donelog.GoalID{}, err
+	}
+	goal, err := donelog.NewGoal(id, cmd.Name, trackID, categoryID, period, target)
+	if err != nil {
+		return donelog.GoalID{}, apperr.Invalid(err)
+	}
+	if err := h.Goals.Save(ctx, goal); err != nil {
+		return donelog.GoalID{}, err
+	}
+	return id, nil
+}
+
+// DeleteGoalCommand removes a Goal. DONELOGs are not affected.
+type DeleteGoalCommand struct {
+	ID string
+}
+
+func (c DeleteGoalCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// DeleteGoalHandler handles DeleteGoalCommand.
+type DeleteGoalHandler struct {
+	Goals GoalRepository
+}
+
+func (h DeleteGoalHandler) Handle(ctx context.Context, cmd DeleteGoalCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	id, err := donelog.NewGoalID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	existing, err := h.Goals.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("goal %s: %w", id.String(), apperr.ErrNotFound)
+	}
+	return h.Goals.Delete(ctx, id)
+}
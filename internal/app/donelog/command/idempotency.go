@@ -0,0 +1,70 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyRecord links a client-supplied key to the DONELOG it created.
+type IdempotencyRecord struct {
+	Actor       string
+	Key         string
+	Fingerprint string
+	DoneLogID   string
+	CreatedAt   time.Time
+}
+
+// IdempotencyStore persists idempotency keys per actor.
+// Save must fail with apperr.ErrConflict when the key already exists, so that racing
+// requests roll back inside a Transactor instead of creating duplicates.
+type IdempotencyStore interface {
+	Find(ctx context.Context, actor, key string) (*IdempotencyRecord, error)
+	Save(ctx context.Context, record IdempotencyRecord) error
+}
+
+// fingerprint identifies the payload of a create command so replays can be compared.
+func (c CreateDoneLogCommand) fingerprint() string {
+	payload := strings.Join([]string{
+		c.Title,
+		c.TrackID,
+		c.CategoryID,
+		strconv.Itoa(c.Count),
+		c.OccurredOn,
+	}, "\x00")
+	// Untagged payloads keep the fingerprints recorded before DONELOGs had tags.
+	if len(c.Tags) > 0 {
+		payload += "\x00" + strings.Join(c.Tags, ",")
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayedDoneLogID returns the ID created by an earlier request with the same key,
+// or a zero ID when the key has not been used yet.
+func replayedDoneLogID(ctx context.Context, store IdempotencyStore, actor string, cmd CreateDoneLogCommand) (donelog.DoneLogID, bool, error) {
+	record, err := store.Find(ctx, actor, cmd.IdempotencyKey)
+	if err != nil {
+		return donelog.DoneLogID{}, false, err
+	}
+	if record == nil {
+		return donelog.DoneLogID{}, false, nil
+	}
+	if record.Fingerprint != cmd.fingerprint() {
+		return donelog.DoneLogID{}, false, fmt.Errorf("idempotency key %q was used with a different payload: %w", cmd.IdempotencyKey, apperr.ErrConflict)
+	}
+	id, err := donelog.NewDoneLogID(record.DoneLogID)
+	if err != nil {
+		return donelog.DoneLogID{}, false, err
+	}
+	return id, true, nil
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"time"
 
 	"github.com/taketosaeki/donelog/internal/domain/donelog"
 )
@@ -13,6 +14,21 @@ type DoneLogRepository interface {
 	Delete(ctx context.Context, id donelog.DoneLogID) error
 }
 
+// TrashRepository lists soft-deleted DONELOGs for purging.
+type TrashRepository interface {
+	ListTrashedBefore(ctx context.Context, cutoff time.Time) ([]donelog.RawDoneLog, error)
+}
+
+// TaggedDoneLogFinder lists the caller's DONELOGs carrying a Tag, including trashed ones.
+type TaggedDoneLogFinder interface {
+	ListByTag(ctx context.Context, tag donelog.Tag) ([]donelog.RawDoneLog, error)
+}
+
+// SettingsRepository stores the caller's settings as key/value pairs, scoped by the owner in ctx.
+type SettingsRepository interface {
+	Set(ctx context.Context, key, value string) error
+}
+
 // TrackRepository provides access to Track aggregates.
 type TrackRepository interface {
 	FindActiveByID(ctx context.Context, id donelog.TrackID) (*Track, error)
@@ -23,12 +39,67 @@ type CategoryRepository interface {
 	FindActiveByID(ctx context.Context, id donelog.CategoryID) (*Category, error)
 }
 
+// TrackStore loads and stores whole Track aggregates for catalog management.
+type TrackStore interface {
+	FindByID(ctx context.Context, id donelog.TrackID) (*donelog.RawTrack, error)
+	Save(ctx context.Context, track *donelog.Track) error
+}
+
+// CategoryStore loads and stores whole Category aggregates for catalog management.
+type CategoryStore interface {
+	FindByID(ctx context.Context, id donelog.CategoryID) (*donelog.RawCategory, error)
+	Save(ctx context.Context, category *donelog.Category) error
+}
+
+// TeamStore loads and stores Team aggregates. Teams are shared, so lookups are not scoped by the owner in ctx.
+type TeamStore interface {
+	// FindByID returns nil when the Team does not exist.
+	FindByID(ctx context.Context, id donelog.TeamID) (*donelog.RawTeam, error)
+	Save(ctx context.Context, team *donelog.Team) error
+}
+
+// TeamTrackStore finds team Tracks across Teams, so a Track ID never names two Tracks for one member.
+type TeamTrackStore interface {
+	// ListTeamTracks returns the Tracks shared by team, archived ones included.
+	ListTeamTracks(ctx context.Context, team donelog.TeamID) ([]donelog.RawTrack, error)
+	// FindMemberTeamTrack returns the Track named id among the Teams member belongs to, or nil.
+	FindMemberTeamTrack(ctx context.Context, member donelog.OwnerID, id donelog.TrackID) (*donelog.RawTrack, error)
+}
+
+// GoalRepository stores Goal aggregates.
+type GoalRepository interface {
+	Save(ctx context.Context, goal *donelog.Goal) error
+	// FindByID returns nil when the Goal does not exist.
+	FindByID(ctx context.Context, id donelog.GoalID) (*donelog.RawGoal, error)
+	Delete(ctx context.Context, id donelog.GoalID) error
+}
+
 // IDGenerator creates unique DoneLogID values.
 type IDGenerator interface {
 	NewDoneLogID(ctx context.Context) (donelog.DoneLogID, error)
 }
 
+// GoalIDGenerator creates unique GoalID values.
+type GoalIDGenerator interface {
+	NewGoalID(ctx context.Context) (donelog.GoalID, error)
+}
+
+// Transactor runs fn inside a single transaction; returning an error rolls it back.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // Clock provides current time for OccurredOn defaults.
 type Clock interface {
 	Now() donelog.OccurredOn
 }
+
+// TimeSource provides wall-clock timestamps (e.g. for audit entries).
+type TimeSource interface {
+	Now() time.Time
+}
+
+// AuditLog stores the change history of DONELOG aggregates.
+type AuditLog interface {
+	Append(ctx context.Context, entry donelog.AuditEntry) error
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// PurgeTrashedDoneLogsCommand permanently removes DONELOGs whose trash retention has expired.
+type PurgeTrashedDoneLogsCommand struct{}
+
+// PurgeTrashedDoneLogsHandler handles PurgeTrashedDoneLogsCommand.
+type PurgeTrashedDoneLogsHandler struct {
+	DoneLogs DoneLogRepository
+	Trash    TrashRepository
+	Policy   donelog.PurgePolicy
+	Audit    AuditLog
+	Time     TimeSource
+}
+
+// Handle purges expired logs and returns the IDs that were removed.
+func (h PurgeTrashedDoneLogsHandler) Handle(ctx context.Context, cmd PurgeTrashedDoneLogsCommand) ([]donelog.DoneLogID, error) {
+	now := h.Time.Now()
+
+	candidates, err := h.Trash.ListTrashedBefore(ctx, h.Policy.Cutoff(now))
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []donelog.DoneLogID
+	for i := range candidates {
+		before := candidates[i]
+		log, err := donelog.RehydrateDoneLog(before)
+		if err != nil {
+			return purged, err
+		}
+		if !h.Policy.ShouldPurge(log, now) {
+			continue
+		}
+
+		if err := h.DoneLogs.Delete(ctx, log.ID()); err != nil {
+			return purged, err
+		}
+		if err := recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionPurged, log.ID(), &before, nil); err != nil {
+			return purged, err
+		}
+		purged = append(purged, log.ID())
+	}
+	return purged, nil
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// RestoreDoneLogCommand takes a DONELOG out of the trash.
+type RestoreDoneLogCommand struct {
+	ID string
+}
+
+func (c RestoreDoneLogCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// RestoreDoneLogHandler handles RestoreDoneLogCommand.
+type RestoreDoneLogHandler struct {
+	DoneLogs DoneLogRepository
+	Audit    AuditLog
+	Time     TimeSource
+}
+
+func (h RestoreDoneLogHandler) Handle(ctx context.Context, cmd RestoreDoneLogCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+
+	id, err := donelog.NewDoneLogID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+
+	before, err := h.DoneLogs.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return fmt.Errorf("doneLog %s: %w", id.String(), apperr.ErrNotFound)
+	}
+
+	log, err := donelog.RehydrateDoneLog(*before)
+	if err != nil {
+		return err
+	}
+	if err := log.Restore(); err != nil {
+		return fmt.Errorf("%w: %w", apperr.ErrConflict, err)
+	}
+
+	if err := h.DoneLogs.Save(ctx, log); err != nil {
+		return err
+	}
+
+	after := log.Raw()
+	return recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionRestored, id, before, &after)
+}
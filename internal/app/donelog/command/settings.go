@@ -0,0 +1,37 @@
+package command
+
+import (
+	"context"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// UpdateSettingsCommand changes the caller's settings. Empty fields keep their current value.
+type UpdateSettingsCommand struct {
+	WeekStart string
+}
+
+func (c UpdateSettingsCommand) Validate() error {
+	if c.WeekStart != "" {
+		if _, err := donelog.ParseWeekStart(c.WeekStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateSettingsHandler handles UpdateSettingsCommand. Each owner has their own settings.
+type UpdateSettingsHandler struct {
+	Settings SettingsRepository
+}
+
+func (h UpdateSettingsHandler) Handle(ctx context.Context, cmd UpdateSettingsCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	if cmd.WeekStart == "" {
+		return nil
+	}
+	return h.Settings.Set(ctx, donelog.WeekStartSetting, cmd.WeekStart)
+}
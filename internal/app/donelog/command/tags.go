@@ -0,0 +1,198 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// TagDoneLogCommand adds and removes Tags on one DONELOG. A Tag in both lists ends up removed.
+type TagDoneLogCommand struct {
+	ID     string
+	Add    []string
+	Remove []string
+}
+
+func (c TagDoneLogCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if len(c.Add) == 0 && len(c.Remove) == 0 {
+		return fmt.Errorf("add or remove is required")
+	}
+	return nil
+}
+
+// TagDoneLogHandler handles TagDoneLogCommand.
+type TagDoneLogHandler struct {
+	DoneLogs DoneLogRepository
+	Audit    AuditLog
+	Time     TimeSource
+	Undo     UndoJournal
+}
+
+func (h TagDoneLogHandler) Handle(ctx context.Context, cmd TagDoneLogCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	id, err := donelog.NewDoneLogID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	add, err := parseTags(cmd.Add)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	remove, err := parseTags(cmd.Remove)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+
+	rawLog, err := h.DoneLogs.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rawLog == nil {
+		return fmt.Errorf("doneLog %s: %w", id.String(), apperr.ErrNotFound)
+	}
+	log, err := donelog.RehydrateDoneLog(*rawLog)
+	if err != nil {
+		return err
+	}
+	if log.IsTrashed() {
+		return fmt.Errorf("doneLog %s is in the trash: %w", id.String(), apperr.ErrConflict)
+	}
+
+	tags, err := log.Tags().With(add...)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	log.SetTags(tags.Without(remove...))
+	if err := h.DoneLogs.Save(ctx, log); err != nil {
+		return err
+	}
+
+	after := log.Raw()
+	if err := recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionUpdated, id, rawLog, &after); err != nil {
+		return err
+	}
+	return recordUndo(ctx, h.Undo, h.Time, donelog.AuditActionUpdated, id, rawLog, &after)
+}
+
+// RenameTagCommand renames a Tag on every DONELOG of the caller, trashed ones included.
+// Renaming onto a Tag a DONELOG already carries merges the two.
+type RenameTagCommand struct {
+	From string
+	To   string
+}
+
+func (c RenameTagCommand) Validate() error {
+	if c.From == "" || c.To == "" {
+		return fmt.Errorf("from and to are required")
+	}
+	return nil
+}
+
+// RenameTagHandler handles RenameTagCommand. Like batches, the changes are audited but not undoable.
+type RenameTagHandler struct {
+	DoneLogs DoneLogRepository
+	Tagged   TaggedDoneLogFinder
+	Audit    AuditLog
+	Time     TimeSource
+}
+
+// Handle returns how many DONELOGs changed.
+func (h RenameTagHandler) Handle(ctx context.Context, cmd RenameTagCommand) (int, error) {
+	if err := cmd.Validate(); err != nil {
+		return 0, apperr.Invalid(err)
+	}
+	from, err := donelog.NewTag(cmd.From)
+	if err != nil {
+		return 0, apperr.Invalid(err)
+	}
+	to, err := donelog.NewTag(cmd.To)
+	if err != nil {
+		return 0, apperr.Invalid(err)
+	}
+	return retag(ctx, h.DoneLogs, h.Tagged, h.Audit, h.Time, from, &to)
+}
+
+// DeleteTagCommand removes a Tag from every DONELOG of the caller, trashed ones included.
+type DeleteTagCommand struct {
+	Tag string
+}
+
+func (c DeleteTagCommand) Validate() error {
+	if c.Tag == "" {
+		return fmt.Errorf("tag is required")
+	}
+	return nil
+}
+
+// DeleteTagHandler handles DeleteTagCommand. Like batches, the changes are audited but not undoable.
+type DeleteTagHandler struct {
+	DoneLogs DoneLogRepository
+	Tagged   TaggedDoneLogFinder
+	Audit    AuditLog
+	Time     TimeSource
+}
+
+// Handle returns how many DONELOGs changed.
+func (h DeleteTagHandler) Handle(ctx context.Context, cmd DeleteTagCommand) (int, error) {
+	if err := cmd.Validate(); err != nil {
+		return 0, apperr.Invalid(err)
+	}
+	tag, err := donelog.NewTag(cmd.Tag)
+	if err != nil {
+		return 0, apperr.Invalid(err)
+	}
+	return retag(ctx, h.DoneLogs, h.Tagged, h.Audit, h.Time, tag, nil)
+}
+
+// retag replaces from with to (or drops it when to is nil) on every DONELOG carrying from.
+func retag(ctx context.Context, logs DoneLogRepository, tagged TaggedDoneLogFinder, audit AuditLog, clock TimeSource, from donelog.Tag, to *donelog.Tag) (int, error) {
+	raws, err := tagged.ListByTag(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for i := range raws {
+		before := raws[i]
+		log, err := donelog.RehydrateDoneLog(before)
+		if err != nil {
+			return changed, err
+		}
+		tags := log.Tags().Without(from)
+		if to != nil {
+			// Swapping one Tag for another never grows the set past its limit.
+			tags, _ = tags.With(*to)
+		}
+		log.SetTags(tags)
+		after := log.Raw()
+		if len(donelog.DiffRawDoneLog(&before, &after)) == 0 {
+			continue
+		}
+		if err := logs.Save(ctx, log); err != nil {
+			return changed, err
+		}
+		if err := recordAudit(ctx, audit, clock, donelog.AuditActionUpdated, log.ID(), &before, &after); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+func parseTags(values []string) ([]donelog.Tag, error) {
+	tags := make([]donelog.Tag, 0, len(values))
+	for _, value := range values {
+		tag, err := donelog.NewTag(value)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
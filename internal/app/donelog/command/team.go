@@ -0,0 +1,296 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// CreateTeamCommand registers a new Team with the caller as its owner. IDs are caller-chosen slugs such as "book-club".
+type CreateTeamCommand struct {
+	ID   string
+	Name string
+}
+
+func (c CreateTeamCommand) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// CreateTeamHandler handles CreateTeamCommand.
+type CreateTeamHandler struct {
+	Teams TeamStore
+}
+
+func (h CreateTeamHandler) Handle(ctx context.Context, cmd CreateTeamCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	id, err := donelog.NewTeamID(cmd.ID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	owner := appctx.Owner(ctx)
+	if owner == (donelog.OwnerID{}) {
+		return apperr.Invalid(fmt.Errorf("teams need a signed-in user"))
+	}
+
+	existing, err := h.Teams.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("team %s already exists: %w", id.String(), apperr.ErrConflict)
+	}
+	team, err := donelog.NewTeam(id, cmd.Name, owner)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Teams.Save(ctx, team)
+}
+
+// SetTeamMemberCommand invites a user to a Team or changes a member's role. Only owners may run it;
+// invited users join once they accept.
+type SetTeamMemberCommand struct {
+	TeamID string
+	Member string
+	Role   string
+}
+
+func (c SetTeamMemberCommand) Validate() error {
+	if c.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	if c.Member == "" {
+		return fmt.Errorf("member is required")
+	}
+	if c.Role == "" {
+		return fmt.Errorf("role is required")
+	}
+	return nil
+}
+
+// SetTeamMemberHandler handles SetTeamMemberCommand.
+type SetTeamMemberHandler struct {
+	Teams TeamStore
+}
+
+func (h SetTeamMemberHandler) Handle(ctx context.Context, cmd SetTeamMemberCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	member, err := donelog.NewOwnerID(cmd.Member)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	role, err := donelog.ParseRole(cmd.Role)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	team, callerRole, err := loadTeam(ctx, h.Teams, cmd.TeamID)
+	if err != nil {
+		return err
+	}
+	if !callerRole.CanManage() {
+		return fmt.Errorf("only owners manage members of team %s: %w", cmd.TeamID, apperr.ErrForbidden)
+	}
+	if _, ok := team.RoleOf(member); ok {
+		err = team.SetRole(member, role)
+	} else {
+		err = team.Invite(member, role)
+	}
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Teams.Save(ctx, team)
+}
+
+// AcceptTeamInviteCommand makes the caller a member of a Team that invited them.
+type AcceptTeamInviteCommand struct {
+	TeamID string
+}
+
+func (c AcceptTeamInviteCommand) Validate() error {
+	if c.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	return nil
+}
+
+// AcceptTeamInviteHandler handles AcceptTeamInviteCommand. It refuses when one of the Team's Tracks
+// shares its ID with a Track of another Team the caller belongs to.
+type AcceptTeamInviteHandler struct {
+	Teams      TeamStore
+	TeamTracks TeamTrackStore
+}
+
+func (h AcceptTeamInviteHandler) Handle(ctx context.Context, cmd AcceptTeamInviteCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	id, err := donelog.NewTeamID(cmd.TeamID)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	raw, err := h.Teams.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Like loadTeam, a Team that has not invited the caller is reported as not found.
+	notFound := fmt.Errorf("invite to team %s: %w", cmd.TeamID, apperr.ErrNotFound)
+	if raw == nil {
+		return notFound
+	}
+	team, err := donelog.RehydrateTeam(*raw)
+	if err != nil {
+		return err
+	}
+	caller := appctx.Owner(ctx)
+	if _, ok := team.InvitedAs(caller); !ok {
+		return notFound
+	}
+
+	tracks, err := h.TeamTracks.ListTeamTracks(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, raw := range tracks {
+		trackID, err := donelog.NewTrackID(raw.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkTeamTrackFree(ctx, h.TeamTracks, caller, trackID); err != nil {
+			return err
+		}
+	}
+	if err := team.Accept(caller); err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Teams.Save(ctx, team)
+}
+
+// RemoveTeamMemberCommand removes a member from a Team, or withdraws an invite. Owners may remove anyone;
+// any member may leave.
+type RemoveTeamMemberCommand struct {
+	TeamID string
+	Member string
+}
+
+func (c RemoveTeamMemberCommand) Validate() error {
+	if c.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	if c.Member == "" {
+		return fmt.Errorf("member is required")
+	}
+	return nil
+}
+
+// RemoveTeamMemberHandler handles RemoveTeamMemberCommand.
+type RemoveTeamMemberHandler struct {
+	Teams TeamStore
+}
+
+func (h RemoveTeamMemberHandler) Handle(ctx context.Context, cmd RemoveTeamMemberCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	member, err := donelog.NewOwnerID(cmd.Member)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	team, callerRole, err := loadTeam(ctx, h.Teams, cmd.TeamID)
+	if err != nil {
+		return err
+	}
+	if !callerRole.CanManage() && member != appctx.Owner(ctx) {
+		return fmt.Errorf("only owners remove other members of team %s: %w", cmd.TeamID, apperr.ErrForbidden)
+	}
+	_, isMember := team.RoleOf(member)
+	_, invited := team.InvitedAs(member)
+	if !isMember && !invited {
+		return fmt.Errorf("member %s of team %s: %w", cmd.Member, cmd.TeamID, apperr.ErrNotFound)
+	}
+	if err := team.RemoveMember(member); err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Teams.Save(ctx, team)
+}
+
+// SetLeaderboardOptOutCommand hides the caller from, or shows them again on, a Team's leaderboards.
+type SetLeaderboardOptOutCommand struct {
+	TeamID string
+	OptOut bool
+}
+
+func (c SetLeaderboardOptOutCommand) Validate() error {
+	if c.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	return nil
+}
+
+// SetLeaderboardOptOutHandler handles SetLeaderboardOptOutCommand. Members only decide for themselves.
+type SetLeaderboardOptOutHandler struct {
+	Teams TeamStore
+}
+
+func (h SetLeaderboardOptOutHandler) Handle(ctx context.Context, cmd SetLeaderboardOptOutCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return apperr.Invalid(err)
+	}
+	team, _, err := loadTeam(ctx, h.Teams, cmd.TeamID)
+	if err != nil {
+		return err
+	}
+	if err := team.SetLeaderboardOptOut(appctx.Owner(ctx), cmd.OptOut); err != nil {
+		return apperr.Invalid(err)
+	}
+	return h.Teams.Save(ctx, team)
+}
+
+// checkTeamTrackFree reports a conflict when id already names a Track of one of member's Teams.
+func checkTeamTrackFree(ctx context.Context, teamTracks TeamTrackStore, member donelog.OwnerID, id donelog.TrackID) error {
+	existing, err := teamTracks.FindMemberTeamTrack(ctx, member, id)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("track %s already names a team track of %s: %w", id.String(), member.String(), apperr.ErrConflict)
+	}
+	return nil
+}
+
+// loadTeam returns the Team and the caller's role in it. Teams the caller does not belong to
+// are reported as not found, so their IDs do not leak.
+func loadTeam(ctx context.Context, teams TeamStore, value string) (*donelog.Team, donelog.Role, error) {
+	id, err := donelog.NewTeamID(value)
+	if err != nil {
+		return nil, "", apperr.Invalid(err)
+	}
+	raw, err := teams.FindByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	notFound := fmt.Errorf("team %s: %w", value, apperr.ErrNotFound)
+	if raw == nil {
+		return nil, "", notFound
+	}
+	team, err := donelog.RehydrateTeam(*raw)
+	if err != nil {
+		return nil, "", err
+	}
+	role, ok := team.RoleOf(appctx.Owner(ctx))
+	if !ok {
+		return nil, "", notFound
+	}
+	return team, role, nil
+}
@@ -7,6 +7,10 @@ type Track struct {
 	ID              donelog.TrackID
 	DefaultCategory *donelog.CategoryID
 	Active          bool
+	// ReadOnly is set for a team Track whose caller's role does not allow recording DONELOGs.
+	ReadOnly bool
+	// Team is set for a team Track; DONELOGs recorded against it count for that Team.
+	Team *donelog.TeamID
 }
 
 // Category is a minimal representation used by commands.
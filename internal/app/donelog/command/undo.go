@@ -0,0 +1,140 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// UndoEntry captures the before/after images needed to revert one DONELOG change.
+type UndoEntry struct {
+	Actor     string
+	Action    donelog.AuditAction
+	DoneLogID string
+	// Before is nil when the change created the DONELOG.
+	Before     *donelog.RawDoneLog
+	After      *donelog.RawDoneLog
+	RecordedAt time.Time
+}
+
+// UndoJournal keeps the undoable changes of each actor, most recent last.
+type UndoJournal interface {
+	Push(ctx context.Context, entry UndoEntry) error
+	Latest(ctx context.Context, actor string) (*UndoEntry, error)
+	DropLatest(ctx context.Context, actor string) error
+}
+
+// undoActor is whose undo stack a change belongs to: the authenticated account when there is one,
+// otherwise the named actor. The two get distinct prefixes, so a caller who merely names itself
+// "alice" never reaches the stack of the signed-in alice. It is empty for anonymous callers,
+// who get no undo at all.
+func undoActor(ctx context.Context) string {
+	if p, ok := appctx.Authenticated(ctx); ok {
+		return "owner:" + p.Owner.String()
+	}
+	if actor := appctx.Actor(ctx); actor != "" {
+		return "actor:" + actor
+	}
+	return ""
+}
+
+// recordUndo pushes an undo entry when the handler has a journal configured and the caller is known.
+func recordUndo(
+	ctx context.Context,
+	journal UndoJournal,
+	clock TimeSource,
+	action donelog.AuditAction,
+	id donelog.DoneLogID,
+	before, after *donelog.RawDoneLog,
+) error {
+	actor := undoActor(ctx)
+	if journal == nil || actor == "" {
+		return nil
+	}
+	return journal.Push(ctx, UndoEntry{
+		Actor:      actor,
+		Action:     action,
+		DoneLogID:  id.String(),
+		Before:     before,
+		After:      after,
+		RecordedAt: clock.Now(),
+	})
+}
+
+// UndoLastChangeCommand reverts the caller's most recent create/update/delete. Anonymous callers cannot undo.
+type UndoLastChangeCommand struct{}
+
+// UndoResult describes what was reverted.
+type UndoResult struct {
+	DoneLogID donelog.DoneLogID
+	Action    donelog.AuditAction
+}
+
+// UndoLastChangeHandler handles UndoLastChangeCommand.
+// Only changes recorded within Window can be undone, and only while the DONELOG
+// still looks exactly as the change left it.
+type UndoLastChangeHandler struct {
+	DoneLogs DoneLogRepository
+	Undo     UndoJournal
+	Audit    AuditLog
+	Time     TimeSource
+	Window   time.Duration
+}
+
+func (h UndoLastChangeHandler) Handle(ctx context.Context, cmd UndoLastChangeCommand) (UndoResult, error) {
+	actor := undoActor(ctx)
+	if actor == "" {
+		return UndoResult{}, fmt.Errorf("undo needs a signed-in or named caller: %w", apperr.ErrUnauthorized)
+	}
+
+	entry, err := h.Undo.Latest(ctx, actor)
+	if err != nil {
+		return UndoResult{}, err
+	}
+	if entry == nil {
+		return UndoResult{}, fmt.Errorf("nothing to undo: %w", apperr.ErrNotFound)
+	}
+	if h.Time.Now().Sub(entry.RecordedAt) > h.Window {
+		return UndoResult{}, fmt.Errorf("last change is older than %s: %w", h.Window, apperr.ErrConflict)
+	}
+
+	id, err := donelog.NewDoneLogID(entry.DoneLogID)
+	if err != nil {
+		return UndoResult{}, err
+	}
+
+	current, err := h.DoneLogs.FindByID(ctx, id)
+	if err != nil {
+		return UndoResult{}, err
+	}
+	if len(donelog.DiffRawDoneLog(current, entry.After)) > 0 {
+		return UndoResult{}, fmt.Errorf("doneLog %s changed since the last %s: %w", id.String(), entry.Action, apperr.ErrConflict)
+	}
+
+	if entry.Before == nil {
+		if err := h.DoneLogs.Delete(ctx, id); err != nil {
+			return UndoResult{}, err
+		}
+	} else {
+		log, err := donelog.RehydrateDoneLog(*entry.Before)
+		if err != nil {
+			return UndoResult{}, err
+		}
+		if err := h.DoneLogs.Save(ctx, log); err != nil {
+			return UndoResult{}, err
+		}
+	}
+
+	if err := h.Undo.DropLatest(ctx, actor); err != nil {
+		return UndoResult{}, err
+	}
+	if err := recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionReverted, id, current, entry.Before); err != nil {
+		return UndoResult{}, err
+	}
+
+	return UndoResult{DoneLogID: id, Action: entry.Action}, nil
+}
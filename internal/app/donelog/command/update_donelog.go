@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/taketosaeki/donelog/internal/app/apperr"
 	"github.com/taketosaeki/donelog/internal/domain/donelog"
 )
 
@@ -12,6 +13,12 @@ type UpdateDoneLogCommand struct {
 	ID         string
 	Title      string
 	CategoryID string
+	// Tags replaces the current Tags; nil keeps them and an empty slice clears them.
+	Tags []string
+	// Note replaces the Markdown note; nil keeps it and an empty string clears it.
+	Note *string
+	// Links replaces the attached URLs; nil keeps them and an empty slice clears them.
+	Links      []string
 	Count      int
 	OccurredOn string
 }
@@ -39,20 +46,23 @@ func (c UpdateDoneLogCommand) Validate() error {
 type UpdateDoneLogHandler struct {
 	DoneLogs   DoneLogRepository
 	Categories CategoryRepository
+	Audit      AuditLog
+	Time       TimeSource
+	Undo       UndoJournal
 }
 
 func (h UpdateDoneLogHandler) Handle(ctx context.Context, cmd UpdateDoneLogCommand) error {
 	if err := cmd.Validate(); err != nil {
-		return err
+		return apperr.Invalid(err)
 	}
 
 	id, err := donelog.NewDoneLogID(cmd.ID)
 	if err != nil {
-		return err
+		return apperr.Invalid(err)
 	}
 	categoryID, err := donelog.NewCategoryID(cmd.CategoryID)
 	if err != nil {
-		return err
+		return apperr.Invalid(err)
 	}
 
 	rawLog, err := h.DoneLogs.FindByID(ctx, id)
@@ -60,40 +70,70 @@ func (h UpdateDoneLogHandler) Handle(ctx context.Context, cmd UpdateDoneLogComma
 		return err
 	}
 	if rawLog == nil {
-		return fmt.Errorf("doneLog %s not found", id.String())
+		return fmt.Errorf("doneLog %s: %w", id.String(), apperr.ErrNotFound)
 	}
 
 	log, err := donelog.RehydrateDoneLog(*rawLog)
 	if err != nil {
 		return err
 	}
+	if log.IsTrashed() {
+		return fmt.Errorf("doneLog %s is in the trash: %w", id.String(), apperr.ErrConflict)
+	}
 
 	category, err := h.Categories.FindActiveByID(ctx, categoryID)
 	if err != nil {
 		return err
 	}
-	if category == nil || !category.Active {
-		return fmt.Errorf("category %s not active", categoryID.String())
+	if category == nil {
+		return fmt.Errorf("category %s: %w", categoryID.String(), apperr.ErrNotFound)
+	}
+	if !category.Active {
+		return apperr.Invalid(fmt.Errorf("category %s not active", categoryID.String()))
 	}
 
+	title, err := donelog.NewTitle(cmd.Title)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
+	count, err := donelog.NewCount(cmd.Count)
+	if err != nil {
+		return apperr.Invalid(err)
+	}
 	occurredOn, err := donelog.NewOccurredOn(cmd.OccurredOn)
 	if err != nil {
-		return err
+		return apperr.Invalid(err)
+	}
+	tags := log.Tags()
+	if cmd.Tags != nil {
+		if tags, err = donelog.ParseTagSet(cmd.Tags); err != nil {
+			return apperr.Invalid(err)
+		}
+	}
+	note := log.Note()
+	if cmd.Note != nil {
+		if note, err = donelog.NewNote(*cmd.Note); err != nil {
+			return apperr.Invalid(err)
+		}
+	}
+	links := log.Links()
+	if cmd.Links != nil {
+		if links, err = donelog.ParseLinks(cmd.Links); err != nil {
+			return apperr.Invalid(err)
+		}
 	}
 
-	raw := donelog.RawDoneLog{
-		ID:         rawLog.ID,
-		Title:      cmd.Title,
-		TrackID:    rawLog.TrackID,
-		CategoryID: cmd.CategoryID,
-		Count:      cmd.Count,
-		OccurredOn: occurredOn.Time(),
-	}
+	// Changing the aggregate in place keeps what the command does not carry, such as its Team.
+	log.Update(title, categoryID, tags, count, occurredOn)
+	log.Annotate(note, links)
 
-	log, err = donelog.RehydrateDoneLog(raw)
-	if err != nil {
+	if err := h.DoneLogs.Save(ctx, log); err != nil {
 		return err
 	}
 
-	return h.DoneLogs.Save(ctx, log)
+	after := log.Raw()
+	if err := recordAudit(ctx, h.Audit, h.Time, donelog.AuditActionUpdated, id, rawLog, &after); err != nil {
+		return err
+	}
+	return recordUndo(ctx, h.Undo, h.Time, donelog.AuditActionUpdated, id, rawLog, &after)
 }
@@ -0,0 +1,195 @@
+// Package export writes DONELOGs out as CSV, JSON Lines or XLSX.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(value); f {
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %q", value)
+	}
+}
+
+// ContentType returns the MIME type for HTTP downloads.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Request selects what to export.
+type Request struct {
+	Period     donelog.Period
+	TrackID    *donelog.TrackID
+	CategoryID *donelog.CategoryID
+	Format     Format
+}
+
+// ParseRequest builds a Request from primitive input such as query strings or CLI flags.
+// Empty track and category mean "all".
+func ParseRequest(startDate, endDate, trackID, categoryID, format string) (Request, error) {
+	start, err := donelog.NewOccurredOn(startDate)
+	if err != nil {
+		return Request{}, fmt.Errorf("startDate: %w", err)
+	}
+	end, err := donelog.NewOccurredOn(endDate)
+	if err != nil {
+		return Request{}, fmt.Errorf("endDate: %w", err)
+	}
+	period, err := donelog.NewPeriod(start, end)
+	if err != nil {
+		return Request{}, err
+	}
+	f, err := ParseFormat(format)
+	if err != nil {
+		return Request{}, err
+	}
+
+	req := Request{Period: period, Format: f}
+	if trackID != "" {
+		id, err := donelog.NewTrackID(trackID)
+		if err != nil {
+			return Request{}, err
+		}
+		req.TrackID = &id
+	}
+	if categoryID != "" {
+		id, err := donelog.NewCategoryID(categoryID)
+		if err != nil {
+			return Request{}, err
+		}
+		req.CategoryID = &id
+	}
+	return req, nil
+}
+
+// Row is one exported DONELOG with Track/Category names resolved.
+type Row struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	TrackID      string `json:"trackId"`
+	TrackName    string `json:"trackName"`
+	CategoryID   string `json:"categoryId"`
+	CategoryName string `json:"categoryName"`
+	Count        int    `json:"count"`
+	OccurredOn   string `json:"occurredOn"`
+}
+
+var header = []string{"id", "title", "trackId", "trackName", "categoryId", "categoryName", "count", "occurredOn"}
+
+func (r Row) fields() []string {
+	return []string{r.ID, r.Title, r.TrackID, r.TrackName, r.CategoryID, r.CategoryName, strconv.Itoa(r.Count), r.OccurredOn}
+}
+
+// rowWriter streams rows in one format.
+type rowWriter interface {
+	Write(row Row) error
+	Close() error
+}
+
+// Exporter reads DONELOGs through the query side and streams them to a writer.
+type Exporter struct {
+	DoneLogs   query.DoneLogReader
+	Tracks     query.TrackReader
+	Categories query.CategoryReader
+}
+
+// Export writes every non-trashed DONELOG matching req to w.
+func (e Exporter) Export(ctx context.Context, req Request, w io.Writer) error {
+	trackNames, err := e.trackNames(ctx)
+	if err != nil {
+		return err
+	}
+	categoryNames, err := e.categoryNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	logs, err := e.DoneLogs.ListByPeriod(ctx, req.Period, query.DoneLogFilter{TrackID: req.TrackID, CategoryID: req.CategoryID})
+	if err != nil {
+		return err
+	}
+
+	out, err := newRowWriter(req.Format, w)
+	if err != nil {
+		return err
+	}
+	for _, raw := range logs {
+		row := Row{
+			ID:           raw.ID,
+			Title:        raw.Title,
+			TrackID:      raw.TrackID,
+			TrackName:    trackNames[raw.TrackID],
+			CategoryID:   raw.CategoryID,
+			CategoryName: categoryNames[raw.CategoryID],
+			Count:        raw.Count,
+			OccurredOn:   donelog.OccurredOnFromTime(raw.OccurredOn).String(),
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+func (e Exporter) trackNames(ctx context.Context) (map[string]string, error) {
+	tracks, err := e.Tracks.ListTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(tracks))
+	for _, t := range tracks {
+		names[t.ID] = t.Name
+	}
+	return names, nil
+}
+
+func (e Exporter) categoryNames(ctx context.Context) (map[string]string, error) {
+	categories, err := e.Categories.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+func newRowWriter(format Format, w io.Writer) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q", format)
+	}
+}
@@ -0,0 +1,197 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+type stubReader struct {
+	logs   []donelog.RawDoneLog
+	filter query.DoneLogFilter
+}
+
+func (s *stubReader) ListByPeriod(ctx context.Context, period donelog.Period, filter query.DoneLogFilter) ([]donelog.RawDoneLog, error) {
+	s.filter = filter
+	return s.logs, nil
+}
+
+type stubCatalog struct{}
+
+func (stubCatalog) ListTracks(ctx context.Context) ([]donelog.RawTrack, error) {
+	return []donelog.RawTrack{{ID: "track_book", Name: "Clean Architecture"}}, nil
+}
+
+func (stubCatalog) ListCategories(ctx context.Context) ([]donelog.RawCategory, error) {
+	return []donelog.RawCategory{{ID: "cat_reading", Name: "読書"}}, nil
+}
+
+func TestExport(t *testing.T) {
+	logs := []donelog.RawDoneLog{{
+		ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Title:      `ch.1 "Design" & <Architecture>`,
+		TrackID:    "track_book",
+		CategoryID: "cat_reading",
+		Count:      12,
+		OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	tests := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, out []byte)
+	}{
+		{
+			name:   "csv",
+			format: "csv",
+			check: func(t *testing.T, out []byte) {
+				want := "id,title,trackId,trackName,categoryId,categoryName,count,occurredOn\n" +
+					`01HYR1X5C9XM9P6H7K71M9QAHX,"ch.1 ""Design"" & <Architecture>",track_book,Clean Architecture,cat_reading,読書,12,2024-05-01` + "\n"
+				if string(out) != want {
+					t.Fatalf("unexpected csv:\n%s", out)
+				}
+			},
+		},
+		{
+			name:   "jsonl",
+			format: "jsonl",
+			check: func(t *testing.T, out []byte) {
+				var row Row
+				if err := json.Unmarshal(bytes.TrimSpace(out), &row); err != nil {
+					t.Fatalf("decode failed: %v", err)
+				}
+				if row.TrackName != "Clean Architecture" || row.CategoryName != "読書" || row.Count != 12 {
+					t.Fatalf("unexpected row: %+v", row)
+				}
+			},
+		},
+		{
+			name:   "xlsx",
+			format: "xlsx",
+			check: func(t *testing.T, out []byte) {
+				zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+				if err != nil {
+					t.Fatalf("not a zip archive: %v", err)
+				}
+				var sheet string
+				for _, f := range zr.File {
+					if f.Name != "xl/worksheets/sheet1.xml" {
+						continue
+					}
+					rc, _ := f.Open()
+					b, _ := io.ReadAll(rc)
+					rc.Close()
+					sheet = string(b)
+				}
+				if !strings.Contains(sheet, `<c r="G2"><v>12</v></c>`) {
+					t.Fatalf("expected numeric count cell, got %s", sheet)
+				}
+				if !strings.Contains(sheet, "&lt;Architecture&gt;") || !strings.Contains(sheet, "Clean Architecture") {
+					t.Fatalf("expected escaped title and resolved track name, got %s", sheet)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParseRequest("2024-05-01", "2024-05-31", "track_book", "", tt.format)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			reader := &stubReader{logs: logs}
+			exporter := Exporter{DoneLogs: reader, Tracks: stubCatalog{}, Categories: stubCatalog{}}
+
+			var buf bytes.Buffer
+			if err := exporter.Export(context.Background(), req, &buf); err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+			if reader.filter.TrackID == nil || reader.filter.CategoryID != nil {
+				t.Fatalf("unexpected filter: %+v", reader.filter)
+			}
+			tt.check(t, buf.Bytes())
+		})
+	}
+}
+
+func TestExportNeutralizesFormulas(t *testing.T) {
+	tests := []struct {
+		title   string
+		wantCSV string
+	}{
+		{title: `=HYPERLINK("http://evil.example","x")`, wantCSV: `"'=HYPERLINK(""http://evil.example"",""x"")"`},
+		{title: "+1+1", wantCSV: "'+1+1"},
+		{title: "-2", wantCSV: "'-2"},
+		{title: "@SUM(A1)", wantCSV: "'@SUM(A1)"},
+		{title: "\t=1", wantCSV: "'\t=1"},
+		{title: "1-2 = -1", wantCSV: "1-2 = -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			logs := []donelog.RawDoneLog{{
+				ID: "01HYR1X5C9XM9P6H7K71M9QAHX", Title: tt.title, TrackID: "track_book", CategoryID: "cat_reading",
+				Count: 1, OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			}}
+			exporter := Exporter{DoneLogs: &stubReader{logs: logs}, Tracks: stubCatalog{}, Categories: stubCatalog{}}
+			export := func(format string) []byte {
+				req, _ := ParseRequest("2024-05-01", "2024-05-31", "", "", format)
+				var buf bytes.Buffer
+				if err := exporter.Export(context.Background(), req, &buf); err != nil {
+					t.Fatalf("export %s: %v", format, err)
+				}
+				return buf.Bytes()
+			}
+
+			if out := string(export("csv")); !strings.Contains(out, ","+tt.wantCSV+",") {
+				t.Fatalf("csv title cell = %q, want %q", out, tt.wantCSV)
+			}
+			out := export("xlsx")
+			zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+			if err != nil {
+				t.Fatalf("not a zip archive: %v", err)
+			}
+			for _, f := range zr.File {
+				if f.Name != "xl/worksheets/sheet1.xml" {
+					continue
+				}
+				rc, _ := f.Open()
+				sheet, _ := io.ReadAll(rc)
+				rc.Close()
+				if !strings.Contains(string(sheet), `<c r="B2" t="inlineStr">`) || strings.Contains(string(sheet), "<f>") {
+					t.Fatalf("xlsx title must be an inline string, got %s", sheet)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		format  string
+		wantErr bool
+	}{
+		{"OK", "2024-05-01", "2024-05-31", "jsonl", false},
+		{"NG: reversed period", "2024-05-31", "2024-05-01", "csv", true},
+		{"NG: unknown format", "2024-05-01", "2024-05-31", "pdf", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRequest(tt.start, tt.end, "", "", tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) Write(row Row) error {
+	fields := row.fields()
+	for i, v := range fields {
+		fields[i] = neutralizeFormula(v)
+	}
+	if err := c.w.Write(fields); err != nil {
+		return err
+	}
+	// Flush per row so large exports reach HTTP clients progressively.
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formulaPrefixes start a cell that spreadsheet apps evaluate instead of showing as text.
+const formulaPrefixes = "=+-@\t\r"
+
+// neutralizeFormula prefixes a quote to values that would otherwise run as a formula when the CSV is
+// opened in a spreadsheet. XLSX needs no prefix: its text cells are inline strings, never formulas.
+func neutralizeFormula(v string) string {
+	if v != "" && strings.ContainsRune(formulaPrefixes, rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{enc: enc}
+}
+
+func (j *jsonlWriter) Write(row Row) error {
+	return j.enc.Encode(row)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,293 @@
+// Package importer loads historic DONELOGs from external files.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// ColumnMapping names the CSV header for each DONELOG field.
+type ColumnMapping struct {
+	Title      string
+	TrackID    string
+	CategoryID string
+	Count      string
+	OccurredOn string
+}
+
+// DefaultColumnMapping matches a header of "title,track,category,count,date".
+var DefaultColumnMapping = ColumnMapping{
+	Title:      "title",
+	TrackID:    "track",
+	CategoryID: "category",
+	Count:      "count",
+	OccurredOn: "date",
+}
+
+// ParseMapping overrides DefaultColumnMapping with "column=field" pairs such as "Name=title".
+// The fields are title, track, category, count and date.
+func ParseMapping(pairs []string) (ColumnMapping, error) {
+	mapping := DefaultColumnMapping
+	for _, pair := range pairs {
+		column, field, ok := strings.Cut(pair, "=")
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return ColumnMapping{}, fmt.Errorf("mapping %q: want column=field", pair)
+		}
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "title":
+			mapping.Title = column
+		case "track":
+			mapping.TrackID = column
+		case "category":
+			mapping.CategoryID = column
+		case "count":
+			mapping.Count = column
+		case "date":
+			mapping.OccurredOn = column
+		default:
+			return ColumnMapping{}, fmt.Errorf("mapping %q: unknown field %q (want title, track, category, count or date)", pair, field)
+		}
+	}
+	return mapping, nil
+}
+
+// Options configures a CSV import.
+type Options struct {
+	Mapping ColumnMapping
+	// DateFormats are Go time layouts tried in order. Defaults to YYYY-MM-DD.
+	DateFormats []string
+	// Comma is the field delimiter. Defaults to ','.
+	Comma rune
+	// Mode defaults to command.BatchModeAtomic.
+	Mode   command.BatchMode
+	DryRun bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.Mapping == (ColumnMapping{}) {
+		o.Mapping = DefaultColumnMapping
+	}
+	if len(o.DateFormats) == 0 {
+		o.DateFormats = []string{"2006-01-02"}
+	}
+	if o.Comma == 0 {
+		o.Comma = ','
+	}
+	if o.Mode == "" {
+		o.Mode = command.BatchModeAtomic
+	}
+	return o
+}
+
+// LineError reports why a CSV line could not be imported. Line is 1-based and counts the header.
+type LineError struct {
+	Line  int
+	Field string
+	Err   error
+}
+
+func (e LineError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Field, e.Err)
+}
+
+// Report summarizes an import run.
+type Report struct {
+	DryRun  bool
+	Rows    int
+	Errors  []LineError
+	Created []donelog.DoneLogID
+}
+
+// CSVImporter parses CSV rows and creates DONELOGs through the batch command.
+type CSVImporter struct {
+	Batch command.BatchDoneLogHandler
+}
+
+type parsedRow struct {
+	line int
+	cmd  command.CreateDoneLogCommand
+}
+
+// Import reads r and creates one DONELOG per valid row.
+// In atomic mode nothing is created when any row fails; with DryRun nothing is ever created.
+func (i CSVImporter) Import(ctx context.Context, r io.Reader, opts Options) (Report, error) {
+	opts = opts.withDefaults()
+	report := Report{DryRun: opts.DryRun}
+
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Comma
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return report, fmt.Errorf("read header: %w", err)
+	}
+	columns, err := resolveColumns(header, opts.Mapping)
+	if err != nil {
+		return report, err
+	}
+
+	var rows []parsedRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				// FieldPos is only valid after a successful Read, so take the line from the error.
+				report.Rows++
+				report.Errors = append(report.Errors, LineError{Line: parseErr.StartLine, Err: parseErr.Err})
+				continue
+			}
+			return report, err
+		}
+		line, _ := reader.FieldPos(0)
+		report.Rows++
+
+		cmd, lineErrs := parseRow(record, columns, opts.DateFormats, line)
+		if len(lineErrs) > 0 {
+			report.Errors = append(report.Errors, lineErrs...)
+			continue
+		}
+		rows = append(rows, parsedRow{line: line, cmd: cmd})
+	}
+
+	if len(rows) == 0 {
+		return report, nil
+	}
+
+	// An atomic import that already has invalid rows cannot succeed, but the
+	// remaining rows are still checked against Track/Category so the report is complete.
+	dryRun := opts.DryRun || (opts.Mode == command.BatchModeAtomic && len(report.Errors) > 0)
+
+	items := make([]command.BatchItem, len(rows))
+	for n := range rows {
+		items[n] = command.BatchItem{Create: &rows[n].cmd}
+	}
+	result, err := i.Batch.Handle(ctx, command.BatchDoneLogCommand{Mode: opts.Mode, Items: items, DryRun: dryRun})
+	if err != nil {
+		return report, err
+	}
+	for _, item := range result.Items {
+		if item.Err != nil {
+			report.Errors = append(report.Errors, LineError{Line: rows[item.Index].line, Err: item.Err})
+			continue
+		}
+		if !result.RolledBack {
+			report.Created = append(report.Created, item.ID)
+		}
+	}
+	return report, nil
+}
+
+type columnIndex struct {
+	title, track, category, count, date int
+}
+
+func resolveColumns(header []string, mapping ColumnMapping) (columnIndex, error) {
+	positions := make(map[string]int, len(header))
+	for n, name := range header {
+		positions[strings.ToLower(strings.TrimSpace(name))] = n
+	}
+	lookup := func(name string) (int, error) {
+		n, ok := positions[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("missing column %q", name)
+		}
+		return n, nil
+	}
+
+	var idx columnIndex
+	var err error
+	if idx.title, err = lookup(mapping.Title); err != nil {
+		return idx, err
+	}
+	if idx.track, err = lookup(mapping.TrackID); err != nil {
+		return idx, err
+	}
+	if idx.category, err = lookup(mapping.CategoryID); err != nil {
+		return idx, err
+	}
+	if idx.count, err = lookup(mapping.Count); err != nil {
+		return idx, err
+	}
+	if idx.date, err = lookup(mapping.OccurredOn); err != nil {
+		return idx, err
+	}
+	return idx, nil
+}
+
+// parseRow validates one record through the DONELOG value objects.
+func parseRow(record []string, columns columnIndex, dateFormats []string, line int) (command.CreateDoneLogCommand, []LineError) {
+	field := func(n int) string {
+		if n >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[n])
+	}
+
+	var errs []LineError
+	fail := func(name string, err error) {
+		errs = append(errs, LineError{Line: line, Field: name, Err: err})
+	}
+
+	title, err := donelog.NewTitle(field(columns.title))
+	if err != nil {
+		fail("title", err)
+	}
+	trackID, err := donelog.NewTrackID(field(columns.track))
+	if err != nil {
+		fail("track", err)
+	}
+	categoryID, err := donelog.NewCategoryID(field(columns.category))
+	if err != nil {
+		fail("category", err)
+	}
+	var count donelog.Count
+	if n, err := strconv.Atoi(field(columns.count)); err != nil {
+		fail("count", fmt.Errorf("invalid count: %q", field(columns.count)))
+	} else if count, err = donelog.NewCount(n); err != nil {
+		fail("count", err)
+	}
+	occurredOn, err := parseDate(field(columns.date), dateFormats)
+	if err != nil {
+		fail("date", err)
+	}
+
+	if len(errs) > 0 {
+		return command.CreateDoneLogCommand{}, errs
+	}
+	return command.CreateDoneLogCommand{
+		Title:      title.String(),
+		TrackID:    trackID.String(),
+		CategoryID: categoryID.String(),
+		Count:      count.Int(),
+		OccurredOn: occurredOn.String(),
+	}, nil
+}
+
+func parseDate(value string, formats []string) (donelog.OccurredOn, error) {
+	for _, layout := range formats {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		return donelog.NewOccurredOn(t.Format("2006-01-02"))
+	}
+	return donelog.OccurredOn{}, fmt.Errorf("date %q does not match %s", value, strings.Join(formats, ", "))
+}
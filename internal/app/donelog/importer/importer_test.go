@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+type memoryDoneLogRepo struct {
+	saved []string
+}
+
+func (m *memoryDoneLogRepo) Save(ctx context.Context, log *donelog.DoneLog) error {
+	m.saved = append(m.saved, log.ID().String())
+	return nil
+}
+func (m *memoryDoneLogRepo) FindByID(ctx context.Context, id donelog.DoneLogID) (*donelog.RawDoneLog, error) {
+	return nil, nil
+}
+func (m *memoryDoneLogRepo) Delete(ctx context.Context, id donelog.DoneLogID) error { return nil }
+
+type memoryTx struct {
+	repo *memoryDoneLogRepo
+}
+
+func (m memoryTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	committed := len(m.repo.saved)
+	if err := fn(ctx); err != nil {
+		m.repo.saved = m.repo.saved[:committed]
+		return err
+	}
+	return nil
+}
+
+type knownTracks struct{}
+
+func (knownTracks) FindActiveByID(ctx context.Context, id donelog.TrackID) (*command.Track, error) {
+	if id.String() != "track_book" {
+		return nil, nil
+	}
+	return &command.Track{ID: id, Active: true}, nil
+}
+
+type knownCategories struct{}
+
+func (knownCategories) FindActiveByID(ctx context.Context, id donelog.CategoryID) (*command.Category, error) {
+	return &command.Category{ID: id, Active: true}, nil
+}
+
+type counterIDs struct {
+	n int
+}
+
+func (c *counterIDs) NewDoneLogID(ctx context.Context) (donelog.DoneLogID, error) {
+	c.n++
+	return donelog.NewDoneLogID(fmt.Sprintf("01HYR1X5C9XM9P6H7K71M9QA%02d", c.n))
+}
+
+type nopAudit struct{}
+
+func (nopAudit) Append(ctx context.Context, entry donelog.AuditEntry) error { return nil }
+
+type nowTime struct{}
+
+func (nowTime) Now() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+func TestCSVImporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		opts        Options
+		wantRows    int
+		wantCreated int
+		wantErrs    []string
+	}{
+		{
+			name: "OK: default mapping",
+			csv: "title,track,category,count,date\n" +
+				"Clean Architecture ch.1,track_book,cat_reading,12,2023-01-05\n" +
+				"Clean Architecture ch.2,track_book,cat_reading,8,2023-01-06\n",
+			wantRows:    2,
+			wantCreated: 2,
+		},
+		{
+			name: "OK: custom columns and date formats",
+			csv: "Date;Book;Pages;Kind;Memo\n" +
+				"2023/01/05;track_book;12;cat_reading;ch.1\n" +
+				"06.01.2023;track_book;8;cat_reading;ch.2\n",
+			opts: Options{
+				Mapping:     ColumnMapping{Title: "memo", TrackID: "book", CategoryID: "kind", Count: "pages", OccurredOn: "date"},
+				DateFormats: []string{"2006/01/02", "02.01.2006"},
+				Comma:       ';',
+			},
+			wantRows:    2,
+			wantCreated: 2,
+		},
+		{
+			name: "NG: dry run lists errors per line and creates nothing",
+			csv: "title,track,category,count,date\n" +
+				"ok,track_book,cat_reading,1,2023-01-05\n" +
+				",track_book,cat_reading,0,2023-13-01\n" +
+				"unknown track,track_missing,cat_reading,1,2023-01-05\n",
+			opts:     Options{DryRun: true},
+			wantRows: 3,
+			wantErrs: []string{"line 3: title", "line 3: count", "line 3: date", "line 4: track track_missing: not found"},
+		},
+		{
+			name: "NG: atomic import creates nothing when a row fails",
+			csv: "title,track,category,count,date\n" +
+				"ok,track_book,cat_reading,1,2023-01-05\n" +
+				"bad,track_book,cat_reading,x,2023-01-05\n",
+			wantRows: 2,
+			wantErrs: []string{"line 3: count"},
+		},
+		{
+			name: "OK: best effort keeps valid rows",
+			csv: "title,track,category,count,date\n" +
+				"ok,track_book,cat_reading,1,2023-01-05\n" +
+				"bad,track_book,cat_reading,x,2023-01-05\n",
+			opts:        Options{Mode: command.BatchModeBestEffort},
+			wantRows:    2,
+			wantCreated: 1,
+			wantErrs:    []string{"line 3: count"},
+		},
+		{
+			name: "NG: malformed quote is reported on its line",
+			csv: "title,track,category,count,date\n" +
+				"ok,track_book,cat_reading,1,2023-01-05\n" +
+				"bad \"quote,track_book,cat_reading,1,2023-01-05\n" +
+				"ok too,track_book,cat_reading,2,2023-01-06\n",
+			opts:        Options{Mode: command.BatchModeBestEffort},
+			wantRows:    3,
+			wantCreated: 2,
+			wantErrs:    []string{"line 3: bare \""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryDoneLogRepo{}
+			importer := CSVImporter{Batch: command.BatchDoneLogHandler{
+				DoneLogs:   repo,
+				Tracks:     knownTracks{},
+				Categories: knownCategories{},
+				IDs:        &counterIDs{},
+				Audit:      nopAudit{},
+				Time:       nowTime{},
+				Tx:         memoryTx{repo: repo},
+			}}
+
+			report, err := importer.Import(context.Background(), strings.NewReader(tt.csv), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Rows != tt.wantRows {
+				t.Fatalf("expected %d rows, got %d", tt.wantRows, report.Rows)
+			}
+			if len(report.Created) != tt.wantCreated || len(repo.saved) != tt.wantCreated {
+				t.Fatalf("expected %d created, got %d (saved %d)", tt.wantCreated, len(report.Created), len(repo.saved))
+			}
+			if len(report.Errors) != len(tt.wantErrs) {
+				t.Fatalf("expected %d errors, got %v", len(tt.wantErrs), report.Errors)
+			}
+			for n, want := range tt.wantErrs {
+				if !strings.HasPrefix(report.Errors[n].Error(), want) {
+					t.Fatalf("error %d = %q, want prefix %q", n, report.Errors[n].Error(), want)
+				}
+			}
+		})
+	}
+
+	t.Run("NG: missing column", func(t *testing.T) {
+		_, err := CSVImporter{}.Import(context.Background(), strings.NewReader("title,track\n"), Options{})
+		if err == nil {
+			t.Fatalf("expected missing column error")
+		}
+	})
+}
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    ColumnMapping
+		wantErr string
+	}{
+		{name: "OK: no pairs keep the defaults", want: DefaultColumnMapping},
+		{
+			name:  "OK: pairs override single fields",
+			pairs: []string{"Name=title", " Pages = COUNT "},
+			want:  ColumnMapping{Title: "Name", TrackID: "track", CategoryID: "category", Count: "Pages", OccurredOn: "date"},
+		},
+		{name: "NG: missing field", pairs: []string{"Name"}, wantErr: "want column=field"},
+		{name: "NG: missing column", pairs: []string{"=title"}, wantErr: "want column=field"},
+		{name: "NG: unknown field", pairs: []string{"Name=name"}, wantErr: `unknown field "name"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMapping(tt.pairs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseMapping = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
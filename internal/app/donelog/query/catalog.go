@@ -0,0 +1,81 @@
+package query
+
+import "context"
+
+// ListTracksQuery lists Tracks. Archived Tracks are hidden unless IncludeArchived is set.
+type ListTracksQuery struct {
+	IncludeArchived bool
+}
+
+// TrackItem is the read model for a Track.
+type TrackItem struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	DefaultCategoryID string `json:"defaultCategoryId,omitempty"`
+	SortOrder         int    `json:"sortOrder"`
+	Active            bool   `json:"active"`
+	// TeamID is set for a Track shared by a Team.
+	TeamID string `json:"teamId,omitempty"`
+}
+
+// ListTracksHandler handles ListTracksQuery.
+type ListTracksHandler struct {
+	Tracks TrackReader
+}
+
+// Handle returns Tracks in the reader's order (SortOrder, then ID).
+func (h ListTracksHandler) Handle(ctx context.Context, q ListTracksQuery) ([]TrackItem, error) {
+	raws, err := h.Tracks.ListTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]TrackItem, 0, len(raws))
+	for _, raw := range raws {
+		if !raw.Active && !q.IncludeArchived {
+			continue
+		}
+		items = append(items, TrackItem{
+			ID:                raw.ID,
+			Name:              raw.Name,
+			DefaultCategoryID: raw.DefaultCategoryID,
+			SortOrder:         raw.SortOrder,
+			Active:            raw.Active,
+			TeamID:            raw.Team,
+		})
+	}
+	return items, nil
+}
+
+// ListCategoriesQuery lists Categories. Archived Categories are hidden unless IncludeArchived is set.
+type ListCategoriesQuery struct {
+	IncludeArchived bool
+}
+
+// CategoryItem is the read model for a Category.
+type CategoryItem struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sortOrder"`
+	Active    bool   `json:"active"`
+}
+
+// ListCategoriesHandler handles ListCategoriesQuery.
+type ListCategoriesHandler struct {
+	Categories CategoryReader
+}
+
+// Handle returns Categories in the reader's order (SortOrder, then ID).
+func (h ListCategoriesHandler) Handle(ctx context.Context, q ListCategoriesQuery) ([]CategoryItem, error) {
+	raws, err := h.Categories.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]CategoryItem, 0, len(raws))
+	for _, raw := range raws {
+		if !raw.Active && !q.IncludeArchived {
+			continue
+		}
+		items = append(items, CategoryItem{ID: raw.ID, Name: raw.Name, SortOrder: raw.SortOrder, Active: raw.Active})
+	}
+	return items, nil
+}
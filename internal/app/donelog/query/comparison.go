@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// CompareYearOverYearQuery compares each month or quarter of Year with the previous year.
+// Year zero means the current year; Unit defaults to "month" and GroupBy to "category".
+// TrackID and CategoryID optionally narrow the DONELOGs compared.
+type CompareYearOverYearQuery struct {
+	Year       int
+	Unit       string
+	GroupBy    string
+	TrackID    string
+	CategoryID string
+}
+
+// YearOverYear is the read model of a year-over-year comparison.
+type YearOverYear struct {
+	Year         int               `json:"year"`
+	PreviousYear int               `json:"previousYear"`
+	Unit         string            `json:"unit"`
+	GroupBy      string            `json:"groupBy"`
+	Groups       []ComparisonGroup `json:"groups"`
+}
+
+// ComparisonGroup is the comparison for one Track or Category.
+type ComparisonGroup struct {
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Rows  []ComparisonRow `json:"rows"`
+	Total ComparisonRow   `json:"total"`
+}
+
+// ComparisonRow is one bucket and the same bucket a year earlier.
+// ChangePercent is null when the previous year had nothing to compare against.
+type ComparisonRow struct {
+	Label         string   `json:"label"`
+	PreviousLabel string   `json:"previousLabel"`
+	Current       int      `json:"current"`
+	Previous      int      `json:"previous"`
+	Change        int      `json:"change"`
+	ChangePercent *float64 `json:"changePercent"`
+}
+
+// CompareYearOverYearHandler handles CompareYearOverYearQuery.
+type CompareYearOverYearHandler struct {
+	DoneLogs   DoneLogReader
+	Tracks     TrackReader
+	Categories CategoryReader
+	Clock      Clock
+	Service    donelog.LogSummaryService
+}
+
+func (h CompareYearOverYearHandler) Handle(ctx context.Context, q CompareYearOverYearQuery) (YearOverYear, error) {
+	year := q.Year
+	if year == 0 {
+		year = h.Clock.Now().Year()
+	}
+	unit := donelog.ComparisonUnitMonth
+	if q.Unit != "" {
+		parsed, err := donelog.ParseComparisonUnit(q.Unit)
+		if err != nil {
+			return YearOverYear{}, apperr.Invalid(err)
+		}
+		unit = parsed
+	}
+	groupBy := donelog.SummaryGroupCategory
+	if q.GroupBy != "" {
+		parsed, err := donelog.ParseSummaryGroup(q.GroupBy)
+		if err != nil {
+			return YearOverYear{}, apperr.Invalid(err)
+		}
+		groupBy = parsed
+	}
+	filter, err := parseFilter(q.TrackID, q.CategoryID)
+	if err != nil {
+		return YearOverYear{}, apperr.Invalid(err)
+	}
+	if year < 2 || year > 9999 {
+		return YearOverYear{}, apperr.Invalid(fmt.Errorf("year must be between 2 and 9999"))
+	}
+
+	start := time.Date(year-1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(start), donelog.OccurredOnFromTime(end))
+	if err != nil {
+		return YearOverYear{}, err
+	}
+	raws, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return YearOverYear{}, err
+	}
+	logs := make([]*donelog.DoneLog, 0, len(raws))
+	for _, raw := range raws {
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return YearOverYear{}, err
+		}
+		logs = append(logs, log)
+	}
+
+	yoy, err := h.Service.CompareYearOverYear(year, unit, groupBy, logs)
+	if err != nil {
+		return YearOverYear{}, apperr.Invalid(err)
+	}
+	names, err := loadNames(ctx, h.Tracks, h.Categories)
+	if err != nil {
+		return YearOverYear{}, err
+	}
+	lookup := names.categories
+	if groupBy == donelog.SummaryGroupTrack {
+		lookup = names.tracks
+	}
+
+	result := YearOverYear{
+		Year:         year,
+		PreviousYear: year - 1,
+		Unit:         string(unit),
+		GroupBy:      string(groupBy),
+		Groups:       []ComparisonGroup{},
+	}
+	for _, g := range yoy.Groups() {
+		group := ComparisonGroup{ID: g.Key(), Name: lookup[g.Key()], Total: newComparisonRow(g.Total())}
+		for _, row := range g.Rows() {
+			group.Rows = append(group.Rows, newComparisonRow(row))
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	return result, nil
+}
+
+func newComparisonRow(r donelog.ComparisonRow) ComparisonRow {
+	row := ComparisonRow{
+		Label:         r.Label(),
+		PreviousLabel: r.PreviousLabel(),
+		Current:       r.Current(),
+		Previous:      r.Previous(),
+		Change:        r.Change(),
+	}
+	if pct, ok := r.ChangePercent(); ok {
+		row.ChangePercent = &pct
+	}
+	return row
+}
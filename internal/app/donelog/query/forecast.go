@@ -0,0 +1,216 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetForecastQuery asks when a cumulative target will be reached at the recent pace.
+//
+// With GoalID, the Goal supplies the Track or Category, the target, the counted period and the deadline.
+// Otherwise TrackID and Target are required, every DONELOG of the Track up to today counts,
+// and Deadline (YYYY-MM-DD) is optional. WindowDays defaults to 28.
+type GetForecastQuery struct {
+	GoalID     string
+	TrackID    string
+	Target     int
+	Deadline   string
+	WindowDays int
+}
+
+func (q GetForecastQuery) Validate() error {
+	if q.GoalID != "" {
+		if q.TrackID != "" || q.Target != 0 || q.Deadline != "" {
+			return fmt.Errorf("goalId cannot be combined with trackId, target or deadline")
+		}
+		return nil
+	}
+	if q.TrackID == "" || q.Target == 0 {
+		return fmt.Errorf("goalId or trackId and target are required")
+	}
+	return nil
+}
+
+// Forecast is the read model for a projected completion date.
+// Dates are null when the target is already reached or nothing was logged in the window;
+// LatestDate is also null when the pessimistic pace is zero.
+type Forecast struct {
+	GoalID        string  `json:"goalId,omitempty"`
+	TrackID       string  `json:"trackId,omitempty"`
+	TrackName     string  `json:"trackName,omitempty"`
+	CategoryID    string  `json:"categoryId,omitempty"`
+	CategoryName  string  `json:"categoryName,omitempty"`
+	Target        int     `json:"target"`
+	Done          int     `json:"done"`
+	Remaining     int     `json:"remaining"`
+	WindowDays    int     `json:"windowDays"`
+	DailyPace     float64 `json:"dailyPace"`
+	DailyPaceLow  float64 `json:"dailyPaceLow"`
+	DailyPaceHigh float64 `json:"dailyPaceHigh"`
+	ProjectedDate *string `json:"projectedDate"`
+	EarliestDate  *string `json:"earliestDate"`
+	LatestDate    *string `json:"latestDate"`
+	Deadline      string  `json:"deadline,omitempty"`
+	DaysLate      int     `json:"daysLate"`
+	Status        string  `json:"status"`
+	Warning       string  `json:"warning,omitempty"`
+}
+
+// GetForecastHandler handles GetForecastQuery.
+type GetForecastHandler struct {
+	Goals      GoalReader
+	DoneLogs   DoneLogReader
+	Tracks     TrackReader
+	Categories CategoryReader
+	Clock      Clock
+}
+
+func (h GetForecastHandler) Handle(ctx context.Context, q GetForecastQuery) (Forecast, error) {
+	if err := q.Validate(); err != nil {
+		return Forecast{}, apperr.Invalid(err)
+	}
+	window := q.WindowDays
+	if window == 0 {
+		window = donelog.DefaultForecastWindowDays
+	}
+	today := donelog.OccurredOnFromTime(h.Clock.Now())
+
+	var (
+		filter   DoneLogFilter
+		counted  donelog.Period
+		target   donelog.Count
+		deadline *donelog.OccurredOn
+		err      error
+	)
+	if q.GoalID != "" {
+		id, err := donelog.NewGoalID(q.GoalID)
+		if err != nil {
+			return Forecast{}, apperr.Invalid(err)
+		}
+		raw, err := h.Goals.FindByID(ctx, id)
+		if err != nil {
+			return Forecast{}, err
+		}
+		if raw == nil {
+			return Forecast{}, fmt.Errorf("goal %s: %w", id.String(), apperr.ErrNotFound)
+		}
+		goal, err := donelog.RehydrateGoal(*raw)
+		if err != nil {
+			return Forecast{}, err
+		}
+		filter = DoneLogFilter{TrackID: goal.TrackID(), CategoryID: goal.CategoryID()}
+		counted, target = goal.Period(), goal.Target()
+		end := donelog.OccurredOnFromTime(counted.End())
+		deadline = &end
+	} else {
+		filter, err = parseFilter(q.TrackID, "")
+		if err != nil {
+			return Forecast{}, apperr.Invalid(err)
+		}
+		if target, err = donelog.NewCount(q.Target); err != nil {
+			return Forecast{}, apperr.Invalid(fmt.Errorf("target: %w", err))
+		}
+		counted, err = donelog.NewPeriod(donelog.OccurredOnFromTime(time.Time{}), today)
+		if err != nil {
+			return Forecast{}, err
+		}
+		if q.Deadline != "" {
+			d, err := donelog.NewOccurredOn(q.Deadline)
+			if err != nil {
+				return Forecast{}, apperr.Invalid(fmt.Errorf("deadline: %w", err))
+			}
+			deadline = &d
+		}
+	}
+
+	// Read everything the counted period and the pace window need, up to today.
+	start := counted.Start()
+	if windowStart := today.Time().AddDate(0, 0, 1-window); windowStart.Before(start) {
+		start = windowStart
+	}
+	if start.After(today.Time()) {
+		start = today.Time()
+	}
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(start), today)
+	if err != nil {
+		return Forecast{}, err
+	}
+	raws, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return Forecast{}, err
+	}
+	logs := make([]*donelog.DoneLog, 0, len(raws))
+	for _, raw := range raws {
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return Forecast{}, err
+		}
+		logs = append(logs, log)
+	}
+
+	f, err := donelog.NewForecast(target, counted, logs, today, window, deadline)
+	if err != nil {
+		return Forecast{}, apperr.Invalid(err)
+	}
+	names, err := loadNames(ctx, h.Tracks, h.Categories)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	low, high := f.DailyPaceBand()
+	result := Forecast{
+		GoalID:        q.GoalID,
+		Target:        target.Int(),
+		Done:          f.Done(),
+		Remaining:     f.Remaining(),
+		WindowDays:    f.WindowDays(),
+		DailyPace:     f.DailyPace(),
+		DailyPaceLow:  low,
+		DailyPaceHigh: high,
+		ProjectedDate: formatDate(f.ProjectedDate()),
+		EarliestDate:  formatDate(f.EarliestDate()),
+		LatestDate:    formatDate(f.LatestDate()),
+		DaysLate:      f.DaysLate(),
+		Status:        string(f.Status()),
+	}
+	if filter.TrackID != nil {
+		result.TrackID = filter.TrackID.String()
+		result.TrackName = names.tracks[result.TrackID]
+	}
+	if filter.CategoryID != nil {
+		result.CategoryID = filter.CategoryID.String()
+		result.CategoryName = names.categories[result.CategoryID]
+	}
+	if d := f.Deadline(); d != nil {
+		result.Deadline = d.Format("2006-01-02")
+	}
+	result.Warning = forecastWarning(result)
+	return result, nil
+}
+
+// forecastWarning explains a forecast that misses, or may miss, its deadline.
+func forecastWarning(f Forecast) string {
+	switch donelog.ForecastStatus(f.Status) {
+	case donelog.ForecastStatusBehind:
+		return fmt.Sprintf("projected to finish on %s, %d days after the deadline %s", *f.ProjectedDate, f.DaysLate, f.Deadline)
+	case donelog.ForecastStatusAtRisk:
+		return fmt.Sprintf("the deadline %s may be missed at the slower end of the recent pace", f.Deadline)
+	case donelog.ForecastStatusStalled:
+		if f.Deadline != "" {
+			return fmt.Sprintf("nothing logged in the last %d days; the deadline %s cannot be projected", f.WindowDays, f.Deadline)
+		}
+	}
+	return ""
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
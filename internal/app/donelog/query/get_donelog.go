@@ -0,0 +1,53 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetDoneLogQuery asks for a single DONELOG.
+type GetDoneLogQuery struct {
+	ID string
+}
+
+func (q GetDoneLogQuery) Validate() error {
+	if q.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// GetDoneLogHandler handles GetDoneLogQuery.
+type GetDoneLogHandler struct {
+	DoneLogs   DoneLogFinder
+	Tracks     TrackReader
+	Categories CategoryReader
+}
+
+// Handle reports trashed DONELOGs as not found, like the listings do.
+func (h GetDoneLogHandler) Handle(ctx context.Context, q GetDoneLogQuery) (DoneLogItem, error) {
+	if err := q.Validate(); err != nil {
+		return DoneLogItem{}, apperr.Invalid(err)
+	}
+	id, err := donelog.NewDoneLogID(q.ID)
+	if err != nil {
+		return DoneLogItem{}, apperr.Invalid(err)
+	}
+
+	raw, err := h.DoneLogs.FindByID(ctx, id)
+	if err != nil {
+		return DoneLogItem{}, err
+	}
+	if raw == nil || raw.TrashedAt != nil {
+		return DoneLogItem{}, fmt.Errorf("doneLog %s: %w", id.String(), apperr.ErrNotFound)
+	}
+
+	names, err := loadNames(ctx, h.Tracks, h.Categories)
+	if err != nil {
+		return DoneLogItem{}, err
+	}
+	return names.item(*raw), nil
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GoalProgressItem is the read model for a Goal and its progress as of today.
+type GoalProgressItem struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name"`
+	TrackID           string  `json:"trackId,omitempty"`
+	TrackName         string  `json:"trackName,omitempty"`
+	CategoryID        string  `json:"categoryId,omitempty"`
+	CategoryName      string  `json:"categoryName,omitempty"`
+	StartDate         string  `json:"startDate"`
+	EndDate           string  `json:"endDate"`
+	Target            int     `json:"target"`
+	Done              int     `json:"done"`
+	Remaining         int     `json:"remaining"`
+	Percent           float64 `json:"percent"`
+	DaysLeft          int     `json:"daysLeft"`
+	RequiredDailyPace float64 `json:"requiredDailyPace"`
+	Status            string  `json:"status"`
+}
+
+// GetGoalProgressQuery asks for the progress of one Goal.
+type GetGoalProgressQuery struct {
+	ID string
+}
+
+// GetGoalProgressHandler handles GetGoalProgressQuery.
+type GetGoalProgressHandler struct {
+	Goals      GoalReader
+	DoneLogs   DoneLogReader
+	Tracks     TrackReader
+	Categories CategoryReader
+	Clock      Clock
+}
+
+func (h GetGoalProgressHandler) Handle(ctx context.Context, q GetGoalProgressQuery) (GoalProgressItem, error) {
+	id, err := donelog.NewGoalID(q.ID)
+	if err != nil {
+		return GoalProgressItem{}, apperr.Invalid(err)
+	}
+	raw, err := h.Goals.FindByID(ctx, id)
+	if err != nil {
+		return GoalProgressItem{}, err
+	}
+	if raw == nil {
+		return GoalProgressItem{}, fmt.Errorf("goal %s: %w", id.String(), apperr.ErrNotFound)
+	}
+	names, err := loadNames(ctx, h.Tracks, h.Categories)
+	if err != nil {
+		return GoalProgressItem{}, err
+	}
+	return goalProgress(ctx, h.DoneLogs, names, *raw, donelog.OccurredOnFromTime(h.Clock.Now()))
+}
+
+// ListGoalProgressQuery asks for the progress of every Goal.
+type ListGoalProgressQuery struct{}
+
+// ListGoalProgressHandler handles ListGoalProgressQuery.
+type ListGoalProgressHandler struct {
+	Goals      GoalReader
+	DoneLogs   DoneLogReader
+	Tracks     TrackReader
+	Categories CategoryReader
+	Clock      Clock
+}
+
+// Handle returns the Goals in the GoalReader's order.
+func (h ListGoalProgressHandler) Handle(ctx context.Context, q ListGoalProgressQuery) ([]GoalProgressItem, error) {
+	raws, err := h.Goals.ListGoals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := loadNames(ctx, h.Tracks, h.Categories)
+	if err != nil {
+		return nil, err
+	}
+	today := donelog.OccurredOnFromTime(h.Clock.Now())
+	items := make([]GoalProgressItem, 0, len(raws))
+	for _, raw := range raws {
+		item, err := goalProgress(ctx, h.DoneLogs, names, raw, today)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// goalProgress sums the DONELOGs inside the Goal's period and target.
+func goalProgress(ctx context.Context, reader DoneLogReader, names catalogNames, raw donelog.RawGoal, today donelog.OccurredOn) (GoalProgressItem, error) {
+	goal, err := donelog.RehydrateGoal(raw)
+	if err != nil {
+		return GoalProgressItem{}, err
+	}
+	filter := DoneLogFilter{TrackID: goal.TrackID(), CategoryID: goal.CategoryID()}
+	raws, err := reader.ListByPeriod(ctx, goal.Period(), filter)
+	if err != nil {
+		return GoalProgressItem{}, err
+	}
+	logs := make([]*donelog.DoneLog, 0, len(raws))
+	for _, r := range raws {
+		log, err := donelog.RehydrateDoneLog(r)
+		if err != nil {
+			return GoalProgressItem{}, err
+		}
+		logs = append(logs, log)
+	}
+
+	p := goal.Progress(logs, today)
+	return GoalProgressItem{
+		ID:                raw.ID,
+		Name:              raw.Name,
+		TrackID:           raw.TrackID,
+		TrackName:         names.tracks[raw.TrackID],
+		CategoryID:        raw.CategoryID,
+		CategoryName:      names.categories[raw.CategoryID],
+		StartDate:         goal.Period().Start().Format("2006-01-02"),
+		EndDate:           goal.Period().End().Format("2006-01-02"),
+		Target:            raw.Target,
+		Done:              p.Done(),
+		Remaining:         p.Remaining(),
+		Percent:           p.Percent(),
+		DaysLeft:          p.DaysLeft(),
+		RequiredDailyPace: p.RequiredDailyPace(),
+		Status:            string(p.Status()),
+	}, nil
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetHeatmapQuery asks for the per-day totals of one calendar year.
+// Year zero means the current year; TrackID and CategoryID are optional filters.
+type GetHeatmapQuery struct {
+	Year       int
+	TrackID    string
+	CategoryID string
+}
+
+// Heatmap is the read model for a contributions-style year view.
+type Heatmap struct {
+	Year       int          `json:"year"`
+	TrackID    string       `json:"trackId,omitempty"`
+	CategoryID string       `json:"categoryId,omitempty"`
+	TotalCount int          `json:"totalCount"`
+	MaxCount   int          `json:"maxCount"`
+	ActiveDays int          `json:"activeDays"`
+	Thresholds []float64    `json:"thresholds"`
+	Days       []HeatmapDay `json:"days"`
+}
+
+// HeatmapDay is one day of the year. Level is 0 for no activity and 1..4 by quantile otherwise.
+type HeatmapDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+	Level int    `json:"level"`
+}
+
+// GetHeatmapHandler handles GetHeatmapQuery.
+type GetHeatmapHandler struct {
+	DoneLogs DoneLogReader
+	Clock    Clock
+}
+
+// Handle returns every day of the year in order, zero-filled.
+func (h GetHeatmapHandler) Handle(ctx context.Context, q GetHeatmapQuery) (Heatmap, error) {
+	year := q.Year
+	if year == 0 {
+		year = h.Clock.Now().Year()
+	}
+	if year < 1 || year > 9999 {
+		return Heatmap{}, apperr.Invalid(fmt.Errorf("year must be between 1 and 9999"))
+	}
+	filter, err := parseFilter(q.TrackID, q.CategoryID)
+	if err != nil {
+		return Heatmap{}, apperr.Invalid(err)
+	}
+
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(first), donelog.OccurredOnFromTime(last))
+	if err != nil {
+		return Heatmap{}, err
+	}
+	raws, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return Heatmap{}, err
+	}
+
+	byDay := map[string]int{}
+	for _, raw := range raws {
+		byDay[raw.OccurredOn.Format("2006-01-02")] += raw.Count
+	}
+
+	var totals []int
+	days := make([]HeatmapDay, 0, 366)
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		label := d.Format("2006-01-02")
+		days = append(days, HeatmapDay{Date: label, Count: byDay[label]})
+		totals = append(totals, byDay[label])
+	}
+
+	scale := donelog.NewIntensityScale(totals)
+	result := Heatmap{
+		Year:       year,
+		TrackID:    q.TrackID,
+		CategoryID: q.CategoryID,
+		Thresholds: scale.Thresholds(),
+		Days:       days,
+	}
+	if result.Thresholds == nil {
+		result.Thresholds = []float64{}
+	}
+	for i := range days {
+		days[i].Level = scale.Level(days[i].Count)
+		result.TotalCount += days[i].Count
+		if days[i].Count > 0 {
+			result.ActiveDays++
+		}
+		if days[i].Count > result.MaxCount {
+			result.MaxCount = days[i].Count
+		}
+	}
+	return result, nil
+}
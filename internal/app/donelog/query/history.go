@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetDoneLogHistoryQuery asks for the audit trail of a single DONELOG.
+type GetDoneLogHistoryQuery struct {
+	ID string
+}
+
+func (q GetDoneLogHistoryQuery) Validate() error {
+	if q.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+// HistoryEntry is the read model for one audit entry.
+type HistoryEntry struct {
+	Action     string        `json:"action"`
+	Actor      string        `json:"actor"`
+	RequestID  string        `json:"requestId"`
+	RecordedAt time.Time     `json:"recordedAt"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// FieldChange is the read model for a single changed field.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// GetDoneLogHistoryHandler handles GetDoneLogHistoryQuery.
+type GetDoneLogHistoryHandler struct {
+	Audit AuditReader
+}
+
+// Handle returns the history oldest first.
+func (h GetDoneLogHistoryHandler) Handle(ctx context.Context, q GetDoneLogHistoryQuery) ([]HistoryEntry, error) {
+	if err := q.Validate(); err != nil {
+		return nil, apperr.Invalid(err)
+	}
+
+	id, err := donelog.NewDoneLogID(q.ID)
+	if err != nil {
+		return nil, apperr.Invalid(err)
+	}
+
+	entries, err := h.Audit.ListByDoneLogID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.Before(entries[j].RecordedAt)
+	})
+
+	history := make([]HistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		changes := make([]FieldChange, 0, len(entry.Changes))
+		for _, c := range entry.Changes {
+			changes = append(changes, FieldChange{Field: c.Field, Before: c.Before, After: c.After})
+		}
+		history = append(history, HistoryEntry{
+			Action:     string(entry.Action),
+			Actor:      entry.Actor,
+			RequestID:  entry.RequestID,
+			RecordedAt: entry.RecordedAt,
+			Changes:    changes,
+		})
+	}
+	return history, nil
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// AuditReader reads the change history recorded by the command side.
+type AuditReader interface {
+	ListByDoneLogID(ctx context.Context, id donelog.DoneLogID) ([]donelog.AuditEntry, error)
+}
+
+// DoneLogFilter narrows a DONELOG listing. Nil fields mean "no filter".
+type DoneLogFilter struct {
+	TrackID    *donelog.TrackID
+	CategoryID *donelog.CategoryID
+	// Tag keeps only DONELOGs carrying the Tag.
+	Tag *donelog.Tag
+}
+
+// DoneLogReader lists DONELOGs for read models.
+// Implementations exclude trashed DONELOGs and order by OccurredOn, then ID.
+type DoneLogReader interface {
+	ListByPeriod(ctx context.Context, period donelog.Period, filter DoneLogFilter) ([]donelog.RawDoneLog, error)
+}
+
+// DoneLogFinder loads a single DONELOG. It returns nil when the DONELOG does not exist.
+type DoneLogFinder interface {
+	FindByID(ctx context.Context, id donelog.DoneLogID) (*donelog.RawDoneLog, error)
+}
+
+// TrackReader lists every Track, including archived ones, so past DONELOGs can be labeled.
+type TrackReader interface {
+	ListTracks(ctx context.Context) ([]donelog.RawTrack, error)
+}
+
+// CategoryReader lists every Category, including archived ones.
+type CategoryReader interface {
+	ListCategories(ctx context.Context) ([]donelog.RawCategory, error)
+}
+
+// GoalReader reads Goal aggregates.
+type GoalReader interface {
+	// ListGoals returns every Goal ordered by start date, then ID.
+	ListGoals(ctx context.Context) ([]donelog.RawGoal, error)
+	// FindByID returns nil when the Goal does not exist.
+	FindByID(ctx context.Context, id donelog.GoalID) (*donelog.RawGoal, error)
+}
+
+// TeamReader reads Team aggregates. Lookups are not scoped by the owner in ctx; handlers check membership.
+type TeamReader interface {
+	// FindByID returns nil when the Team does not exist.
+	FindByID(ctx context.Context, id donelog.TeamID) (*donelog.RawTeam, error)
+	// ListByMember returns the Teams member belongs to, ordered by ID.
+	ListByMember(ctx context.Context, member donelog.OwnerID) ([]donelog.RawTeam, error)
+	// ListByInvitee returns the Teams that invited invitee and await their answer, ordered by ID.
+	ListByInvitee(ctx context.Context, invitee donelog.OwnerID) ([]donelog.RawTeam, error)
+}
+
+// TeamDoneLogReader lists the DONELOGs that the members of a Team recorded against its Tracks.
+// Implementations exclude trashed DONELOGs and order by OccurredOn, then ID; RawDoneLog.Owner tells the member.
+type TeamDoneLogReader interface {
+	ListTeamDoneLogs(ctx context.Context, team donelog.TeamID, period donelog.Period, filter DoneLogFilter) ([]donelog.RawDoneLog, error)
+}
+
+// SettingsReader reads the caller's settings stored as key/value pairs. Implementations scope keys by the owner in ctx.
+type SettingsReader interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+}
+
+// Clock tells queries what "today" is (e.g. for streaks).
+type Clock interface {
+	Now() time.Time
+}
+
+// Matches reports whether raw passes the filter. Readers may use it to share filtering rules.
+func (f DoneLogFilter) Matches(raw donelog.RawDoneLog) bool {
+	if f.TrackID != nil && raw.TrackID != f.TrackID.String() {
+		return false
+	}
+	if f.CategoryID != nil && raw.CategoryID != f.CategoryID.String() {
+		return false
+	}
+	if f.Tag != nil && !slices.Contains(raw.Tags, f.Tag.String()) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetLeaderboardQuery ranks a Team's members by total Count between two dates (YYYY-MM-DD, inclusive).
+// TrackID narrows it to one team Track.
+type GetLeaderboardQuery struct {
+	TeamID    string
+	TrackID   string
+	StartDate string
+	EndDate   string
+}
+
+func (q GetLeaderboardQuery) Validate() error {
+	if q.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// Leaderboard is the read model of a Team ranking.
+type Leaderboard struct {
+	TeamID  string         `json:"teamId"`
+	TrackID string         `json:"trackId,omitempty"`
+	Period  PeriodDTO      `json:"period"`
+	Entries []StandingItem `json:"entries"`
+	// HiddenMembers counts the members who opted out of leaderboards.
+	HiddenMembers int `json:"hiddenMembers"`
+}
+
+// StandingItem is one member's place and totals. Rank is 0 for a member who opted out.
+type StandingItem struct {
+	Rank     int    `json:"rank"`
+	Member   string `json:"member"`
+	Count    int    `json:"count"`
+	DoneLogs int    `json:"doneLogs"`
+	// Categories holds the member's own CategoryIDs, largest total first.
+	Categories []CategoryCount `json:"categories"`
+}
+
+// CategoryCount is a total under one CategoryID.
+type CategoryCount struct {
+	CategoryID string `json:"categoryId"`
+	Count      int    `json:"count"`
+}
+
+// GetLeaderboardHandler handles GetLeaderboardQuery. Any member may read it.
+type GetLeaderboardHandler struct {
+	Teams    TeamReader
+	DoneLogs TeamDoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+// Handle ranks the visible members; tied members share a rank (1, 2, 2, 4) and are listed by name.
+func (h GetLeaderboardHandler) Handle(ctx context.Context, q GetLeaderboardQuery) (Leaderboard, error) {
+	if err := q.Validate(); err != nil {
+		return Leaderboard{}, apperr.Invalid(err)
+	}
+	team, period, logs, err := loadTeamLogs(ctx, h.Teams, h.DoneLogs, q.TeamID, q.TrackID, q.StartDate, q.EndDate)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	board := h.Service.RankMembers(team, period, logs)
+
+	result := Leaderboard{
+		TeamID:        q.TeamID,
+		TrackID:       q.TrackID,
+		Period:        PeriodDTO{StartDate: q.StartDate, EndDate: q.EndDate},
+		Entries:       []StandingItem{},
+		HiddenMembers: board.Hidden(),
+	}
+	for _, entry := range board.Entries() {
+		result.Entries = append(result.Entries, newStandingItem(entry))
+	}
+	return result, nil
+}
+
+// CompareMembersQuery compares the caller with another member of a Team between two dates.
+type CompareMembersQuery struct {
+	TeamID    string
+	Member    string
+	TrackID   string
+	StartDate string
+	EndDate   string
+}
+
+func (q CompareMembersQuery) Validate() error {
+	if q.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	if q.Member == "" {
+		return fmt.Errorf("member is required")
+	}
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// MemberComparison is the read model of two members side by side.
+type MemberComparison struct {
+	TeamID  string       `json:"teamId"`
+	TrackID string       `json:"trackId,omitempty"`
+	Period  PeriodDTO    `json:"period"`
+	You     StandingItem `json:"you"`
+	Other   StandingItem `json:"other"`
+	// Difference is your total minus the other member's.
+	Difference int                     `json:"difference"`
+	Categories []CategoryComparisonRow `json:"categories"`
+}
+
+// CategoryComparisonRow puts both totals for one CategoryID side by side.
+type CategoryComparisonRow struct {
+	CategoryID string `json:"categoryId"`
+	You        int    `json:"you"`
+	Other      int    `json:"other"`
+	Difference int    `json:"difference"`
+}
+
+// CompareMembersHandler handles CompareMembersQuery.
+type CompareMembersHandler struct {
+	Teams    TeamReader
+	DoneLogs TeamDoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+// Handle refuses to compare with a member who opted out of leaderboards; callers who opted out
+// may still compare themselves with others.
+func (h CompareMembersHandler) Handle(ctx context.Context, q CompareMembersQuery) (MemberComparison, error) {
+	if err := q.Validate(); err != nil {
+		return MemberComparison{}, apperr.Invalid(err)
+	}
+	other, err := donelog.NewOwnerID(q.Member)
+	if err != nil {
+		return MemberComparison{}, apperr.Invalid(err)
+	}
+	team, period, logs, err := loadTeamLogs(ctx, h.Teams, h.DoneLogs, q.TeamID, q.TrackID, q.StartDate, q.EndDate)
+	if err != nil {
+		return MemberComparison{}, err
+	}
+	if _, ok := team.RoleOf(other); !ok {
+		return MemberComparison{}, fmt.Errorf("member %s of team %s: %w", q.Member, q.TeamID, apperr.ErrNotFound)
+	}
+	caller := appctx.Owner(ctx)
+	if other != caller && team.OptedOut(other) {
+		return MemberComparison{}, fmt.Errorf("%s opted out of leaderboards: %w", q.Member, apperr.ErrForbidden)
+	}
+	cmp, err := h.Service.CompareMembers(team, period, caller, other, logs)
+	if err != nil {
+		return MemberComparison{}, err
+	}
+
+	result := MemberComparison{
+		TeamID:     q.TeamID,
+		TrackID:    q.TrackID,
+		Period:     PeriodDTO{StartDate: q.StartDate, EndDate: q.EndDate},
+		You:        newStandingItem(cmp.Member()),
+		Other:      newStandingItem(cmp.Other()),
+		Difference: cmp.Difference(),
+		Categories: []CategoryComparisonRow{},
+	}
+	for _, c := range cmp.Categories() {
+		result.Categories = append(result.Categories, CategoryComparisonRow{
+			CategoryID: c.CategoryID().String(),
+			You:        c.Member().Int(),
+			Other:      c.Other().Int(),
+			Difference: c.Difference(),
+		})
+	}
+	return result, nil
+}
+
+// loadTeamLogs loads a Team the caller belongs to and its members' DONELOGs on team Tracks within the dates.
+func loadTeamLogs(ctx context.Context, teams TeamReader, reader TeamDoneLogReader, teamID, trackID, startDate, endDate string) (*donelog.Team, donelog.Period, map[donelog.OwnerID][]*donelog.DoneLog, error) {
+	period, err := parsePeriod(startDate, endDate)
+	if err != nil {
+		return nil, donelog.Period{}, nil, apperr.Invalid(err)
+	}
+	filter, err := parseFilter(trackID, "")
+	if err != nil {
+		return nil, donelog.Period{}, nil, apperr.Invalid(err)
+	}
+	team, err := findTeam(ctx, teams, teamID)
+	if err != nil {
+		return nil, donelog.Period{}, nil, err
+	}
+	raws, err := reader.ListTeamDoneLogs(ctx, team.ID(), period, filter)
+	if err != nil {
+		return nil, donelog.Period{}, nil, err
+	}
+	logs := map[donelog.OwnerID][]*donelog.DoneLog{}
+	for _, raw := range raws {
+		owner, err := donelog.NewOwnerID(raw.Owner)
+		if err != nil {
+			return nil, donelog.Period{}, nil, err
+		}
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return nil, donelog.Period{}, nil, err
+		}
+		logs[owner] = append(logs[owner], log)
+	}
+	return team, period, logs, nil
+}
+
+func newStandingItem(s donelog.MemberStanding) StandingItem {
+	item := StandingItem{
+		Rank:       s.Rank(),
+		Member:     s.Member().String(),
+		Count:      s.Total().Int(),
+		DoneLogs:   s.DoneLogs(),
+		Categories: []CategoryCount{},
+	}
+	for _, c := range s.Categories() {
+		item.Categories = append(item.Categories, CategoryCount{CategoryID: c.CategoryID().String(), Count: c.Count().Int()})
+	}
+	return item
+}
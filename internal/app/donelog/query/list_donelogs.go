@@ -0,0 +1,200 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListDoneLogsQuery asks for one page of DONELOGs inside a period, newest first.
+// Empty TrackID, CategoryID and Tag mean "all"; Page starts at 1.
+type ListDoneLogsQuery struct {
+	TrackID    string
+	CategoryID string
+	Tag        string
+	StartDate  string
+	EndDate    string
+	Page       int
+	Limit      int
+}
+
+func (q ListDoneLogsQuery) Validate() error {
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	if q.Page < 0 {
+		return fmt.Errorf("page must be >= 1")
+	}
+	if q.Limit < 0 || q.Limit > maxListLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+	}
+	return nil
+}
+
+// DoneLogItem is the read model for one DONELOG in a listing.
+type DoneLogItem struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	TrackID      string   `json:"trackId"`
+	TrackName    string   `json:"trackName"`
+	CategoryID   string   `json:"categoryId"`
+	CategoryName string   `json:"categoryName"`
+	Tags         []string `json:"tags,omitempty"`
+	Note         string   `json:"note,omitempty"`
+	Links        []string `json:"links,omitempty"`
+	Count        int      `json:"count"`
+	OccurredOn   string   `json:"occurredOn"`
+}
+
+// DoneLogPage is one page of DoneLogItems.
+type DoneLogPage struct {
+	Items      []DoneLogItem `json:"items"`
+	Page       int           `json:"page"`
+	Limit      int           `json:"limit"`
+	TotalCount int           `json:"totalCount"`
+}
+
+// ListDoneLogsHandler handles ListDoneLogsQuery.
+type ListDoneLogsHandler struct {
+	DoneLogs   DoneLogReader
+	Tracks     TrackReader
+	Categories CategoryReader
+}
+
+// Handle returns the requested page. Archived Tracks and Categories still provide names.
+func (h ListDoneLogsHandler) Handle(ctx context.Context, q ListDoneLogsQuery) (DoneLogPage, error) {
+	if err := q.Validate(); err != nil {
+		return DoneLogPage{}, apperr.Invalid(err)
+	}
+	period, err := parsePeriod(q.StartDate, q.EndDate)
+	if err != nil {
+		return DoneLogPage{}, apperr.Invalid(err)
+	}
+	filter, err := parseFilter(q.TrackID, q.CategoryID)
+	if err != nil {
+		return DoneLogPage{}, apperr.Invalid(err)
+	}
+	if filter, err = withTag(filter, q.Tag); err != nil {
+		return DoneLogPage{}, apperr.Invalid(err)
+	}
+
+	page, limit := q.Page, q.Limit
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	logs, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return DoneLogPage{}, err
+	}
+	names, err := loadNames(ctx, h.Tracks, h.Categories)
+	if err != nil {
+		return DoneLogPage{}, err
+	}
+
+	result := DoneLogPage{Items: []DoneLogItem{}, Page: page, Limit: limit, TotalCount: len(logs)}
+	// Pages past the end are empty. Compare by division so a huge page cannot overflow the offset.
+	if page-1 > (len(logs)-1)/limit {
+		return result, nil
+	}
+	// Readers return oldest first; walk backwards for newest first.
+	for i := len(logs) - 1 - (page-1)*limit; i >= 0 && len(result.Items) < limit; i-- {
+		result.Items = append(result.Items, names.item(logs[i]))
+	}
+	return result, nil
+}
+
+// catalogNames maps Track and Category IDs to display names.
+type catalogNames struct {
+	tracks     map[string]string
+	categories map[string]string
+}
+
+func (n catalogNames) item(raw donelog.RawDoneLog) DoneLogItem {
+	return DoneLogItem{
+		ID:           raw.ID,
+		Title:        raw.Title,
+		TrackID:      raw.TrackID,
+		TrackName:    n.tracks[raw.TrackID],
+		CategoryID:   raw.CategoryID,
+		CategoryName: n.categories[raw.CategoryID],
+		Tags:         raw.Tags,
+		Note:         raw.Note,
+		Links:        raw.Links,
+		Count:        raw.Count,
+		OccurredOn:   raw.OccurredOn.Format("2006-01-02"),
+	}
+}
+
+func loadNames(ctx context.Context, tracks TrackReader, categories CategoryReader) (catalogNames, error) {
+	names := catalogNames{tracks: map[string]string{}, categories: map[string]string{}}
+	rawTracks, err := tracks.ListTracks(ctx)
+	if err != nil {
+		return names, err
+	}
+	for _, t := range rawTracks {
+		names.tracks[t.ID] = t.Name
+	}
+	rawCategories, err := categories.ListCategories(ctx)
+	if err != nil {
+		return names, err
+	}
+	for _, c := range rawCategories {
+		names.categories[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+func parsePeriod(startDate, endDate string) (donelog.Period, error) {
+	start, err := donelog.NewOccurredOn(startDate)
+	if err != nil {
+		return donelog.Period{}, fmt.Errorf("startDate: %w", err)
+	}
+	end, err := donelog.NewOccurredOn(endDate)
+	if err != nil {
+		return donelog.Period{}, fmt.Errorf("endDate: %w", err)
+	}
+	return donelog.NewPeriod(start, end)
+}
+
+func parseFilter(trackID, categoryID string) (DoneLogFilter, error) {
+	var filter DoneLogFilter
+	if trackID != "" {
+		id, err := donelog.NewTrackID(trackID)
+		if err != nil {
+			return filter, err
+		}
+		filter.TrackID = &id
+	}
+	if categoryID != "" {
+		id, err := donelog.NewCategoryID(categoryID)
+		if err != nil {
+			return filter, err
+		}
+		filter.CategoryID = &id
+	}
+	return filter, nil
+}
+
+// withTag narrows filter to DONELOGs carrying tag. An empty tag leaves it unchanged.
+func withTag(filter DoneLogFilter, tag string) (DoneLogFilter, error) {
+	if tag == "" {
+		return filter, nil
+	}
+	t, err := donelog.NewTag(tag)
+	if err != nil {
+		return filter, err
+	}
+	filter.Tag = &t
+	return filter, nil
+}
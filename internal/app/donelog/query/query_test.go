@@ -0,0 +1,849 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+	"strings"
+)
+
+type mockAuditReader struct {
+	entries []donelog.AuditEntry
+	err     error
+}
+
+func (m mockAuditReader) ListByDoneLogID(ctx context.Context, id donelog.DoneLogID) ([]donelog.AuditEntry, error) {
+	return m.entries, m.err
+}
+
+func TestGetDoneLogHistory(t *testing.T) {
+	created := donelog.AuditEntry{
+		DoneLogID:  "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Action:     donelog.AuditActionCreated,
+		Actor:      "taketo",
+		RecordedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+	}
+	updated := donelog.AuditEntry{
+		DoneLogID:  "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Action:     donelog.AuditActionUpdated,
+		Actor:      "guest",
+		RequestID:  "req-2",
+		RecordedAt: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC),
+		Changes:    []donelog.FieldChange{{Field: "count", Before: "50", After: "5"}},
+	}
+
+	tests := []struct {
+		name       string
+		query      GetDoneLogHistoryQuery
+		entries    []donelog.AuditEntry
+		wantErr    bool
+		wantLen    int
+		wantLastBy string
+	}{
+		{
+			name:       "OK: sorted oldest first",
+			query:      GetDoneLogHistoryQuery{ID: "01HYR1X5C9XM9P6H7K71M9QAHX"},
+			entries:    []donelog.AuditEntry{updated, created},
+			wantLen:    2,
+			wantLastBy: "guest",
+		},
+		{
+			name:    "NG: invalid id",
+			query:   GetDoneLogHistoryQuery{ID: "invalid"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := GetDoneLogHistoryHandler{Audit: mockAuditReader{entries: tt.entries}}
+
+			history, err := handler.Handle(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(history) != tt.wantLen {
+				t.Fatalf("expected %d entries, got %d", tt.wantLen, len(history))
+			}
+			last := history[len(history)-1]
+			if last.Actor != tt.wantLastBy {
+				t.Fatalf("expected last actor %s, got %s", tt.wantLastBy, last.Actor)
+			}
+			if last.Changes[0].Before != "50" || last.Changes[0].After != "5" {
+				t.Fatalf("unexpected change: %+v", last.Changes[0])
+			}
+		})
+	}
+}
+
+type stubDoneLogReader struct {
+	logs []donelog.RawDoneLog
+}
+
+// ListByPeriod mimics the store: filter, then oldest first (input is already sorted).
+func (s stubDoneLogReader) ListByPeriod(ctx context.Context, period donelog.Period, filter DoneLogFilter) ([]donelog.RawDoneLog, error) {
+	var out []donelog.RawDoneLog
+	for _, raw := range s.logs {
+		if filter.Matches(raw) && period.Contains(donelog.OccurredOnFromTime(raw.OccurredOn)) {
+			out = append(out, raw)
+		}
+	}
+	return out, nil
+}
+
+type stubCatalog struct{}
+
+func (stubCatalog) ListTracks(ctx context.Context) ([]donelog.RawTrack, error) {
+	return []donelog.RawTrack{
+		{ID: "reading", Name: "Reading", Active: true},
+		{ID: "old", Name: "Old", Active: false},
+	}, nil
+}
+
+func (stubCatalog) ListCategories(ctx context.Context) ([]donelog.RawCategory, error) {
+	return []donelog.RawCategory{{ID: "pages", Name: "Pages", Active: true}}, nil
+}
+
+func sampleLogs() []donelog.RawDoneLog {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	return []donelog.RawDoneLog{
+		{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Title: "a", TrackID: "reading", CategoryID: "pages", Count: 10, OccurredOn: day(1)},
+		{ID: "01HYR1X5C9XM9P6H7K71M9QAH2", Title: "b", TrackID: "reading", CategoryID: "pages", Count: 5, OccurredOn: day(1)},
+		{ID: "01HYR1X5C9XM9P6H7K71M9QAH3", Title: "c", TrackID: "old", CategoryID: "pages", Count: 7, OccurredOn: day(3)},
+	}
+}
+
+func TestListDoneLogs(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     ListDoneLogsQuery
+		wantErr   bool
+		wantIDs   []string
+		wantTotal int
+	}{
+		{
+			name:      "OK: newest first with names",
+			query:     ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31"},
+			wantIDs:   []string{"01HYR1X5C9XM9P6H7K71M9QAH3", "01HYR1X5C9XM9P6H7K71M9QAH2", "01HYR1X5C9XM9P6H7K71M9QAH1"},
+			wantTotal: 3,
+		},
+		{
+			name:      "OK: second page",
+			query:     ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31", Page: 2, Limit: 2},
+			wantIDs:   []string{"01HYR1X5C9XM9P6H7K71M9QAH1"},
+			wantTotal: 3,
+		},
+		{
+			name:      "OK: page past the end is empty",
+			query:     ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31", Page: 3, Limit: 2},
+			wantIDs:   []string{},
+			wantTotal: 3,
+		},
+		{
+			name:      "OK: huge page does not overflow",
+			query:     ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31", Page: 4611686018427387904},
+			wantIDs:   []string{},
+			wantTotal: 3,
+		},
+		{
+			name:      "OK: track filter",
+			query:     ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31", TrackID: "old"},
+			wantIDs:   []string{"01HYR1X5C9XM9P6H7K71M9QAH3"},
+			wantTotal: 1,
+		},
+		{
+			name:    "NG: missing period",
+			query:   ListDoneLogsQuery{StartDate: "2024-05-01"},
+			wantErr: true,
+		},
+		{
+			name:    "NG: limit too large",
+			query:   ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31", Limit: 101},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ListDoneLogsHandler{DoneLogs: stubDoneLogReader{logs: sampleLogs()}, Tracks: stubCatalog{}, Categories: stubCatalog{}}
+
+			page, err := handler.Handle(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if page.TotalCount != tt.wantTotal {
+				t.Fatalf("expected total %d, got %d", tt.wantTotal, page.TotalCount)
+			}
+			if len(page.Items) != len(tt.wantIDs) {
+				t.Fatalf("expected %d items, got %+v", len(tt.wantIDs), page.Items)
+			}
+			for i, id := range tt.wantIDs {
+				if page.Items[i].ID != id {
+					t.Fatalf("item %d = %s, want %s", i, page.Items[i].ID, id)
+				}
+			}
+			if len(page.Items) > 0 && page.Items[0].CategoryName != "Pages" {
+				t.Fatalf("expected category name, got %+v", page.Items[0])
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	reader := stubDoneLogReader{logs: sampleLogs()}
+
+	daily, err := SummarizeByDayHandler{DoneLogs: reader}.Handle(context.Background(), SummarizeByDayQuery{StartDate: "2024-05-01", EndDate: "2024-05-03"})
+	if err != nil {
+		t.Fatalf("daily: %v", err)
+	}
+	if daily.TotalCount != 22 || len(daily.Points) != 3 || daily.Points[0].Count != 15 || daily.Points[1].Count != 0 {
+		t.Fatalf("unexpected daily summary: %+v", daily)
+	}
+
+	monthly, err := SummarizeByMonthHandler{DoneLogs: reader}.Handle(context.Background(), SummarizeByMonthQuery{CategoryID: "pages", StartMonth: "2024-04", EndMonth: "2024-05"})
+	if err != nil {
+		t.Fatalf("monthly: %v", err)
+	}
+	if monthly.Period.EndDate != "2024-05-31" || monthly.CategoryID != "pages" || len(monthly.Points) != 2 || monthly.Points[1].Count != 22 {
+		t.Fatalf("unexpected monthly summary: %+v", monthly)
+	}
+
+	if _, err := (SummarizeByMonthHandler{DoneLogs: reader}).Handle(context.Background(), SummarizeByMonthQuery{StartMonth: "2024-13", EndMonth: "2024-05"}); err == nil {
+		t.Fatal("expected invalid month to fail")
+	}
+}
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func TestGetStreaks(t *testing.T) {
+	handler := GetStreaksHandler{
+		DoneLogs: stubDoneLogReader{logs: sampleLogs()},
+		Tracks:   stubCatalog{},
+		Clock:    fixedClock{now: time.Date(2024, 5, 2, 20, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name      string
+		query     GetStreaksQuery
+		wantErr   error
+		wantIDs   []string
+		wantState string
+		wantLen   int
+	}{
+		{
+			name:      "OK: active tracks only, yesterday keeps the streak at risk",
+			query:     GetStreaksQuery{},
+			wantIDs:   []string{"reading"},
+			wantState: "at_risk",
+			wantLen:   1,
+		},
+		{
+			name:      "OK: archived track by id, future logs ignored",
+			query:     GetStreaksQuery{TrackID: "old", Unit: "week"},
+			wantIDs:   []string{"old"},
+			wantState: "none",
+		},
+		{
+			name:    "NG: unknown unit",
+			query:   GetStreaksQuery{Unit: "month"},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name:    "NG: unknown track",
+			query:   GetStreaksQuery{TrackID: "ghost"},
+			wantErr: apperr.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streaks, err := handler.Handle(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(streaks) != len(tt.wantIDs) || streaks[0].TrackID != tt.wantIDs[0] {
+				t.Fatalf("unexpected streaks: %+v", streaks)
+			}
+			if streaks[0].State != tt.wantState || streaks[0].Current.Length != tt.wantLen {
+				t.Fatalf("unexpected streak: %+v", streaks[0])
+			}
+		})
+	}
+}
+
+type stubGoals []donelog.RawGoal
+
+func (s stubGoals) ListGoals(ctx context.Context) ([]donelog.RawGoal, error) {
+	return s, nil
+}
+
+func (s stubGoals) FindByID(ctx context.Context, id donelog.GoalID) (*donelog.RawGoal, error) {
+	for _, raw := range s {
+		if raw.ID == id.String() {
+			return &raw, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestGoalProgress(t *testing.T) {
+	may := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	goals := stubGoals{
+		{ID: "01HYR1X5C9XM9P6H7K71M9QAG1", Name: "Reading in May", TrackID: "reading", StartDate: may(1), EndDate: may(31), Target: 45},
+		{ID: "01HYR1X5C9XM9P6H7K71M9QAG2", Name: "Pages", CategoryID: "pages", StartDate: may(1), EndDate: may(3), Target: 20},
+	}
+	deps := GetGoalProgressHandler{
+		Goals:      goals,
+		DoneLogs:   stubDoneLogReader{logs: sampleLogs()},
+		Tracks:     stubCatalog{},
+		Categories: stubCatalog{},
+		Clock:      fixedClock{now: time.Date(2024, 5, 2, 20, 0, 0, 0, time.UTC)},
+	}
+
+	item, err := deps.Handle(context.Background(), GetGoalProgressQuery{ID: "01HYR1X5C9XM9P6H7K71M9QAG1"})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	want := GoalProgressItem{
+		ID: "01HYR1X5C9XM9P6H7K71M9QAG1", Name: "Reading in May", TrackID: "reading", TrackName: "Reading",
+		StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 45,
+		Done: 15, Remaining: 30, Percent: 33.3, DaysLeft: 30, RequiredDailyPace: 1, Status: "in_progress",
+	}
+	if item != want {
+		t.Fatalf("progress = %+v, want %+v", item, want)
+	}
+
+	if _, err := deps.Handle(context.Background(), GetGoalProgressQuery{ID: "01HYR1X5C9XM9P6H7K71M9QAG9"}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("unknown goal error = %v, want not found", err)
+	}
+	if _, err := deps.Handle(context.Background(), GetGoalProgressQuery{ID: "nope"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("invalid id error = %v, want invalid", err)
+	}
+
+	list, err := ListGoalProgressHandler(deps).Handle(context.Background(), ListGoalProgressQuery{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 || list[1].Done != 22 || list[1].Status != "achieved" || list[1].CategoryName != "Pages" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+}
+
+func TestGetHeatmap(t *testing.T) {
+	handler := GetHeatmapHandler{
+		DoneLogs: stubDoneLogReader{logs: sampleLogs()},
+		Clock:    fixedClock{now: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	heatmap, err := handler.Handle(context.Background(), GetHeatmapQuery{})
+	if err != nil {
+		t.Fatalf("heatmap: %v", err)
+	}
+	if heatmap.Year != 2024 || len(heatmap.Days) != 366 || heatmap.Days[0].Date != "2024-01-01" {
+		t.Fatalf("unexpected year layout: year=%d days=%d", heatmap.Year, len(heatmap.Days))
+	}
+	may1, may3 := heatmap.Days[121], heatmap.Days[123]
+	if may1.Date != "2024-05-01" || may1.Count != 15 || may1.Level != 4 || may3.Count != 7 || may3.Level != 1 {
+		t.Fatalf("unexpected days: %+v %+v", may1, may3)
+	}
+	if heatmap.TotalCount != 22 || heatmap.MaxCount != 15 || heatmap.ActiveDays != 2 {
+		t.Fatalf("unexpected totals: %+v", heatmap)
+	}
+
+	filtered, err := handler.Handle(context.Background(), GetHeatmapQuery{Year: 2024, TrackID: "old"})
+	if err != nil || filtered.TotalCount != 7 || filtered.Days[121].Level != 0 {
+		t.Fatalf("filtered heatmap = %+v, %v", filtered.TotalCount, err)
+	}
+
+	empty, err := handler.Handle(context.Background(), GetHeatmapQuery{Year: 2023})
+	if err != nil || len(empty.Days) != 365 || len(empty.Thresholds) != 0 || empty.Thresholds == nil {
+		t.Fatalf("empty heatmap = %d days, thresholds %v, %v", len(empty.Days), empty.Thresholds, err)
+	}
+
+	if _, err := handler.Handle(context.Background(), GetHeatmapQuery{Year: 2024, CategoryID: "Bad Id"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected invalid filter, got %v", err)
+	}
+}
+
+type stubSettings map[string]string
+
+func (s stubSettings) Get(ctx context.Context, key string) (string, bool, error) {
+	value, ok := s[key]
+	return value, ok, nil
+}
+
+func TestSummarizeByWeek(t *testing.T) {
+	reader := stubDoneLogReader{logs: sampleLogs()}
+
+	tests := []struct {
+		name       string
+		settings   SettingsReader
+		query      SummarizeByWeekQuery
+		wantErr    error
+		wantLabels []string
+		wantCounts []int
+	}{
+		{
+			name:       "OK: monday by default",
+			query:      SummarizeByWeekQuery{StartDate: "2024-04-28", EndDate: "2024-05-06"},
+			wantLabels: []string{"2024-W17", "2024-W18", "2024-W19"},
+			wantCounts: []int{0, 22, 0},
+		},
+		{
+			name:       "OK: sunday from settings",
+			settings:   stubSettings{WeekStartSetting: "sunday"},
+			query:      SummarizeByWeekQuery{StartDate: "2024-04-28", EndDate: "2024-05-06"},
+			wantLabels: []string{"2024-W18", "2024-W19"},
+			wantCounts: []int{22, 0},
+		},
+		{
+			name:       "OK: query overrides settings",
+			settings:   stubSettings{WeekStartSetting: "sunday"},
+			query:      SummarizeByWeekQuery{StartDate: "2024-04-28", EndDate: "2024-05-06", WeekStart: "monday"},
+			wantLabels: []string{"2024-W17", "2024-W18", "2024-W19"},
+			wantCounts: []int{0, 22, 0},
+		},
+		{
+			name:    "NG: unknown week start",
+			query:   SummarizeByWeekQuery{StartDate: "2024-04-28", EndDate: "2024-05-06", WeekStart: "friday"},
+			wantErr: apperr.ErrInvalid,
+		},
+		{
+			name:    "NG: missing dates",
+			query:   SummarizeByWeekQuery{StartDate: "2024-04-28"},
+			wantErr: apperr.ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := SummarizeByWeekHandler{DoneLogs: reader, Settings: tt.settings}.Handle(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(summary.Points) != len(tt.wantLabels) {
+				t.Fatalf("unexpected points: %+v", summary.Points)
+			}
+			for i, p := range summary.Points {
+				if p.Label != tt.wantLabels[i] || p.Count != tt.wantCounts[i] {
+					t.Fatalf("point %d = %+v, want %s:%d", i, p, tt.wantLabels[i], tt.wantCounts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeByQuarterAndYear(t *testing.T) {
+	reader := stubDoneLogReader{logs: sampleLogs()}
+
+	quarterly, err := SummarizeByQuarterHandler{DoneLogs: reader}.Handle(context.Background(), SummarizeByQuarterQuery{StartQuarter: "2024-Q1", EndQuarter: "2024-Q2"})
+	if err != nil {
+		t.Fatalf("quarterly: %v", err)
+	}
+	if quarterly.Period.EndDate != "2024-06-30" || len(quarterly.Points) != 2 || quarterly.Points[1].Label != "2024-Q2" || quarterly.Points[1].Count != 22 {
+		t.Fatalf("unexpected quarterly summary: %+v", quarterly)
+	}
+
+	yearly, err := SummarizeByYearHandler{DoneLogs: reader}.Handle(context.Background(), SummarizeByYearQuery{StartYear: "2023", EndYear: "2024"})
+	if err != nil {
+		t.Fatalf("yearly: %v", err)
+	}
+	if yearly.Period.EndDate != "2024-12-31" || len(yearly.Points) != 2 || yearly.Points[0].Count != 0 || yearly.Points[1].Count != 22 {
+		t.Fatalf("unexpected yearly summary: %+v", yearly)
+	}
+
+	for _, q := range []SummarizeByQuarterQuery{
+		{StartQuarter: "2024-Q5", EndQuarter: "2024-Q1"},
+		{StartQuarter: "2024-1", EndQuarter: "2024-Q1"},
+		{StartQuarter: "2024-Q1"},
+	} {
+		if _, err := (SummarizeByQuarterHandler{DoneLogs: reader}).Handle(context.Background(), q); !errors.Is(err, apperr.ErrInvalid) {
+			t.Fatalf("%+v: expected invalid, got %v", q, err)
+		}
+	}
+}
+
+func TestCompareYearOverYear(t *testing.T) {
+	logs := append(sampleLogs(), donelog.RawDoneLog{
+		ID: "01HYR1X5C9XM9P6H7K71M9QAH4", Title: "d", TrackID: "reading", CategoryID: "pages", Count: 20,
+		OccurredOn: time.Date(2023, 5, 20, 0, 0, 0, 0, time.UTC),
+	})
+	handler := CompareYearOverYearHandler{
+		DoneLogs:   stubDoneLogReader{logs: logs},
+		Tracks:     stubCatalog{},
+		Categories: stubCatalog{},
+		Clock:      fixedClock{now: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	yoy, err := handler.Handle(context.Background(), CompareYearOverYearQuery{GroupBy: "track"})
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if yoy.Year != 2024 || yoy.PreviousYear != 2023 || yoy.Unit != "month" || len(yoy.Groups) != 2 {
+		t.Fatalf("unexpected comparison: %+v", yoy)
+	}
+	old, reading := yoy.Groups[0], yoy.Groups[1]
+	may := reading.Rows[4]
+	if reading.Name != "Reading" || may.Label != "2024-05" || may.Current != 15 || may.Previous != 20 || may.Change != -5 || may.ChangePercent == nil || *may.ChangePercent != -25 {
+		t.Fatalf("unexpected reading/may: %+v %+v", reading.Name, may)
+	}
+	if old.Name != "Old" || old.Total.Current != 7 || old.Total.ChangePercent != nil {
+		t.Fatalf("unexpected old track: %+v", old.Total)
+	}
+
+	quarters, err := handler.Handle(context.Background(), CompareYearOverYearQuery{Year: 2024, Unit: "quarter", CategoryID: "pages"})
+	if err != nil {
+		t.Fatalf("quarters: %v", err)
+	}
+	if len(quarters.Groups) != 1 || quarters.Groups[0].Name != "Pages" || len(quarters.Groups[0].Rows) != 4 || quarters.Groups[0].Rows[1].Current != 22 {
+		t.Fatalf("unexpected quarter comparison: %+v", quarters)
+	}
+
+	for _, q := range []CompareYearOverYearQuery{{Unit: "week"}, {GroupBy: "title"}, {TrackID: "Bad Id"}} {
+		if _, err := handler.Handle(context.Background(), q); !errors.Is(err, apperr.ErrInvalid) {
+			t.Fatalf("%+v: expected invalid, got %v", q, err)
+		}
+	}
+}
+
+func TestGetTrend(t *testing.T) {
+	handler := GetTrendHandler{DoneLogs: stubDoneLogReader{logs: sampleLogs()}, Settings: stubSettings{WeekStartSetting: "sunday"}}
+
+	trend, err := handler.Handle(context.Background(), GetTrendQuery{TrackID: "reading", StartDate: "2024-04-28", EndDate: "2024-05-04"})
+	if err != nil {
+		t.Fatalf("trend: %v", err)
+	}
+	if trend.WeekStart != "sunday" || trend.TotalCount != 15 || len(trend.Points) != 7 || trend.MedianDailyCount != 0 {
+		t.Fatalf("unexpected trend: %+v", trend)
+	}
+	if trend.BestDay.Label != "2024-05-01" || trend.BestDay.Count != 15 || trend.BestWeek == nil || trend.BestWeek.Label != "2024-W18" {
+		t.Fatalf("unexpected best: %+v %+v", trend.BestDay, trend.BestWeek)
+	}
+	last := trend.Points[6]
+	if last.Cumulative != 15 || last.MovingAverage7 == nil || *last.MovingAverage7 != 2.14 || last.MovingAverage30 != nil {
+		t.Fatalf("unexpected last point: %+v", last)
+	}
+	if trend.Points[5].MovingAverage7 != nil {
+		t.Fatalf("expected no 7-day average before the window is full: %+v", trend.Points[5])
+	}
+
+	for _, q := range []GetTrendQuery{
+		{StartDate: "2024-05-01"},
+		{StartDate: "2024-05-01", EndDate: "2024-05-07", WeekStart: "friday"},
+		{StartDate: "2023-01-01", EndDate: "2024-05-07"},
+	} {
+		if _, err := handler.Handle(context.Background(), q); !errors.Is(err, apperr.ErrInvalid) {
+			t.Fatalf("%+v: expected invalid, got %v", q, err)
+		}
+	}
+}
+
+func TestGetForecast(t *testing.T) {
+	may := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	handler := GetForecastHandler{
+		Goals:      stubGoals{{ID: "01HYR1X5C9XM9P6H7K71M9QAG1", Name: "Reading in May", TrackID: "reading", StartDate: may(1), EndDate: may(31), Target: 45}},
+		DoneLogs:   stubDoneLogReader{logs: sampleLogs()},
+		Tracks:     stubCatalog{},
+		Categories: stubCatalog{},
+		Clock:      fixedClock{now: time.Date(2024, 5, 3, 20, 0, 0, 0, time.UTC)},
+	}
+
+	byTrack, err := handler.Handle(context.Background(), GetForecastQuery{TrackID: "reading", Target: 30, Deadline: "2024-05-31", WindowDays: 7})
+	if err != nil {
+		t.Fatalf("track forecast: %v", err)
+	}
+	if byTrack.TrackName != "Reading" || byTrack.Done != 15 || byTrack.DailyPace != 2.14 || byTrack.DailyPaceLow != 0 ||
+		byTrack.ProjectedDate == nil || *byTrack.ProjectedDate != "2024-05-10" || byTrack.LatestDate != nil ||
+		byTrack.Status != "at_risk" || byTrack.Warning == "" {
+		t.Fatalf("unexpected track forecast: %+v", byTrack)
+	}
+
+	byGoal, err := handler.Handle(context.Background(), GetForecastQuery{GoalID: "01HYR1X5C9XM9P6H7K71M9QAG1"})
+	if err != nil {
+		t.Fatalf("goal forecast: %v", err)
+	}
+	if byGoal.Target != 45 || byGoal.WindowDays != 28 || byGoal.Deadline != "2024-05-31" || *byGoal.ProjectedDate != "2024-06-28" ||
+		byGoal.DaysLate != 28 || byGoal.Status != "behind" || !strings.Contains(byGoal.Warning, "28 days after") {
+		t.Fatalf("unexpected goal forecast: %+v", byGoal)
+	}
+
+	if _, err := handler.Handle(context.Background(), GetForecastQuery{GoalID: "01HYR1X5C9XM9P6H7K71M9QAG9"}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("unknown goal error = %v, want not found", err)
+	}
+	for _, q := range []GetForecastQuery{
+		{},
+		{TrackID: "reading"},
+		{GoalID: "01HYR1X5C9XM9P6H7K71M9QAG1", Target: 10},
+		{TrackID: "reading", Target: -1},
+		{TrackID: "reading", Target: 10, Deadline: "05/31"},
+		{TrackID: "reading", Target: 10, WindowDays: 400},
+	} {
+		if _, err := handler.Handle(context.Background(), q); !errors.Is(err, apperr.ErrInvalid) {
+			t.Fatalf("%+v: expected invalid, got %v", q, err)
+		}
+	}
+}
+
+type stubTeams struct {
+	teams []donelog.RawTeam
+	logs  []donelog.RawDoneLog
+}
+
+func (s stubTeams) FindByID(ctx context.Context, id donelog.TeamID) (*donelog.RawTeam, error) {
+	for _, raw := range s.teams {
+		if raw.ID == id.String() {
+			return &raw, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s stubTeams) ListByMember(ctx context.Context, member donelog.OwnerID) ([]donelog.RawTeam, error) {
+	var teams []donelog.RawTeam
+	for _, raw := range s.teams {
+		if _, ok := raw.Members[member.String()]; ok {
+			teams = append(teams, raw)
+		}
+	}
+	return teams, nil
+}
+
+func (s stubTeams) ListByInvitee(ctx context.Context, invitee donelog.OwnerID) ([]donelog.RawTeam, error) {
+	var teams []donelog.RawTeam
+	for _, raw := range s.teams {
+		if _, ok := raw.Invites[invitee.String()]; ok {
+			teams = append(teams, raw)
+		}
+	}
+	return teams, nil
+}
+
+func (s stubTeams) ListTeamDoneLogs(ctx context.Context, team donelog.TeamID, period donelog.Period, filter DoneLogFilter) ([]donelog.RawDoneLog, error) {
+	return s.logs, nil
+}
+
+func TestGetTeamSummary(t *testing.T) {
+	may := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	teams := stubTeams{
+		teams: []donelog.RawTeam{{ID: "club", Name: "Club", Members: map[string]string{"alice": "owner", "bob": "viewer", "carol": "editor"}, Invites: map[string]string{"erin": "viewer"}}},
+		logs: []donelog.RawDoneLog{
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Owner: "alice", TrackID: "run", Count: 3, OccurredOn: may(1)},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH2", Owner: "carol", TrackID: "run", Count: 5, OccurredOn: may(2)},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH3", Owner: "alice", TrackID: "run", Count: 2, OccurredOn: may(3)},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH4", Owner: "dave", TrackID: "run", Count: 9, OccurredOn: may(3)},
+		},
+	}
+	as := func(name string) context.Context {
+		owner, _ := donelog.NewOwnerID(name)
+		return appctx.WithOwner(context.Background(), owner)
+	}
+	handler := GetTeamSummaryHandler{Teams: teams, DoneLogs: teams}
+
+	summary, err := handler.Handle(as("bob"), GetTeamSummaryQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("team summary: %v", err)
+	}
+	want := []MemberTotal{
+		{Member: "alice", Role: "owner", Count: 5, DoneLogs: 2},
+		{Member: "bob", Role: "viewer"},
+		{Member: "carol", Role: "editor", Count: 5, DoneLogs: 1},
+	}
+	if summary.TotalCount != 10 || len(summary.Members) != len(want) {
+		t.Fatalf("unexpected team summary: %+v", summary)
+	}
+	for i := range want {
+		if summary.Members[i] != want[i] {
+			t.Fatalf("member %d = %+v, want %+v", i, summary.Members[i], want[i])
+		}
+	}
+
+	optedOut := teams
+	optedOut.teams = []donelog.RawTeam{{ID: "club", Name: "Club", Members: teams.teams[0].Members, LeaderboardOptOut: []string{"carol"}}}
+	hidden := GetTeamSummaryHandler{Teams: optedOut, DoneLogs: optedOut}
+	summary, err = hidden.Handle(as("bob"), GetTeamSummaryQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("team summary: %v", err)
+	}
+	if summary.TotalCount != 10 || summary.HiddenMembers != 1 || len(summary.Members) != 2 || summary.Members[1].Member != "bob" {
+		t.Fatalf("opted-out member should be hidden from others: %+v", summary)
+	}
+	summary, err = hidden.Handle(as("carol"), GetTeamSummaryQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("team summary: %v", err)
+	}
+	if summary.HiddenMembers != 0 || len(summary.Members) != 3 || summary.Members[2] != want[2] {
+		t.Fatalf("opted-out member should still see themselves: %+v", summary)
+	}
+
+	if items, _ := (ListTeamsHandler{Teams: teams}).Handle(as("carol")); len(items) != 1 || items[0].Role != "editor" || len(items[0].Members) != 3 || len(items[0].Invites) != 1 {
+		t.Fatalf("unexpected teams: %+v", items)
+	}
+	if items, _ := (ListTeamsHandler{Teams: teams}).Handle(as("erin")); len(items) != 0 {
+		t.Fatalf("invitees are not members until they accept, got %+v", items)
+	}
+	if invites, _ := (ListTeamInvitesHandler{Teams: teams}).Handle(as("erin")); len(invites) != 1 || invites[0] != (TeamInviteItem{TeamID: "club", Name: "Club", Role: "viewer"}) {
+		t.Fatalf("unexpected invites: %+v", invites)
+	}
+	if items, _ := (ListTeamsHandler{Teams: teams}).Handle(as("dave")); len(items) != 0 {
+		t.Fatalf("dave is in no team, got %+v", items)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		ctx     context.Context
+		q       GetTeamSummaryQuery
+		wantErr error
+	}{
+		{name: "non-member", ctx: as("dave"), q: GetTeamSummaryQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"}, wantErr: apperr.ErrNotFound},
+		{name: "unknown team", ctx: as("alice"), q: GetTeamSummaryQuery{TeamID: "other", StartDate: "2024-05-01", EndDate: "2024-05-31"}, wantErr: apperr.ErrNotFound},
+		{name: "missing dates", ctx: as("alice"), q: GetTeamSummaryQuery{TeamID: "club"}, wantErr: apperr.ErrInvalid},
+		{name: "bad team id", ctx: as("alice"), q: GetTeamSummaryQuery{TeamID: "Club!", StartDate: "2024-05-01", EndDate: "2024-05-31"}, wantErr: apperr.ErrInvalid},
+	} {
+		if _, err := handler.Handle(tt.ctx, tt.q); !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	may := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	teams := stubTeams{
+		teams: []donelog.RawTeam{{
+			ID: "club", Name: "Club",
+			Members:           map[string]string{"alice": "owner", "bob": "editor", "carol": "editor", "erin": "viewer"},
+			LeaderboardOptOut: []string{"carol"},
+		}},
+		logs: []donelog.RawDoneLog{
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH1", Title: "run", Owner: "alice", TrackID: "run", CategoryID: "km", Count: 3, OccurredOn: may(1)},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH2", Title: "run", Owner: "bob", TrackID: "run", CategoryID: "km", Count: 5, OccurredOn: may(2)},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH3", Title: "run", Owner: "alice", TrackID: "run", CategoryID: "hill", Count: 2, OccurredOn: may(3)},
+			{ID: "01HYR1X5C9XM9P6H7K71M9QAH4", Title: "run", Owner: "carol", TrackID: "run", CategoryID: "km", Count: 9, OccurredOn: may(3)},
+		},
+	}
+	as := func(name string) context.Context {
+		owner, _ := donelog.NewOwnerID(name)
+		return appctx.WithOwner(context.Background(), owner)
+	}
+	period := func(q GetLeaderboardQuery) GetLeaderboardQuery {
+		q.TeamID, q.StartDate, q.EndDate = "club", "2024-05-01", "2024-05-31"
+		return q
+	}
+
+	board, err := GetLeaderboardHandler{Teams: teams, DoneLogs: teams}.Handle(as("erin"), period(GetLeaderboardQuery{}))
+	if err != nil {
+		t.Fatalf("leaderboard: %v", err)
+	}
+	if board.HiddenMembers != 1 || len(board.Entries) != 3 {
+		t.Fatalf("unexpected leaderboard: %+v", board)
+	}
+	for i, want := range []struct {
+		rank   int
+		member string
+		count  int
+	}{{1, "alice", 5}, {1, "bob", 5}, {3, "erin", 0}} {
+		got := board.Entries[i]
+		if got.Rank != want.rank || got.Member != want.member || got.Count != want.count {
+			t.Fatalf("entry %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if cats := board.Entries[0].Categories; len(cats) != 2 || cats[0] != (CategoryCount{CategoryID: "km", Count: 3}) {
+		t.Fatalf("unexpected breakdown: %+v", cats)
+	}
+
+	compare := CompareMembersHandler{Teams: teams, DoneLogs: teams}
+	cmp, err := compare.Handle(as("alice"), CompareMembersQuery{TeamID: "club", Member: "bob", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if cmp.You.Rank != 1 || cmp.Other.Rank != 1 || cmp.Difference != 0 || len(cmp.Categories) != 2 ||
+		cmp.Categories[0] != (CategoryComparisonRow{CategoryID: "hill", You: 2, Other: 0, Difference: 2}) {
+		t.Fatalf("unexpected comparison: %+v", cmp)
+	}
+	// carol opted out, but may still compare herself with others.
+	if cmp, err := compare.Handle(as("carol"), CompareMembersQuery{TeamID: "club", Member: "bob", StartDate: "2024-05-01", EndDate: "2024-05-31"}); err != nil || cmp.You.Rank != 0 || cmp.Difference != 4 {
+		t.Fatalf("opted-out caller comparison = %+v, %v", cmp, err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		ctx     context.Context
+		q       CompareMembersQuery
+		wantErr error
+	}{
+		{name: "opted-out member", ctx: as("alice"), q: CompareMembersQuery{Member: "carol"}, wantErr: apperr.ErrForbidden},
+		{name: "stranger", ctx: as("alice"), q: CompareMembersQuery{Member: "zed"}, wantErr: apperr.ErrNotFound},
+		{name: "non-member caller", ctx: as("zed"), q: CompareMembersQuery{Member: "alice"}, wantErr: apperr.ErrNotFound},
+		{name: "missing member", ctx: as("alice"), q: CompareMembersQuery{}, wantErr: apperr.ErrInvalid},
+	} {
+		tt.q.TeamID, tt.q.StartDate, tt.q.EndDate = "club", "2024-05-01", "2024-05-31"
+		if _, err := compare.Handle(tt.ctx, tt.q); !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTagFilters(t *testing.T) {
+	logs := sampleLogs()
+	logs[0].Tags = []string{"go", "performance"}
+	logs[1].Tags = []string{"go"}
+	reader := stubDoneLogReader{logs: logs}
+	ctx := context.Background()
+
+	daily, err := SummarizeByDayHandler{DoneLogs: reader}.Handle(ctx, SummarizeByDayQuery{Tag: "performance", StartDate: "2024-05-01", EndDate: "2024-05-03"})
+	if err != nil || daily.Tag != "performance" || daily.TotalCount != 10 {
+		t.Fatalf("tag-filtered daily summary = %+v, %v", daily, err)
+	}
+	page, err := ListDoneLogsHandler{DoneLogs: reader, Tracks: stubCatalog{}, Categories: stubCatalog{}}.Handle(ctx, ListDoneLogsQuery{Tag: "go", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil || page.TotalCount != 2 || strings.Join(page.Items[1].Tags, ",") != "go,performance" {
+		t.Fatalf("tag-filtered page = %+v, %v", page, err)
+	}
+
+	byTag, err := SummarizeByTagHandler{DoneLogs: reader}.Handle(ctx, SummarizeByTagQuery{StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("tag summary: %v", err)
+	}
+	want := []TagTotal{{Tag: "go", Count: 15, DoneLogs: 2}, {Tag: "performance", Count: 10, DoneLogs: 1}}
+	if len(byTag.Tags) != len(want) || byTag.Tags[0] != want[0] || byTag.Tags[1] != want[1] || byTag.Untagged != (TagTotal{Count: 7, DoneLogs: 1}) {
+		t.Fatalf("unexpected tag summary: %+v", byTag)
+	}
+
+	for _, err := range []error{
+		func() error {
+			_, err := SummarizeByWeekHandler{DoneLogs: reader}.Handle(ctx, SummarizeByWeekQuery{Tag: "Go!", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+			return err
+		}(),
+		func() error {
+			_, err := SummarizeByTagHandler{DoneLogs: reader}.Handle(ctx, SummarizeByTagQuery{StartDate: "2024-05-01"})
+			return err
+		}(),
+	} {
+		if !errors.Is(err, apperr.ErrInvalid) {
+			t.Fatalf("expected invalid, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package query
+
+import "context"
+
+// GetSettingsQuery asks for the caller's settings, with defaults filled in.
+type GetSettingsQuery struct{}
+
+// SettingsItem is the read model of the caller's settings.
+type SettingsItem struct {
+	WeekStart string `json:"weekStart"`
+}
+
+// GetSettingsHandler handles GetSettingsQuery.
+type GetSettingsHandler struct {
+	// Settings is optional; without it every setting reports its default.
+	Settings SettingsReader
+}
+
+func (h GetSettingsHandler) Handle(ctx context.Context, q GetSettingsQuery) (SettingsItem, error) {
+	weekStart, err := resolveWeekStart(ctx, h.Settings, "")
+	if err != nil {
+		return SettingsItem{}, err
+	}
+	return SettingsItem{WeekStart: string(weekStart)}, nil
+}
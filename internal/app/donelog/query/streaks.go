@@ -0,0 +1,121 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetStreaksQuery asks for current and longest streaks per Track.
+// An empty TrackID means every active Track; an empty Unit means "day".
+type GetStreaksQuery struct {
+	TrackID string
+	Unit    string
+	Freezes int
+}
+
+// TrackStreak is the read model for the streaks of one Track.
+type TrackStreak struct {
+	TrackID   string    `json:"trackId"`
+	TrackName string    `json:"trackName"`
+	Unit      string    `json:"unit"`
+	Freezes   int       `json:"freezes"`
+	State     string    `json:"state"`
+	Current   StreakDTO `json:"current"`
+	Longest   StreakDTO `json:"longest"`
+}
+
+// StreakDTO is one streak. Dates are empty when Length is zero.
+type StreakDTO struct {
+	Length      int    `json:"length"`
+	StartDate   string `json:"startDate,omitempty"`
+	EndDate     string `json:"endDate,omitempty"`
+	FreezesUsed int    `json:"freezesUsed"`
+}
+
+// GetStreaksHandler handles GetStreaksQuery.
+type GetStreaksHandler struct {
+	DoneLogs DoneLogReader
+	Tracks   TrackReader
+	Clock    Clock
+}
+
+// Handle returns one TrackStreak per Track in the TrackReader's order.
+func (h GetStreaksHandler) Handle(ctx context.Context, q GetStreaksQuery) ([]TrackStreak, error) {
+	unit := donelog.StreakUnitDay
+	if q.Unit != "" {
+		parsed, err := donelog.ParseStreakUnit(q.Unit)
+		if err != nil {
+			return nil, apperr.Invalid(err)
+		}
+		unit = parsed
+	}
+	rule, err := donelog.NewStreakRule(unit, q.Freezes)
+	if err != nil {
+		return nil, apperr.Invalid(err)
+	}
+	filter, err := parseFilter(q.TrackID, "")
+	if err != nil {
+		return nil, apperr.Invalid(err)
+	}
+
+	tracks, err := h.Tracks.ListTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var selected []donelog.RawTrack
+	for _, t := range tracks {
+		if (q.TrackID == "" && t.Active) || t.ID == q.TrackID {
+			selected = append(selected, t)
+		}
+	}
+	if q.TrackID != "" && len(selected) == 0 {
+		return nil, fmt.Errorf("track %s: %w", q.TrackID, apperr.ErrNotFound)
+	}
+
+	today := donelog.OccurredOnFromTime(h.Clock.Now())
+	// Streaks look at the whole history, so read from the earliest representable date.
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(time.Time{}), today)
+	if err != nil {
+		return nil, err
+	}
+	raws, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return nil, err
+	}
+	byTrack := map[string][]*donelog.DoneLog{}
+	for _, raw := range raws {
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return nil, err
+		}
+		byTrack[raw.TrackID] = append(byTrack[raw.TrackID], log)
+	}
+
+	result := make([]TrackStreak, 0, len(selected))
+	for _, t := range selected {
+		report := rule.Calculate(byTrack[t.ID], today)
+		result = append(result, TrackStreak{
+			TrackID:   t.ID,
+			TrackName: t.Name,
+			Unit:      string(unit),
+			Freezes:   q.Freezes,
+			State:     string(report.State()),
+			Current:   newStreakDTO(report.Current()),
+			Longest:   newStreakDTO(report.Longest()),
+		})
+	}
+	return result, nil
+}
+
+func newStreakDTO(s donelog.Streak) StreakDTO {
+	dto := StreakDTO{Length: s.Length(), FreezesUsed: s.FreezesUsed()}
+	if s.Length() > 0 {
+		dto.StartDate = s.First().Format("2006-01-02")
+		dto.EndDate = s.Last().Format("2006-01-02")
+	}
+	return dto
+}
@@ -0,0 +1,317 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// SummarizeByDayQuery asks for daily totals between two dates (YYYY-MM-DD, inclusive).
+// Like every Summarize query, CategoryID and Tag narrow the totals when set.
+type SummarizeByDayQuery struct {
+	CategoryID string
+	Tag        string
+	StartDate  string
+	EndDate    string
+}
+
+func (q SummarizeByDayQuery) Validate() error {
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// WeekStartSetting is the settings key holding the caller's default week start ("monday" or "sunday").
+const WeekStartSetting = donelog.WeekStartSetting
+
+// SummarizeByWeekQuery asks for weekly totals between two dates (YYYY-MM-DD, inclusive).
+// An empty WeekStart falls back to the week_start setting, then to Monday.
+type SummarizeByWeekQuery struct {
+	CategoryID string
+	Tag        string
+	StartDate  string
+	EndDate    string
+	WeekStart  string
+}
+
+func (q SummarizeByWeekQuery) Validate() error {
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// SummarizeByMonthQuery asks for monthly totals between two months (YYYY-MM, inclusive).
+type SummarizeByMonthQuery struct {
+	CategoryID string
+	Tag        string
+	StartMonth string
+	EndMonth   string
+}
+
+func (q SummarizeByMonthQuery) Validate() error {
+	if q.StartMonth == "" || q.EndMonth == "" {
+		return fmt.Errorf("startMonth and endMonth are required")
+	}
+	return nil
+}
+
+// SummarizeByQuarterQuery asks for quarterly totals between two quarters (YYYY-Qn, inclusive).
+type SummarizeByQuarterQuery struct {
+	CategoryID   string
+	Tag          string
+	StartQuarter string
+	EndQuarter   string
+}
+
+func (q SummarizeByQuarterQuery) Validate() error {
+	if q.StartQuarter == "" || q.EndQuarter == "" {
+		return fmt.Errorf("startQuarter and endQuarter are required")
+	}
+	return nil
+}
+
+// SummarizeByYearQuery asks for yearly totals between two years (YYYY, inclusive).
+type SummarizeByYearQuery struct {
+	CategoryID string
+	Tag        string
+	StartYear  string
+	EndYear    string
+}
+
+func (q SummarizeByYearQuery) Validate() error {
+	if q.StartYear == "" || q.EndYear == "" {
+		return fmt.Errorf("startYear and endYear are required")
+	}
+	return nil
+}
+
+// Summary is the read model for a LogSummary.
+type Summary struct {
+	Period     PeriodDTO      `json:"period"`
+	CategoryID string         `json:"categoryId,omitempty"`
+	Tag        string         `json:"tag,omitempty"`
+	TotalCount int            `json:"totalCount"`
+	Points     []SummaryPoint `json:"points"`
+}
+
+// PeriodDTO is the inclusive date range of a Summary.
+type PeriodDTO struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+// SummaryPoint is one bucket of a Summary.
+type SummaryPoint struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// SummarizeByDayHandler handles SummarizeByDayQuery.
+type SummarizeByDayHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+func (h SummarizeByDayHandler) Handle(ctx context.Context, q SummarizeByDayQuery) (Summary, error) {
+	if err := q.Validate(); err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	period, err := parsePeriod(q.StartDate, q.EndDate)
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	return summarize(ctx, h.DoneLogs, q.CategoryID, q.Tag, period, h.Service.SummarizeByDay)
+}
+
+// SummarizeByWeekHandler handles SummarizeByWeekQuery.
+type SummarizeByWeekHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+	// Settings is optional; without it the default week start is Monday.
+	Settings SettingsReader
+}
+
+func (h SummarizeByWeekHandler) Handle(ctx context.Context, q SummarizeByWeekQuery) (Summary, error) {
+	if err := q.Validate(); err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	period, err := parsePeriod(q.StartDate, q.EndDate)
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	weekStart, err := resolveWeekStart(ctx, h.Settings, q.WeekStart)
+	if err != nil {
+		return Summary{}, err
+	}
+	return summarize(ctx, h.DoneLogs, q.CategoryID, q.Tag, period, func(categoryID *donelog.CategoryID, period donelog.Period, logs []*donelog.DoneLog) (donelog.LogSummary, error) {
+		return h.Service.SummarizeByWeek(categoryID, period, logs, weekStart)
+	})
+}
+
+// resolveWeekStart resolves the requested week start, falling back to the stored setting.
+// settings may be nil.
+func resolveWeekStart(ctx context.Context, settings SettingsReader, requested string) (donelog.WeekStart, error) {
+	if requested != "" {
+		weekStart, err := donelog.ParseWeekStart(requested)
+		if err != nil {
+			return "", apperr.Invalid(err)
+		}
+		return weekStart, nil
+	}
+	if settings != nil {
+		value, ok, err := settings.Get(ctx, WeekStartSetting)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			weekStart, err := donelog.ParseWeekStart(value)
+			if err != nil {
+				return "", fmt.Errorf("setting %s: %w", WeekStartSetting, err)
+			}
+			return weekStart, nil
+		}
+	}
+	return donelog.WeekStartMonday, nil
+}
+
+// SummarizeByMonthHandler handles SummarizeByMonthQuery.
+type SummarizeByMonthHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+func (h SummarizeByMonthHandler) Handle(ctx context.Context, q SummarizeByMonthQuery) (Summary, error) {
+	if err := q.Validate(); err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	start, err := time.Parse("2006-01", q.StartMonth)
+	if err != nil {
+		return Summary{}, apperr.Invalid(fmt.Errorf("startMonth: %w", err))
+	}
+	end, err := time.Parse("2006-01", q.EndMonth)
+	if err != nil {
+		return Summary{}, apperr.Invalid(fmt.Errorf("endMonth: %w", err))
+	}
+	// The period runs to the last day of EndMonth.
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(start), donelog.OccurredOnFromTime(end.AddDate(0, 1, -1)))
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	return summarize(ctx, h.DoneLogs, q.CategoryID, q.Tag, period, h.Service.SummarizeByMonth)
+}
+
+// SummarizeByQuarterHandler handles SummarizeByQuarterQuery.
+type SummarizeByQuarterHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+func (h SummarizeByQuarterHandler) Handle(ctx context.Context, q SummarizeByQuarterQuery) (Summary, error) {
+	if err := q.Validate(); err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	start, err := parseQuarter(q.StartQuarter)
+	if err != nil {
+		return Summary{}, apperr.Invalid(fmt.Errorf("startQuarter: %w", err))
+	}
+	end, err := parseQuarter(q.EndQuarter)
+	if err != nil {
+		return Summary{}, apperr.Invalid(fmt.Errorf("endQuarter: %w", err))
+	}
+	// The period runs to the last day of EndQuarter.
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(start), donelog.OccurredOnFromTime(end.AddDate(0, 3, -1)))
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	return summarize(ctx, h.DoneLogs, q.CategoryID, q.Tag, period, h.Service.SummarizeByQuarter)
+}
+
+// parseQuarter returns the first day of a YYYY-Qn quarter.
+func parseQuarter(value string) (time.Time, error) {
+	var year, quarter int
+	if n, err := fmt.Sscanf(value, "%4d-Q%1d", &year, &quarter); err != nil || n != 2 || len(value) != 7 || quarter < 1 || quarter > 4 {
+		return time.Time{}, fmt.Errorf("invalid quarter %q (want YYYY-Qn)", value)
+	}
+	return time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// SummarizeByYearHandler handles SummarizeByYearQuery.
+type SummarizeByYearHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+func (h SummarizeByYearHandler) Handle(ctx context.Context, q SummarizeByYearQuery) (Summary, error) {
+	if err := q.Validate(); err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	start, err := time.Parse("2006", q.StartYear)
+	if err != nil {
+		return Summary{}, apperr.Invalid(fmt.Errorf("startYear: %w", err))
+	}
+	end, err := time.Parse("2006", q.EndYear)
+	if err != nil {
+		return Summary{}, apperr.Invalid(fmt.Errorf("endYear: %w", err))
+	}
+	period, err := donelog.NewPeriod(donelog.OccurredOnFromTime(start), donelog.OccurredOnFromTime(end.AddDate(1, 0, -1)))
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	return summarize(ctx, h.DoneLogs, q.CategoryID, q.Tag, period, h.Service.SummarizeByYear)
+}
+
+type summarizeFunc func(categoryID *donelog.CategoryID, period donelog.Period, logs []*donelog.DoneLog) (donelog.LogSummary, error)
+
+func summarize(ctx context.Context, reader DoneLogReader, categoryID, tag string, period donelog.Period, fn summarizeFunc) (Summary, error) {
+	filter, err := parseFilter("", categoryID)
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	if filter, err = withTag(filter, tag); err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+
+	raws, err := reader.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return Summary{}, err
+	}
+	logs := make([]*donelog.DoneLog, 0, len(raws))
+	for _, raw := range raws {
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return Summary{}, err
+		}
+		logs = append(logs, log)
+	}
+
+	summary, err := fn(filter.CategoryID, period, logs)
+	if err != nil {
+		return Summary{}, apperr.Invalid(err)
+	}
+	dto := newSummary(summary)
+	dto.Tag = tag
+	return dto, nil
+}
+
+func newSummary(s donelog.LogSummary) Summary {
+	dto := Summary{
+		Period: PeriodDTO{
+			StartDate: s.Period().Start().Format("2006-01-02"),
+			EndDate:   s.Period().End().Format("2006-01-02"),
+		},
+		TotalCount: s.TotalCount().Int(),
+		Points:     []SummaryPoint{},
+	}
+	if id := s.CategoryID(); id != nil {
+		dto.CategoryID = id.String()
+	}
+	for _, p := range s.Points() {
+		dto.Points = append(dto.Points, SummaryPoint{Label: p.Label(), Count: p.Count().Int()})
+	}
+	return dto
+}
@@ -0,0 +1,84 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// SummarizeByTagQuery asks for totals per Tag between two dates (YYYY-MM-DD, inclusive).
+// TrackID and CategoryID narrow it.
+type SummarizeByTagQuery struct {
+	TrackID    string
+	CategoryID string
+	StartDate  string
+	EndDate    string
+}
+
+func (q SummarizeByTagQuery) Validate() error {
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// TagSummary is the read model of totals per Tag. A DONELOG with several Tags counts towards each.
+type TagSummary struct {
+	Period   PeriodDTO  `json:"period"`
+	Tags     []TagTotal `json:"tags"`
+	Untagged TagTotal   `json:"untagged"`
+}
+
+// TagTotal is the total of one Tag; Tag is empty for the untagged total.
+type TagTotal struct {
+	Tag      string `json:"tag,omitempty"`
+	Count    int    `json:"count"`
+	DoneLogs int    `json:"doneLogs"`
+}
+
+// SummarizeByTagHandler handles SummarizeByTagQuery.
+type SummarizeByTagHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+}
+
+// Handle returns the Tags largest first, then by name.
+func (h SummarizeByTagHandler) Handle(ctx context.Context, q SummarizeByTagQuery) (TagSummary, error) {
+	if err := q.Validate(); err != nil {
+		return TagSummary{}, apperr.Invalid(err)
+	}
+	period, err := parsePeriod(q.StartDate, q.EndDate)
+	if err != nil {
+		return TagSummary{}, apperr.Invalid(err)
+	}
+	filter, err := parseFilter(q.TrackID, q.CategoryID)
+	if err != nil {
+		return TagSummary{}, apperr.Invalid(err)
+	}
+	raws, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return TagSummary{}, err
+	}
+	logs := make([]*donelog.DoneLog, 0, len(raws))
+	for _, raw := range raws {
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return TagSummary{}, err
+		}
+		logs = append(logs, log)
+	}
+
+	summary := h.Service.SummarizeByTag(period, logs)
+	untagged := summary.Untagged()
+	result := TagSummary{
+		Period:   PeriodDTO{StartDate: q.StartDate, EndDate: q.EndDate},
+		Tags:     []TagTotal{},
+		Untagged: TagTotal{Count: untagged.Count().Int(), DoneLogs: untagged.DoneLogs()},
+	}
+	for _, total := range summary.Tags() {
+		result.Tags = append(result.Tags, TagTotal{Tag: total.Tag().String(), Count: total.Count().Int(), DoneLogs: total.DoneLogs()})
+	}
+	return result, nil
+}
@@ -0,0 +1,224 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// TeamItem is the read model for a Team the caller belongs to.
+type TeamItem struct {
+	ID      string           `json:"id"`
+	Name    string           `json:"name"`
+	Role    string           `json:"role"`
+	Members []TeamMemberItem `json:"members"`
+	// Invites lists the users invited to the Team who have not accepted yet.
+	Invites []TeamMemberItem `json:"invites,omitempty"`
+}
+
+// TeamMemberItem is a member and their role.
+type TeamMemberItem struct {
+	Member string `json:"member"`
+	Role   string `json:"role"`
+	// OptedOut is set for members hidden from leaderboards.
+	OptedOut bool `json:"optedOut,omitempty"`
+}
+
+// ListTeamsHandler lists the caller's Teams.
+type ListTeamsHandler struct {
+	Teams TeamReader
+}
+
+// Handle returns the Teams ordered by ID, each with members ordered by name.
+func (h ListTeamsHandler) Handle(ctx context.Context) ([]TeamItem, error) {
+	owner := appctx.Owner(ctx)
+	raws, err := h.Teams.ListByMember(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]TeamItem, 0, len(raws))
+	for _, raw := range raws {
+		team, err := donelog.RehydrateTeam(raw)
+		if err != nil {
+			return nil, err
+		}
+		role, _ := team.RoleOf(owner)
+		items = append(items, TeamItem{ID: raw.ID, Name: team.Name(), Role: string(role), Members: memberItems(team), Invites: inviteItems(team)})
+	}
+	return items, nil
+}
+
+// TeamInviteItem is a pending invite to the caller.
+type TeamInviteItem struct {
+	TeamID string `json:"teamId"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+}
+
+// ListTeamInvitesHandler lists the invites the caller has not accepted yet.
+type ListTeamInvitesHandler struct {
+	Teams TeamReader
+}
+
+// Handle returns the invites ordered by team ID.
+func (h ListTeamInvitesHandler) Handle(ctx context.Context) ([]TeamInviteItem, error) {
+	invitee := appctx.Owner(ctx)
+	raws, err := h.Teams.ListByInvitee(ctx, invitee)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]TeamInviteItem, 0, len(raws))
+	for _, raw := range raws {
+		team, err := donelog.RehydrateTeam(raw)
+		if err != nil {
+			return nil, err
+		}
+		role, _ := team.InvitedAs(invitee)
+		items = append(items, TeamInviteItem{TeamID: raw.ID, Name: team.Name(), Role: string(role)})
+	}
+	return items, nil
+}
+
+// GetTeamSummaryQuery asks for a Team's totals per member between two dates (YYYY-MM-DD, inclusive).
+// TrackID narrows it to one team Track.
+type GetTeamSummaryQuery struct {
+	TeamID    string
+	TrackID   string
+	StartDate string
+	EndDate   string
+}
+
+func (q GetTeamSummaryQuery) Validate() error {
+	if q.TeamID == "" {
+		return fmt.Errorf("teamId is required")
+	}
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// TeamSummary is the read model of a Team's progress, broken down per member.
+type TeamSummary struct {
+	TeamID     string        `json:"teamId"`
+	TrackID    string        `json:"trackId,omitempty"`
+	Period     PeriodDTO     `json:"period"`
+	TotalCount int           `json:"totalCount"`
+	Members    []MemberTotal `json:"members"`
+	// HiddenMembers counts the members who opted out of leaderboards; TotalCount still includes them.
+	HiddenMembers int `json:"hiddenMembers"`
+}
+
+// MemberTotal is one member's share of a TeamSummary.
+type MemberTotal struct {
+	Member   string `json:"member"`
+	Role     string `json:"role"`
+	Count    int    `json:"count"`
+	DoneLogs int    `json:"doneLogs"`
+}
+
+// GetTeamSummaryHandler handles GetTeamSummaryQuery. Any member may read it.
+type GetTeamSummaryHandler struct {
+	Teams    TeamReader
+	DoneLogs TeamDoneLogReader
+}
+
+// Handle lists every current member ordered by name, including those with nothing recorded.
+// Members who opted out of leaderboards are left off unless they are the caller.
+func (h GetTeamSummaryHandler) Handle(ctx context.Context, q GetTeamSummaryQuery) (TeamSummary, error) {
+	if err := q.Validate(); err != nil {
+		return TeamSummary{}, apperr.Invalid(err)
+	}
+	period, err := parsePeriod(q.StartDate, q.EndDate)
+	if err != nil {
+		return TeamSummary{}, apperr.Invalid(err)
+	}
+	filter, err := parseFilter(q.TrackID, "")
+	if err != nil {
+		return TeamSummary{}, apperr.Invalid(err)
+	}
+	team, err := findTeam(ctx, h.Teams, q.TeamID)
+	if err != nil {
+		return TeamSummary{}, err
+	}
+	raws, err := h.DoneLogs.ListTeamDoneLogs(ctx, team.ID(), period, filter)
+	if err != nil {
+		return TeamSummary{}, err
+	}
+
+	summary := TeamSummary{
+		TeamID:  q.TeamID,
+		TrackID: q.TrackID,
+		Period:  PeriodDTO{StartDate: q.StartDate, EndDate: q.EndDate},
+	}
+	caller := appctx.Owner(ctx)
+	index := map[string]int{}
+	hidden := map[string]bool{}
+	for _, m := range team.Members() {
+		if m.Member != caller && team.OptedOut(m.Member) {
+			hidden[m.Member.String()] = true
+			summary.HiddenMembers++
+			continue
+		}
+		index[m.Member.String()] = len(summary.Members)
+		summary.Members = append(summary.Members, MemberTotal{Member: m.Member.String(), Role: string(m.Role)})
+	}
+	for _, raw := range raws {
+		if hidden[raw.Owner] {
+			summary.TotalCount += raw.Count
+			continue
+		}
+		i, ok := index[raw.Owner]
+		if !ok {
+			continue
+		}
+		summary.Members[i].Count += raw.Count
+		summary.Members[i].DoneLogs++
+		summary.TotalCount += raw.Count
+	}
+	return summary, nil
+}
+
+// findTeam loads a Team the caller belongs to. Other Teams are reported as not found.
+func findTeam(ctx context.Context, teams TeamReader, value string) (*donelog.Team, error) {
+	id, err := donelog.NewTeamID(value)
+	if err != nil {
+		return nil, apperr.Invalid(err)
+	}
+	raw, err := teams.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	notFound := fmt.Errorf("team %s: %w", value, apperr.ErrNotFound)
+	if raw == nil {
+		return nil, notFound
+	}
+	team, err := donelog.RehydrateTeam(*raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := team.RoleOf(appctx.Owner(ctx)); !ok {
+		return nil, notFound
+	}
+	return team, nil
+}
+
+func inviteItems(team *donelog.Team) []TeamMemberItem {
+	var items []TeamMemberItem
+	for _, invite := range team.Invites() {
+		items = append(items, TeamMemberItem{Member: invite.Member.String(), Role: string(invite.Role)})
+	}
+	return items
+}
+
+func memberItems(team *donelog.Team) []TeamMemberItem {
+	members := team.Members()
+	items := make([]TeamMemberItem, 0, len(members))
+	for _, m := range members {
+		items = append(items, TeamMemberItem{Member: m.Member.String(), Role: string(m.Role), OptedOut: team.OptedOut(m.Member)})
+	}
+	return items
+}
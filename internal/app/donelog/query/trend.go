@@ -0,0 +1,137 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GetTrendQuery asks for the daily series between two dates (YYYY-MM-DD, inclusive) with trend statistics.
+// TrackID and CategoryID are optional filters. An empty WeekStart falls back to the week_start setting, then to Monday.
+type GetTrendQuery struct {
+	TrackID    string
+	CategoryID string
+	StartDate  string
+	EndDate    string
+	WeekStart  string
+}
+
+func (q GetTrendQuery) Validate() error {
+	if q.StartDate == "" || q.EndDate == "" {
+		return fmt.Errorf("startDate and endDate are required")
+	}
+	return nil
+}
+
+// Trend is the read model for a daily series and the statistics derived from it.
+type Trend struct {
+	Period           PeriodDTO    `json:"period"`
+	TrackID          string       `json:"trackId,omitempty"`
+	CategoryID       string       `json:"categoryId,omitempty"`
+	WeekStart        string       `json:"weekStart"`
+	TotalCount       int          `json:"totalCount"`
+	Slope            float64      `json:"slope"`
+	MedianDailyCount float64      `json:"medianDailyCount"`
+	BestDay          BucketTotal  `json:"bestDay"`
+	WorstDay         BucketTotal  `json:"worstDay"`
+	BestWeek         *BucketTotal `json:"bestWeek"`
+	WorstWeek        *BucketTotal `json:"worstWeek"`
+	Points           []TrendPoint `json:"points"`
+}
+
+// TrendPoint is one day of a Trend. Moving averages are null until their window is full.
+type TrendPoint struct {
+	Label           string   `json:"label"`
+	Count           int      `json:"count"`
+	MovingAverage7  *float64 `json:"movingAverage7"`
+	MovingAverage30 *float64 `json:"movingAverage30"`
+	Cumulative      int      `json:"cumulative"`
+}
+
+// BucketTotal is the total of one day (2024-05-01) or week (2024-W18).
+type BucketTotal struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// GetTrendHandler handles GetTrendQuery.
+type GetTrendHandler struct {
+	DoneLogs DoneLogReader
+	Service  donelog.LogSummaryService
+	// Settings is optional; without it the default week start is Monday.
+	Settings SettingsReader
+}
+
+func (h GetTrendHandler) Handle(ctx context.Context, q GetTrendQuery) (Trend, error) {
+	if err := q.Validate(); err != nil {
+		return Trend{}, apperr.Invalid(err)
+	}
+	period, err := parsePeriod(q.StartDate, q.EndDate)
+	if err != nil {
+		return Trend{}, apperr.Invalid(err)
+	}
+	filter, err := parseFilter(q.TrackID, q.CategoryID)
+	if err != nil {
+		return Trend{}, apperr.Invalid(err)
+	}
+	weekStart, err := resolveWeekStart(ctx, h.Settings, q.WeekStart)
+	if err != nil {
+		return Trend{}, err
+	}
+
+	raws, err := h.DoneLogs.ListByPeriod(ctx, period, filter)
+	if err != nil {
+		return Trend{}, err
+	}
+	logs := make([]*donelog.DoneLog, 0, len(raws))
+	for _, raw := range raws {
+		log, err := donelog.RehydrateDoneLog(raw)
+		if err != nil {
+			return Trend{}, err
+		}
+		logs = append(logs, log)
+	}
+
+	trend, err := h.Service.AnalyzeTrend(filter.CategoryID, period, logs, weekStart)
+	if err != nil {
+		return Trend{}, apperr.Invalid(err)
+	}
+	summary := newSummary(trend.Summary())
+	result := Trend{
+		Period:           summary.Period,
+		TrackID:          q.TrackID,
+		CategoryID:       summary.CategoryID,
+		WeekStart:        string(weekStart),
+		TotalCount:       summary.TotalCount,
+		Slope:            trend.Slope(),
+		MedianDailyCount: trend.Median(),
+		BestDay:          newBucketTotal(trend.BestDay()),
+		WorstDay:         newBucketTotal(trend.WorstDay()),
+		Points:           []TrendPoint{},
+	}
+	if week := trend.BestWeek(); week != nil {
+		best := newBucketTotal(*week)
+		result.BestWeek = &best
+	}
+	if week := trend.WorstWeek(); week != nil {
+		worst := newBucketTotal(*week)
+		result.WorstWeek = &worst
+	}
+	for _, p := range trend.Points() {
+		point := TrendPoint{Label: p.Label(), Count: p.Count().Int(), Cumulative: p.Cumulative()}
+		if avg, ok := p.MovingAverage7(); ok {
+			point.MovingAverage7 = &avg
+		}
+		if avg, ok := p.MovingAverage30(); ok {
+			point.MovingAverage30 = &avg
+		}
+		result.Points = append(result.Points, point)
+	}
+	return result, nil
+}
+
+func newBucketTotal(b donelog.BucketTotal) BucketTotal {
+	return BucketTotal{Label: b.Label(), Count: b.Count()}
+}
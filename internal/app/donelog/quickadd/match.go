@@ -0,0 +1,176 @@
+package quickadd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxTypoDistance is how many edits a slug may be off and still match.
+const maxTypoDistance = 2
+
+// candidate is a Track or Category that a @/# reference may resolve to.
+type candidate struct {
+	id   string
+	name string
+}
+
+// AmbiguousError reports a reference that matched several Tracks or Categories equally well.
+type AmbiguousError struct {
+	Kind       string
+	Query      string
+	Candidates []string
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("%s %q is ambiguous: %s", e.Kind, e.Query, strings.Join(e.Candidates, ", "))
+}
+
+// UnknownError reports a reference that matched nothing.
+type UnknownError struct {
+	Kind  string
+	Query string
+	Known []string
+}
+
+func (e *UnknownError) Error() string {
+	if len(e.Known) == 0 {
+		return fmt.Sprintf("no %s matches %q (there are no active %ss)", e.Kind, e.Query, e.Kind)
+	}
+	return fmt.Sprintf("no %s matches %q (known: %s)", e.Kind, e.Query, strings.Join(e.Known, ", "))
+}
+
+// resolve finds the candidate meant by q. Matching runs in tiers from strict to loose and stops
+// at the first tier with any hit: exact ID, exact name, prefix, substring, then small typos.
+// Several hits within one tier are ambiguous.
+func resolve(kind, q string, candidates []candidate) (string, error) {
+	return match(kind, q, candidates, true)
+}
+
+// resolveExact is resolve limited to the exact ID and exact name tiers, for values that must not be guessed.
+func resolveExact(kind, q string, candidates []candidate) (string, error) {
+	return match(kind, q, candidates, false)
+}
+
+func match(kind, q string, candidates []candidate, fuzzy bool) (string, error) {
+	norm := normalize(q)
+	tiers := []func(c candidate) bool{
+		func(c candidate) bool { return strings.EqualFold(c.id, q) },
+		func(c candidate) bool { return strings.EqualFold(c.name, q) },
+	}
+	if fuzzy {
+		tiers = append(tiers,
+			func(c candidate) bool { return hasWordPrefix(c, norm) },
+			func(c candidate) bool {
+				return strings.Contains(normalize(c.id), norm) || strings.Contains(normalize(c.name), norm)
+			},
+		)
+	}
+	for _, match := range tiers {
+		if norm == "" {
+			break
+		}
+		var hits []string
+		for _, c := range candidates {
+			if match(c) {
+				hits = append(hits, c.id)
+			}
+		}
+		if len(hits) == 1 {
+			return hits[0], nil
+		}
+		if len(hits) > 1 {
+			sort.Strings(hits)
+			return "", &AmbiguousError{Kind: kind, Query: q, Candidates: hits}
+		}
+	}
+
+	if fuzzy {
+		if hits := closest(norm, candidates); len(hits) == 1 {
+			return hits[0], nil
+		} else if len(hits) > 1 {
+			return "", &AmbiguousError{Kind: kind, Query: q, Candidates: hits}
+		}
+	}
+
+	known := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		known = append(known, c.id)
+	}
+	sort.Strings(known)
+	return "", &UnknownError{Kind: kind, Query: q, Known: known}
+}
+
+// hasWordPrefix reports whether q starts the ID, any "_"/"-" separated part of it, or any word of the name.
+// So "clean" finds track_clean_arch and "arch" finds "Clean Architecture".
+func hasWordPrefix(c candidate, q string) bool {
+	for _, word := range words(c.id + " " + c.name) {
+		if strings.HasPrefix(normalize(word), q) {
+			return true
+		}
+	}
+	return strings.HasPrefix(normalize(c.id), q)
+}
+
+// closest returns the candidates within maxTypoDistance of q, keeping only the nearest ones.
+func closest(q string, candidates []candidate) []string {
+	if len([]rune(q)) < 4 {
+		// Short queries are too easy to mistype into something else.
+		return nil
+	}
+	best := maxTypoDistance + 1
+	var hits []string
+	for _, c := range candidates {
+		d := min(levenshtein(q, normalize(c.id)), levenshtein(q, normalize(c.name)))
+		for _, word := range words(c.id) {
+			d = min(d, levenshtein(q, normalize(word)))
+		}
+		switch {
+		case d < best:
+			best, hits = d, []string{c.id}
+		case d == best:
+			hits = append(hits, c.id)
+		}
+	}
+	sort.Strings(hits)
+	return hits
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || unicode.IsSpace(r)
+	})
+}
+
+// normalize lower-cases and drops everything but letters and digits.
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,265 @@
+// Package quickadd turns free-form input such as
+//
+//	"Clean Architecture ch.5" 12 @track_clean_arch #cat_reading yesterday
+//
+// into a CreateDoneLogCommand.
+package quickadd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	countPattern    = regexp.MustCompile(`^[0-9]+$`)
+	relativePattern = regexp.MustCompile(`^-([0-9]+)([dw])$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Parser resolves references against the active Tracks and Categories.
+type Parser struct {
+	Tracks     []query.TrackItem
+	Categories []query.CategoryItem
+	// Today anchors relative dates.
+	Today time.Time
+	// Flags holds values given as options instead of tokens.
+	Flags Flags
+}
+
+// Flags are Parse inputs that are taken literally rather than guessed: Track and Category must
+// equal an ID or name, Count must be a positive number and Date must be one whole date expression.
+// Giving a value both as a flag and as a token is an error.
+type Flags struct {
+	Track    string
+	Category string
+	Count    string
+	Date     string
+}
+
+// Parse reads tokens (typically shell arguments) with these rules:
+//   - @x is the Track and #x the Category, matched fuzzily against IDs and names;
+//   - a bare positive integer is the Count (default 1); a second one is an error;
+//   - today, yesterday, -3d, -2w, friday, last friday or YYYY-MM-DD is the date (default today);
+//   - everything else, and any token containing spaces, forms the Title.
+//
+// Values in Flags are checked strictly on top of the tokens.
+//
+// Without #category the Track's default Category is used. Errors wrap apperr.ErrInvalid.
+func (p Parser) Parse(tokens []string) (command.CreateDoneLogCommand, error) {
+	var (
+		cmd                   command.CreateDoneLogCommand
+		title                 []string
+		trackRef, categoryRef string
+		countSet, dateSet     bool
+	)
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimSpace(tokens[i])
+		switch {
+		case token == "":
+			continue
+		case strings.ContainsAny(token, " \t"):
+			title = append(title, token)
+		case strings.HasPrefix(token, "@") && len(token) > 1:
+			if trackRef != "" {
+				return cmd, invalid("track given twice (@%s and %s)", trackRef, token)
+			}
+			trackRef = token[1:]
+		case strings.HasPrefix(token, "#") && len(token) > 1:
+			if categoryRef != "" {
+				return cmd, invalid("category given twice (#%s and %s)", categoryRef, token)
+			}
+			categoryRef = token[1:]
+		case countPattern.MatchString(token):
+			if countSet {
+				return cmd, invalid("count given twice (%d and %s); quote the title if it contains numbers", cmd.Count, token)
+			}
+			n, err := strconv.Atoi(token)
+			if err != nil || n <= 0 {
+				return cmd, invalid("count must be a positive number, got %s", token)
+			}
+			cmd.Count, countSet = n, true
+		default:
+			date, used, ok := p.date(tokens[i:])
+			if !ok {
+				title = append(title, token)
+				continue
+			}
+			if dateSet {
+				return cmd, invalid("date given twice (%s and %s)", cmd.OccurredOn, strings.Join(tokens[i:i+used], " "))
+			}
+			cmd.OccurredOn, dateSet = date.Format(dateLayout), true
+			i += used - 1
+		}
+	}
+
+	cmd.Title = strings.Join(title, " ")
+	if cmd.Title == "" {
+		return cmd, invalid("title is required")
+	}
+	if p.Flags.Count != "" {
+		if countSet {
+			return cmd, invalid("count given twice (%d and --count %s)", cmd.Count, p.Flags.Count)
+		}
+		n, err := strconv.Atoi(p.Flags.Count)
+		if err != nil || n <= 0 {
+			return cmd, invalid("--count must be a positive number, got %q", p.Flags.Count)
+		}
+		cmd.Count, countSet = n, true
+	}
+	if !countSet {
+		cmd.Count = 1
+	}
+	if p.Flags.Date != "" {
+		if dateSet {
+			return cmd, invalid("date given twice (%s and --date %s)", cmd.OccurredOn, p.Flags.Date)
+		}
+		date, ok := p.wholeDate(p.Flags.Date)
+		if !ok {
+			return cmd, invalid("--date %q is not a date (use YYYY-MM-DD, today, yesterday, -3d, -2w or a weekday)", p.Flags.Date)
+		}
+		cmd.OccurredOn, dateSet = date.Format(dateLayout), true
+	}
+	if !dateSet {
+		cmd.OccurredOn = p.Today.Format(dateLayout)
+	}
+
+	trackID, err := p.reference("track", trackRef, p.Flags.Track, p.trackCandidates())
+	if err != nil {
+		return cmd, err
+	}
+	if trackID == "" {
+		return cmd, invalid("track is required (add @track)")
+	}
+	cmd.TrackID = trackID
+
+	categoryID, err := p.reference("category", categoryRef, p.Flags.Category, p.categoryCandidates())
+	if err != nil {
+		return cmd, err
+	}
+	if categoryID == "" {
+		cmd.CategoryID = p.defaultCategory(trackID)
+		if cmd.CategoryID == "" {
+			return cmd, invalid("track %s has no default category (add #category)", trackID)
+		}
+		return cmd, nil
+	}
+	cmd.CategoryID = categoryID
+	return cmd, nil
+}
+
+// reference resolves a @/# token fuzzily or a flag exactly. It returns "" when neither was given.
+func (p Parser) reference(kind, token, flag string, candidates []candidate) (string, error) {
+	var (
+		id  string
+		err error
+	)
+	switch {
+	case token != "" && flag != "":
+		return "", invalid("%s given twice (%s and --%s %s)", kind, token, kind, flag)
+	case flag != "":
+		id, err = resolveExact(kind, flag, candidates)
+	case token != "":
+		id, err = resolve(kind, token, candidates)
+	}
+	if err != nil {
+		return "", apperr.Invalid(err)
+	}
+	return id, nil
+}
+
+// date reads a date expression at the head of tokens and reports how many tokens it used.
+func (p Parser) date(tokens []string) (time.Time, int, bool) {
+	today := time.Date(p.Today.Year(), p.Today.Month(), p.Today.Day(), 0, 0, 0, 0, p.Today.Location())
+	word := strings.ToLower(tokens[0])
+
+	switch word {
+	case "today":
+		return today, 1, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), 1, true
+	case "last":
+		if len(tokens) > 1 {
+			if wd, ok := weekdays[strings.ToLower(tokens[1])]; ok {
+				return previousWeekday(today.AddDate(0, 0, -1), wd), 2, true
+			}
+		}
+		return time.Time{}, 0, false
+	}
+	if wd, ok := weekdays[word]; ok {
+		return previousWeekday(today, wd), 1, true
+	}
+	if m := relativePattern.FindStringSubmatch(word); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		return today.AddDate(0, 0, -n), 1, true
+	}
+	if t, err := time.ParseInLocation(dateLayout, word, today.Location()); err == nil {
+		return t, 1, true
+	}
+	return time.Time{}, 0, false
+}
+
+// wholeDate reads expr as a single date expression with nothing left over.
+func (p Parser) wholeDate(expr string) (time.Time, bool) {
+	words := strings.Fields(expr)
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	date, used, ok := p.date(words)
+	return date, ok && used == len(words)
+}
+
+// previousWeekday returns the latest day on or before from that falls on wd.
+func previousWeekday(from time.Time, wd time.Weekday) time.Time {
+	back := (int(from.Weekday()) - int(wd) + 7) % 7
+	return from.AddDate(0, 0, -back)
+}
+
+func (p Parser) trackCandidates() []candidate {
+	out := make([]candidate, 0, len(p.Tracks))
+	for _, t := range p.Tracks {
+		out = append(out, candidate{id: t.ID, name: t.Name})
+	}
+	return out
+}
+
+func (p Parser) categoryCandidates() []candidate {
+	out := make([]candidate, 0, len(p.Categories))
+	for _, c := range p.Categories {
+		out = append(out, candidate{id: c.ID, name: c.Name})
+	}
+	return out
+}
+
+func (p Parser) defaultCategory(trackID string) string {
+	for _, t := range p.Tracks {
+		if t.ID == trackID {
+			return t.DefaultCategoryID
+		}
+	}
+	return ""
+}
+
+func invalid(format string, args ...any) error {
+	return apperr.Invalid(fmt.Errorf(format, args...))
+}
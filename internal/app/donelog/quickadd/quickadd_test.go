@@ -0,0 +1,154 @@
+package quickadd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func TestParse(t *testing.T) {
+	parser := Parser{
+		Tracks: []query.TrackItem{
+			{ID: "track_clean_arch", Name: "Clean Architecture", DefaultCategoryID: "cat_reading"},
+			{ID: "track_clean_code", Name: "Clean Code", DefaultCategoryID: "cat_reading"},
+			{ID: "track_atcoder", Name: "AtCoder"},
+		},
+		Categories: []query.CategoryItem{
+			{ID: "cat_reading", Name: "Reading"},
+			{ID: "cat_problems", Name: "Problems"},
+		},
+		// A Wednesday.
+		Today: time.Date(2024, 5, 8, 21, 30, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		tokens  []string
+		flags   Flags
+		want    command.CreateDoneLogCommand
+		wantErr string
+	}{
+		{
+			name:   "OK: the example from the request",
+			tokens: []string{"Clean Architecture ch.5", "12", "@track_clean_arch", "#cat_reading", "yesterday"},
+			want:   command.CreateDoneLogCommand{Title: "Clean Architecture ch.5", TrackID: "track_clean_arch", CategoryID: "cat_reading", Count: 12, OccurredOn: "2024-05-07"},
+		},
+		{
+			name:   "OK: defaults, unquoted title and the track's default category",
+			tokens: []string{"read", "ch.6", "@clean_arch"},
+			want:   command.CreateDoneLogCommand{Title: "read ch.6", TrackID: "track_clean_arch", CategoryID: "cat_reading", Count: 1, OccurredOn: "2024-05-08"},
+		},
+		{
+			name:   "OK: fuzzy by name prefix, typo in category, relative days",
+			tokens: []string{"abc", "3", "@atcod", "#problms", "-3d"},
+			want:   command.CreateDoneLogCommand{Title: "abc", TrackID: "track_atcoder", CategoryID: "cat_problems", Count: 3, OccurredOn: "2024-05-05"},
+		},
+		{
+			name:   "OK: last friday",
+			tokens: []string{"review", "@track_clean_code", "last", "friday"},
+			want:   command.CreateDoneLogCommand{Title: "review", TrackID: "track_clean_code", CategoryID: "cat_reading", Count: 1, OccurredOn: "2024-05-03"},
+		},
+		{
+			name:   "OK: weekday includes today, last alone stays in the title",
+			tokens: []string{"last", "chapter", "wed", "@clean_code"},
+			want:   command.CreateDoneLogCommand{Title: "last chapter", TrackID: "track_clean_code", CategoryID: "cat_reading", Count: 1, OccurredOn: "2024-05-08"},
+		},
+		{
+			name:   "OK: flags are exact IDs or names",
+			tokens: []string{"abc"},
+			flags:  Flags{Track: "AtCoder", Category: "cat_problems", Count: "4", Date: "last friday"},
+			want:   command.CreateDoneLogCommand{Title: "abc", TrackID: "track_atcoder", CategoryID: "cat_problems", Count: 4, OccurredOn: "2024-05-03"},
+		},
+		{
+			name:    "NG: --track is not matched fuzzily",
+			tokens:  []string{"abc"},
+			flags:   Flags{Track: "atcod", Category: "cat_problems"},
+			wantErr: `no track matches "atcod"`,
+		},
+		{
+			name:    "NG: --category is not matched fuzzily",
+			tokens:  []string{"abc"},
+			flags:   Flags{Track: "track_atcoder", Category: "problms"},
+			wantErr: `no category matches "problms"`,
+		},
+		{
+			name:    "NG: --count must be a number",
+			tokens:  []string{"abc", "@atcoder", "#problems"},
+			flags:   Flags{Count: "ten"},
+			wantErr: `--count must be a positive number, got "ten"`,
+		},
+		{
+			name:    "NG: --date must be a whole date",
+			tokens:  []string{"abc", "@atcoder", "#problems"},
+			flags:   Flags{Date: "yesterday night"},
+			wantErr: `--date "yesterday night" is not a date`,
+		},
+		{
+			name:    "NG: track as both token and flag",
+			tokens:  []string{"abc", "@atcoder"},
+			flags:   Flags{Track: "track_atcoder"},
+			wantErr: "track given twice",
+		},
+		{
+			name:    "NG: ambiguous track lists the candidates",
+			tokens:  []string{"x", "@clean"},
+			wantErr: `track "clean" is ambiguous: track_clean_arch, track_clean_code`,
+		},
+		{
+			name:    "NG: unknown category lists the known ones",
+			tokens:  []string{"x", "@atcoder", "#video"},
+			wantErr: `no category matches "video" (known: cat_problems, cat_reading)`,
+		},
+		{
+			name:    "NG: track without default category needs #category",
+			tokens:  []string{"x", "@atcoder"},
+			wantErr: "track track_atcoder has no default category",
+		},
+		{
+			name:    "NG: two counts",
+			tokens:  []string{"chapter", "3", "12", "@atcoder", "#problems"},
+			wantErr: "count given twice (3 and 12)",
+		},
+		{
+			name:    "NG: two dates",
+			tokens:  []string{"x", "@atcoder", "#problems", "today", "-1d"},
+			wantErr: "date given twice",
+		},
+		{
+			name:    "NG: missing track",
+			tokens:  []string{"x", "#problems"},
+			wantErr: "track is required",
+		},
+		{
+			name:    "NG: missing title",
+			tokens:  []string{"@atcoder", "#problems", "5"},
+			wantErr: "title is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser
+			p.Flags = tt.flags
+			got, err := p.Parse(tt.tokens)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, apperr.ErrInvalid) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
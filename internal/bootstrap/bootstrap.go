@@ -0,0 +1,227 @@
+// Package bootstrap is the composition root: it wires application handlers to the file store.
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/auth"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/export"
+	"github.com/taketosaeki/donelog/internal/app/donelog/importer"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+	"github.com/taketosaeki/donelog/internal/infrastructure/clock"
+	"github.com/taketosaeki/donelog/internal/infrastructure/id"
+	"github.com/taketosaeki/donelog/internal/infrastructure/persistence/filestore"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+// UndoWindow is how long a change stays undoable.
+const UndoWindow = 10 * time.Minute
+
+// App bundles every command and query handler of the application.
+type App struct {
+	Tx command.Transactor
+
+	CreateDoneLog     command.CreateDoneLogHandler
+	UpdateDoneLog     command.UpdateDoneLogHandler
+	DeleteDoneLog     command.DeleteDoneLogHandler
+	RestoreDoneLog    command.RestoreDoneLogHandler
+	PurgeTrash        command.PurgeTrashedDoneLogsHandler
+	UndoLastChange    command.UndoLastChangeHandler
+	CreateTrack       command.CreateTrackHandler
+	ArchiveTrack      command.ArchiveTrackHandler
+	CreateCategory    command.CreateCategoryHandler
+	ArchiveCategory   command.ArchiveCategoryHandler
+	CreateGoal        command.CreateGoalHandler
+	DeleteGoal        command.DeleteGoalHandler
+	CreateTeam        command.CreateTeamHandler
+	SetTeamMember     command.SetTeamMemberHandler
+	RemoveTeamMember  command.RemoveTeamMemberHandler
+	AcceptTeamInvite  command.AcceptTeamInviteHandler
+	LeaderboardOptOut command.SetLeaderboardOptOutHandler
+	TagDoneLog        command.TagDoneLogHandler
+	RenameTag         command.RenameTagHandler
+	DeleteTag         command.DeleteTagHandler
+	UpdateSettings    command.UpdateSettingsHandler
+
+	ListDoneLogs       query.ListDoneLogsHandler
+	GetDoneLog         query.GetDoneLogHandler
+	DoneLogHistory     query.GetDoneLogHistoryHandler
+	SummarizeByDay     query.SummarizeByDayHandler
+	SummarizeByWeek    query.SummarizeByWeekHandler
+	SummarizeByMonth   query.SummarizeByMonthHandler
+	SummarizeByQuarter query.SummarizeByQuarterHandler
+	SummarizeByYear    query.SummarizeByYearHandler
+	YearOverYear       query.CompareYearOverYearHandler
+	Trend              query.GetTrendHandler
+	ListTracks         query.ListTracksHandler
+	ListCategories     query.ListCategoriesHandler
+	Streaks            query.GetStreaksHandler
+	ListGoals          query.ListGoalProgressHandler
+	GoalProgress       query.GetGoalProgressHandler
+	Forecast           query.GetForecastHandler
+	Heatmap            query.GetHeatmapHandler
+	ListTeams          query.ListTeamsHandler
+	ListTeamInvites    query.ListTeamInvitesHandler
+	TeamSummary        query.GetTeamSummaryHandler
+	Leaderboard        query.GetLeaderboardHandler
+	CompareMembers     query.CompareMembersHandler
+	SummarizeByTag     query.SummarizeByTagHandler
+	GetSettings        query.GetSettingsHandler
+
+	Export export.Exporter
+	Import importer.CSVImporter
+
+	Auth auth.Service
+}
+
+// New wires the handlers to store using the system clock and ULID identifiers.
+func New(store *filestore.Store) App {
+	var (
+		doneLogs   = store.DoneLogs()
+		tracks     = store.Tracks()
+		categories = store.Categories()
+		audit      = store.Audit()
+		undo       = store.Undo()
+		goals      = store.Goals()
+		teams      = store.Teams()
+		settings   = store.Settings()
+		ids        = id.NewULIDGenerator()
+		now        = clock.SystemClock{}
+	)
+	return App{
+		Tx: store,
+
+		CreateDoneLog: command.CreateDoneLogHandler{
+			DoneLogs:    doneLogs,
+			Tracks:      tracks,
+			Categories:  categories,
+			IDs:         ids,
+			Audit:       audit,
+			Time:        now,
+			Undo:        undo,
+			Idempotency: store.Idempotency(),
+		},
+		UpdateDoneLog:     command.UpdateDoneLogHandler{DoneLogs: doneLogs, Categories: categories, Audit: audit, Time: now, Undo: undo},
+		DeleteDoneLog:     command.DeleteDoneLogHandler{DoneLogs: doneLogs, Audit: audit, Time: now, Undo: undo},
+		RestoreDoneLog:    command.RestoreDoneLogHandler{DoneLogs: doneLogs, Audit: audit, Time: now},
+		PurgeTrash:        command.PurgeTrashedDoneLogsHandler{DoneLogs: doneLogs, Trash: doneLogs, Policy: donelog.PurgePolicy{}, Audit: audit, Time: now},
+		UndoLastChange:    command.UndoLastChangeHandler{DoneLogs: doneLogs, Undo: undo, Audit: audit, Time: now, Window: UndoWindow},
+		CreateTrack:       command.CreateTrackHandler{Tracks: tracks, Categories: categories, Teams: teams, TeamTracks: tracks},
+		ArchiveTrack:      command.ArchiveTrackHandler{Tracks: tracks, Teams: teams},
+		CreateCategory:    command.CreateCategoryHandler{Categories: categories},
+		ArchiveCategory:   command.ArchiveCategoryHandler{Categories: categories},
+		CreateGoal:        command.CreateGoalHandler{Goals: goals, Tracks: tracks, Categories: categories, IDs: ids},
+		DeleteGoal:        command.DeleteGoalHandler{Goals: goals},
+		CreateTeam:        command.CreateTeamHandler{Teams: teams},
+		SetTeamMember:     command.SetTeamMemberHandler{Teams: teams},
+		RemoveTeamMember:  command.RemoveTeamMemberHandler{Teams: teams},
+		AcceptTeamInvite:  command.AcceptTeamInviteHandler{Teams: teams, TeamTracks: tracks},
+		LeaderboardOptOut: command.SetLeaderboardOptOutHandler{Teams: teams},
+		TagDoneLog:        command.TagDoneLogHandler{DoneLogs: doneLogs, Audit: audit, Time: now, Undo: undo},
+		RenameTag:         command.RenameTagHandler{DoneLogs: doneLogs, Tagged: doneLogs, Audit: audit, Time: now},
+		DeleteTag:         command.DeleteTagHandler{DoneLogs: doneLogs, Tagged: doneLogs, Audit: audit, Time: now},
+		UpdateSettings:    command.UpdateSettingsHandler{Settings: settings},
+
+		ListDoneLogs:       query.ListDoneLogsHandler{DoneLogs: doneLogs, Tracks: tracks, Categories: categories},
+		GetDoneLog:         query.GetDoneLogHandler{DoneLogs: doneLogs, Tracks: tracks, Categories: categories},
+		DoneLogHistory:     query.GetDoneLogHistoryHandler{Audit: audit},
+		SummarizeByDay:     query.SummarizeByDayHandler{DoneLogs: doneLogs},
+		SummarizeByWeek:    query.SummarizeByWeekHandler{DoneLogs: doneLogs, Settings: settings},
+		SummarizeByMonth:   query.SummarizeByMonthHandler{DoneLogs: doneLogs},
+		SummarizeByQuarter: query.SummarizeByQuarterHandler{DoneLogs: doneLogs},
+		SummarizeByYear:    query.SummarizeByYearHandler{DoneLogs: doneLogs},
+		YearOverYear:       query.CompareYearOverYearHandler{DoneLogs: doneLogs, Tracks: tracks, Categories: categories, Clock: now},
+		Trend:              query.GetTrendHandler{DoneLogs: doneLogs, Settings: settings},
+		ListTracks:         query.ListTracksHandler{Tracks: tracks},
+		ListCategories:     query.ListCategoriesHandler{Categories: categories},
+		Streaks:            query.GetStreaksHandler{DoneLogs: doneLogs, Tracks: tracks, Clock: now},
+		ListGoals:          query.ListGoalProgressHandler{Goals: goals, DoneLogs: doneLogs, Tracks: tracks, Categories: categories, Clock: now},
+		GoalProgress:       query.GetGoalProgressHandler{Goals: goals, DoneLogs: doneLogs, Tracks: tracks, Categories: categories, Clock: now},
+		Forecast:           query.GetForecastHandler{Goals: goals, DoneLogs: doneLogs, Tracks: tracks, Categories: categories, Clock: now},
+		Heatmap:            query.GetHeatmapHandler{DoneLogs: doneLogs, Clock: now},
+		ListTeams:          query.ListTeamsHandler{Teams: teams},
+		ListTeamInvites:    query.ListTeamInvitesHandler{Teams: teams},
+		TeamSummary:        query.GetTeamSummaryHandler{Teams: teams, DoneLogs: teams},
+		Leaderboard:        query.GetLeaderboardHandler{Teams: teams, DoneLogs: teams},
+		CompareMembers:     query.CompareMembersHandler{Teams: teams, DoneLogs: teams},
+		SummarizeByTag:     query.SummarizeByTagHandler{DoneLogs: doneLogs},
+		GetSettings:        query.GetSettingsHandler{Settings: settings},
+
+		Export: export.Exporter{DoneLogs: doneLogs, Tracks: tracks, Categories: categories},
+		Import: importer.CSVImporter{Batch: command.BatchDoneLogHandler{
+			DoneLogs:   doneLogs,
+			Tracks:     tracks,
+			Categories: categories,
+			IDs:        ids,
+			Audit:      audit,
+			Time:       now,
+			Tx:         store,
+		}},
+
+		Auth: auth.Service{
+			Accounts:      store.Accounts(),
+			Sessions:      store.Sessions(),
+			Tokens:        store.Tokens(),
+			Time:          now,
+			Identities:    store.Identities(),
+			PendingLogins: store.PendingLogins(),
+			Owners:        store.Owners(),
+		},
+	}
+}
+
+// HTTPHandler exposes the application over REST.
+func (a App) HTTPHandler() httpapi.Handler {
+	return httpapi.Handler{
+		Tx:                 a.Tx,
+		Auth:               a.Auth,
+		CreateDoneLog:      a.CreateDoneLog,
+		UpdateDoneLog:      a.UpdateDoneLog,
+		DeleteDoneLog:      a.DeleteDoneLog,
+		RestoreDoneLog:     a.RestoreDoneLog,
+		PurgeTrash:         a.PurgeTrash,
+		Undo:               a.UndoLastChange,
+		CreateTrack:        a.CreateTrack,
+		ArchiveTrack:       a.ArchiveTrack,
+		CreateCategory:     a.CreateCategory,
+		ArchiveCategory:    a.ArchiveCategory,
+		CreateGoal:         a.CreateGoal,
+		DeleteGoal:         a.DeleteGoal,
+		CreateTeam:         a.CreateTeam,
+		SetTeamMember:      a.SetTeamMember,
+		RemoveTeamMember:   a.RemoveTeamMember,
+		AcceptTeamInvite:   a.AcceptTeamInvite,
+		LeaderboardOptOut:  a.LeaderboardOptOut,
+		TagDoneLog:         a.TagDoneLog,
+		RenameTag:          a.RenameTag,
+		DeleteTag:          a.DeleteTag,
+		UpdateSettings:     a.UpdateSettings,
+		ListDoneLogs:       a.ListDoneLogs,
+		GetDoneLog:         a.GetDoneLog,
+		History:            a.DoneLogHistory,
+		SummarizeByDay:     a.SummarizeByDay,
+		SummarizeByWeek:    a.SummarizeByWeek,
+		SummarizeByMonth:   a.SummarizeByMonth,
+		SummarizeByQuarter: a.SummarizeByQuarter,
+		SummarizeByYear:    a.SummarizeByYear,
+		YearOverYear:       a.YearOverYear,
+		Trend:              a.Trend,
+		ListTracks:         a.ListTracks,
+		ListCategories:     a.ListCategories,
+		Streaks:            a.Streaks,
+		ListGoals:          a.ListGoals,
+		GoalProgress:       a.GoalProgress,
+		Forecast:           a.Forecast,
+		Heatmap:            a.Heatmap,
+		ListTeams:          a.ListTeams,
+		ListTeamInvites:    a.ListTeamInvites,
+		TeamSummary:        a.TeamSummary,
+		Leaderboard:        a.Leaderboard,
+		CompareMembers:     a.CompareMembers,
+		SummarizeByTag:     a.SummarizeByTag,
+		GetSettings:        a.GetSettings,
+		Export:             a.Export,
+	}
+}
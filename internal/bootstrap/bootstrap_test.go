@@ -0,0 +1,749 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/app/auth"
+	"github.com/taketosaeki/donelog/internal/app/backup"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+	"github.com/taketosaeki/donelog/internal/infrastructure/clock"
+	"github.com/taketosaeki/donelog/internal/infrastructure/oidc"
+	"github.com/taketosaeki/donelog/internal/infrastructure/oidc/oidctest"
+	"github.com/taketosaeki/donelog/internal/infrastructure/persistence/filestore"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+// TestRemoteRoundTrip drives the wired application through the REST API and Client.
+func TestRemoteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := filestore.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(New(store).HTTPHandler().Routes())
+	defer server.Close()
+	client := &httpapi.Client{BaseURL: server.URL, Actor: "taketo"}
+
+	if err := client.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "pages", Name: "Pages"}); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	if err := client.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"}); err != nil {
+		t.Fatalf("create track: %v", err)
+	}
+	err = client.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Again"})
+	if !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("expected conflict for duplicate track, got %v", err)
+	}
+
+	id, err := client.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title: "ch.1", TrackID: "reading", CategoryID: "pages", Count: 12, OccurredOn: "2024-05-01",
+	}, "key-1")
+	if err != nil {
+		t.Fatalf("create donelog: %v", err)
+	}
+	note := "Dependency rule<br>**inwards**"
+	if err := client.UpdateDoneLog(ctx, id, httpapi.UpdateDoneLogRequest{
+		Title: "ch.1-2", CategoryID: "pages", Count: 20, OccurredOn: "2024-05-01", Note: &note, Links: []string{"https://example.com/ch1"},
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	err = client.UpdateDoneLog(ctx, id, httpapi.UpdateDoneLogRequest{Title: "ch.1-2", CategoryID: "pages", Count: 20, OccurredOn: "2024-05-01", Links: []string{"file:///etc/passwd"}})
+	if !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected invalid link, got %v", err)
+	}
+
+	page, err := client.ListDoneLogs(ctx, query.ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.TotalCount != 1 || page.Items[0].Title != "ch.1-2" || page.Items[0].TrackName != "Reading" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if page.Items[0].Note != "Dependency rule**inwards**" || len(page.Items[0].Links) != 1 {
+		t.Fatalf("expected a sanitized note and one link: %+v", page.Items[0])
+	}
+
+	summary, err := client.SummarizeByMonth(ctx, query.SummarizeByMonthQuery{StartMonth: "2024-05", EndMonth: "2024-05"})
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.TotalCount != 20 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	yoy, err := client.YearOverYear(ctx, query.CompareYearOverYearQuery{Year: 2024, Unit: "quarter", GroupBy: "track"})
+	if err != nil {
+		t.Fatalf("year over year: %v", err)
+	}
+	if len(yoy.Groups) != 1 || yoy.Groups[0].Name != "Reading" || yoy.Groups[0].Rows[1].Current != 20 || yoy.Groups[0].Rows[1].ChangePercent != nil {
+		t.Fatalf("unexpected year over year: %+v", yoy)
+	}
+
+	trend, err := client.Trend(ctx, query.GetTrendQuery{StartDate: "2024-04-25", EndDate: "2024-05-01"})
+	if err != nil {
+		t.Fatalf("trend: %v", err)
+	}
+	if trend.BestDay.Label != "2024-05-01" || trend.Points[6].Cumulative != 20 || trend.Points[6].MovingAverage7 == nil {
+		t.Fatalf("unexpected trend: %+v", trend)
+	}
+
+	if err := client.DeleteDoneLog(ctx, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := client.GetDoneLog(ctx, id); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected trashed donelog to be hidden, got %v", err)
+	}
+	undone, err := client.Undo(ctx)
+	if err != nil || undone.Action != "deleted" {
+		t.Fatalf("undo = %+v, %v", undone, err)
+	}
+	if _, err := client.GetDoneLog(ctx, id); err != nil {
+		t.Fatalf("expected undo to bring the donelog back: %v", err)
+	}
+
+	if _, err := client.ListDoneLogs(ctx, query.ListDoneLogsQuery{StartDate: "2024-05-01"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected invalid query error, got %v", err)
+	}
+}
+
+func TestStreaksEndpoint(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	server := httptest.NewServer(New(store).HTTPHandler().Routes())
+	defer server.Close()
+	client := &httpapi.Client{BaseURL: server.URL}
+
+	_ = client.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "problems", Name: "Problems"})
+	_ = client.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "atcoder", Name: "AtCoder", DefaultCategoryID: "problems"})
+	today := time.Now()
+	for _, back := range []int{0, 1, 3} {
+		_, err := client.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+			Title: "abc", TrackID: "atcoder", CategoryID: "problems", Count: 3, OccurredOn: today.AddDate(0, 0, -back).Format("2006-01-02"),
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	streaks, err := client.Streaks(ctx, query.GetStreaksQuery{Freezes: 1})
+	if err != nil {
+		t.Fatalf("streaks: %v", err)
+	}
+	if len(streaks) != 1 || streaks[0].State != "on_fire" || streaks[0].Current.Length != 3 || streaks[0].Current.FreezesUsed != 1 {
+		t.Fatalf("unexpected streaks: %+v", streaks)
+	}
+	if _, err := client.Streaks(ctx, query.GetStreaksQuery{Unit: "year"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected invalid unit, got %v", err)
+	}
+}
+
+func TestGoalsEndpoint(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	server := httptest.NewServer(New(store).HTTPHandler().Routes())
+	defer server.Close()
+	client := &httpapi.Client{BaseURL: server.URL}
+
+	_ = client.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "pages", Name: "Pages"})
+	_ = client.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"})
+	today := time.Now()
+	_, err := client.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title: "ch.1", TrackID: "reading", CategoryID: "pages", Count: 30, OccurredOn: today.Format("2006-01-02"),
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := client.CreateGoal(ctx, httpapi.CreateGoalRequest{
+		Name: "Sprint", TrackID: "reading", Target: 100,
+		StartDate: today.AddDate(0, 0, -3).Format("2006-01-02"), EndDate: today.AddDate(0, 0, 6).Format("2006-01-02"),
+	})
+	if err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	progress, err := client.GoalProgress(ctx, id)
+	if err != nil {
+		t.Fatalf("progress: %v", err)
+	}
+	if progress.Done != 30 || progress.Remaining != 70 || progress.DaysLeft != 7 || progress.RequiredDailyPace != 10 || progress.Status != "in_progress" {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+
+	forecast, err := client.Forecast(ctx, query.GetForecastQuery{GoalID: id, WindowDays: 7})
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if forecast.Done != 30 || forecast.Remaining != 70 || forecast.Status != "behind" || forecast.Warning == "" {
+		t.Fatalf("unexpected forecast: %+v", forecast)
+	}
+	if _, err := client.Forecast(ctx, query.GetForecastQuery{TrackID: "reading"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected missing target to be invalid, got %v", err)
+	}
+
+	if _, err := client.CreateGoal(ctx, httpapi.CreateGoalRequest{Name: "Bad", TrackID: "ghost", StartDate: "2024-05-01", EndDate: "2024-05-31", Target: 1}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("expected unknown track, got %v", err)
+	}
+	if err := client.DeleteGoal(ctx, id); err != nil {
+		t.Fatalf("delete goal: %v", err)
+	}
+	goals, err := client.ListGoals(ctx)
+	if err != nil || len(goals) != 0 {
+		t.Fatalf("goals after delete = %+v, %v", goals, err)
+	}
+}
+
+func TestOwnerIsolation(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	server := httptest.NewServer(New(store).HTTPHandler().Routes())
+	defer server.Close()
+	alice := &httpapi.Client{BaseURL: server.URL, Actor: "alice"}
+	bob := &httpapi.Client{BaseURL: server.URL, Actor: "bob"}
+
+	_ = alice.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "pages", Name: "Pages"})
+	_ = alice.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"})
+	id, err := alice.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{Title: "ch.1", TrackID: "reading", CategoryID: "pages", Count: 12, OccurredOn: "2024-05-01"}, "")
+	if err != nil {
+		t.Fatalf("alice create: %v", err)
+	}
+
+	if _, err := bob.GetDoneLog(ctx, id); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("bob must not read alice's donelog, got %v", err)
+	}
+	if err := bob.DeleteDoneLog(ctx, id); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("bob must not delete alice's donelog, got %v", err)
+	}
+	if _, err := bob.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{Title: "x", TrackID: "reading", CategoryID: "pages", Count: 1, OccurredOn: "2024-05-01"}, ""); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("bob must not use alice's track, got %v", err)
+	}
+	if tracks, err := bob.ListTracks(ctx, query.ListTracksQuery{}); err != nil || len(tracks) != 0 {
+		t.Fatalf("bob's tracks = %+v, %v", tracks, err)
+	}
+
+	// The same IDs are free for bob.
+	if err := bob.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "pages", Name: "Pages"}); err != nil {
+		t.Fatalf("bob create category: %v", err)
+	}
+	if err := bob.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Bob reads", DefaultCategoryID: "pages"}); err != nil {
+		t.Fatalf("bob create track: %v", err)
+	}
+	page, err := bob.ListDoneLogs(ctx, query.ListDoneLogsQuery{StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil || page.TotalCount != 0 {
+		t.Fatalf("bob's donelogs = %+v, %v", page, err)
+	}
+	if got, err := alice.GetDoneLog(ctx, id); err != nil || got.TrackName != "Reading" {
+		t.Fatalf("alice's donelog = %+v, %v", got, err)
+	}
+
+	// Settings are per owner too.
+	if err := alice.UpdateSettings(ctx, httpapi.UpdateSettingsRequest{WeekStart: "sunday"}); err != nil {
+		t.Fatalf("alice settings: %v", err)
+	}
+	if got, err := bob.GetSettings(ctx); err != nil || got.WeekStart != "monday" {
+		t.Fatalf("bob's settings = %+v, %v", got, err)
+	}
+	weekly, err := alice.SummarizeByWeek(ctx, query.SummarizeByWeekQuery{StartDate: "2024-04-28", EndDate: "2024-05-04"})
+	if err != nil || len(weekly.Points) != 1 || weekly.Points[0].Count != 12 {
+		t.Fatalf("alice's weekly summary should start on sunday: %+v, %v", weekly, err)
+	}
+	if err := alice.UpdateSettings(ctx, httpapi.UpdateSettingsRequest{WeekStart: "friday"}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected an unknown week start to be rejected, got %v", err)
+	}
+
+	invalid := &httpapi.Client{BaseURL: server.URL, Actor: "no spaces"}
+	if _, err := invalid.ListTracks(ctx, query.ListTracksQuery{}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected an invalid actor to be rejected, got %v", err)
+	}
+}
+
+func TestTeams(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	server := httptest.NewServer(New(store).HTTPHandler().Routes())
+	defer server.Close()
+	alice := &httpapi.Client{BaseURL: server.URL, Actor: "alice"}
+	bob := &httpapi.Client{BaseURL: server.URL, Actor: "bob"}
+	carol := &httpapi.Client{BaseURL: server.URL, Actor: "carol"}
+
+	if err := alice.CreateTeam(ctx, httpapi.CreateTeamRequest{ID: "club", Name: "Running club"}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	_ = alice.SetTeamMember(ctx, "club", "bob", "editor")
+	_ = alice.SetTeamMember(ctx, "club", "carol", "viewer")
+	if teams, err := bob.ListTeams(ctx); err != nil || len(teams) != 0 {
+		t.Fatalf("bob must not join before accepting, got %+v, %v", teams, err)
+	}
+	if invites, err := bob.ListTeamInvites(ctx); err != nil || len(invites) != 1 || invites[0].TeamID != "club" || invites[0].Role != "editor" {
+		t.Fatalf("bob's invites = %+v, %v", invites, err)
+	}
+	for _, c := range []*httpapi.Client{bob, carol} {
+		if err := c.AcceptTeamInvite(ctx, "club"); err != nil {
+			t.Fatalf("%s accepts: %v", c.Actor, err)
+		}
+	}
+	if err := bob.SetTeamMember(ctx, "club", "carol", "owner"); !errors.Is(err, apperr.ErrForbidden) {
+		t.Fatalf("editors must not manage members, got %v", err)
+	}
+	if err := alice.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "run", Name: "Run", TeamID: "club"}); err != nil {
+		t.Fatalf("create team track: %v", err)
+	}
+	if tracks, err := carol.ListTracks(ctx, query.ListTracksQuery{}); err != nil || len(tracks) != 1 || tracks[0].TeamID != "club" {
+		t.Fatalf("carol's tracks = %+v, %v", tracks, err)
+	}
+
+	for _, c := range []*httpapi.Client{alice, bob, carol} {
+		_ = c.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "km", Name: "km"})
+	}
+	log := func(c *httpapi.Client, count int) error {
+		_, err := c.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{Title: "run", TrackID: "run", CategoryID: "km", Count: count, OccurredOn: "2024-05-01"}, "")
+		return err
+	}
+	aliceLog, err := alice.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{Title: "run", TrackID: "run", CategoryID: "km", Count: 4, OccurredOn: "2024-05-01"}, "")
+	if err != nil {
+		t.Fatalf("owner log: %v", err)
+	}
+	// Editing a team DONELOG must keep it on the team.
+	if err := alice.UpdateDoneLog(ctx, aliceLog, httpapi.UpdateDoneLogRequest{Title: "long run", CategoryID: "km", Count: 5, OccurredOn: "2024-05-01"}); err != nil {
+		t.Fatalf("update team log: %v", err)
+	}
+	if err := log(bob, 3); err != nil {
+		t.Fatalf("editor log: %v", err)
+	}
+	if err := log(carol, 1); !errors.Is(err, apperr.ErrForbidden) {
+		t.Fatalf("viewers must not log against team tracks, got %v", err)
+	}
+
+	summary, err := carol.TeamSummary(ctx, query.GetTeamSummaryQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil || summary.TotalCount != 8 || summary.Members[0].Count != 5 || len(summary.Members) != 3 || summary.Members[1].Member != "bob" || summary.Members[1].Count != 3 {
+		t.Fatalf("team summary = %+v, %v", summary, err)
+	}
+
+	may := query.GetLeaderboardQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"}
+	board, err := carol.Leaderboard(ctx, may)
+	if err != nil || len(board.Entries) != 3 || board.Entries[0].Member != "alice" || board.Entries[2].Rank != 3 {
+		t.Fatalf("leaderboard = %+v, %v", board, err)
+	}
+	if err := bob.SetLeaderboardOptOut(ctx, "club", true); err != nil {
+		t.Fatalf("opt out: %v", err)
+	}
+	if board, _ := carol.Leaderboard(ctx, may); len(board.Entries) != 2 || board.HiddenMembers != 1 {
+		t.Fatalf("bob must be hidden, got %+v", board)
+	}
+	compareBob := query.CompareMembersQuery{TeamID: "club", Member: "bob", StartDate: "2024-05-01", EndDate: "2024-05-31"}
+	if _, err := alice.CompareMembers(ctx, compareBob); !errors.Is(err, apperr.ErrForbidden) {
+		t.Fatalf("comparing with an opted-out member must be forbidden, got %v", err)
+	}
+	compareBob.Member = "alice"
+	if cmp, err := bob.CompareMembers(ctx, compareBob); err != nil || cmp.Difference != -2 || cmp.Other.Rank != 1 {
+		t.Fatalf("bob vs alice = %+v, %v", cmp, err)
+	}
+
+	// A Track ID names at most one team Track for each member, whichever way the clash would arise.
+	dave := &httpapi.Client{BaseURL: server.URL, Actor: "dave"}
+	_ = dave.CreateTeam(ctx, httpapi.CreateTeamRequest{ID: "guild", Name: "Swim guild"})
+	if err := dave.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "swim", Name: "Swim", TeamID: "guild"}); err != nil {
+		t.Fatalf("create guild track: %v", err)
+	}
+	_ = alice.SetTeamMember(ctx, "club", "dave", "viewer")
+	if err := dave.AcceptTeamInvite(ctx, "club"); err != nil {
+		t.Fatalf("dave accepts: %v", err)
+	}
+	if err := alice.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "swim", Name: "Swim", TeamID: "club"}); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("a club track must not clash with dave's guild track, got %v", err)
+	}
+	if err := dave.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "run", Name: "Run", TeamID: "guild"}); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("a guild track must not clash with dave's club track, got %v", err)
+	}
+	erin := &httpapi.Client{BaseURL: server.URL, Actor: "erin"}
+	_ = erin.CreateTeam(ctx, httpapi.CreateTeamRequest{ID: "crew", Name: "Crew"})
+	_ = erin.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "run", Name: "Run", TeamID: "crew"})
+	_ = erin.SetTeamMember(ctx, "crew", "bob", "editor")
+	if err := bob.AcceptTeamInvite(ctx, "crew"); !errors.Is(err, apperr.ErrConflict) {
+		t.Fatalf("joining a team with a clashing track must fail, got %v", err)
+	}
+
+	outsider := &httpapi.Client{BaseURL: server.URL, Actor: "mallory"}
+	if _, err := outsider.TeamSummary(ctx, query.GetTeamSummaryQuery{TeamID: "club", StartDate: "2024-05-01", EndDate: "2024-05-31"}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("outsiders must not see the team, got %v", err)
+	}
+	if err := bob.RemoveTeamMember(ctx, "club", "bob"); err != nil {
+		t.Fatalf("bob leaves: %v", err)
+	}
+	if tracks, _ := bob.ListTracks(ctx, query.ListTracksQuery{}); len(tracks) != 0 {
+		t.Fatalf("bob must lose the team track after leaving, got %+v", tracks)
+	}
+	if teams, err := alice.ListTeams(ctx); err != nil || len(teams) != 1 || len(teams[0].Members) != 3 {
+		t.Fatalf("alice's teams = %+v, %v", teams, err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	server := httptest.NewServer(New(store).HTTPHandler().Routes())
+	defer server.Close()
+	client := &httpapi.Client{BaseURL: server.URL, Actor: "taketo"}
+
+	_ = client.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "articles", Name: "Articles"})
+	_ = client.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "blog", Name: "Blog", DefaultCategoryID: "articles"})
+	first, err := client.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title: "escape analysis", TrackID: "blog", CategoryID: "articles", Count: 3, OccurredOn: "2024-05-01", Tags: []string{"performance", "go"},
+	}, "")
+	if err != nil {
+		t.Fatalf("create tagged donelog: %v", err)
+	}
+	second, _ := client.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title: "generics", TrackID: "blog", CategoryID: "articles", Count: 2, OccurredOn: "2024-05-02", Tags: []string{"go"},
+	}, "")
+	if _, err := client.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title: "bad", TrackID: "blog", CategoryID: "articles", Count: 1, OccurredOn: "2024-05-02", Tags: []string{"Go Lang"},
+	}, ""); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("expected invalid tag, got %v", err)
+	}
+
+	// An update without tags keeps them.
+	if err := client.UpdateDoneLog(ctx, second, httpapi.UpdateDoneLogRequest{Title: "generics", CategoryID: "articles", Count: 4, OccurredOn: "2024-05-02"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := client.TagDoneLog(ctx, second, httpapi.TagDoneLogRequest{Add: []string{"tutorial"}}); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+	item, _ := client.GetDoneLog(ctx, second)
+	if strings.Join(item.Tags, ",") != "go,tutorial" {
+		t.Fatalf("unexpected tags: %v", item.Tags)
+	}
+	if undone, err := client.Undo(ctx); err != nil || undone.ID != second {
+		t.Fatalf("undo tagging = %+v, %v", undone, err)
+	}
+
+	byTag, err := client.SummarizeByTag(ctx, query.SummarizeByTagQuery{StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil {
+		t.Fatalf("tag summary: %v", err)
+	}
+	if len(byTag.Tags) != 2 || byTag.Tags[0] != (query.TagTotal{Tag: "go", Count: 7, DoneLogs: 2}) || byTag.Tags[1].Count != 3 {
+		t.Fatalf("unexpected tag summary: %+v", byTag)
+	}
+	daily, err := client.SummarizeByDay(ctx, query.SummarizeByDayQuery{Tag: "performance", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if err != nil || daily.TotalCount != 3 || daily.Tag != "performance" {
+		t.Fatalf("tag-filtered summary = %+v, %v", daily, err)
+	}
+
+	if n, err := client.RenameTag(ctx, "go", "golang"); err != nil || n != 2 {
+		t.Fatalf("rename = %d, %v", n, err)
+	}
+	if n, err := client.DeleteTag(ctx, "performance"); err != nil || n != 1 {
+		t.Fatalf("delete = %d, %v", n, err)
+	}
+	page, _ := client.ListDoneLogs(ctx, query.ListDoneLogsQuery{Tag: "golang", StartDate: "2024-05-01", EndDate: "2024-05-31"})
+	if page.TotalCount != 2 {
+		t.Fatalf("unexpected tag-filtered page: %+v", page)
+	}
+	item, _ = client.GetDoneLog(ctx, first)
+	if strings.Join(item.Tags, ",") != "golang" {
+		t.Fatalf("unexpected tags after rename and delete: %v", item.Tags)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	app := New(store)
+	app.Auth.Iterations = 1000
+	if err := app.Auth.CreateAccount(ctx, "alice", "correct horse"); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	handler := app.HTTPHandler()
+	handler.RequireAuth = true
+	server := httptest.NewServer(handler.Routes())
+	defer server.Close()
+
+	anonymous := &httpapi.Client{BaseURL: server.URL, Actor: "alice"}
+	if _, err := anonymous.ListTracks(ctx, query.ListTracksQuery{}); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("X-Actor alone must not authenticate, got %v", err)
+	}
+
+	// Sign in with a password; the session cookie authenticates the browser.
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar}
+	post := func(path, body string) *http.Response {
+		t.Helper()
+		res, err := browser.Post(server.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		res.Body.Close()
+		return res
+	}
+	if res := post("/api/auth/login", `{"username":"alice","password":"wrong horse"}`); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong password: status %d", res.StatusCode)
+	}
+	res := post("/api/auth/login", `{"username":"alice","password":"correct horse"}`)
+	if res.StatusCode != http.StatusOK || len(res.Cookies()) != 1 || !res.Cookies()[0].HttpOnly || res.Cookies()[0].SameSite != http.SameSiteStrictMode {
+		t.Fatalf("login: status %d, cookies %+v", res.StatusCode, res.Cookies())
+	}
+	if res := post("/api/categories", `{"id":"pages","name":"Pages"}`); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("create category with session: status %d", res.StatusCode)
+	}
+	res = post("/api/tokens", `{"name":"reports","scopes":["read"]}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create token: status %d", res.StatusCode)
+	}
+	res, err := browser.Post(server.URL+"/api/tokens", "application/json", strings.NewReader(`{"name":"sync","scopes":["read","write"]}`))
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	var issued auth.IssuedToken
+	if err := json.NewDecoder(res.Body).Decode(&issued); err != nil || !strings.HasPrefix(issued.Token, "dlt_") {
+		t.Fatalf("issued token = %+v, %v", issued, err)
+	}
+	res.Body.Close()
+
+	// Personal API tokens authenticate scripts as the same owner, limited to their scopes.
+	script := &httpapi.Client{BaseURL: server.URL, Actor: "mallory", Token: issued.Token}
+	if err := script.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"}); err != nil {
+		t.Fatalf("create track with token: %v", err)
+	}
+	if tracks, err := script.ListTracks(ctx, query.ListTracksQuery{}); err != nil || len(tracks) != 1 {
+		t.Fatalf("tracks = %+v, %v", tracks, err)
+	}
+	raws, _ := store.Tracks().ListTracks(appctx.WithOwner(ctx, mustOwner(t, "alice")))
+	if len(raws) != 1 || raws[0].Owner != "alice" {
+		t.Fatalf("track should belong to alice, not the X-Actor: %+v", raws)
+	}
+
+	tokens, _ := app.Auth.ListTokens(appctx.WithPrincipal(ctx, appctx.Principal{Owner: mustOwner(t, "alice"), Scopes: account.AllScopes()}))
+	if len(tokens) != 2 || tokens[0].Name != "reports" {
+		t.Fatalf("tokens = %+v", tokens)
+	}
+	readOnly, err := app.Auth.IssueToken(appctx.WithPrincipal(ctx, appctx.Principal{Owner: mustOwner(t, "alice"), Scopes: account.AllScopes()}), "ro", []string{"read"})
+	if err != nil {
+		t.Fatalf("issue read-only token: %v", err)
+	}
+	reader := &httpapi.Client{BaseURL: server.URL, Token: readOnly.Token}
+	if err := reader.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "x", Name: "X"}); !errors.Is(err, apperr.ErrForbidden) {
+		t.Fatalf("read-only token must not write, got %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/tokens/"+issued.ID, nil)
+	if res, err := browser.Do(req); err != nil || res.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke token: %v %v", res, err)
+	}
+	if _, err := script.ListTracks(ctx, query.ListTracksQuery{}); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("revoked token must be rejected, got %v", err)
+	}
+
+	if res := post("/api/auth/logout", ``); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("logout: status %d", res.StatusCode)
+	}
+	if res := post("/api/categories", `{"id":"more","name":"More"}`); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("request after logout: status %d", res.StatusCode)
+	}
+
+	// Changing the password needs a signed-in caller and the current password, and revokes every credential.
+	if res := post("/api/auth/password", `{"currentPassword":"correct horse","newPassword":"battery staple"}`); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("anonymous password change: status %d", res.StatusCode)
+	}
+	post("/api/auth/login", `{"username":"alice","password":"correct horse"}`)
+	if res := post("/api/auth/password", `{"currentPassword":"wrong horse","newPassword":"battery staple"}`); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("password change with a wrong current password: status %d", res.StatusCode)
+	}
+	if res := post("/api/auth/password", `{"currentPassword":"correct horse","newPassword":"battery staple"}`); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("password change: status %d", res.StatusCode)
+	}
+	if res := post("/api/categories", `{"id":"more","name":"More"}`); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("session survived a password change: status %d", res.StatusCode)
+	}
+	if _, err := reader.ListTracks(ctx, query.ListTracksQuery{}); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("token survived a password change, got %v", err)
+	}
+	if res := post("/api/auth/login", `{"username":"alice","password":"battery staple"}`); res.StatusCode != http.StatusOK {
+		t.Fatalf("login with the new password: status %d", res.StatusCode)
+	}
+}
+
+func mustOwner(t *testing.T, value string) donelog.OwnerID {
+	t.Helper()
+	owner, err := donelog.NewOwnerID(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return owner
+}
+
+// TestSingleSignOn signs in through a stand-in OpenID Connect provider with a browser-like client.
+func TestSingleSignOn(t *testing.T) {
+	ctx := context.Background()
+	idp, err := oidctest.NewServer("donelog", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	store, _ := filestore.Open("")
+	app := New(store)
+	app.Auth.Iterations = 1000
+	if err := app.Auth.CreateAccount(ctx, "bob", "correct horse"); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	// carol has no account but already owns data written under X-Actor.
+	if err := app.CreateCategory.Handle(appctx.WithOwner(ctx, mustOwner(t, "carol")), command.CreateCategoryCommand{ID: "pages", Name: "Pages"}); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	// The redirect URL must be known before the provider is discovered, so the server starts first.
+	var routes http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { routes.ServeHTTP(w, r) }))
+	defer server.Close()
+	app.Auth.Provider, err = oidc.Discover(ctx, oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     "donelog",
+		ClientSecret: "s3cret",
+		RedirectURL:  server.URL + "/api/auth/sso/callback",
+	})
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	handler := app.HTTPHandler()
+	handler.RequireAuth = true
+	routes = handler.Routes()
+
+	// signIn runs the whole redirect chain in a fresh browser and returns who it is signed in as.
+	signIn := func(user oidctest.User) string {
+		t.Helper()
+		idp.SignIn(user)
+		jar, _ := cookiejar.New(nil)
+		browser := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+			if req.URL.Path == "/" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		}}
+		res, err := browser.Get(server.URL + "/api/auth/sso/login")
+		if err != nil {
+			t.Fatalf("sso login: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusFound || res.Header.Get("Location") != "/" {
+			t.Fatalf("sso callback: status %d, location %q", res.StatusCode, res.Header.Get("Location"))
+		}
+		res, err = browser.Get(server.URL + "/api/auth/me")
+		if err != nil {
+			t.Fatalf("me: %v", err)
+		}
+		defer res.Body.Close()
+		var me httpapi.MeResponse
+		if err := json.NewDecoder(res.Body).Decode(&me); err != nil || me.Method != string(appctx.AuthMethodSession) {
+			t.Fatalf("me = %+v, %v", me, err)
+		}
+		return me.Username
+	}
+
+	tests := []struct {
+		name string
+		user oidctest.User
+		want string
+		// prefix marks want as a prefix of a name derived from the subject.
+		prefix bool
+	}{
+		{name: "first sign-in maps preferred_username", user: oidctest.User{Subject: "1001", PreferredUsername: "alice", Email: "alice@example.com"}, want: "alice"},
+		{name: "returning subject keeps its username", user: oidctest.User{Subject: "1001", PreferredUsername: "alice.renamed"}, want: "alice"},
+		{name: "taken preferred_username falls back to email", user: oidctest.User{Subject: "1002", PreferredUsername: "alice", Email: "alice.b@example.com"}, want: "alice.b@example.com"},
+		{name: "local account is not taken over", user: oidctest.User{Subject: "1003", PreferredUsername: "bob"}, want: "sso-", prefix: true},
+		{name: "existing data owner is not taken over", user: oidctest.User{Subject: "1004", PreferredUsername: "carol"}, want: "sso-", prefix: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := signIn(tt.user)
+			if got != tt.want && !(tt.prefix && strings.HasPrefix(got, tt.want)) {
+				t.Fatalf("signed in as %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// After a restore onto a fresh install, the subject still signs in as the user who owns the restored data.
+	if err := app.CreateCategory.Handle(appctx.WithOwner(ctx, mustOwner(t, "alice")), command.CreateCategoryCommand{ID: "runs", Name: "Runs"}); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	var archive bytes.Buffer
+	if _, err := (backup.Service{Store: store, Time: clock.SystemClock{}}).Backup(ctx, &archive); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	fresh, _ := filestore.Open("")
+	if _, err := (backup.Service{Store: fresh, Time: clock.SystemClock{}}).Restore(ctx, archive.Bytes()); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	restored := New(fresh)
+	restored.Auth.Provider = app.Auth.Provider
+	handler = restored.HTTPHandler()
+	handler.RequireAuth = true
+	routes = handler.Routes()
+	if got := signIn(oidctest.User{Subject: "1001", PreferredUsername: "alice", Email: "alice@example.com"}); got != "alice" {
+		t.Fatalf("signed in as %q after a restore, want alice", got)
+	}
+
+	// A callback from a browser that did not start the sign-in is rejected.
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := noRedirect.Get(server.URL + "/api/auth/sso/login")
+	if err != nil {
+		t.Fatalf("sso login: %v", err)
+	}
+	res.Body.Close()
+	code, state, err := oidctest.Authorize(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	res, err = noRedirect.Get(server.URL + "/api/auth/sso/callback?code=" + code + "&state=" + state)
+	if err != nil {
+		t.Fatalf("callback: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized || len(res.Cookies()) != 0 {
+		t.Fatalf("callback without the state cookie: status %d, cookies %+v", res.StatusCode, res.Cookies())
+	}
+}
+
+func TestTrashRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "OK: empty keeps the default", value: "", want: "30 days"},
+		{name: "OK: days", value: "7d", want: "7 days"},
+		{name: "OK: one day", value: "24h", want: "1 day"},
+		{name: "OK: duration", value: "36h", want: "36h0m0s"},
+		{name: "NG: zero", value: "0d", wantErr: "must be > 0"},
+		{name: "NG: negative", value: "-1h", wantErr: "must be > 0"},
+		{name: "NG: not a duration", value: "a month", wantErr: "want days such as 30d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseTrashRetention(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTrashRetention: %v", err)
+			}
+			if got := FormatTrashRetention(policy); got != tt.want {
+				t.Fatalf("FormatTrashRetention = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package bootstrap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// ParseTrashRetention reads the --trash-retention setting: whole days such as "30d", or a Go duration
+// such as "36h". An empty value keeps donelog.DefaultTrashRetention.
+func ParseTrashRetention(value string) (donelog.PurgePolicy, error) {
+	if value == "" {
+		return donelog.PurgePolicy{}, nil
+	}
+	var retention time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return donelog.PurgePolicy{}, fmt.Errorf("trash retention %q: want days such as 30d or a duration such as 36h", value)
+		}
+		retention = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return donelog.PurgePolicy{}, fmt.Errorf("trash retention %q: want days such as 30d or a duration such as 36h", value)
+		}
+		retention = d
+	}
+	policy, err := donelog.NewPurgePolicy(retention)
+	if err != nil {
+		return donelog.PurgePolicy{}, fmt.Errorf("trash retention %q: %w", value, err)
+	}
+	return policy, nil
+}
+
+// FormatTrashRetention describes the retention of policy for help texts, in days when it is whole days.
+func FormatTrashRetention(policy donelog.PurgePolicy) string {
+	retention := policy.Retention()
+	const day = 24 * time.Hour
+	switch {
+	case retention == day:
+		return "1 day"
+	case retention%day == 0:
+		return fmt.Sprintf("%d days", retention/day)
+	default:
+		return retention.String()
+	}
+}
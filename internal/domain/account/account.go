@@ -0,0 +1,81 @@
+// Package account models local user accounts and the credentials that authenticate them:
+// password hashes, web sessions and personal API tokens.
+package account
+
+import (
+	"errors"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Account is a local user. Its username is the OwnerID that scopes the user's data.
+type Account struct {
+	username  donelog.OwnerID
+	password  PasswordHash
+	createdAt time.Time
+}
+
+// NewAccount constructs an Account. The single-user owner cannot sign in, so username must be set.
+func NewAccount(username donelog.OwnerID, password PasswordHash, createdAt time.Time) (*Account, error) {
+	if username == (donelog.OwnerID{}) {
+		return nil, errors.New("account username must not be empty")
+	}
+	if password.iterations == 0 {
+		return nil, errors.New("account password hash must not be empty")
+	}
+	return &Account{username: username, password: password, createdAt: createdAt}, nil
+}
+
+// Username returns the login name.
+func (a *Account) Username() donelog.OwnerID {
+	return a.username
+}
+
+// CreatedAt returns when the account was created.
+func (a *Account) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+// Authenticate reports whether password is the account's password.
+func (a *Account) Authenticate(password string) bool {
+	return a.password.Matches(password)
+}
+
+// ChangePassword replaces the password hash.
+func (a *Account) ChangePassword(password PasswordHash) error {
+	if password.iterations == 0 {
+		return errors.New("account password hash must not be empty")
+	}
+	a.password = password
+	return nil
+}
+
+// RawAccount is the persisted form of an Account.
+type RawAccount struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Raw returns the persisted form.
+func (a *Account) Raw() RawAccount {
+	return RawAccount{
+		Username:     a.username.String(),
+		PasswordHash: a.password.String(),
+		CreatedAt:    a.createdAt,
+	}
+}
+
+// RehydrateAccount rebuilds an Account from its persisted form.
+func RehydrateAccount(raw RawAccount) (*Account, error) {
+	username, err := donelog.NewOwnerID(raw.Username)
+	if err != nil {
+		return nil, err
+	}
+	password, err := ParsePasswordHash(raw.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccount(username, password, raw.CreatedAt)
+}
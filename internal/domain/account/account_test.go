@@ -0,0 +1,186 @@
+package account
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+var testSalt = []byte("0123456789abcdef")
+
+func TestPBKDF2SHA256(t *testing.T) {
+	tests := []struct {
+		name       string
+		iterations int
+		want       string
+	}{
+		{"OK: 1 iteration", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"OK: 2 iterations", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"OK: 4096 iterations", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hex.EncodeToString(pbkdf2SHA256([]byte("password"), []byte("salt"), tt.iterations, 32))
+			if got != tt.want {
+				t.Fatalf("pbkdf2 = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		salt     []byte
+		wantErr  bool
+	}{
+		{"OK: long enough", "correct horse", testSalt, false},
+		{"OK: multibyte characters count as one", "パスワード１２３", testSalt, false},
+		{"NG: too short", "short", testSalt, true},
+		{"NG: too long", strings.Repeat("x", 257), testSalt, true},
+		{"NG: short salt", "correct horse", []byte("salt"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := HashPassword(tt.password, tt.salt, 10)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !h.Matches(tt.password) || h.Matches(tt.password+"!") {
+				t.Fatal("hash does not verify its own password only")
+			}
+			parsed, err := ParsePasswordHash(h.String())
+			if err != nil || !parsed.Matches(tt.password) || parsed.Iterations() != 10 {
+				t.Fatalf("round trip of %q failed: %v", h.String(), err)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "bcrypt$10$a$b", "pbkdf2-sha256$x$c2FsdA$aGFzaA", "pbkdf2-sha256$10$c2FsdA$aGFzaA"} {
+		if _, err := ParsePasswordHash(bad); err == nil {
+			t.Errorf("ParsePasswordHash(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    string
+		wantErr bool
+	}{
+		{"OK: single", []string{"read"}, "read", false},
+		{"OK: sorted without duplicates", []string{"write", "read", "write"}, "read,write", false},
+		{"NG: unknown", []string{"admin"}, "", true},
+		{"NG: empty", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScopes(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err == nil && strings.Join(got.Strings(), ",") != tt.want {
+				t.Fatalf("scopes = %v, want %s", got, tt.want)
+			}
+		})
+	}
+	if !AllScopes().Covers(Scopes{ScopeWrite}) || (Scopes{ScopeRead}).Covers(Scopes{ScopeWrite}) {
+		t.Fatal("unexpected Covers result")
+	}
+}
+
+func TestToken(t *testing.T) {
+	owner, _ := donelog.NewOwnerID("alice")
+	id, _ := NewTokenID("0123456789abcdef")
+	secret := strings.Repeat("s", 64)
+	token, err := NewToken(id, owner, " backup script ", Scopes{ScopeRead}, secret, time.Now())
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	if token.Name() != "backup script" {
+		t.Fatalf("name = %q", token.Name())
+	}
+
+	plaintext := FormatToken(id, secret)
+	gotID, gotSecret, err := ParseToken(plaintext)
+	if err != nil || gotID != id || !token.Verify(gotSecret) || token.Verify(strings.Repeat("t", 64)) {
+		t.Fatalf("ParseToken(%q) = %v %q %v", plaintext, gotID, gotSecret, err)
+	}
+	for _, bad := range []string{"", "dlt_0123456789abcdef", "dlt_0123456789ABCDEF_" + secret, "xyz_0123456789abcdef_" + secret, "dlt_0123456789abcdef_short"} {
+		if _, _, err := ParseToken(bad); err == nil {
+			t.Errorf("ParseToken(%q) should fail", bad)
+		}
+	}
+
+	restored, err := RehydrateToken(token.Raw())
+	if err != nil || !restored.Verify(secret) || !restored.Scopes().Has(ScopeRead) {
+		t.Fatalf("RehydrateToken: %v", err)
+	}
+	if _, err := NewToken(id, donelog.OwnerID{}, "x", Scopes{ScopeRead}, secret, time.Now()); err == nil {
+		t.Fatal("token of the single-user owner should be rejected")
+	}
+}
+
+func TestSessionAndAccount(t *testing.T) {
+	owner, _ := donelog.NewOwnerID("alice")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	secret := strings.Repeat("a", 64)
+	session, err := NewSession(secret, owner, now, time.Hour)
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if session.Key() != SessionKey(secret) || session.Key() == secret {
+		t.Fatal("session must be keyed by the digest of its secret")
+	}
+	if session.Expired(now.Add(59*time.Minute)) || !session.Expired(now.Add(time.Hour)) {
+		t.Fatal("unexpected expiry")
+	}
+
+	hash, _ := HashPassword("correct horse", testSalt, 10)
+	acct, err := NewAccount(owner, hash, now)
+	if err != nil {
+		t.Fatalf("NewAccount: %v", err)
+	}
+	restored, err := RehydrateAccount(acct.Raw())
+	if err != nil || !restored.Authenticate("correct horse") || restored.Authenticate("wrong horse") {
+		t.Fatalf("RehydrateAccount: %v", err)
+	}
+	if _, err := NewAccount(donelog.OwnerID{}, hash, now); err == nil {
+		t.Fatal("account without username should be rejected")
+	}
+}
+
+func TestPendingLogin(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	state, verifier, nonce := strings.Repeat("s", 32), strings.Repeat("v", 43), strings.Repeat("n", 32)
+	if _, err := NewPendingLogin("short", verifier, nonce, now, time.Minute); err == nil {
+		t.Fatal("a guessable state should be rejected")
+	}
+	login, err := NewPendingLogin(state, verifier, nonce, now, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("NewPendingLogin: %v", err)
+	}
+	restored, err := RehydratePendingLogin(login.Raw())
+	if err != nil {
+		t.Fatalf("RehydratePendingLogin: %v", err)
+	}
+	switch {
+	case restored.Raw().Key != PendingLoginKey(state) || restored.Raw().Key == state:
+		t.Fatal("pending login must be keyed by the digest of its state")
+	case restored.CodeVerifier() != verifier:
+		t.Fatal("code verifier was not kept")
+	case !restored.MatchesNonce(nonce) || restored.MatchesNonce("") || restored.MatchesNonce(strings.Repeat("m", 32)):
+		t.Fatal("unexpected nonce match")
+	case restored.Expired(now.Add(9*time.Minute)) || !restored.Expired(now.Add(10*time.Minute)):
+		t.Fatal("unexpected expiry")
+	}
+}
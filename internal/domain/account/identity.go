@@ -0,0 +1,124 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Identity links the subject of an OpenID Connect issuer to a donelog username.
+// It is created on the first single sign-on and looked up on every later one.
+type Identity struct {
+	issuer    string
+	subject   string
+	username  donelog.OwnerID
+	createdAt time.Time
+}
+
+// IdentityKey returns the storage key of the identity of subject at issuer.
+func IdentityKey(issuer, subject string) string {
+	return issuer + " " + subject
+}
+
+// NewIdentity constructs an Identity.
+func NewIdentity(issuer, subject string, username donelog.OwnerID, createdAt time.Time) (*Identity, error) {
+	if issuer == "" || subject == "" {
+		return nil, errors.New("identity issuer and subject must not be empty")
+	}
+	if username == (donelog.OwnerID{}) {
+		return nil, errors.New("identity username must not be empty")
+	}
+	return &Identity{issuer: issuer, subject: subject, username: username, createdAt: createdAt}, nil
+}
+
+// Username returns the donelog user the subject signs in as.
+func (i *Identity) Username() donelog.OwnerID {
+	return i.username
+}
+
+// RawIdentity is the persisted form of an Identity.
+type RawIdentity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Raw returns the persisted form.
+func (i *Identity) Raw() RawIdentity {
+	return RawIdentity{Issuer: i.issuer, Subject: i.subject, Username: i.username.String(), CreatedAt: i.createdAt}
+}
+
+// RehydrateIdentity rebuilds an Identity from its persisted form.
+func RehydrateIdentity(raw RawIdentity) (*Identity, error) {
+	username, err := donelog.NewOwnerID(raw.Username)
+	if err != nil {
+		return nil, err
+	}
+	return NewIdentity(raw.Issuer, raw.Subject, username, raw.CreatedAt)
+}
+
+// PendingLogin remembers an authorization code flow between the redirect to the provider and the callback.
+// It is keyed by the digest of the state parameter and holds the PKCE verifier and the ID token nonce.
+type PendingLogin struct {
+	key          string
+	codeVerifier string
+	nonce        string
+	expiresAt    time.Time
+}
+
+// PendingLoginKey returns the storage key of the flow started with state.
+func PendingLoginKey(state string) string {
+	return hashSecret(state)
+}
+
+// NewPendingLogin starts a flow that must complete within ttl.
+func NewPendingLogin(state, codeVerifier, nonce string, createdAt time.Time, ttl time.Duration) (*PendingLogin, error) {
+	for _, v := range [][2]string{{"state", state}, {"code verifier", codeVerifier}, {"nonce", nonce}} {
+		if len(v[1]) < minSecretLength {
+			return nil, fmt.Errorf("login %s must be >= %d characters", v[0], minSecretLength)
+		}
+	}
+	if ttl <= 0 {
+		return nil, errors.New("login ttl must be positive")
+	}
+	return &PendingLogin{key: PendingLoginKey(state), codeVerifier: codeVerifier, nonce: nonce, expiresAt: createdAt.Add(ttl)}, nil
+}
+
+// CodeVerifier returns the PKCE verifier sent with the code exchange.
+func (p *PendingLogin) CodeVerifier() string {
+	return p.codeVerifier
+}
+
+// MatchesNonce reports whether the ID token carries the nonce of this flow.
+func (p *PendingLogin) MatchesNonce(nonce string) bool {
+	return constantTimeEqual(nonce, p.nonce)
+}
+
+// Expired reports whether the flow can no longer complete at now.
+func (p *PendingLogin) Expired(now time.Time) bool {
+	return !now.Before(p.expiresAt)
+}
+
+// RawPendingLogin is the persisted form of a PendingLogin.
+type RawPendingLogin struct {
+	Key          string    `json:"key"`
+	CodeVerifier string    `json:"codeVerifier"`
+	Nonce        string    `json:"nonce"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Raw returns the persisted form.
+func (p *PendingLogin) Raw() RawPendingLogin {
+	return RawPendingLogin{Key: p.key, CodeVerifier: p.codeVerifier, Nonce: p.nonce, ExpiresAt: p.expiresAt}
+}
+
+// RehydratePendingLogin rebuilds a PendingLogin from its persisted form.
+func RehydratePendingLogin(raw RawPendingLogin) (*PendingLogin, error) {
+	if raw.Key == "" || raw.CodeVerifier == "" || raw.Nonce == "" {
+		return nil, errors.New("pending login is incomplete")
+	}
+	return &PendingLogin{key: raw.Key, codeVerifier: raw.CodeVerifier, nonce: raw.Nonce, expiresAt: raw.ExpiresAt}, nil
+}
@@ -0,0 +1,143 @@
+package account
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultIterations is the PBKDF2 work factor for new password hashes.
+const DefaultIterations = 600_000
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 256
+	minSaltLength     = 16
+	hashLength        = 32
+	hashScheme        = "pbkdf2-sha256"
+)
+
+var b64 = base64.RawStdEncoding
+
+// ValidatePassword enforces the password policy: 8 to 256 characters.
+func ValidatePassword(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if n > maxPasswordLength {
+		return fmt.Errorf("password must be <= %d characters", maxPasswordLength)
+	}
+	return nil
+}
+
+// PasswordHash is a salted PBKDF2-HMAC-SHA256 digest of a password.
+// Its string form is "pbkdf2-sha256$<iterations>$<salt>$<hash>" with unpadded base64 salt and hash.
+type PasswordHash struct {
+	iterations int
+	salt       []byte
+	hash       []byte
+}
+
+// HashPassword validates password against the policy and derives its hash with salt.
+func HashPassword(password string, salt []byte, iterations int) (PasswordHash, error) {
+	if err := ValidatePassword(password); err != nil {
+		return PasswordHash{}, err
+	}
+	if len(salt) < minSaltLength {
+		return PasswordHash{}, fmt.Errorf("password salt must be >= %d bytes", minSaltLength)
+	}
+	if iterations < 1 {
+		return PasswordHash{}, errors.New("password iterations must be positive")
+	}
+	return PasswordHash{
+		iterations: iterations,
+		salt:       append([]byte(nil), salt...),
+		hash:       pbkdf2SHA256([]byte(password), salt, iterations, hashLength),
+	}, nil
+}
+
+// ParsePasswordHash decodes the string form produced by String.
+func ParsePasswordHash(encoded string) (PasswordHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return PasswordHash{}, errors.New("invalid password hash format")
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return PasswordHash{}, fmt.Errorf("invalid password hash iterations: %s", parts[1])
+	}
+	salt, err := b64.DecodeString(parts[2])
+	if err != nil || len(salt) == 0 {
+		return PasswordHash{}, errors.New("invalid password hash salt")
+	}
+	hash, err := b64.DecodeString(parts[3])
+	if err != nil || len(hash) != hashLength {
+		return PasswordHash{}, errors.New("invalid password hash digest")
+	}
+	return PasswordHash{iterations: iterations, salt: salt, hash: hash}, nil
+}
+
+// Matches reports whether password produces the same hash. The digests are compared in constant time.
+func (h PasswordHash) Matches(password string) bool {
+	if h.iterations < 1 {
+		return false
+	}
+	got := pbkdf2SHA256([]byte(password), h.salt, h.iterations, len(h.hash))
+	return subtle.ConstantTimeCompare(got, h.hash) == 1
+}
+
+// MatchNothing does the work of Matches against a hash with iterations and always fails.
+// Sign-in uses it for unknown usernames so they take as long as wrong passwords.
+func MatchNothing(password string, iterations int) bool {
+	h := PasswordHash{iterations: iterations, salt: make([]byte, minSaltLength), hash: make([]byte, hashLength)}
+	h.Matches(password)
+	return false
+}
+
+// Iterations returns the PBKDF2 work factor the hash was derived with.
+func (h PasswordHash) Iterations() int {
+	return h.iterations
+}
+
+// String returns the encoded form stored in RawAccount.
+func (h PasswordHash) String() string {
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, h.iterations, b64.EncodeToString(h.salt), b64.EncodeToString(h.hash))
+}
+
+// pbkdf2SHA256 implements PBKDF2 (RFC 8018) with HMAC-SHA256 as the PRF.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	size := prf.Size()
+	blocks := (keyLen + size - 1) / size
+
+	out := make([]byte, 0, blocks*size)
+	var counter [4]byte
+	u := make([]byte, size)
+	t := make([]byte, size)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
@@ -0,0 +1,75 @@
+package account
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Scope is a permission granted to a credential.
+type Scope string
+
+const (
+	// ScopeRead allows reading data (GET and HEAD requests).
+	ScopeRead Scope = "read"
+	// ScopeWrite allows changing data. It does not imply ScopeRead.
+	ScopeWrite Scope = "write"
+)
+
+// Scopes is a sorted set of Scope values.
+type Scopes []Scope
+
+// AllScopes is what a password login grants.
+func AllScopes() Scopes {
+	return Scopes{ScopeRead, ScopeWrite}
+}
+
+// ParseScopes validates names and returns them as a sorted set without duplicates.
+func ParseScopes(names []string) (Scopes, error) {
+	seen := map[Scope]bool{}
+	var scopes Scopes
+	for _, name := range names {
+		scope := Scope(strings.TrimSpace(name))
+		if scope != ScopeRead && scope != ScopeWrite {
+			return nil, fmt.Errorf("unknown scope %q (want read or write)", name)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i] < scopes[j] })
+	return scopes, nil
+}
+
+// Has reports whether scope is in the set.
+func (s Scopes) Has(scope Scope) bool {
+	for _, v := range s {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether every scope of other is in the set.
+func (s Scopes) Covers(other Scopes) bool {
+	for _, v := range other {
+		if !s.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Strings returns the scope names.
+func (s Scopes) Strings() []string {
+	names := make([]string, len(s))
+	for i, v := range s {
+		names[i] = string(v)
+	}
+	return names
+}
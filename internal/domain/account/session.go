@@ -0,0 +1,82 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Session is a signed-in browser. The cookie holds a random secret; the store keys the session by its digest,
+// so a leaked store file cannot be replayed as cookies.
+type Session struct {
+	key       string
+	owner     donelog.OwnerID
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// SessionKey returns the storage key of the session whose cookie holds secret.
+func SessionKey(secret string) string {
+	return hashSecret(secret)
+}
+
+// NewSession starts a session for owner that expires ttl after createdAt.
+func NewSession(secret string, owner donelog.OwnerID, createdAt time.Time, ttl time.Duration) (*Session, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("session secret must be >= %d characters", minSecretLength)
+	}
+	if owner == (donelog.OwnerID{}) {
+		return nil, errors.New("session owner must not be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("session ttl must be positive")
+	}
+	return &Session{key: SessionKey(secret), owner: owner, createdAt: createdAt, expiresAt: createdAt.Add(ttl)}, nil
+}
+
+// Key returns the storage key.
+func (s *Session) Key() string {
+	return s.key
+}
+
+// Owner returns the signed-in account.
+func (s *Session) Owner() donelog.OwnerID {
+	return s.owner
+}
+
+// ExpiresAt returns when the session stops being accepted.
+func (s *Session) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+// Expired reports whether the session is no longer valid at now.
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.expiresAt)
+}
+
+// RawSession is the persisted form of a Session.
+type RawSession struct {
+	Key       string    `json:"key"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Raw returns the persisted form.
+func (s *Session) Raw() RawSession {
+	return RawSession{Key: s.key, Owner: s.owner.String(), CreatedAt: s.createdAt, ExpiresAt: s.expiresAt}
+}
+
+// RehydrateSession rebuilds a Session from its persisted form.
+func RehydrateSession(raw RawSession) (*Session, error) {
+	owner, err := donelog.NewOwnerID(raw.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if raw.Key == "" {
+		return nil, errors.New("session key must not be empty")
+	}
+	return &Session{key: raw.Key, owner: owner, createdAt: raw.CreatedAt, expiresAt: raw.ExpiresAt}, nil
+}
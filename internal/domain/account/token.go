@@ -0,0 +1,187 @@
+package account
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+const (
+	// tokenPrefix marks personal API tokens so they are easy to spot in configs and logs.
+	tokenPrefix     = "dlt_"
+	minSecretLength = 32
+	maxTokenName    = 60
+)
+
+var tokenIDPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// TokenID identifies a personal API token. It is the public part of the token.
+type TokenID struct {
+	value string
+}
+
+// NewTokenID validates and creates a TokenID (16 lowercase hex digits).
+func NewTokenID(value string) (TokenID, error) {
+	if !tokenIDPattern.MatchString(value) {
+		return TokenID{}, fmt.Errorf("invalid token id: %s", value)
+	}
+	return TokenID{value: value}, nil
+}
+
+// String returns the identifier value.
+func (id TokenID) String() string {
+	return id.value
+}
+
+// FormatToken returns the plaintext token "dlt_<id>_<secret>" handed to the user once.
+func FormatToken(id TokenID, secret string) string {
+	return tokenPrefix + id.value + "_" + secret
+}
+
+// ParseToken splits a plaintext token into its ID and secret.
+func ParseToken(plaintext string) (TokenID, string, error) {
+	rest, ok := strings.CutPrefix(plaintext, tokenPrefix)
+	if !ok {
+		return TokenID{}, "", errors.New("malformed API token")
+	}
+	rawID, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(secret) < minSecretLength {
+		return TokenID{}, "", errors.New("malformed API token")
+	}
+	id, err := NewTokenID(rawID)
+	if err != nil {
+		return TokenID{}, "", errors.New("malformed API token")
+	}
+	return id, secret, nil
+}
+
+// hashSecret returns the hex SHA-256 of a random secret. Secrets carry enough entropy that
+// a fast hash suffices; only the digest is stored.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Token is a personal API token: a named, scoped credential for scripts.
+type Token struct {
+	id         TokenID
+	owner      donelog.OwnerID
+	name       string
+	scopes     Scopes
+	secretHash string
+	createdAt  time.Time
+}
+
+// NewToken constructs a Token for secret. Only the secret's digest is kept.
+func NewToken(id TokenID, owner donelog.OwnerID, name string, scopes Scopes, secret string, createdAt time.Time) (*Token, error) {
+	if id == (TokenID{}) {
+		return nil, errors.New("token id must not be empty")
+	}
+	if owner == (donelog.OwnerID{}) {
+		return nil, errors.New("token owner must not be empty")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("token name must not be empty")
+	}
+	if len([]rune(name)) > maxTokenName || strings.ContainsAny(name, "\r\n") {
+		return nil, fmt.Errorf("token name must be one line of <= %d characters", maxTokenName)
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("token needs at least one scope")
+	}
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("token secret must be >= %d characters", minSecretLength)
+	}
+	return &Token{
+		id:         id,
+		owner:      owner,
+		name:       name,
+		scopes:     append(Scopes(nil), scopes...),
+		secretHash: hashSecret(secret),
+		createdAt:  createdAt,
+	}, nil
+}
+
+// ID returns the token identifier.
+func (t *Token) ID() TokenID {
+	return t.id
+}
+
+// Owner returns the account the token acts as.
+func (t *Token) Owner() donelog.OwnerID {
+	return t.owner
+}
+
+// Name returns the label the user gave the token.
+func (t *Token) Name() string {
+	return t.name
+}
+
+// Scopes returns what the token is allowed to do.
+func (t *Token) Scopes() Scopes {
+	return append(Scopes(nil), t.scopes...)
+}
+
+// CreatedAt returns when the token was issued.
+func (t *Token) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// Verify reports whether secret is the token's secret.
+func (t *Token) Verify(secret string) bool {
+	return constantTimeEqual(hashSecret(secret), t.secretHash)
+}
+
+// RawToken is the persisted form of a Token.
+type RawToken struct {
+	ID         string    `json:"id"`
+	Owner      string    `json:"owner"`
+	Name       string    `json:"name"`
+	Scopes     []string  `json:"scopes"`
+	SecretHash string    `json:"secretHash"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Raw returns the persisted form.
+func (t *Token) Raw() RawToken {
+	return RawToken{
+		ID:         t.id.String(),
+		Owner:      t.owner.String(),
+		Name:       t.name,
+		Scopes:     t.scopes.Strings(),
+		SecretHash: t.secretHash,
+		CreatedAt:  t.createdAt,
+	}
+}
+
+// RehydrateToken rebuilds a Token from its persisted form.
+func RehydrateToken(raw RawToken) (*Token, error) {
+	id, err := NewTokenID(raw.ID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := donelog.NewOwnerID(raw.Owner)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := ParseScopes(raw.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.SecretHash) != sha256.Size*2 {
+		return nil, errors.New("invalid token secret hash")
+	}
+	return &Token{id: id, owner: owner, name: raw.Name, scopes: scopes, secretHash: raw.SecretHash, createdAt: raw.CreatedAt}, nil
+}
+
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,80 @@
+package donelog
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditAction names the kind of change recorded for a DONELOG.
+type AuditAction string
+
+const (
+	AuditActionCreated  AuditAction = "created"
+	AuditActionUpdated  AuditAction = "updated"
+	AuditActionDeleted  AuditAction = "deleted"
+	AuditActionRestored AuditAction = "restored"
+	AuditActionPurged   AuditAction = "purged"
+	AuditActionReverted AuditAction = "reverted"
+)
+
+// FieldChange is a single field-level difference between two DONELOG states.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// AuditEntry records who changed which DONELOG and when.
+type AuditEntry struct {
+	DoneLogID  string
+	Action     AuditAction
+	Actor      string
+	RequestID  string
+	RecordedAt time.Time
+	Changes    []FieldChange
+	// Owner is stamped by the repository from the request context; empty in a single-user store.
+	Owner string
+}
+
+// DiffRawDoneLog lists the fields that differ between before and after.
+// A nil side is treated as "does not exist", so every field shows up as a change.
+func DiffRawDoneLog(before, after *RawDoneLog) []FieldChange {
+	b := auditFields(before)
+	a := auditFields(after)
+
+	var changes []FieldChange
+	for i, field := range auditFieldNames {
+		if b[i] == a[i] {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Before: b[i], After: a[i]})
+	}
+	return changes
+}
+
+var auditFieldNames = []string{"title", "trackId", "categoryId", "tags", "note", "links", "count", "occurredOn", "trashedAt"}
+
+func auditFields(raw *RawDoneLog) []string {
+	if raw == nil {
+		return make([]string, len(auditFieldNames))
+	}
+	return []string{
+		raw.Title,
+		raw.TrackID,
+		raw.CategoryID,
+		strings.Join(raw.Tags, ","),
+		raw.Note,
+		strings.Join(raw.Links, " "),
+		strconv.Itoa(raw.Count),
+		OccurredOnFromTime(raw.OccurredOn).String(),
+		formatTrashedAt(raw.TrashedAt),
+	}
+}
+
+func formatTrashedAt(at *time.Time) string {
+	if at == nil {
+		return ""
+	}
+	return at.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,92 @@
+package donelog
+
+import "errors"
+
+// Category is the aggregate used to classify DONELOGs across Tracks.
+type Category struct {
+	id        CategoryID
+	name      string
+	sortOrder int
+	active    bool
+}
+
+// NewCategory constructs an active Category.
+func NewCategory(id CategoryID, name string, sortOrder int) (*Category, error) {
+	if id == (CategoryID{}) {
+		return nil, errors.New("category id must not be empty")
+	}
+	validName, err := newName("category", name)
+	if err != nil {
+		return nil, err
+	}
+	return &Category{id: id, name: validName, sortOrder: sortOrder, active: true}, nil
+}
+
+// Rename changes the display name.
+func (c *Category) Rename(name string) error {
+	validName, err := newName("category", name)
+	if err != nil {
+		return err
+	}
+	c.name = validName
+	return nil
+}
+
+// Archive deactivates the Category. Past DONELOGs keep referencing it.
+func (c *Category) Archive() {
+	c.active = false
+}
+
+// Activate re-enables an archived Category.
+func (c *Category) Activate() {
+	c.active = true
+}
+
+// ID returns the Category identifier.
+func (c *Category) ID() CategoryID {
+	return c.id
+}
+
+// Name returns the display name.
+func (c *Category) Name() string {
+	return c.name
+}
+
+// SortOrder returns the display order.
+func (c *Category) SortOrder() int {
+	return c.sortOrder
+}
+
+// Active reports whether new DONELOGs may reference the Category.
+func (c *Category) Active() bool {
+	return c.active
+}
+
+// RawCategory represents persisted primitive values of a Category.
+type RawCategory struct {
+	ID        string
+	Name      string
+	SortOrder int
+	Active    bool
+	// Owner is stamped by the repository from the request context; empty in a single-user store.
+	Owner string
+}
+
+// RehydrateCategory rebuilds a Category from persisted primitives.
+func RehydrateCategory(raw RawCategory) (*Category, error) {
+	id, err := NewCategoryID(raw.ID)
+	if err != nil {
+		return nil, err
+	}
+	category, err := NewCategory(id, raw.Name, raw.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+	category.active = raw.Active
+	return category, nil
+}
+
+// Raw flattens the Category into persisted primitives.
+func (c *Category) Raw() RawCategory {
+	return RawCategory{ID: c.id.String(), Name: c.name, SortOrder: c.sortOrder, Active: c.active}
+}
@@ -0,0 +1,216 @@
+package donelog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ComparisonUnit is the bucket size of a year-over-year comparison.
+type ComparisonUnit string
+
+const (
+	ComparisonUnitMonth   ComparisonUnit = "month"
+	ComparisonUnitQuarter ComparisonUnit = "quarter"
+)
+
+// ParseComparisonUnit validates a comparison unit name.
+func ParseComparisonUnit(value string) (ComparisonUnit, error) {
+	switch ComparisonUnit(value) {
+	case ComparisonUnitMonth, ComparisonUnitQuarter:
+		return ComparisonUnit(value), nil
+	}
+	return "", fmt.Errorf("comparison unit must be month or quarter, got %q", value)
+}
+
+// SummaryGroup selects which reference a comparison is broken down by.
+type SummaryGroup string
+
+const (
+	SummaryGroupCategory SummaryGroup = "category"
+	SummaryGroupTrack    SummaryGroup = "track"
+)
+
+// ParseSummaryGroup validates a grouping name.
+func ParseSummaryGroup(value string) (SummaryGroup, error) {
+	switch SummaryGroup(value) {
+	case SummaryGroupCategory, SummaryGroupTrack:
+		return SummaryGroup(value), nil
+	}
+	return "", fmt.Errorf("group must be category or track, got %q", value)
+}
+
+func (g SummaryGroup) key(log *DoneLog) string {
+	if g == SummaryGroupTrack {
+		return log.TrackID().String()
+	}
+	return log.CategoryID().String()
+}
+
+// ComparisonRow pairs one bucket with the same bucket of the previous year.
+type ComparisonRow struct {
+	label         string
+	previousLabel string
+	current       int
+	previous      int
+}
+
+// Label returns the bucket of the compared year, e.g. 2024-03 or 2024-Q1.
+func (r ComparisonRow) Label() string {
+	return r.label
+}
+
+// PreviousLabel returns the aligned bucket of the previous year, e.g. 2023-03.
+func (r ComparisonRow) PreviousLabel() string {
+	return r.previousLabel
+}
+
+// Current returns the total of the compared year's bucket.
+func (r ComparisonRow) Current() int {
+	return r.current
+}
+
+// Previous returns the total of the previous year's bucket.
+func (r ComparisonRow) Previous() int {
+	return r.previous
+}
+
+// Change returns Current minus Previous.
+func (r ComparisonRow) Change() int {
+	return r.current - r.previous
+}
+
+// ChangePercent returns the change relative to Previous, rounded to one decimal.
+// ok is false when Previous is zero and the percentage is undefined.
+func (r ComparisonRow) ChangePercent() (percent float64, ok bool) {
+	if r.previous == 0 {
+		return 0, false
+	}
+	return math.Round(float64(r.Change())*1000/float64(r.previous)) / 10, true
+}
+
+// ComparisonGroup is the comparison for one TrackID or CategoryID.
+type ComparisonGroup struct {
+	key   string
+	rows  []ComparisonRow
+	total ComparisonRow
+}
+
+// Key returns the TrackID or CategoryID of the group.
+func (g ComparisonGroup) Key() string {
+	return g.key
+}
+
+// Rows returns one row per bucket in calendar order.
+func (g ComparisonGroup) Rows() []ComparisonRow {
+	return append([]ComparisonRow(nil), g.rows...)
+}
+
+// Total compares the whole years.
+func (g ComparisonGroup) Total() ComparisonRow {
+	return g.total
+}
+
+// YearOverYear is the read-only result of CompareYearOverYear.
+type YearOverYear struct {
+	year    int
+	unit    ComparisonUnit
+	groupBy SummaryGroup
+	groups  []ComparisonGroup
+}
+
+// Year returns the compared year; the baseline is Year-1.
+func (y YearOverYear) Year() int {
+	return y.year
+}
+
+// Unit returns the bucket size.
+func (y YearOverYear) Unit() ComparisonUnit {
+	return y.unit
+}
+
+// GroupBy returns the grouping reference.
+func (y YearOverYear) GroupBy() SummaryGroup {
+	return y.groupBy
+}
+
+// Groups returns one group per TrackID or CategoryID seen in either year, ordered by key.
+func (y YearOverYear) Groups() []ComparisonGroup {
+	return append([]ComparisonGroup(nil), y.groups...)
+}
+
+// CompareYearOverYear aligns each month or quarter of year with the same bucket of the previous year,
+// per TrackID or CategoryID. Trashed DONELOGs and DONELOGs outside the two years are ignored.
+func (LogSummaryService) CompareYearOverYear(year int, unit ComparisonUnit, groupBy SummaryGroup, logs []*DoneLog) (YearOverYear, error) {
+	if _, err := ParseComparisonUnit(string(unit)); err != nil {
+		return YearOverYear{}, err
+	}
+	if _, err := ParseSummaryGroup(string(groupBy)); err != nil {
+		return YearOverYear{}, err
+	}
+	if year < 2 || year > 9999 {
+		return YearOverYear{}, fmt.Errorf("year must be between 2 and 9999")
+	}
+
+	label := monthLabel
+	buckets := 12
+	if unit == ComparisonUnitQuarter {
+		label = quarterLabel
+		buckets = 4
+	}
+
+	// totals[key][bucket index] for the compared (0) and previous (1) year.
+	type yearTotals [2][]int
+	totals := map[string]*yearTotals{}
+	for _, log := range logs {
+		if log.IsTrashed() {
+			continue
+		}
+		t := log.OccurredOn().Time()
+		side := year - t.Year()
+		if side != 0 && side != 1 {
+			continue
+		}
+		key := groupBy.key(log)
+		if totals[key] == nil {
+			totals[key] = &yearTotals{make([]int, buckets), make([]int, buckets)}
+		}
+		index := int(t.Month()) - 1
+		if unit == ComparisonUnitQuarter {
+			index /= 3
+		}
+		totals[key][side][index] += log.Count().Int()
+	}
+
+	keys := make([]string, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := YearOverYear{year: year, unit: unit, groupBy: groupBy, groups: make([]ComparisonGroup, 0, len(keys))}
+	for _, key := range keys {
+		group := ComparisonGroup{
+			key:   key,
+			total: ComparisonRow{label: fmt.Sprintf("%04d", year), previousLabel: fmt.Sprintf("%04d", year-1)},
+		}
+		for i := 0; i < buckets; i++ {
+			month := time.Month(i + 1)
+			if unit == ComparisonUnitQuarter {
+				month = time.Month(i*3 + 1)
+			}
+			row := ComparisonRow{
+				label:         label(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)),
+				previousLabel: label(time.Date(year-1, month, 1, 0, 0, 0, 0, time.UTC)),
+				current:       totals[key][0][i],
+				previous:      totals[key][1][i],
+			}
+			group.rows = append(group.rows, row)
+			group.total.current += row.current
+			group.total.previous += row.previous
+		}
+		result.groups = append(result.groups, group)
+	}
+	return result, nil
+}
@@ -1,13 +1,24 @@
 package donelog
 
+import (
+	"errors"
+	"time"
+)
+
 // DoneLog is the aggregate root that represents a single record of work done.
 type DoneLog struct {
 	id         DoneLogID
 	title      Title
 	trackID    TrackID
 	categoryID CategoryID
+	tags       TagSet
+	note       Note
+	links      Links
 	count      Count
 	occurredOn OccurredOn
+	trashedAt  time.Time
+	// team is set when the DONELOG was recorded against a team Track.
+	team *TeamID
 }
 
 // NewDoneLog constructs a DONELOG aggregate.
@@ -16,6 +27,7 @@ func NewDoneLog(
 	title Title,
 	trackID TrackID,
 	categoryID CategoryID,
+	tags TagSet,
 	count Count,
 	occurredOn OccurredOn,
 ) (*DoneLog, error) {
@@ -24,19 +36,74 @@ func NewDoneLog(
 		title:      title,
 		trackID:    trackID,
 		categoryID: categoryID,
+		tags:       tags,
 		count:      count,
 		occurredOn: occurredOn,
 	}, nil
 }
 
 // Update overwrites mutable fields of DONELOG.
-func (d *DoneLog) Update(title Title, categoryID CategoryID, count Count, occurredOn OccurredOn) {
+func (d *DoneLog) Update(title Title, categoryID CategoryID, tags TagSet, count Count, occurredOn OccurredOn) {
 	d.title = title
 	d.categoryID = categoryID
+	d.tags = tags
 	d.count = count
 	d.occurredOn = occurredOn
 }
 
+// SetTags replaces the Tags, leaving the other fields as they are.
+func (d *DoneLog) SetTags(tags TagSet) {
+	d.tags = tags
+}
+
+// RecordFor attributes the DONELOG to the Team whose Track it was recorded against.
+// Team summaries count it for that Team only, whatever its TrackID resolves to later.
+func (d *DoneLog) RecordFor(team TeamID) {
+	d.team = &team
+}
+
+// Team returns the Team the DONELOG counts for, or nil for a personal DONELOG.
+func (d *DoneLog) Team() *TeamID {
+	return d.team
+}
+
+// Annotate replaces the Note and Links, leaving the other fields as they are.
+func (d *DoneLog) Annotate(note Note, links Links) {
+	d.note = note
+	d.links = links
+}
+
+// Trash moves the DONELOG into the trash. Trashed logs are excluded from lists and summaries.
+func (d *DoneLog) Trash(at time.Time) error {
+	if d.IsTrashed() {
+		return errors.New("DONELOG is already in the trash")
+	}
+	if at.IsZero() {
+		return errors.New("trashed time must not be zero")
+	}
+	d.trashedAt = at
+	return nil
+}
+
+// Restore takes the DONELOG out of the trash.
+func (d *DoneLog) Restore() error {
+	if !d.IsTrashed() {
+		return errors.New("DONELOG is not in the trash")
+	}
+	d.trashedAt = time.Time{}
+	return nil
+}
+
+// IsTrashed reports whether the DONELOG is in the trash.
+func (d *DoneLog) IsTrashed() bool {
+	return !d.trashedAt.IsZero()
+}
+
+// TrashedAt returns when the DONELOG was trashed (zero when it is not).
+func (d *DoneLog) TrashedAt() time.Time {
+	return d.trashedAt
+}
+
 // Title returns the current title.
 func (d *DoneLog) Title() Title {
 	return d.title
@@ -52,6 +119,21 @@ func (d *DoneLog) CategoryID() CategoryID {
 	return d.categoryID
 }
 
+// Tags returns the Tags, which may be empty.
+func (d *DoneLog) Tags() TagSet {
+	return d.tags
+}
+
+// Note returns the Markdown note, which may be empty.
+func (d *DoneLog) Note() Note {
+	return d.note
+}
+
+// Links returns the attached URLs, which may be empty.
+func (d *DoneLog) Links() Links {
+	return d.links
+}
+
 // Count returns the number of things done.
 func (d *DoneLog) Count() Count {
 	return d.count
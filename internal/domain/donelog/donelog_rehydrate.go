@@ -8,8 +8,20 @@ type RawDoneLog struct {
 	Title      string
 	TrackID    string
 	CategoryID string
+	// Tags are ordered and unique; nil when the DONELOG is untagged.
+	Tags []string
+	// Note is sanitized Markdown; empty when there is none.
+	Note string
+	// Links are http(s) URLs in the order given; nil when there are none.
+	Links      []string
 	Count      int
 	OccurredOn time.Time
+	// TrashedAt is nil unless the DONELOG has been moved to the trash.
+	TrashedAt *time.Time
+	// Owner is stamped by the repository from the request context; empty in a single-user store.
+	Owner string
+	// Team is set when the DONELOG was recorded against a team Track.
+	Team string
 }
 
 // RehydrateDoneLog rebuilds a DoneLog aggregate from persisted primitives.
@@ -30,11 +42,66 @@ func RehydrateDoneLog(raw RawDoneLog) (*DoneLog, error) {
 	if err != nil {
 		return nil, err
 	}
+	tags, err := ParseTagSet(raw.Tags)
+	if err != nil {
+		return nil, err
+	}
+	note, err := NewNote(raw.Note)
+	if err != nil {
+		return nil, err
+	}
+	links, err := ParseLinks(raw.Links)
+	if err != nil {
+		return nil, err
+	}
 	count, err := NewCount(raw.Count)
 	if err != nil {
 		return nil, err
 	}
 	occurredOn := OccurredOnFromTime(raw.OccurredOn)
 
-	return NewDoneLog(id, title, trackID, categoryID, count, occurredOn)
+	log, err := NewDoneLog(id, title, trackID, categoryID, tags, count, occurredOn)
+	if err != nil {
+		return nil, err
+	}
+	log.Annotate(note, links)
+	if raw.Team != "" {
+		team, err := NewTeamID(raw.Team)
+		if err != nil {
+			return nil, err
+		}
+		log.RecordFor(team)
+	}
+	if raw.TrashedAt != nil {
+		if err := log.Trash(*raw.TrashedAt); err != nil {
+			return nil, err
+		}
+	}
+	return log, nil
+}
+
+// Raw flattens the aggregate into persisted primitives.
+func (d *DoneLog) Raw() RawDoneLog {
+	var trashedAt *time.Time
+	if d.IsTrashed() {
+		at := d.trashedAt
+		trashedAt = &at
+	}
+	var team string
+	if d.team != nil {
+		team = d.team.String()
+	}
+	return RawDoneLog{
+		ID:         d.id.String(),
+		Title:      d.title.String(),
+		TrackID:    d.trackID.String(),
+		CategoryID: d.categoryID.String(),
+		Tags:       d.tags.Strings(),
+		Note:       d.note.String(),
+		Links:      d.links.Strings(),
+		Count:      d.count.Int(),
+		OccurredOn: d.occurredOn.Time(),
+		TrashedAt:  trashedAt,
+		Team:       team,
+	}
 }
@@ -1,6 +1,7 @@
 package donelog
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -58,7 +59,7 @@ func TestNewDoneLog(t *testing.T) {
 			count, _ := NewCount(tt.count)
 			occurredOn, _ := NewOccurredOn(tt.occurred)
 
-			log, err := NewDoneLog(id, title, trackID, categoryID, count, occurredOn)
+			log, err := NewDoneLog(id, title, trackID, categoryID, TagSet{}, count, occurredOn)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("NewDoneLog error = %v, wantErr = %v", err, tt.wantErr)
 			}
@@ -77,10 +78,12 @@ func TestDoneLogUpdate(t *testing.T) {
 		name      string
 		newTitle  string
 		newCat    string
+		newTags   []string
 		newCount  int
 		newDate   string
 		wantTitle string
 		wantCat   string
+		wantTags  []string
 		wantCount int
 		wantDate  string
 	}{
@@ -88,10 +91,12 @@ func TestDoneLogUpdate(t *testing.T) {
 			name:      "updates mutable fields",
 			newTitle:  "Updated Title",
 			newCat:    "cat_new",
+			newTags:   []string{"perf", "go", "perf"},
 			newCount:  5,
 			newDate:   "2024-05-03",
 			wantTitle: "Updated Title",
 			wantCat:   "cat_new",
+			wantTags:  []string{"go", "perf"},
 			wantCount: 5,
 			wantDate:  "2024-05-03",
 		},
@@ -106,7 +111,7 @@ func TestDoneLogUpdate(t *testing.T) {
 			count, _ := NewCount(2)
 			occurredOn, _ := NewOccurredOn("2024-05-01")
 
-			log, err := NewDoneLog(id, title, trackID, categoryID, count, occurredOn)
+			log, err := NewDoneLog(id, title, trackID, categoryID, TagSet{}, count, occurredOn)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -116,7 +121,12 @@ func TestDoneLogUpdate(t *testing.T) {
 			newCount, _ := NewCount(tt.newCount)
 			newDate, _ := NewOccurredOn(tt.newDate)
 
-			log.Update(newTitle, newCategory, newCount, newDate)
+			newTags, err := ParseTagSet(tt.newTags)
+			if err != nil {
+				t.Fatalf("tags: %v", err)
+			}
+
+			log.Update(newTitle, newCategory, newTags, newCount, newDate)
 
 			if log.Title().String() != tt.wantTitle {
 				t.Fatalf("expected title %s, got %s", tt.wantTitle, log.Title())
@@ -124,6 +134,9 @@ func TestDoneLogUpdate(t *testing.T) {
 			if log.CategoryID().String() != tt.wantCat {
 				t.Fatalf("expected category %s, got %s", tt.wantCat, log.CategoryID())
 			}
+			if got := strings.Join(log.Tags().Strings(), ","); got != strings.Join(tt.wantTags, ",") {
+				t.Fatalf("expected tags %v, got %s", tt.wantTags, got)
+			}
 			if log.Count().Int() != tt.wantCount {
 				t.Fatalf("expected count %d, got %d", tt.wantCount, log.Count().Int())
 			}
@@ -147,11 +160,40 @@ func TestRehydrateDoneLog(t *testing.T) {
 				Title:      "Rehydrated",
 				TrackID:    "track_sample",
 				CategoryID: "cat_default",
+				Tags:       []string{"go", "performance"},
+				Note:       "## Learned\n- escape analysis",
+				Links:      []string{"https://go.dev/blog/pprof"},
 				Count:      3,
 				OccurredOn: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
 			},
 			wantErr: false,
 		},
+		{
+			name: "NG: invalid tag",
+			raw: RawDoneLog{
+				ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+				Title:      "Rehydrated",
+				TrackID:    "track_sample",
+				CategoryID: "cat_default",
+				Tags:       []string{"go", "Not A Slug"},
+				Count:      3,
+				OccurredOn: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "NG: invalid link",
+			raw: RawDoneLog{
+				ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+				Title:      "Rehydrated",
+				TrackID:    "track_sample",
+				CategoryID: "cat_default",
+				Links:      []string{"javascript:alert(1)"},
+				Count:      3,
+				OccurredOn: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
 		{
 			name: "NG: invalid raw data",
 			raw: RawDoneLog{
@@ -181,6 +223,106 @@ func TestRehydrateDoneLog(t *testing.T) {
 			if log.OccurredOn().String() != "2024-05-01" {
 				t.Fatalf("expected occurredOn %s, got %s", "2024-05-01", log.OccurredOn().String())
 			}
+			if got := log.Raw().Tags; strings.Join(got, ",") != strings.Join(tt.raw.Tags, ",") {
+				t.Fatalf("expected tags %v, got %v", tt.raw.Tags, got)
+			}
+			if got := log.Raw(); got.Note != tt.raw.Note || strings.Join(got.Links, " ") != strings.Join(tt.raw.Links, " ") {
+				t.Fatalf("expected note %q and links %v, got %q and %v", tt.raw.Note, tt.raw.Links, got.Note, got.Links)
+			}
+		})
+	}
+}
+
+func TestDoneLogTrash(t *testing.T) {
+	trashedAt := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		trashedAt   *time.Time
+		wantErr     bool
+		wantTrashed bool
+	}{
+		{"OK: trashes active log", nil, false, true},
+		{"NG: already trashed", &trashedAt, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := RehydrateDoneLog(RawDoneLog{
+				ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+				Title:      "Initial",
+				TrackID:    "track_sample",
+				CategoryID: "cat_default",
+				Count:      2,
+				OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				TrashedAt:  tt.trashedAt,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err = log.Trash(trashedAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if log.IsTrashed() != tt.wantTrashed {
+				t.Fatalf("IsTrashed = %v, want %v", log.IsTrashed(), tt.wantTrashed)
+			}
+			if raw := log.Raw(); raw.TrashedAt == nil || !raw.TrashedAt.Equal(trashedAt) {
+				t.Fatalf("expected raw trashedAt %v, got %v", trashedAt, raw.TrashedAt)
+			}
+
+			if err := log.Restore(); err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			if log.IsTrashed() || log.Raw().TrashedAt != nil {
+				t.Fatalf("expected restored log")
+			}
+		})
+	}
+}
+
+func TestPurgePolicy(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		trashedAt time.Time
+		want      bool
+	}{
+		{"expired", now.Add(-8 * 24 * time.Hour), true},
+		{"exactly at cutoff", now.Add(-7 * 24 * time.Hour), true},
+		{"within retention", now.Add(-24 * time.Hour), false},
+	}
+
+	policy, err := NewPurgePolicy(7 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewPurgePolicy(0); err == nil {
+		t.Fatalf("expected error for zero retention")
+	}
+	if zero := (PurgePolicy{}); zero.Retention() != DefaultTrashRetention || !zero.Cutoff(now).Equal(now.Add(-DefaultTrashRetention)) {
+		t.Fatalf("zero policy must fall back to the default retention, got %v", zero.Retention())
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := mustDoneLogID(t, "01HYR1X5C9XM9P6H7K71M9QAHX")
+			title, _ := NewTitle("Initial")
+			trackID, _ := NewTrackID("track_sample")
+			categoryID, _ := NewCategoryID("cat_default")
+			count, _ := NewCount(2)
+			occurredOn, _ := NewOccurredOn("2024-05-01")
+			log, _ := NewDoneLog(id, title, trackID, categoryID, TagSet{}, count, occurredOn)
+
+			if policy.ShouldPurge(log, now) {
+				t.Fatalf("active log must never be purged")
+			}
+			_ = log.Trash(tt.trashedAt)
+			if got := policy.ShouldPurge(log, now); got != tt.want {
+				t.Fatalf("ShouldPurge = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
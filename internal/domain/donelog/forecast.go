@@ -0,0 +1,197 @@
+package donelog
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultForecastWindowDays is how many recent days the pace is measured over when no window is given.
+const DefaultForecastWindowDays = 28
+
+// maxForecastWindowDays bounds the pace window.
+const maxForecastWindowDays = 365
+
+// forecastZ is the two-sided 80% normal quantile used for the pace band.
+const forecastZ = 1.2816
+
+// ForecastStatus tells whether a projected completion meets the deadline.
+type ForecastStatus string
+
+const (
+	ForecastStatusAchieved ForecastStatus = "achieved"
+	ForecastStatusOnTrack  ForecastStatus = "on_track"
+	ForecastStatusAtRisk   ForecastStatus = "at_risk"
+	ForecastStatusBehind   ForecastStatus = "behind"
+	ForecastStatusStalled  ForecastStatus = "stalled"
+)
+
+// Forecast projects when a cumulative target will be reached at the recent pace.
+type Forecast struct {
+	done      int
+	remaining int
+	window    int
+	pace      float64
+	paceLow   float64
+	paceHigh  float64
+	projected *time.Time
+	earliest  *time.Time
+	latest    *time.Time
+	deadline  *time.Time
+	status    ForecastStatus
+}
+
+// Done is the Count accumulated inside the counted period up to today.
+func (f Forecast) Done() int {
+	return f.done
+}
+
+// Remaining is the Count still needed; zero once the target is reached.
+func (f Forecast) Remaining() int {
+	return f.remaining
+}
+
+// WindowDays is the number of days, ending today, the pace was measured over.
+func (f Forecast) WindowDays() int {
+	return f.window
+}
+
+// DailyPace is the mean daily Count over the window, rounded to two decimals.
+func (f Forecast) DailyPace() float64 {
+	return f.pace
+}
+
+// DailyPaceBand is the 80% confidence band of the mean daily pace, rounded to two decimals.
+// The low end is never negative.
+func (f Forecast) DailyPaceBand() (low, high float64) {
+	return f.paceLow, f.paceHigh
+}
+
+// ProjectedDate is the day the target is reached at DailyPace, or nil when achieved or stalled.
+func (f Forecast) ProjectedDate() *time.Time {
+	return f.projected
+}
+
+// EarliestDate and LatestDate bound ProjectedDate using the pace band.
+// LatestDate is nil when the low end of the band is zero and no finish can be promised.
+func (f Forecast) EarliestDate() *time.Time {
+	return f.earliest
+}
+
+func (f Forecast) LatestDate() *time.Time {
+	return f.latest
+}
+
+// Deadline returns the deadline the forecast was checked against, if any.
+func (f Forecast) Deadline() *time.Time {
+	return f.deadline
+}
+
+// DaysLate is how many days ProjectedDate falls after the deadline; zero when on time or without a deadline.
+func (f Forecast) DaysLate() int {
+	if f.deadline == nil || f.projected == nil || !f.projected.After(*f.deadline) {
+		return 0
+	}
+	return daysBetween(*f.deadline, *f.projected)
+}
+
+// Status returns the forecast status.
+func (f Forecast) Status() ForecastStatus {
+	return f.status
+}
+
+// NewForecast sums the logs inside counted up to today and projects the rest at the mean daily pace
+// of the window days ending today. Trashed logs are ignored.
+//
+// The status is achieved once the target is reached, stalled when the window has no activity,
+// behind when the projected date is after the deadline, at_risk when only the pessimistic date is,
+// and on_track otherwise (always, when there is no deadline).
+func NewForecast(target Count, counted Period, logs []*DoneLog, today OccurredOn, window int, deadline *OccurredOn) (Forecast, error) {
+	if target.Int() < minCountValue {
+		return Forecast{}, fmt.Errorf("forecast target must be >= %d", minCountValue)
+	}
+	if window < 1 || window > maxForecastWindowDays {
+		return Forecast{}, fmt.Errorf("forecast window must be between 1 and %d days", maxForecastWindowDays)
+	}
+
+	day := today.Time()
+	windowStart := day.AddDate(0, 0, -(window - 1))
+	daily := make([]int, window)
+	f := Forecast{window: window}
+	for _, log := range logs {
+		if log == nil || log.IsTrashed() {
+			continue
+		}
+		t := log.OccurredOn().Time()
+		if t.After(day) {
+			continue
+		}
+		if counted.Contains(log.OccurredOn()) {
+			f.done += log.Count().Int()
+		}
+		if !t.Before(windowStart) {
+			daily[daysBetween(windowStart, t)] += log.Count().Int()
+		}
+	}
+	if f.done < target.Int() {
+		f.remaining = target.Int() - f.done
+	}
+	if deadline != nil {
+		d := deadline.Time()
+		f.deadline = &d
+	}
+
+	mean, stderr := meanAndStandardError(daily)
+	f.pace = round2(mean)
+	f.paceLow = round2(math.Max(0, mean-forecastZ*stderr))
+	f.paceHigh = round2(mean + forecastZ*stderr)
+
+	switch {
+	case f.remaining == 0:
+		f.status = ForecastStatusAchieved
+		return f, nil
+	case mean == 0:
+		f.status = ForecastStatusStalled
+		return f, nil
+	}
+	f.projected = projectDate(day, f.remaining, mean)
+	f.earliest = projectDate(day, f.remaining, mean+forecastZ*stderr)
+	if low := mean - forecastZ*stderr; low > 0 {
+		f.latest = projectDate(day, f.remaining, low)
+	}
+
+	switch {
+	case f.deadline == nil:
+		f.status = ForecastStatusOnTrack
+	case f.projected.After(*f.deadline):
+		f.status = ForecastStatusBehind
+	case f.latest == nil || f.latest.After(*f.deadline):
+		f.status = ForecastStatusAtRisk
+	default:
+		f.status = ForecastStatusOnTrack
+	}
+	return f, nil
+}
+
+// projectDate returns the day remaining is covered when pace is added every day after today.
+func projectDate(today time.Time, remaining int, pace float64) *time.Time {
+	d := today.AddDate(0, 0, int(math.Ceil(float64(remaining)/pace)))
+	return &d
+}
+
+// meanAndStandardError returns the sample mean and the standard error of the mean.
+func meanAndStandardError(values []int) (mean, stderr float64) {
+	n := float64(len(values))
+	for _, v := range values {
+		mean += float64(v)
+	}
+	mean /= n
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (float64(v) - mean) * (float64(v) - mean)
+	}
+	return mean, math.Sqrt(sq/(n-1)) / math.Sqrt(n)
+}
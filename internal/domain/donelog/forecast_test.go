@@ -0,0 +1,115 @@
+package donelog
+
+import "testing"
+
+func TestNewForecast(t *testing.T) {
+	day := func(value string) OccurredOn {
+		o, _ := NewOccurredOn(value)
+		return o
+	}
+	format := func(d interface{ Format(string) string }) string {
+		return d.Format("2006-01-02")
+	}
+	counted := mustPeriod(t, "2024-05-01", "2024-12-31")
+	target, _ := NewCount(100)
+
+	steady := []*DoneLog{
+		mustLog(t, "cat_reading", 10, "2024-05-01", false),
+		mustLog(t, "cat_reading", 10, "2024-04-30", false), // before the counted period
+		mustLog(t, "cat_reading", 10, "2024-05-07", false),
+		mustLog(t, "cat_reading", 10, "2024-05-08", false),
+		mustLog(t, "cat_reading", 10, "2024-05-09", false),
+		mustLog(t, "cat_reading", 10, "2024-05-10", false),
+		mustLog(t, "cat_reading", 99, "2024-05-10", true),
+		mustLog(t, "cat_reading", 99, "2024-05-11", false), // after today
+	}
+	uneven := []*DoneLog{
+		mustLog(t, "cat_reading", 10, "2024-05-01", false),
+		mustLog(t, "cat_reading", 20, "2024-05-08", false),
+		mustLog(t, "cat_reading", 20, "2024-05-10", false),
+	}
+
+	tests := []struct {
+		name         string
+		logs         []*DoneLog
+		today        string
+		window       int
+		deadline     string
+		wantErr      bool
+		wantDone     int
+		wantPace     float64
+		wantBand     [2]float64
+		wantDates    [3]string // projected, earliest, latest
+		wantDaysLate int
+		wantStatus   ForecastStatus
+	}{
+		{
+			name: "OK: steady pace meets the deadline", logs: steady, window: 4, deadline: "2024-05-20",
+			wantDone: 50, wantPace: 10, wantBand: [2]float64{10, 10},
+			wantDates: [3]string{"2024-05-15", "2024-05-15", "2024-05-15"}, wantStatus: ForecastStatusOnTrack,
+		},
+		{
+			name: "OK: projected past the deadline", logs: steady, window: 4, deadline: "2024-05-13",
+			wantDone: 50, wantPace: 10, wantBand: [2]float64{10, 10},
+			wantDates: [3]string{"2024-05-15", "2024-05-15", "2024-05-15"}, wantDaysLate: 2, wantStatus: ForecastStatusBehind,
+		},
+		{
+			name: "OK: noisy pace puts the pessimistic date past the deadline", logs: uneven, window: 4, deadline: "2024-05-20",
+			wantDone: 50, wantPace: 10, wantBand: [2]float64{2.6, 17.4},
+			wantDates: [3]string{"2024-05-15", "2024-05-13", "2024-05-30"}, wantStatus: ForecastStatusAtRisk,
+		},
+		{
+			name: "OK: no deadline", logs: uneven, window: 4,
+			wantDone: 50, wantPace: 10, wantBand: [2]float64{2.6, 17.4},
+			wantDates: [3]string{"2024-05-15", "2024-05-13", "2024-05-30"}, wantStatus: ForecastStatusOnTrack,
+		},
+		{
+			name: "OK: stalled when the window is empty", logs: uneven, today: "2024-05-09", window: 1, deadline: "2024-05-20",
+			wantDone: 30, wantStatus: ForecastStatusStalled,
+		},
+		{
+			name: "OK: achieved", logs: append(uneven, mustLog(t, "cat_reading", 60, "2024-05-02", false)), window: 4,
+			wantDone: 110, wantPace: 10, wantBand: [2]float64{2.6, 17.4}, wantStatus: ForecastStatusAchieved,
+		},
+		{name: "NG: window too short", logs: steady, window: 0, wantErr: true},
+		{name: "NG: window too long", logs: steady, window: 366, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline *OccurredOn
+			if tt.deadline != "" {
+				d := day(tt.deadline)
+				deadline = &d
+			}
+			today := day("2024-05-10")
+			if tt.today != "" {
+				today = day(tt.today)
+			}
+			f, err := NewForecast(target, counted, tt.logs, today, tt.window, deadline)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			low, high := f.DailyPaceBand()
+			if f.Done() != tt.wantDone || f.DailyPace() != tt.wantPace || low != tt.wantBand[0] || high != tt.wantBand[1] {
+				t.Fatalf("done/pace/band = %d/%v/%v..%v", f.Done(), f.DailyPace(), low, high)
+			}
+			if f.Status() != tt.wantStatus || f.DaysLate() != tt.wantDaysLate {
+				t.Fatalf("status/daysLate = %s/%d", f.Status(), f.DaysLate())
+			}
+			if tt.wantDates[0] == "" {
+				if f.ProjectedDate() != nil || f.EarliestDate() != nil || f.LatestDate() != nil {
+					t.Fatalf("expected no dates, got %v %v %v", f.ProjectedDate(), f.EarliestDate(), f.LatestDate())
+				}
+				return
+			}
+			got := [3]string{format(f.ProjectedDate()), format(f.EarliestDate()), format(f.LatestDate())}
+			if got != tt.wantDates {
+				t.Fatalf("dates = %v, want %v", got, tt.wantDates)
+			}
+		})
+	}
+}
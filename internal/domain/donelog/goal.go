@@ -0,0 +1,269 @@
+package donelog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// GoalID identifies a Goal aggregate.
+type GoalID struct {
+	value string
+}
+
+// NewGoalID validates and creates a GoalID.
+func NewGoalID(value string) (GoalID, error) {
+	if value == "" {
+		return GoalID{}, errors.New("goal id must not be empty")
+	}
+	if !ulidPattern.MatchString(value) {
+		return GoalID{}, fmt.Errorf("invalid goal id: %s", value)
+	}
+	return GoalID{value: value}, nil
+}
+
+// String returns the string form.
+func (id GoalID) String() string {
+	return id.value
+}
+
+// Goal is a target Count to reach within a Period, for either one Track or one Category.
+type Goal struct {
+	id         GoalID
+	name       string
+	trackID    *TrackID
+	categoryID *CategoryID
+	period     Period
+	target     Count
+}
+
+// NewGoal constructs a Goal. Exactly one of trackID and categoryID must be set.
+func NewGoal(id GoalID, name string, trackID *TrackID, categoryID *CategoryID, period Period, target Count) (*Goal, error) {
+	if id == (GoalID{}) {
+		return nil, errors.New("goal id must not be empty")
+	}
+	validName, err := newName("goal", name)
+	if err != nil {
+		return nil, err
+	}
+	if (trackID == nil) == (categoryID == nil) {
+		return nil, errors.New("goal must target exactly one of track or category")
+	}
+	if target.Int() < minCountValue {
+		return nil, fmt.Errorf("goal target must be >= %d", minCountValue)
+	}
+	return &Goal{
+		id:         id,
+		name:       validName,
+		trackID:    trackID,
+		categoryID: categoryID,
+		period:     period,
+		target:     target,
+	}, nil
+}
+
+// ID returns the Goal identifier.
+func (g *Goal) ID() GoalID {
+	return g.id
+}
+
+// Name returns the display name.
+func (g *Goal) Name() string {
+	return g.name
+}
+
+// TrackID returns the targeted Track, or nil for a Category goal.
+func (g *Goal) TrackID() *TrackID {
+	return g.trackID
+}
+
+// CategoryID returns the targeted Category, or nil for a Track goal.
+func (g *Goal) CategoryID() *CategoryID {
+	return g.categoryID
+}
+
+// Period returns the inclusive period the target applies to.
+func (g *Goal) Period() Period {
+	return g.period
+}
+
+// Target returns the Count to reach.
+func (g *Goal) Target() Count {
+	return g.target
+}
+
+// GoalStatus summarizes where a Goal stands on a given day.
+type GoalStatus string
+
+const (
+	GoalStatusNotStarted GoalStatus = "not_started"
+	GoalStatusInProgress GoalStatus = "in_progress"
+	GoalStatusAchieved   GoalStatus = "achieved"
+	GoalStatusMissed     GoalStatus = "missed"
+)
+
+// GoalProgress is the state of a Goal as of one day.
+type GoalProgress struct {
+	done      int
+	remaining int
+	percent   float64
+	daysLeft  int
+	pace      float64
+	status    GoalStatus
+}
+
+// Done is the sum of matching DONELOG counts inside the period.
+func (p GoalProgress) Done() int {
+	return p.done
+}
+
+// Remaining is how much is still needed; zero once the target is reached.
+func (p GoalProgress) Remaining() int {
+	return p.remaining
+}
+
+// Percent is Done relative to the target, rounded to one decimal. It exceeds 100 when overachieved.
+func (p GoalProgress) Percent() float64 {
+	return p.percent
+}
+
+// DaysLeft counts the remaining days of the period including today.
+func (p GoalProgress) DaysLeft() int {
+	return p.daysLeft
+}
+
+// RequiredDailyPace is Remaining spread over DaysLeft, or zero when nothing is left to do or no days remain.
+func (p GoalProgress) RequiredDailyPace() float64 {
+	return p.pace
+}
+
+// Status returns the Goal status.
+func (p GoalProgress) Status() GoalStatus {
+	return p.status
+}
+
+// Progress sums the logs that match the Goal and reports the state as of today.
+// Trashed logs and logs outside the period are ignored.
+func (g *Goal) Progress(logs []*DoneLog, today OccurredOn) GoalProgress {
+	done := 0
+	for _, log := range logs {
+		if log == nil || log.IsTrashed() || !g.period.Contains(log.OccurredOn()) {
+			continue
+		}
+		if g.trackID != nil && log.TrackID() != *g.trackID {
+			continue
+		}
+		if g.categoryID != nil && log.CategoryID() != *g.categoryID {
+			continue
+		}
+		done += log.Count().Int()
+	}
+
+	target := g.target.Int()
+	p := GoalProgress{
+		done:    done,
+		percent: math.Round(float64(done)*1000/float64(target)) / 10,
+	}
+	if done < target {
+		p.remaining = target - done
+	}
+
+	start, end, day := g.period.Start(), g.period.End(), today.Time()
+	switch {
+	case day.After(end):
+		p.daysLeft = 0
+	case day.Before(start):
+		p.daysLeft = daysBetween(start, end) + 1
+	default:
+		p.daysLeft = daysBetween(day, end) + 1
+	}
+	if p.remaining > 0 && p.daysLeft > 0 {
+		p.pace = math.Round(float64(p.remaining)*100/float64(p.daysLeft)) / 100
+	}
+
+	switch {
+	case p.remaining == 0:
+		p.status = GoalStatusAchieved
+	case day.After(end):
+		p.status = GoalStatusMissed
+	case day.Before(start):
+		p.status = GoalStatusNotStarted
+	default:
+		p.status = GoalStatusInProgress
+	}
+	return p
+}
+
+// daysBetween counts calendar days from a to b, ignoring the time of day.
+func daysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// RawGoal represents persisted primitive values of a Goal.
+type RawGoal struct {
+	ID         string
+	Name       string
+	TrackID    string
+	CategoryID string
+	StartDate  time.Time
+	EndDate    time.Time
+	Target     int
+	// Owner is stamped by the repository from the request context; empty in a single-user store.
+	Owner string
+}
+
+// RehydrateGoal rebuilds a Goal from persisted primitives.
+func RehydrateGoal(raw RawGoal) (*Goal, error) {
+	id, err := NewGoalID(raw.ID)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		trackID    *TrackID
+		categoryID *CategoryID
+	)
+	if raw.TrackID != "" {
+		t, err := NewTrackID(raw.TrackID)
+		if err != nil {
+			return nil, err
+		}
+		trackID = &t
+	}
+	if raw.CategoryID != "" {
+		c, err := NewCategoryID(raw.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		categoryID = &c
+	}
+	period, err := NewPeriod(OccurredOnFromTime(raw.StartDate), OccurredOnFromTime(raw.EndDate))
+	if err != nil {
+		return nil, err
+	}
+	target, err := NewCount(raw.Target)
+	if err != nil {
+		return nil, err
+	}
+	return NewGoal(id, raw.Name, trackID, categoryID, period, target)
+}
+
+// Raw flattens the Goal into persisted primitives.
+func (g *Goal) Raw() RawGoal {
+	raw := RawGoal{
+		ID:        g.id.String(),
+		Name:      g.name,
+		StartDate: g.period.Start(),
+		EndDate:   g.period.End(),
+		Target:    g.target.Int(),
+	}
+	if g.trackID != nil {
+		raw.TrackID = g.trackID.String()
+	}
+	if g.categoryID != nil {
+		raw.CategoryID = g.categoryID.String()
+	}
+	return raw
+}
@@ -0,0 +1,115 @@
+package donelog
+
+import "testing"
+
+func TestGoalProgress(t *testing.T) {
+	period := func(start, end string) Period {
+		s, _ := NewOccurredOn(start)
+		e, _ := NewOccurredOn(end)
+		p, err := NewPeriod(s, e)
+		if err != nil {
+			t.Fatalf("period: %v", err)
+		}
+		return p
+	}
+	day := func(value string) OccurredOn {
+		o, _ := NewOccurredOn(value)
+		return o
+	}
+	categoryID, _ := NewCategoryID("cat_reading")
+	trackID, _ := NewTrackID("track_sample")
+	otherTrack, _ := NewTrackID("track_other")
+	id, _ := NewGoalID("01HYR1X5C9XM9P6H7K71M9QAHX")
+	target, _ := NewCount(100)
+
+	logs := []*DoneLog{
+		mustLog(t, "cat_reading", 20, "2024-05-01", false),
+		mustLog(t, "cat_reading", 10, "2024-05-02", false),
+		mustLog(t, "cat_reading", 50, "2024-05-03", true),
+		mustLog(t, "cat_other", 5, "2024-05-03", false),
+		mustLog(t, "cat_reading", 40, "2024-06-01", false),
+	}
+
+	tests := []struct {
+		name          string
+		trackID       *TrackID
+		categoryID    *CategoryID
+		today         string
+		logs          []*DoneLog
+		wantDone      int
+		wantRemaining int
+		wantPercent   float64
+		wantDaysLeft  int
+		wantPace      float64
+		wantStatus    GoalStatus
+	}{
+		{
+			name: "category goal in progress", categoryID: &categoryID, today: "2024-05-10", logs: logs,
+			wantDone: 30, wantRemaining: 70, wantPercent: 30, wantDaysLeft: 22, wantPace: 3.18, wantStatus: GoalStatusInProgress,
+		},
+		{
+			name: "track goal counts every category", trackID: &trackID, today: "2024-05-31", logs: logs,
+			wantDone: 35, wantRemaining: 65, wantPercent: 35, wantDaysLeft: 1, wantPace: 65, wantStatus: GoalStatusInProgress,
+		},
+		{
+			name: "other track sees nothing", trackID: &otherTrack, today: "2024-04-20", logs: logs,
+			wantDone: 0, wantRemaining: 100, wantPercent: 0, wantDaysLeft: 31, wantPace: 3.23, wantStatus: GoalStatusNotStarted,
+		},
+		{
+			name: "period over", categoryID: &categoryID, today: "2024-06-02", logs: logs,
+			wantDone: 30, wantRemaining: 70, wantPercent: 30, wantDaysLeft: 0, wantPace: 0, wantStatus: GoalStatusMissed,
+		},
+		{
+			name: "overachieved", trackID: &trackID, today: "2024-05-15",
+			logs:     []*DoneLog{mustLog(t, "cat_reading", 120, "2024-05-05", false)},
+			wantDone: 120, wantRemaining: 0, wantPercent: 120, wantDaysLeft: 17, wantPace: 0, wantStatus: GoalStatusAchieved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal, err := NewGoal(id, "May reading", tt.trackID, tt.categoryID, period("2024-05-01", "2024-05-31"), target)
+			if err != nil {
+				t.Fatalf("NewGoal: %v", err)
+			}
+			p := goal.Progress(tt.logs, day(tt.today))
+			if p.Done() != tt.wantDone || p.Remaining() != tt.wantRemaining || p.Percent() != tt.wantPercent {
+				t.Errorf("done/remaining/percent = %d/%d/%v, want %d/%d/%v",
+					p.Done(), p.Remaining(), p.Percent(), tt.wantDone, tt.wantRemaining, tt.wantPercent)
+			}
+			if p.DaysLeft() != tt.wantDaysLeft || p.RequiredDailyPace() != tt.wantPace {
+				t.Errorf("daysLeft/pace = %d/%v, want %d/%v", p.DaysLeft(), p.RequiredDailyPace(), tt.wantDaysLeft, tt.wantPace)
+			}
+			if p.Status() != tt.wantStatus {
+				t.Errorf("status = %s, want %s", p.Status(), tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewGoalRequiresExactlyOneTarget(t *testing.T) {
+	id, _ := NewGoalID("01HYR1X5C9XM9P6H7K71M9QAHX")
+	trackID, _ := NewTrackID("track_sample")
+	categoryID, _ := NewCategoryID("cat_reading")
+	start, _ := NewOccurredOn("2024-05-01")
+	period, _ := NewPeriod(start, start)
+	target, _ := NewCount(1)
+
+	if _, err := NewGoal(id, "goal", nil, nil, period, target); err == nil {
+		t.Error("expected error without track or category")
+	}
+	if _, err := NewGoal(id, "goal", &trackID, &categoryID, period, target); err == nil {
+		t.Error("expected error with both track and category")
+	}
+	goal, err := NewGoal(id, "goal", &trackID, nil, period, target)
+	if err != nil {
+		t.Fatalf("NewGoal: %v", err)
+	}
+	back, err := RehydrateGoal(goal.Raw())
+	if err != nil {
+		t.Fatalf("RehydrateGoal: %v", err)
+	}
+	if back.Raw() != goal.Raw() {
+		t.Errorf("round trip = %+v, want %+v", back.Raw(), goal.Raw())
+	}
+}
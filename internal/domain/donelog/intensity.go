@@ -0,0 +1,62 @@
+package donelog
+
+import "sort"
+
+// IntensityLevels is the number of non-empty heatmap levels. Level 0 is reserved for zero.
+const IntensityLevels = 4
+
+// IntensityScale maps daily totals to heatmap levels 0..IntensityLevels.
+// Thresholds are quantiles of the non-zero totals, so the scale adapts to each dataset.
+type IntensityScale struct {
+	thresholds []float64
+}
+
+// NewIntensityScale builds the scale from the observed totals. Zero totals are ignored.
+func NewIntensityScale(totals []int) IntensityScale {
+	var nonZero []int
+	for _, t := range totals {
+		if t > 0 {
+			nonZero = append(nonZero, t)
+		}
+	}
+	if len(nonZero) == 0 {
+		return IntensityScale{}
+	}
+	sort.Ints(nonZero)
+
+	thresholds := make([]float64, 0, IntensityLevels-1)
+	for i := 1; i < IntensityLevels; i++ {
+		thresholds = append(thresholds, quantile(nonZero, float64(i)/IntensityLevels))
+	}
+	return IntensityScale{thresholds: thresholds}
+}
+
+// quantile interpolates linearly between the closest ranks of sorted values.
+func quantile(sorted []int, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return float64(sorted[lower])
+	}
+	frac := pos - float64(lower)
+	return float64(sorted[lower]) + frac*float64(sorted[lower+1]-sorted[lower])
+}
+
+// Thresholds returns the inclusive upper bounds of levels 1..IntensityLevels-1.
+// It is empty when there were no non-zero totals.
+func (s IntensityScale) Thresholds() []float64 {
+	return append([]float64(nil), s.thresholds...)
+}
+
+// Level returns 0 for zero totals and 1..IntensityLevels otherwise.
+func (s IntensityScale) Level(total int) int {
+	if total <= 0 {
+		return 0
+	}
+	for i, upper := range s.thresholds {
+		if float64(total) <= upper {
+			return i + 1
+		}
+	}
+	return IntensityLevels
+}
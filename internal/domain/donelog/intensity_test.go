@@ -0,0 +1,53 @@
+package donelog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntensityScale(t *testing.T) {
+	tests := []struct {
+		name           string
+		totals         []int
+		wantThresholds []float64
+		levels         map[int]int
+	}{
+		{
+			name:           "quartiles of non-zero totals",
+			totals:         []int{0, 0, 1, 2, 3, 4, 5, 6, 7, 8},
+			wantThresholds: []float64{2.75, 4.5, 6.25},
+			levels:         map[int]int{0: 0, 1: 1, 2: 1, 3: 2, 4: 2, 6: 3, 7: 4, 100: 4},
+		},
+		{
+			name:           "all equal totals stay on the lowest level",
+			totals:         []int{5, 5, 5},
+			wantThresholds: []float64{5, 5, 5},
+			levels:         map[int]int{5: 1, 6: 4},
+		},
+		{
+			name:           "two active days span the whole scale",
+			totals:         []int{7, 0, 15},
+			wantThresholds: []float64{9, 11, 13},
+			levels:         map[int]int{7: 1, 15: 4},
+		},
+		{
+			name:   "no activity",
+			totals: []int{0, 0},
+			levels: map[int]int{0: 0, 3: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scale := NewIntensityScale(tt.totals)
+			if got := scale.Thresholds(); !reflect.DeepEqual(got, tt.wantThresholds) {
+				t.Fatalf("thresholds = %v, want %v", got, tt.wantThresholds)
+			}
+			for total, want := range tt.levels {
+				if got := scale.Level(total); got != want {
+					t.Errorf("Level(%d) = %d, want %d", total, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package donelog
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CategoryTotal is the Count a member recorded under one of their Categories.
+type CategoryTotal struct {
+	categoryID CategoryID
+	count      Count
+}
+
+// CategoryID returns the member's Category.
+func (c CategoryTotal) CategoryID() CategoryID {
+	return c.categoryID
+}
+
+// Count returns the total recorded under the Category.
+func (c CategoryTotal) Count() Count {
+	return c.count
+}
+
+// MemberStanding is one member's totals over a Period.
+type MemberStanding struct {
+	member     OwnerID
+	rank       int
+	total      Count
+	doneLogs   int
+	categories []CategoryTotal
+}
+
+// Member returns the member.
+func (s MemberStanding) Member() OwnerID {
+	return s.member
+}
+
+// Rank returns the place on the leaderboard, starting at 1. It is 0 for a member who opted out.
+func (s MemberStanding) Rank() int {
+	return s.rank
+}
+
+// Total returns the sum of Count over the Period. It may be zero.
+func (s MemberStanding) Total() Count {
+	return s.total
+}
+
+// DoneLogs returns how many DONELOGs were counted.
+func (s MemberStanding) DoneLogs() int {
+	return s.doneLogs
+}
+
+// Categories returns the totals per Category, largest first, then by CategoryID.
+func (s MemberStanding) Categories() []CategoryTotal {
+	return append([]CategoryTotal(nil), s.categories...)
+}
+
+// Leaderboard is the read-only ranking of a Team's members.
+type Leaderboard struct {
+	period  Period
+	entries []MemberStanding
+	hidden  int
+}
+
+// Period returns the ranked period.
+func (b Leaderboard) Period() Period {
+	return b.period
+}
+
+// Entries returns the visible members by rank, tied members by name.
+func (b Leaderboard) Entries() []MemberStanding {
+	return append([]MemberStanding(nil), b.entries...)
+}
+
+// Hidden returns how many members opted out and were left off.
+func (b Leaderboard) Hidden() int {
+	return b.hidden
+}
+
+// RankMembers ranks the members of team by their total Count within period. Members with equal totals
+// share a rank and the following rank is skipped (1, 2, 2, 4). Members without DONELOGs are ranked with
+// a zero total; members who opted out are only counted in Hidden.
+// logs holds each member's DONELOGs; trashed ones and ones outside period are ignored.
+func (LogSummaryService) RankMembers(team *Team, period Period, logs map[OwnerID][]*DoneLog) Leaderboard {
+	board := Leaderboard{period: period}
+	for _, m := range team.Members() {
+		if team.OptedOut(m.Member) {
+			board.hidden++
+			continue
+		}
+		board.entries = append(board.entries, standingOf(m.Member, period, logs[m.Member]))
+	}
+	// Members are ordered by name, so a stable sort keeps ties in name order.
+	sort.SliceStable(board.entries, func(i, j int) bool {
+		return board.entries[i].total.Int() > board.entries[j].total.Int()
+	})
+	for i := range board.entries {
+		if i > 0 && board.entries[i].total == board.entries[i-1].total {
+			board.entries[i].rank = board.entries[i-1].rank
+		} else {
+			board.entries[i].rank = i + 1
+		}
+	}
+	return board
+}
+
+// CategoryComparison puts two members' totals for the same CategoryID side by side.
+// Categories are personal, so the same ID may carry a different name for each member.
+type CategoryComparison struct {
+	categoryID CategoryID
+	member     Count
+	other      Count
+}
+
+// CategoryID returns the compared CategoryID.
+func (c CategoryComparison) CategoryID() CategoryID {
+	return c.categoryID
+}
+
+// Member returns the first member's total.
+func (c CategoryComparison) Member() Count {
+	return c.member
+}
+
+// Other returns the second member's total.
+func (c CategoryComparison) Other() Count {
+	return c.other
+}
+
+// Difference returns Member minus Other.
+func (c CategoryComparison) Difference() int {
+	return c.member.Int() - c.other.Int()
+}
+
+// MemberComparison is the read-only result of CompareMembers.
+type MemberComparison struct {
+	period     Period
+	member     MemberStanding
+	other      MemberStanding
+	categories []CategoryComparison
+}
+
+// Period returns the compared period.
+func (c MemberComparison) Period() Period {
+	return c.period
+}
+
+// Member returns the first member's standing.
+func (c MemberComparison) Member() MemberStanding {
+	return c.member
+}
+
+// Other returns the second member's standing.
+func (c MemberComparison) Other() MemberStanding {
+	return c.other
+}
+
+// Difference returns the first member's total minus the second's.
+func (c MemberComparison) Difference() int {
+	return c.member.total.Int() - c.other.total.Int()
+}
+
+// Categories returns every CategoryID either member recorded under, ordered by ID.
+func (c MemberComparison) Categories() []CategoryComparison {
+	return append([]CategoryComparison(nil), c.categories...)
+}
+
+// CompareMembers compares two members of team over period, with their leaderboard ranks.
+// Whether a member may be compared despite opting out is the caller's decision.
+func (s LogSummaryService) CompareMembers(team *Team, period Period, member, other OwnerID, logs map[OwnerID][]*DoneLog) (MemberComparison, error) {
+	for _, m := range []OwnerID{member, other} {
+		if _, ok := team.RoleOf(m); !ok {
+			return MemberComparison{}, fmt.Errorf("%s is not a member of team %s", m.String(), team.ID().String())
+		}
+	}
+	ranks := map[OwnerID]int{}
+	for _, entry := range s.RankMembers(team, period, logs).entries {
+		ranks[entry.member] = entry.rank
+	}
+	result := MemberComparison{
+		period: period,
+		member: standingOf(member, period, logs[member]),
+		other:  standingOf(other, period, logs[other]),
+	}
+	result.member.rank = ranks[member]
+	result.other.rank = ranks[other]
+
+	byID := map[CategoryID]*CategoryComparison{}
+	for side, standing := range []MemberStanding{result.member, result.other} {
+		for _, c := range standing.categories {
+			row := byID[c.categoryID]
+			if row == nil {
+				row = &CategoryComparison{categoryID: c.categoryID}
+				byID[c.categoryID] = row
+			}
+			if side == 0 {
+				row.member = c.count
+			} else {
+				row.other = c.count
+			}
+		}
+	}
+	for _, row := range byID {
+		result.categories = append(result.categories, *row)
+	}
+	sort.Slice(result.categories, func(i, j int) bool {
+		return result.categories[i].categoryID.String() < result.categories[j].categoryID.String()
+	})
+	return result, nil
+}
+
+// standingOf totals member's DONELOGs within period. The rank is left to the caller.
+func standingOf(member OwnerID, period Period, logs []*DoneLog) MemberStanding {
+	standing := MemberStanding{member: member}
+	total := 0
+	byCategory := map[CategoryID]int{}
+	for _, log := range logs {
+		if log.IsTrashed() || !period.Contains(log.OccurredOn()) {
+			continue
+		}
+		total += log.Count().Int()
+		byCategory[log.CategoryID()] += log.Count().Int()
+		standing.doneLogs++
+	}
+	// Totals of valid DONELOGs are never negative.
+	standing.total, _ = newCountFromNonNegative(total)
+	for id, sum := range byCategory {
+		count, _ := newCountFromNonNegative(sum)
+		standing.categories = append(standing.categories, CategoryTotal{categoryID: id, count: count})
+	}
+	sort.Slice(standing.categories, func(i, j int) bool {
+		a, b := standing.categories[i], standing.categories[j]
+		if a.count != b.count {
+			return a.count.Int() > b.count.Int()
+		}
+		return a.categoryID.String() < b.categoryID.String()
+	})
+	return standing
+}
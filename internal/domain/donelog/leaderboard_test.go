@@ -0,0 +1,69 @@
+package donelog
+
+import "testing"
+
+func TestRankMembers(t *testing.T) {
+	member := func(name string) OwnerID {
+		id, _ := NewOwnerID(name)
+		return id
+	}
+	alice, bob, carol, dave, erin := member("alice"), member("bob"), member("carol"), member("dave"), member("erin")
+	id, _ := NewTeamID("club")
+	team, _ := NewTeam(id, "Club", alice)
+	for _, m := range []OwnerID{bob, carol, dave, erin} {
+		_ = team.Invite(m, RoleEditor)
+		_ = team.Accept(m)
+	}
+	if err := team.SetLeaderboardOptOut(erin, true); err != nil {
+		t.Fatalf("opt out: %v", err)
+	}
+	if err := team.SetLeaderboardOptOut(member("zed"), true); err == nil {
+		t.Fatal("strangers cannot opt out")
+	}
+	restored, err := RehydrateTeam(team.Raw())
+	if err != nil || !restored.OptedOut(erin) || restored.OptedOut(bob) {
+		t.Fatalf("opt-out must survive rehydration: %+v, %v", restored, err)
+	}
+
+	may := mustPeriod(t, "2024-05-01", "2024-05-31")
+	logs := map[OwnerID][]*DoneLog{
+		alice: {mustLog(t, "cat_run", 5, "2024-05-01", false), mustLog(t, "cat_swim", 3, "2024-05-02", false)},
+		bob:   {mustLog(t, "cat_run", 10, "2024-05-03", false), mustLog(t, "cat_run", 10, "2024-04-30", false)},
+		carol: {mustLog(t, "cat_run", 8, "2024-05-04", false), mustLog(t, "cat_run", 50, "2024-05-05", true)},
+		erin:  {mustLog(t, "cat_run", 99, "2024-05-06", false)},
+	}
+
+	board := LogSummaryService{}.RankMembers(team, may, logs)
+	want := []struct {
+		member OwnerID
+		rank   int
+		total  int
+	}{{bob, 1, 10}, {alice, 2, 8}, {carol, 2, 8}, {dave, 4, 0}}
+	entries := board.Entries()
+	if board.Hidden() != 1 || len(entries) != len(want) {
+		t.Fatalf("unexpected board: %+v", board)
+	}
+	for i, w := range want {
+		if entries[i].Member() != w.member || entries[i].Rank() != w.rank || entries[i].Total().Int() != w.total {
+			t.Fatalf("entry %d = %+v, want %+v", i, entries[i], w)
+		}
+	}
+	if cats := entries[1].Categories(); len(cats) != 2 || cats[0].CategoryID().String() != "cat_run" || cats[1].Count().Int() != 3 {
+		t.Fatalf("unexpected category breakdown: %+v", cats)
+	}
+
+	cmp, err := LogSummaryService{}.CompareMembers(team, may, alice, erin, logs)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if cmp.Member().Rank() != 2 || cmp.Other().Rank() != 0 || cmp.Difference() != -91 {
+		t.Fatalf("unexpected comparison: %+v", cmp)
+	}
+	cats := cmp.Categories()
+	if len(cats) != 2 || cats[0].CategoryID().String() != "cat_run" || cats[0].Difference() != -94 || cats[1].Other().Int() != 0 {
+		t.Fatalf("unexpected category comparison: %+v", cats)
+	}
+	if _, err := (LogSummaryService{}).CompareMembers(team, may, alice, member("zed"), logs); err == nil {
+		t.Fatal("comparing with a stranger must fail")
+	}
+}
@@ -0,0 +1,45 @@
+package donelog
+
+// SummaryPoint is one bucket of a LOGSUMMARY series (a day, a month, ...).
+type SummaryPoint struct {
+	label string
+	count Count
+}
+
+// Label returns the bucket label such as 2024-05-01 or 2024-05.
+func (p SummaryPoint) Label() string {
+	return p.label
+}
+
+// Count returns the bucket total. It may be zero.
+func (p SummaryPoint) Count() Count {
+	return p.count
+}
+
+// LogSummary is the read-only aggregation result over a set of DONELOGs.
+type LogSummary struct {
+	categoryID *CategoryID
+	period     Period
+	totalCount Count
+	points     []SummaryPoint
+}
+
+// CategoryID returns the Category filter, or nil for all categories.
+func (s LogSummary) CategoryID() *CategoryID {
+	return s.categoryID
+}
+
+// Period returns the summarized period.
+func (s LogSummary) Period() Period {
+	return s.period
+}
+
+// TotalCount returns the sum over the whole period. It may be zero.
+func (s LogSummary) TotalCount() Count {
+	return s.totalCount
+}
+
+// Points returns the zero-filled series in chronological order.
+func (s LogSummary) Points() []SummaryPoint {
+	return append([]SummaryPoint(nil), s.points...)
+}
@@ -0,0 +1,41 @@
+package donelog
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTrashRetention is how long the zero PurgePolicy keeps trashed DONELOGs.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// PurgePolicy decides when a trashed DONELOG may be removed permanently.
+// The zero value keeps trashed logs for DefaultTrashRetention rather than purging them at once.
+type PurgePolicy struct {
+	retention time.Duration
+}
+
+// NewPurgePolicy creates a policy that keeps trashed logs for the given retention period.
+func NewPurgePolicy(retention time.Duration) (PurgePolicy, error) {
+	if retention <= 0 {
+		return PurgePolicy{}, errors.New("trash retention must be > 0")
+	}
+	return PurgePolicy{retention: retention}, nil
+}
+
+// Retention returns how long trashed logs are kept.
+func (p PurgePolicy) Retention() time.Duration {
+	if p.retention <= 0 {
+		return DefaultTrashRetention
+	}
+	return p.retention
+}
+
+// Cutoff returns the instant before which trashed logs are due for purging.
+func (p PurgePolicy) Cutoff(now time.Time) time.Time {
+	return now.Add(-p.Retention())
+}
+
+// ShouldPurge reports whether the DONELOG has stayed in the trash longer than the retention period.
+func (p PurgePolicy) ShouldPurge(log *DoneLog, now time.Time) bool {
+	return log.IsTrashed() && !log.TrashedAt().After(p.Cutoff(now))
+}
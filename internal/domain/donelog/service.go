@@ -0,0 +1,198 @@
+package donelog
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// maxDailySummaryDays keeps daily series to roughly three months.
+	maxDailySummaryDays = 92
+	// maxWeeklySummaryWeeks keeps weekly series to two years.
+	maxWeeklySummaryWeeks = 106
+	// maxMonthlySummaryMonths keeps monthly series to two years.
+	maxMonthlySummaryMonths = 24
+	// maxQuarterlySummaryQuarters keeps quarterly series to five years.
+	maxQuarterlySummaryQuarters = 20
+	// maxYearlySummaryYears keeps yearly series to ten years.
+	maxYearlySummaryYears = 10
+)
+
+// WeekStart is the first day of a summary week.
+type WeekStart string
+
+const (
+	WeekStartMonday WeekStart = "monday"
+	WeekStartSunday WeekStart = "sunday"
+)
+
+// WeekStartSetting is the settings key holding an owner's default WeekStart.
+const WeekStartSetting = "week_start"
+
+// ParseWeekStart validates a week start name.
+func ParseWeekStart(value string) (WeekStart, error) {
+	switch WeekStart(value) {
+	case WeekStartMonday, WeekStartSunday:
+		return WeekStart(value), nil
+	}
+	return "", fmt.Errorf("week start must be monday or sunday, got %q", value)
+}
+
+// startOfWeek returns the first day of the week containing t.
+func (w WeekStart) startOfWeek(t time.Time) time.Time {
+	first := time.Monday
+	if w == WeekStartSunday {
+		first = time.Sunday
+	}
+	back := (int(t.Weekday()) - int(first) + 7) % 7
+	y, m, d := t.Date()
+	return time.Date(y, m, d-back, 0, 0, 0, 0, t.Location())
+}
+
+// label names the week after the ISO week of its Monday, e.g. 2026-W42.
+// A Sunday-start week shares six days with that ISO week, so the label stays unambiguous.
+// The ISO week-numbering year is used, so 2024-12-30 belongs to 2025-W01.
+func (w WeekStart) label(t time.Time) string {
+	monday := w.startOfWeek(t)
+	if w == WeekStartSunday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	year, week := monday.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// LogSummaryService aggregates DONELOGs into LogSummary values.
+// It has no side effects on the aggregates it reads.
+type LogSummaryService struct{}
+
+// bucketFunc maps a date to the label of the bucket it belongs to.
+type bucketFunc func(t time.Time) string
+
+// SummarizeByDay totals counts per day. Days without DONELOGs are reported as zero.
+func (LogSummaryService) SummarizeByDay(categoryID *CategoryID, period Period, logs []*DoneLog) (LogSummary, error) {
+	days := int(period.End().Sub(period.Start()).Hours()/24) + 1
+	if days > maxDailySummaryDays {
+		return LogSummary{}, fmt.Errorf("daily summary period must be <= %d days", maxDailySummaryDays)
+	}
+
+	var labels []string
+	for d := period.Start(); !d.After(period.End()); d = d.AddDate(0, 0, 1) {
+		labels = append(labels, dayLabel(d))
+	}
+	return summarize(categoryID, period, logs, labels, dayLabel)
+}
+
+// SummarizeByWeek totals counts per week. Every week overlapping period gets a bucket, zero-filled;
+// only DONELOGs inside period are counted, so the first and last weeks may be partial.
+func (LogSummaryService) SummarizeByWeek(categoryID *CategoryID, period Period, logs []*DoneLog, weekStart WeekStart) (LogSummary, error) {
+	if _, err := ParseWeekStart(string(weekStart)); err != nil {
+		return LogSummary{}, err
+	}
+	first := weekStart.startOfWeek(period.Start())
+	if weeks := int(period.End().Sub(first).Hours()/24)/7 + 1; weeks > maxWeeklySummaryWeeks {
+		return LogSummary{}, fmt.Errorf("weekly summary period must be <= %d weeks", maxWeeklySummaryWeeks)
+	}
+	var labels []string
+	for w := first; !w.After(period.End()); w = w.AddDate(0, 0, 7) {
+		labels = append(labels, weekStart.label(w))
+	}
+	return summarize(categoryID, period, logs, labels, weekStart.label)
+}
+
+// SummarizeByMonth totals counts per calendar month. Months without DONELOGs are reported as zero.
+func (LogSummaryService) SummarizeByMonth(categoryID *CategoryID, period Period, logs []*DoneLog) (LogSummary, error) {
+	first := firstOfMonth(period.Start())
+	if monthsBetween(first, period.End())+1 > maxMonthlySummaryMonths {
+		return LogSummary{}, fmt.Errorf("monthly summary period must be <= %d months", maxMonthlySummaryMonths)
+	}
+	var labels []string
+	for m := first; !m.After(period.End()); m = m.AddDate(0, 1, 0) {
+		labels = append(labels, monthLabel(m))
+	}
+	return summarize(categoryID, period, logs, labels, monthLabel)
+}
+
+// SummarizeByQuarter totals counts per calendar quarter (2024-Q1). Quarters without DONELOGs are reported as zero.
+func (LogSummaryService) SummarizeByQuarter(categoryID *CategoryID, period Period, logs []*DoneLog) (LogSummary, error) {
+	first := firstOfQuarter(period.Start())
+	if monthsBetween(first, period.End())/3+1 > maxQuarterlySummaryQuarters {
+		return LogSummary{}, fmt.Errorf("quarterly summary period must be <= %d quarters", maxQuarterlySummaryQuarters)
+	}
+	var labels []string
+	for q := first; !q.After(period.End()); q = q.AddDate(0, 3, 0) {
+		labels = append(labels, quarterLabel(q))
+	}
+	return summarize(categoryID, period, logs, labels, quarterLabel)
+}
+
+// SummarizeByYear totals counts per calendar year. Years without DONELOGs are reported as zero.
+func (LogSummaryService) SummarizeByYear(categoryID *CategoryID, period Period, logs []*DoneLog) (LogSummary, error) {
+	if period.End().Year()-period.Start().Year()+1 > maxYearlySummaryYears {
+		return LogSummary{}, fmt.Errorf("yearly summary period must be <= %d years", maxYearlySummaryYears)
+	}
+	var labels []string
+	for y := period.Start().Year(); y <= period.End().Year(); y++ {
+		labels = append(labels, fmt.Sprintf("%04d", y))
+	}
+	return summarize(categoryID, period, logs, labels, yearLabel)
+}
+
+// summarize sums logs inside period (and category, when given) into the pre-computed buckets.
+// Trashed DONELOGs are never counted.
+func summarize(categoryID *CategoryID, period Period, logs []*DoneLog, labels []string, bucket bucketFunc) (LogSummary, error) {
+	totals := make(map[string]int, len(labels))
+	total := 0
+	for _, log := range logs {
+		if log.IsTrashed() || !period.Contains(log.OccurredOn()) {
+			continue
+		}
+		if categoryID != nil && log.CategoryID() != *categoryID {
+			continue
+		}
+		totals[bucket(log.OccurredOn().Time())] += log.Count().Int()
+		total += log.Count().Int()
+	}
+
+	points := make([]SummaryPoint, 0, len(labels))
+	for _, label := range labels {
+		count, err := newCountFromNonNegative(totals[label])
+		if err != nil {
+			return LogSummary{}, err
+		}
+		points = append(points, SummaryPoint{label: label, count: count})
+	}
+	totalCount, err := newCountFromNonNegative(total)
+	if err != nil {
+		return LogSummary{}, err
+	}
+	return LogSummary{categoryID: categoryID, period: period, totalCount: totalCount, points: points}, nil
+}
+
+func dayLabel(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func monthLabel(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// monthsBetween counts the calendar months from the month of a to the month of b.
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
+
+func quarterLabel(t time.Time) string {
+	return fmt.Sprintf("%04d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+func yearLabel(t time.Time) string {
+	return t.Format("2006")
+}
+
+func firstOfQuarter(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location())
+}
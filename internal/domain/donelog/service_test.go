@@ -0,0 +1,406 @@
+package donelog
+
+import (
+	"testing"
+	"time"
+
+	"fmt"
+
+	"strings"
+)
+
+func mustLog(t *testing.T, category string, count int, date string, trashed bool) *DoneLog {
+	t.Helper()
+	occurredOn, err := NewOccurredOn(date)
+	if err != nil {
+		t.Fatalf("invalid date: %v", err)
+	}
+	raw := RawDoneLog{
+		ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Title:      "Log",
+		TrackID:    "track_sample",
+		CategoryID: category,
+		Count:      count,
+		OccurredOn: occurredOn.Time(),
+	}
+	if trashed {
+		at := time.Now()
+		raw.TrashedAt = &at
+	}
+	log, err := RehydrateDoneLog(raw)
+	if err != nil {
+		t.Fatalf("rehydrate failed: %v", err)
+	}
+	return log
+}
+
+func mustPeriod(t *testing.T, start, end string) Period {
+	t.Helper()
+	s, _ := NewOccurredOn(start)
+	e, _ := NewOccurredOn(end)
+	p, err := NewPeriod(s, e)
+	if err != nil {
+		t.Fatalf("invalid period: %v", err)
+	}
+	return p
+}
+
+func TestSummarizeByDay(t *testing.T) {
+	logs := []*DoneLog{
+		mustLog(t, "cat_reading", 3, "2024-05-01", false),
+		mustLog(t, "cat_reading", 2, "2024-05-01", false),
+		mustLog(t, "cat_exam", 10, "2024-05-03", false),
+		mustLog(t, "cat_reading", 7, "2024-05-02", true),
+		mustLog(t, "cat_reading", 9, "2024-04-30", false),
+	}
+	reading, _ := NewCategoryID("cat_reading")
+
+	tests := []struct {
+		name       string
+		category   *CategoryID
+		period     Period
+		wantErr    bool
+		wantTotal  int
+		wantCounts []int
+	}{
+		{"all categories, zero-filled", nil, mustPeriod(t, "2024-05-01", "2024-05-03"), false, 15, []int{5, 0, 10}},
+		{"category filter", &reading, mustPeriod(t, "2024-05-01", "2024-05-03"), false, 5, []int{5, 0, 0}},
+		{"NG: period too long", nil, mustPeriod(t, "2024-01-01", "2024-12-31"), true, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := LogSummaryService{}.SummarizeByDay(tt.category, tt.period, logs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if summary.TotalCount().Int() != tt.wantTotal {
+				t.Fatalf("expected total %d, got %d", tt.wantTotal, summary.TotalCount().Int())
+			}
+			points := summary.Points()
+			if len(points) != len(tt.wantCounts) {
+				t.Fatalf("expected %d points, got %d", len(tt.wantCounts), len(points))
+			}
+			for i, want := range tt.wantCounts {
+				if points[i].Count().Int() != want {
+					t.Fatalf("point %s = %d, want %d", points[i].Label(), points[i].Count().Int(), want)
+				}
+			}
+			if points[0].Label() != "2024-05-01" {
+				t.Fatalf("unexpected first label: %s", points[0].Label())
+			}
+		})
+	}
+}
+
+func TestSummarizeByMonth(t *testing.T) {
+	logs := []*DoneLog{
+		mustLog(t, "cat_reading", 3, "2024-01-15", false),
+		mustLog(t, "cat_reading", 4, "2024-03-31", false),
+		mustLog(t, "cat_reading", 5, "2024-03-01", false),
+	}
+
+	summary, err := LogSummaryService{}.SummarizeByMonth(nil, mustPeriod(t, "2024-01-10", "2024-03-31"), logs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		label string
+		count int
+	}{{"2024-01", 3}, {"2024-02", 0}, {"2024-03", 9}}
+	points := summary.Points()
+	if len(points) != len(want) {
+		t.Fatalf("expected %d points, got %d", len(want), len(points))
+	}
+	for i, w := range want {
+		if points[i].Label() != w.label || points[i].Count().Int() != w.count {
+			t.Fatalf("point %d = %s:%d, want %s:%d", i, points[i].Label(), points[i].Count().Int(), w.label, w.count)
+		}
+	}
+	if summary.TotalCount().Int() != 12 {
+		t.Fatalf("expected total 12, got %d", summary.TotalCount().Int())
+	}
+}
+
+func TestSummarizeByWeek(t *testing.T) {
+	logs := []*DoneLog{
+		mustLog(t, "cat_reading", 9, "2024-12-21", false),
+		mustLog(t, "cat_reading", 1, "2024-12-22", false),
+		mustLog(t, "cat_reading", 2, "2024-12-29", false),
+		mustLog(t, "cat_reading", 3, "2024-12-31", false),
+		mustLog(t, "cat_reading", 4, "2025-01-05", false),
+		mustLog(t, "cat_reading", 5, "2025-01-12", false),
+	}
+
+	type point struct {
+		label string
+		count int
+	}
+	tests := []struct {
+		name      string
+		weekStart WeekStart
+		period    Period
+		wantErr   bool
+		want      []point
+	}{
+		{
+			name:      "monday start across the year boundary",
+			weekStart: WeekStartMonday,
+			period:    mustPeriod(t, "2024-12-22", "2025-01-12"),
+			want:      []point{{"2024-W51", 1}, {"2024-W52", 2}, {"2025-W01", 7}, {"2025-W02", 5}},
+		},
+		{
+			name:      "sunday start labels by the week's monday",
+			weekStart: WeekStartSunday,
+			period:    mustPeriod(t, "2024-12-22", "2025-01-12"),
+			want:      []point{{"2024-W52", 1}, {"2025-W01", 5}, {"2025-W02", 4}, {"2025-W03", 5}},
+		},
+		{
+			name:      "week 53 belongs to the previous ISO year",
+			weekStart: WeekStartMonday,
+			period:    mustPeriod(t, "2020-12-28", "2021-01-04"),
+			want:      []point{{"2020-W53", 0}, {"2021-W01", 0}},
+		},
+		{
+			name:      "NG: unknown week start",
+			weekStart: "friday",
+			period:    mustPeriod(t, "2024-12-22", "2025-01-12"),
+			wantErr:   true,
+		},
+		{
+			name:      "NG: period too long",
+			weekStart: WeekStartMonday,
+			period:    mustPeriod(t, "2020-01-01", "2024-12-31"),
+			wantErr:   true,
+		},
+		{
+			name:      "NG: one week over the limit",
+			weekStart: WeekStartMonday,
+			period:    mustPeriod(t, "2024-01-01", "2026-01-12"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := LogSummaryService{}.SummarizeByWeek(nil, tt.period, logs, tt.weekStart)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			points := summary.Points()
+			if len(points) != len(tt.want) {
+				t.Fatalf("expected %d points, got %d", len(tt.want), len(points))
+			}
+			for i, w := range tt.want {
+				if points[i].Label() != w.label || points[i].Count().Int() != w.count {
+					t.Fatalf("point %d = %s:%d, want %s:%d", i, points[i].Label(), points[i].Count().Int(), w.label, w.count)
+				}
+			}
+		})
+	}
+
+	// The longest allowed span still gets every week.
+	summary, err := LogSummaryService{}.SummarizeByWeek(nil, mustPeriod(t, "2024-01-01", "2026-01-11"), logs, WeekStartMonday)
+	if err != nil || len(summary.Points()) != maxWeeklySummaryWeeks {
+		t.Fatalf("%d-week period: %v (%d points)", maxWeeklySummaryWeeks, err, len(summary.Points()))
+	}
+}
+
+func TestSummarizeByQuarterAndYear(t *testing.T) {
+	logs := []*DoneLog{
+		mustLog(t, "cat_reading", 3, "2023-12-31", false),
+		mustLog(t, "cat_reading", 4, "2024-03-31", false),
+		mustLog(t, "cat_reading", 5, "2024-04-01", false),
+	}
+
+	quarterly, err := LogSummaryService{}.SummarizeByQuarter(nil, mustPeriod(t, "2023-11-15", "2024-06-30"), logs)
+	if err != nil {
+		t.Fatalf("quarterly: %v", err)
+	}
+	var got []string
+	for _, p := range quarterly.Points() {
+		got = append(got, fmt.Sprintf("%s:%d", p.Label(), p.Count().Int()))
+	}
+	if strings.Join(got, " ") != "2023-Q4:3 2024-Q1:4 2024-Q2:5" {
+		t.Fatalf("unexpected quarters: %v", got)
+	}
+
+	yearly, err := LogSummaryService{}.SummarizeByYear(nil, mustPeriod(t, "2022-01-01", "2024-12-31"), logs)
+	if err != nil {
+		t.Fatalf("yearly: %v", err)
+	}
+	got = nil
+	for _, p := range yearly.Points() {
+		got = append(got, fmt.Sprintf("%s:%d", p.Label(), p.Count().Int()))
+	}
+	if strings.Join(got, " ") != "2022:0 2023:3 2024:9" {
+		t.Fatalf("unexpected years: %v", got)
+	}
+
+	if _, err := (LogSummaryService{}).SummarizeByYear(nil, mustPeriod(t, "2000-01-01", "2024-12-31"), logs); err == nil {
+		t.Fatal("expected too many years to fail")
+	}
+}
+
+func TestCompareYearOverYear(t *testing.T) {
+	logs := []*DoneLog{
+		mustLog(t, "cat_reading", 10, "2023-01-10", false),
+		mustLog(t, "cat_reading", 15, "2024-01-20", false),
+		mustLog(t, "cat_reading", 8, "2024-02-01", false),
+		mustLog(t, "cat_reading", 4, "2023-05-01", false),
+		mustLog(t, "cat_reading", 99, "2023-05-02", true),
+		mustLog(t, "cat_exam", 6, "2022-01-01", false),
+		mustLog(t, "cat_exam", 2, "2024-11-30", false),
+	}
+
+	yoy, err := LogSummaryService{}.CompareYearOverYear(2024, ComparisonUnitMonth, SummaryGroupCategory, logs)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	groups := yoy.Groups()
+	if len(groups) != 2 || groups[0].Key() != "cat_exam" || groups[1].Key() != "cat_reading" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	reading := groups[1].Rows()
+	if len(reading) != 12 || reading[0].Label() != "2024-01" || reading[0].PreviousLabel() != "2023-01" {
+		t.Fatalf("unexpected rows: %+v", reading)
+	}
+	if reading[0].Change() != 5 {
+		t.Fatalf("january change = %d, want 5", reading[0].Change())
+	}
+	if pct, ok := reading[0].ChangePercent(); !ok || pct != 50 {
+		t.Fatalf("january percent = %v, %v", pct, ok)
+	}
+	if _, ok := reading[1].ChangePercent(); ok {
+		t.Fatal("february has no baseline, percentage must be undefined")
+	}
+	if pct, ok := reading[4].ChangePercent(); !ok || pct != -100 || reading[4].Previous() != 4 {
+		t.Fatalf("may = %+v, percent %v %v", reading[4], pct, ok)
+	}
+	total := groups[1].Total()
+	if total.Label() != "2024" || total.Current() != 23 || total.Previous() != 14 || total.Change() != 9 {
+		t.Fatalf("unexpected total: %+v", total)
+	}
+	if pct, _ := total.ChangePercent(); pct != 64.3 {
+		t.Fatalf("total percent = %v, want 64.3", pct)
+	}
+
+	quarters, err := LogSummaryService{}.CompareYearOverYear(2024, ComparisonUnitQuarter, SummaryGroupTrack, logs)
+	if err != nil {
+		t.Fatalf("compare quarters: %v", err)
+	}
+	rows := quarters.Groups()[0].Rows()
+	if len(quarters.Groups()) != 1 || len(rows) != 4 || rows[0].Label() != "2024-Q1" || rows[0].Current() != 23 || rows[0].Previous() != 10 || rows[3].Current() != 2 {
+		t.Fatalf("unexpected quarters: %+v", rows)
+	}
+
+	if _, err := (LogSummaryService{}).CompareYearOverYear(2024, "week", SummaryGroupTrack, logs); err == nil {
+		t.Fatal("expected unknown unit to fail")
+	}
+}
+
+func TestAnalyzeTrend(t *testing.T) {
+	// 2024-04-29 is a Monday; the count grows by one each day over two full ISO weeks.
+	var logs []*DoneLog
+	start := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 14; i++ {
+		logs = append(logs, mustLog(t, "cat_reading", i+1, start.AddDate(0, 0, i).Format("2006-01-02"), false))
+	}
+	logs = append(logs, mustLog(t, "cat_reading", 99, "2024-05-01", true))
+	period := mustPeriod(t, "2024-04-29", "2024-05-12")
+
+	trend, err := LogSummaryService{}.AnalyzeTrend(nil, period, logs, WeekStartMonday)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if trend.Summary().TotalCount().Int() != 105 || trend.Slope() != 1 || trend.Median() != 7.5 {
+		t.Fatalf("total/slope/median = %d/%v/%v", trend.Summary().TotalCount().Int(), trend.Slope(), trend.Median())
+	}
+	if trend.BestDay().Label() != "2024-05-12" || trend.BestDay().Count() != 14 || trend.WorstDay().Label() != "2024-04-29" || trend.WorstDay().Count() != 1 {
+		t.Fatalf("best/worst day = %+v/%+v", trend.BestDay(), trend.WorstDay())
+	}
+	if trend.BestWeek() == nil || trend.BestWeek().Label() != "2024-W19" || trend.BestWeek().Count() != 77 ||
+		trend.WorstWeek() == nil || trend.WorstWeek().Label() != "2024-W18" || trend.WorstWeek().Count() != 28 {
+		t.Fatalf("best/worst week = %+v/%+v", trend.BestWeek(), trend.WorstWeek())
+	}
+
+	points := trend.Points()
+	if _, ok := points[5].MovingAverage7(); ok {
+		t.Fatal("7-day average must be undefined before the window is full")
+	}
+	if avg, ok := points[6].MovingAverage7(); !ok || avg != 4 {
+		t.Fatalf("7-day average on day 7 = %v, %v", avg, ok)
+	}
+	if avg, ok := points[13].MovingAverage7(); !ok || avg != 11 {
+		t.Fatalf("7-day average on day 14 = %v, %v", avg, ok)
+	}
+	if _, ok := points[13].MovingAverage30(); ok {
+		t.Fatal("30-day average must be undefined for a 14-day series")
+	}
+	if points[13].Cumulative() != 105 || points[2].Cumulative() != 6 {
+		t.Fatalf("cumulative = %d/%d", points[2].Cumulative(), points[13].Cumulative())
+	}
+
+	// Sunday weeks: only 2024-05-05..2024-05-11 lies inside the period.
+	sunday, err := LogSummaryService{}.AnalyzeTrend(nil, period, logs, WeekStartSunday)
+	if err != nil {
+		t.Fatalf("analyze sunday: %v", err)
+	}
+	if sunday.BestWeek() == nil || sunday.BestWeek().Label() != "2024-W19" || sunday.BestWeek().Count() != 70 || sunday.WorstWeek().Count() != 70 {
+		t.Fatalf("sunday weeks = %+v/%+v", sunday.BestWeek(), sunday.WorstWeek())
+	}
+
+	short, err := LogSummaryService{}.AnalyzeTrend(nil, mustPeriod(t, "2024-05-01", "2024-05-04"), logs, WeekStartMonday)
+	if err != nil {
+		t.Fatalf("analyze short: %v", err)
+	}
+	if short.BestWeek() != nil || short.WorstWeek() != nil || short.Median() != 4.5 {
+		t.Fatalf("short trend = %+v", short)
+	}
+
+	if _, err := (LogSummaryService{}).AnalyzeTrend(nil, mustPeriod(t, "2023-01-01", "2024-01-02"), logs, WeekStartMonday); err == nil {
+		t.Fatal("expected a period over 366 days to fail")
+	}
+	if _, err := (LogSummaryService{}).AnalyzeTrend(nil, period, logs, "friday"); err == nil {
+		t.Fatal("expected unknown week start to fail")
+	}
+}
+
+func TestSummarizeByTag(t *testing.T) {
+	tagged := func(count int, date string, trashed bool, tags ...string) *DoneLog {
+		log := mustLog(t, "cat_reading", count, date, trashed)
+		set, err := ParseTagSet(tags)
+		if err != nil {
+			t.Fatalf("tags: %v", err)
+		}
+		log.SetTags(set)
+		return log
+	}
+	logs := []*DoneLog{
+		tagged(3, "2024-05-01", false, "go", "performance"),
+		tagged(2, "2024-05-02", false, "go"),
+		tagged(4, "2024-05-02", false, "rust"),
+		tagged(9, "2024-05-03", true, "rust"),
+		tagged(8, "2024-04-30", false, "rust"),
+		tagged(1, "2024-05-04", false),
+	}
+
+	summary := LogSummaryService{}.SummarizeByTag(mustPeriod(t, "2024-05-01", "2024-05-31"), logs)
+	var got []string
+	for _, total := range summary.Tags() {
+		got = append(got, fmt.Sprintf("%s=%d/%d", total.Tag().String(), total.Count().Int(), total.DoneLogs()))
+	}
+	if want := "go=5/2,rust=4/1,performance=3/1"; strings.Join(got, ",") != want {
+		t.Fatalf("tags = %v, want %s", got, want)
+	}
+	if untagged := summary.Untagged(); untagged.Count().Int() != 1 || untagged.DoneLogs() != 1 {
+		t.Fatalf("unexpected untagged total: %+v", untagged)
+	}
+}
@@ -0,0 +1,203 @@
+package donelog
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxStreakFreezes bounds how many missed units a single streak may bridge.
+const maxStreakFreezes = 30
+
+// StreakUnit is the length of one streak step.
+type StreakUnit string
+
+const (
+	StreakUnitDay StreakUnit = "day"
+	// StreakUnitWeek uses ISO weeks (Monday to Sunday).
+	StreakUnitWeek StreakUnit = "week"
+)
+
+// ParseStreakUnit validates a unit name.
+func ParseStreakUnit(value string) (StreakUnit, error) {
+	switch StreakUnit(value) {
+	case StreakUnitDay, StreakUnitWeek:
+		return StreakUnit(value), nil
+	}
+	return "", fmt.Errorf("streak unit must be day or week, got %q", value)
+}
+
+// StreakState tells whether the current streak is already extended in the current unit.
+type StreakState string
+
+const (
+	// StreakStateOnFire means there is a DONELOG in the current day/week.
+	StreakStateOnFire StreakState = "on_fire"
+	// StreakStateAtRisk means the streak is alive but needs a DONELOG before the current unit ends.
+	StreakStateAtRisk StreakState = "at_risk"
+	// StreakStateNone means there is no live streak.
+	StreakStateNone StreakState = "none"
+)
+
+// StreakRule defines how consecutive activity is counted.
+// Freezes is how many missed units a streak may skip without breaking; skipped units do not add to its length.
+type StreakRule struct {
+	unit    StreakUnit
+	freezes int
+}
+
+// NewStreakRule creates a rule for the unit with up to freezes bridged gaps per streak.
+func NewStreakRule(unit StreakUnit, freezes int) (StreakRule, error) {
+	if _, err := ParseStreakUnit(string(unit)); err != nil {
+		return StreakRule{}, err
+	}
+	if freezes < 0 || freezes > maxStreakFreezes {
+		return StreakRule{}, fmt.Errorf("freezes must be between 0 and %d", maxStreakFreezes)
+	}
+	return StreakRule{unit: unit, freezes: freezes}, nil
+}
+
+// Unit returns the streak unit.
+func (r StreakRule) Unit() StreakUnit {
+	return r.unit
+}
+
+// Freezes returns how many missed units a streak may bridge.
+func (r StreakRule) Freezes() int {
+	return r.freezes
+}
+
+// Streak is a run of active units. The zero value means "no streak".
+type Streak struct {
+	length      int
+	first       time.Time
+	last        time.Time
+	freezesUsed int
+}
+
+// Length is the number of active units in the streak.
+func (s Streak) Length() int {
+	return s.length
+}
+
+// First returns the first active date; zero when there is no streak.
+func (s Streak) First() time.Time {
+	return s.first
+}
+
+// Last returns the last active date; zero when there is no streak.
+func (s Streak) Last() time.Time {
+	return s.last
+}
+
+// FreezesUsed is how many missed units the streak bridged.
+func (s Streak) FreezesUsed() int {
+	return s.freezesUsed
+}
+
+// StreakReport is the result of applying a StreakRule to a set of DONELOGs.
+type StreakReport struct {
+	current Streak
+	longest Streak
+	state   StreakState
+}
+
+// Current returns the live streak, or the zero Streak when it is broken.
+func (r StreakReport) Current() Streak {
+	return r.current
+}
+
+// Longest returns the longest streak ever; the most recent one wins ties.
+func (r StreakReport) Longest() Streak {
+	return r.longest
+}
+
+// State reports whether the current streak is on fire, at risk or absent.
+func (r StreakReport) State() StreakState {
+	return r.state
+}
+
+// Calculate finds the current and longest streaks of logs as of today.
+// Trashed DONELOGs and DONELOGs after today are ignored. A live streak not yet extended in the
+// current unit is still current (at risk) while the missed units since it fit in its remaining freezes.
+func (r StreakRule) Calculate(logs []*DoneLog, today OccurredOn) StreakReport {
+	todayUnit := r.unitIndex(today.Time())
+
+	type span struct{ first, last time.Time }
+	active := map[int]*span{}
+	for _, log := range logs {
+		if log.IsTrashed() {
+			continue
+		}
+		date := log.OccurredOn().Time()
+		u := r.unitIndex(date)
+		if u > todayUnit {
+			continue
+		}
+		if s, ok := active[u]; ok {
+			if date.Before(s.first) {
+				s.first = date
+			}
+			if date.After(s.last) {
+				s.last = date
+			}
+			continue
+		}
+		active[u] = &span{first: date, last: date}
+	}
+	if len(active) == 0 {
+		return StreakReport{state: StreakStateNone}
+	}
+
+	units := make([]int, 0, len(active))
+	for u := range active {
+		units = append(units, u)
+	}
+	sort.Ints(units)
+
+	var (
+		report   StreakReport
+		current  Streak
+		lastUnit int
+	)
+	for i, u := range units {
+		gap := u - lastUnit - 1
+		if i > 0 && gap <= r.freezes-current.freezesUsed {
+			current.length++
+			current.freezesUsed += gap
+			current.last = active[u].last
+		} else {
+			current = Streak{length: 1, first: active[u].first, last: active[u].last}
+		}
+		lastUnit = u
+		if current.length >= report.longest.length {
+			report.longest = current
+		}
+	}
+
+	switch missed := todayUnit - lastUnit - 1; {
+	case lastUnit == todayUnit:
+		report.current, report.state = current, StreakStateOnFire
+	case missed <= r.freezes-current.freezesUsed:
+		report.current, report.state = current, StreakStateAtRisk
+	default:
+		report.state = StreakStateNone
+	}
+	return report
+}
+
+// unitIndex numbers days (or ISO weeks) consecutively so gaps can be computed by subtraction.
+func (r StreakRule) unitIndex(t time.Time) int {
+	y, m, d := t.Date()
+	days := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+	if r.unit == StreakUnitWeek {
+		// 1970-01-01 was a Thursday; shift so weeks start on Monday.
+		// Floor the division so days before the epoch land in the right week.
+		shifted := days + 3
+		if shifted < 0 {
+			return (shifted - 6) / 7
+		}
+		return shifted / 7
+	}
+	return days
+}
@@ -0,0 +1,116 @@
+package donelog
+
+import "testing"
+
+func TestStreakRuleCalculate(t *testing.T) {
+	days := func(dates ...string) []*DoneLog {
+		logs := make([]*DoneLog, 0, len(dates))
+		for _, d := range dates {
+			logs = append(logs, mustLog(t, "cat_reading", 1, d, false))
+		}
+		return logs
+	}
+
+	tests := []struct {
+		name        string
+		unit        StreakUnit
+		freezes     int
+		logs        []*DoneLog
+		today       string
+		wantCurrent int
+		wantLongest int
+		wantState   StreakState
+		wantFirst   string
+	}{
+		{
+			name:        "daily streak including today",
+			unit:        StreakUnitDay,
+			logs:        days("2024-05-01", "2024-05-03", "2024-05-04", "2024-05-05"),
+			today:       "2024-05-05",
+			wantCurrent: 3, wantLongest: 3, wantState: StreakStateOnFire, wantFirst: "2024-05-03",
+		},
+		{
+			name:        "not logged today yet keeps the streak at risk",
+			unit:        StreakUnitDay,
+			logs:        days("2024-05-03", "2024-05-04"),
+			today:       "2024-05-05",
+			wantCurrent: 2, wantLongest: 2, wantState: StreakStateAtRisk, wantFirst: "2024-05-03",
+		},
+		{
+			name:        "a missed day breaks the current streak but not the longest",
+			unit:        StreakUnitDay,
+			logs:        days("2024-05-01", "2024-05-02", "2024-05-03"),
+			today:       "2024-05-05",
+			wantCurrent: 0, wantLongest: 3, wantState: StreakStateNone,
+		},
+		{
+			name:        "freezes bridge gaps without adding length",
+			unit:        StreakUnitDay,
+			freezes:     2,
+			logs:        days("2024-05-01", "2024-05-03", "2024-05-05", "2024-05-06"),
+			today:       "2024-05-06",
+			wantCurrent: 4, wantLongest: 4, wantState: StreakStateOnFire, wantFirst: "2024-05-01",
+		},
+		{
+			name:        "freezes run out",
+			unit:        StreakUnitDay,
+			freezes:     1,
+			logs:        days("2024-05-01", "2024-05-03", "2024-05-05"),
+			today:       "2024-05-05",
+			wantCurrent: 1, wantLongest: 2, wantState: StreakStateOnFire, wantFirst: "2024-05-05",
+		},
+		{
+			name:        "weekly streak across a year boundary",
+			unit:        StreakUnitWeek,
+			logs:        days("2024-12-23", "2024-12-31", "2025-01-06", "2025-01-08"),
+			today:       "2025-01-09",
+			wantCurrent: 3, wantLongest: 3, wantState: StreakStateOnFire, wantFirst: "2024-12-23",
+		},
+		{
+			name:        "weekly streak across the Unix epoch",
+			unit:        StreakUnitWeek,
+			logs:        days("1969-12-16", "1969-12-23", "1970-01-01"),
+			today:       "1970-01-01",
+			wantCurrent: 3, wantLongest: 3, wantState: StreakStateOnFire, wantFirst: "1969-12-16",
+		},
+		{
+			name:        "trashed and future logs are ignored",
+			unit:        StreakUnitDay,
+			logs:        append(days("2024-05-05", "2024-05-09"), mustLog(t, "cat_reading", 1, "2024-05-04", true)),
+			today:       "2024-05-05",
+			wantCurrent: 1, wantLongest: 1, wantState: StreakStateOnFire, wantFirst: "2024-05-05",
+		},
+		{
+			name:      "no logs",
+			unit:      StreakUnitDay,
+			today:     "2024-05-05",
+			wantState: StreakStateNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewStreakRule(tt.unit, tt.freezes)
+			if err != nil {
+				t.Fatalf("rule: %v", err)
+			}
+			today, _ := NewOccurredOn(tt.today)
+			report := rule.Calculate(tt.logs, today)
+
+			if report.Current().Length() != tt.wantCurrent || report.Longest().Length() != tt.wantLongest || report.State() != tt.wantState {
+				t.Fatalf("current=%d longest=%d state=%s, want %d/%d/%s",
+					report.Current().Length(), report.Longest().Length(), report.State(), tt.wantCurrent, tt.wantLongest, tt.wantState)
+			}
+			if tt.wantFirst != "" && report.Current().First().Format("2006-01-02") != tt.wantFirst {
+				t.Fatalf("current starts %s, want %s", report.Current().First().Format("2006-01-02"), tt.wantFirst)
+			}
+		})
+	}
+
+	if _, err := NewStreakRule(StreakUnit("month"), 0); err == nil {
+		t.Fatal("expected unknown unit to fail")
+	}
+	if _, err := NewStreakRule(StreakUnitDay, -1); err == nil {
+		t.Fatal("expected negative freezes to fail")
+	}
+}
@@ -0,0 +1,89 @@
+package donelog
+
+import "sort"
+
+// TagTotal is the total of the DONELOGs carrying one Tag, or of the untagged ones.
+type TagTotal struct {
+	tag      Tag
+	count    Count
+	doneLogs int
+}
+
+// Tag returns the Tag; it is the zero Tag for the untagged total.
+func (t TagTotal) Tag() Tag {
+	return t.tag
+}
+
+// Count returns the sum of Count.
+func (t TagTotal) Count() Count {
+	return t.count
+}
+
+// DoneLogs returns how many DONELOGs were counted.
+func (t TagTotal) DoneLogs() int {
+	return t.doneLogs
+}
+
+// TagSummary is the read-only result of SummarizeByTag.
+type TagSummary struct {
+	period   Period
+	tags     []TagTotal
+	untagged TagTotal
+}
+
+// Period returns the summarized period.
+func (s TagSummary) Period() Period {
+	return s.period
+}
+
+// Tags returns one total per Tag seen, largest first, then by Tag. A DONELOG with several Tags
+// counts towards each of them, so the totals may add up to more than the period's total.
+func (s TagSummary) Tags() []TagTotal {
+	return append([]TagTotal(nil), s.tags...)
+}
+
+// Untagged returns the total of the DONELOGs without Tags.
+func (s TagSummary) Untagged() TagTotal {
+	return s.untagged
+}
+
+// SummarizeByTag totals counts per Tag. Trashed DONELOGs and DONELOGs outside period are ignored.
+func (LogSummaryService) SummarizeByTag(period Period, logs []*DoneLog) TagSummary {
+	type total struct{ count, doneLogs int }
+	byTag := map[Tag]*total{}
+	var untagged total
+	for _, log := range logs {
+		if log.IsTrashed() || !period.Contains(log.OccurredOn()) {
+			continue
+		}
+		if log.Tags().Len() == 0 {
+			untagged.count += log.Count().Int()
+			untagged.doneLogs++
+			continue
+		}
+		for _, tag := range log.Tags().Tags() {
+			if byTag[tag] == nil {
+				byTag[tag] = &total{}
+			}
+			byTag[tag].count += log.Count().Int()
+			byTag[tag].doneLogs++
+		}
+	}
+
+	summary := TagSummary{period: period}
+	// Totals of valid DONELOGs are never negative.
+	summary.untagged.count, _ = newCountFromNonNegative(untagged.count)
+	summary.untagged.doneLogs = untagged.doneLogs
+	for tag, t := range byTag {
+		count, _ := newCountFromNonNegative(t.count)
+		summary.tags = append(summary.tags, TagTotal{tag: tag, count: count, doneLogs: t.doneLogs})
+	}
+	sort.Slice(summary.tags, func(i, j int) bool {
+		a, b := summary.tags[i], summary.tags[j]
+		if a.count != b.count {
+			return a.count.Int() > b.count.Int()
+		}
+		return a.tag.String() < b.tag.String()
+	})
+	return summary
+}
@@ -0,0 +1,305 @@
+package donelog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// TeamID identifies a Team. Unlike Track and Category IDs it is unique across all owners.
+type TeamID struct {
+	value string
+}
+
+// NewTeamID validates and creates a TeamID.
+func NewTeamID(value string) (TeamID, error) {
+	if value == "" {
+		return TeamID{}, errors.New("team id must not be empty")
+	}
+	if !slugPattern.MatchString(value) {
+		return TeamID{}, fmt.Errorf("invalid team id: %s", value)
+	}
+	return TeamID{value: value}, nil
+}
+
+// String returns the identifier value.
+func (id TeamID) String() string {
+	return id.value
+}
+
+// Role is what a member may do in a Team.
+type Role string
+
+const (
+	// RoleOwner manages members and the team's Tracks, and records DONELOGs.
+	RoleOwner Role = "owner"
+	// RoleEditor records DONELOGs against the team's Tracks.
+	RoleEditor Role = "editor"
+	// RoleViewer sees the team's Tracks and summaries only.
+	RoleViewer Role = "viewer"
+)
+
+// ParseRole converts "owner", "editor" or "viewer" into a Role.
+func ParseRole(value string) (Role, error) {
+	switch role := Role(value); role {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return role, nil
+	}
+	return "", fmt.Errorf("invalid role %q (want owner, editor or viewer)", value)
+}
+
+// CanLog reports whether the role may record DONELOGs against team Tracks.
+func (r Role) CanLog() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// CanManage reports whether the role may change members and team Tracks.
+func (r Role) CanManage() bool {
+	return r == RoleOwner
+}
+
+// TeamMember is a member and their role.
+type TeamMember struct {
+	Member OwnerID
+	Role   Role
+}
+
+// Team is the aggregate that shares Tracks between members. It always has at least one owner.
+type Team struct {
+	id      TeamID
+	name    string
+	members map[OwnerID]Role
+	// optedOut holds the members hidden from leaderboards.
+	optedOut map[OwnerID]bool
+	// invites holds the roles offered to users who have not accepted yet.
+	invites map[OwnerID]Role
+}
+
+// NewTeam constructs a Team whose only member is owner, with RoleOwner.
+func NewTeam(id TeamID, name string, owner OwnerID) (*Team, error) {
+	if id == (TeamID{}) {
+		return nil, errors.New("team id must not be empty")
+	}
+	if owner == (OwnerID{}) {
+		return nil, errors.New("a team owner must be a named user")
+	}
+	validName, err := newName("team", name)
+	if err != nil {
+		return nil, err
+	}
+	return &Team{id: id, name: validName, members: map[OwnerID]Role{owner: RoleOwner}, optedOut: map[OwnerID]bool{}, invites: map[OwnerID]Role{}}, nil
+}
+
+// ID returns the Team identifier.
+func (t *Team) ID() TeamID {
+	return t.id
+}
+
+// Name returns the display name.
+func (t *Team) Name() string {
+	return t.name
+}
+
+// RoleOf returns the member's role, and false when they are not a member.
+func (t *Team) RoleOf(member OwnerID) (Role, bool) {
+	role, ok := t.members[member]
+	return role, ok
+}
+
+// Members returns the members ordered by name.
+func (t *Team) Members() []TeamMember {
+	members := make([]TeamMember, 0, len(t.members))
+	for member, role := range t.members {
+		members = append(members, TeamMember{Member: member, Role: role})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Member.String() < members[j].Member.String() })
+	return members
+}
+
+// SetRole changes the role of an existing member. New members join through Invite and Accept.
+// The last owner cannot be demoted.
+func (t *Team) SetRole(member OwnerID, role Role) error {
+	if _, err := ParseRole(string(role)); err != nil {
+		return err
+	}
+	if _, ok := t.members[member]; !ok {
+		return fmt.Errorf("%s is not a member of team %s", member.String(), t.id.String())
+	}
+	if t.members[member] == RoleOwner && role != RoleOwner && t.owners() == 1 {
+		return errors.New("a team needs at least one owner")
+	}
+	t.members[member] = role
+	return nil
+}
+
+// Invite offers role to a user who is not a member yet, replacing any earlier offer.
+// They join only once they Accept.
+func (t *Team) Invite(member OwnerID, role Role) error {
+	if member == (OwnerID{}) {
+		return errors.New("a team member must be a named user")
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return err
+	}
+	if _, ok := t.members[member]; ok {
+		return fmt.Errorf("%s is already a member of team %s", member.String(), t.id.String())
+	}
+	t.invites[member] = role
+	return nil
+}
+
+// InvitedAs returns the role offered to member, and false when they have no pending invite.
+func (t *Team) InvitedAs(member OwnerID) (Role, bool) {
+	role, ok := t.invites[member]
+	return role, ok
+}
+
+// Invites returns the pending invites ordered by name.
+func (t *Team) Invites() []TeamMember {
+	invites := make([]TeamMember, 0, len(t.invites))
+	for member, role := range t.invites {
+		invites = append(invites, TeamMember{Member: member, Role: role})
+	}
+	sort.Slice(invites, func(i, j int) bool { return invites[i].Member.String() < invites[j].Member.String() })
+	return invites
+}
+
+// Accept turns member's pending invite into membership with the offered role.
+func (t *Team) Accept(member OwnerID) error {
+	role, ok := t.invites[member]
+	if !ok {
+		return fmt.Errorf("%s has no invite to team %s", member.String(), t.id.String())
+	}
+	delete(t.invites, member)
+	t.members[member] = role
+	return nil
+}
+
+// RemoveMember removes member, or withdraws their pending invite. The last owner cannot leave.
+func (t *Team) RemoveMember(member OwnerID) error {
+	if _, ok := t.invites[member]; ok {
+		delete(t.invites, member)
+		return nil
+	}
+	role, ok := t.members[member]
+	if !ok {
+		return fmt.Errorf("%s is not a member of team %s", member.String(), t.id.String())
+	}
+	if role == RoleOwner && t.owners() == 1 {
+		return errors.New("a team needs at least one owner")
+	}
+	delete(t.members, member)
+	delete(t.optedOut, member)
+	return nil
+}
+
+// SetLeaderboardOptOut hides member from, or shows them again on, the team's leaderboards.
+func (t *Team) SetLeaderboardOptOut(member OwnerID, optOut bool) error {
+	if _, ok := t.members[member]; !ok {
+		return fmt.Errorf("%s is not a member of team %s", member.String(), t.id.String())
+	}
+	if optOut {
+		t.optedOut[member] = true
+	} else {
+		delete(t.optedOut, member)
+	}
+	return nil
+}
+
+// OptedOut reports whether member is hidden from leaderboards.
+func (t *Team) OptedOut(member OwnerID) bool {
+	return t.optedOut[member]
+}
+
+func (t *Team) owners() int {
+	n := 0
+	for _, role := range t.members {
+		if role == RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// RawTeam represents persisted primitive values of a Team.
+type RawTeam struct {
+	ID   string
+	Name string
+	// Members maps each member's OwnerID to their role.
+	Members map[string]string
+	// LeaderboardOptOut lists the members hidden from leaderboards, ordered by name.
+	LeaderboardOptOut []string
+	// Invites maps each invited user to the role they will get once they accept.
+	Invites map[string]string
+}
+
+// RehydrateTeam rebuilds a Team from persisted primitives.
+func RehydrateTeam(raw RawTeam) (*Team, error) {
+	id, err := NewTeamID(raw.ID)
+	if err != nil {
+		return nil, err
+	}
+	validName, err := newName("team", raw.Name)
+	if err != nil {
+		return nil, err
+	}
+	team := &Team{id: id, name: validName, members: make(map[OwnerID]Role, len(raw.Members)), optedOut: map[OwnerID]bool{}, invites: map[OwnerID]Role{}}
+	for value, roleValue := range raw.Members {
+		member, err := NewOwnerID(value)
+		if err != nil {
+			return nil, err
+		}
+		role, err := ParseRole(roleValue)
+		if err != nil {
+			return nil, err
+		}
+		team.members[member] = role
+	}
+	if team.owners() == 0 {
+		return nil, fmt.Errorf("team %s has no owner", raw.ID)
+	}
+	for _, value := range raw.LeaderboardOptOut {
+		member, err := NewOwnerID(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := team.SetLeaderboardOptOut(member, true); err != nil {
+			return nil, err
+		}
+	}
+	for value, roleValue := range raw.Invites {
+		member, err := NewOwnerID(value)
+		if err != nil {
+			return nil, err
+		}
+		role, err := ParseRole(roleValue)
+		if err != nil {
+			return nil, err
+		}
+		if err := team.Invite(member, role); err != nil {
+			return nil, err
+		}
+	}
+	return team, nil
+}
+
+// Raw flattens the Team into persisted primitives.
+func (t *Team) Raw() RawTeam {
+	members := make(map[string]string, len(t.members))
+	for member, role := range t.members {
+		members[member.String()] = string(role)
+	}
+	var optedOut []string
+	for member := range t.optedOut {
+		optedOut = append(optedOut, member.String())
+	}
+	sort.Strings(optedOut)
+	var invites map[string]string
+	if len(t.invites) > 0 {
+		invites = make(map[string]string, len(t.invites))
+		for member, role := range t.invites {
+			invites[member.String()] = string(role)
+		}
+	}
+	return RawTeam{ID: t.id.String(), Name: t.name, Members: members, LeaderboardOptOut: optedOut, Invites: invites}
+}
@@ -0,0 +1,87 @@
+package donelog
+
+import "testing"
+
+func TestTeamMembership(t *testing.T) {
+	alice, _ := NewOwnerID("alice")
+	bob, _ := NewOwnerID("bob")
+	carol, _ := NewOwnerID("carol")
+	id, _ := NewTeamID("book-club")
+	if _, err := NewTeam(id, "Book club", OwnerID{}); err == nil {
+		t.Fatal("the single-user owner must not own a team")
+	}
+	team, err := NewTeam(id, " Book club ", alice)
+	if err != nil {
+		t.Fatalf("NewTeam: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func() error
+		wantErr bool
+	}{
+		{name: "NG: set the role of a non-member", change: func() error { return team.SetRole(bob, RoleEditor) }, wantErr: true},
+		{name: "OK: invite an editor", change: func() error { return team.Invite(bob, RoleEditor) }},
+		{name: "NG: invitees are not members yet", change: func() error { return team.SetLeaderboardOptOut(bob, true) }, wantErr: true},
+		{name: "OK: accept the invite", change: func() error { return team.Accept(bob) }},
+		{name: "NG: accept without an invite", change: func() error { return team.Accept(carol) }, wantErr: true},
+		{name: "NG: invite a member", change: func() error { return team.Invite(bob, RoleViewer) }, wantErr: true},
+		{name: "NG: unknown role", change: func() error { return team.SetRole(bob, Role("admin")) }, wantErr: true},
+		{name: "NG: demote the last owner", change: func() error { return team.SetRole(alice, RoleViewer) }, wantErr: true},
+		{name: "NG: the last owner leaves", change: func() error { return team.RemoveMember(alice) }, wantErr: true},
+		{name: "OK: promote a second owner", change: func() error { return team.SetRole(bob, RoleOwner) }},
+		{name: "OK: an owner steps down once another remains", change: func() error { return team.SetRole(alice, RoleViewer) }},
+		{name: "NG: remove a stranger", change: func() error { return team.RemoveMember(carol) }, wantErr: true},
+		{name: "OK: invite a viewer", change: func() error { return team.Invite(carol, RoleViewer) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.change(); (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	restored, err := RehydrateTeam(team.Raw())
+	if err != nil {
+		t.Fatalf("RehydrateTeam: %v", err)
+	}
+	members := restored.Members()
+	if restored.Name() != "Book club" || len(members) != 2 || members[0] != (TeamMember{Member: alice, Role: RoleViewer}) || members[1].Role != RoleOwner {
+		t.Fatalf("unexpected team: %q %+v", restored.Name(), members)
+	}
+	if role, ok := restored.InvitedAs(carol); !ok || role != RoleViewer {
+		t.Fatalf("the pending invite must survive rehydration: %+v", restored.Invites())
+	}
+	if err := restored.RemoveMember(carol); err != nil || len(restored.Invites()) != 0 {
+		t.Fatalf("withdraw invite: %v %+v", err, restored.Invites())
+	}
+	if role, _ := restored.RoleOf(alice); role.CanLog() || !RoleEditor.CanLog() || RoleEditor.CanManage() {
+		t.Fatal("unexpected role permissions")
+	}
+	if _, err := RehydrateTeam(RawTeam{ID: "book-club", Name: "Book club", Members: map[string]string{"alice": "viewer"}}); err == nil {
+		t.Fatal("a team without an owner should be rejected")
+	}
+
+	track, err := NewTeamTrack(mustTrackID(t, "dune"), "Dune", id, 10)
+	if err != nil {
+		t.Fatalf("NewTeamTrack: %v", err)
+	}
+	raw := track.Raw()
+	if raw.Team != "book-club" || raw.DefaultCategoryID != "" {
+		t.Fatalf("unexpected raw team track: %+v", raw)
+	}
+	raw.DefaultCategoryID = "pages"
+	if _, err := RehydrateTrack(raw); err == nil {
+		t.Fatal("a team track with a default category should be rejected")
+	}
+}
+
+func mustTrackID(t *testing.T, value string) TrackID {
+	t.Helper()
+	id, err := NewTrackID(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
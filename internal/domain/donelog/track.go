@@ -0,0 +1,179 @@
+package donelog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const maxNameLength = 60
+
+// newName validates a display name shared by Track and Category.
+func newName(kind, value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return "", fmt.Errorf("%s name must not be empty", kind)
+	}
+	if strings.ContainsAny(trimmed, "\r\n") {
+		return "", fmt.Errorf("%s name must not contain line breaks", kind)
+	}
+	if len([]rune(trimmed)) > maxNameLength {
+		return "", fmt.Errorf("%s name must be <= %d characters", kind, maxNameLength)
+	}
+	return trimmed, nil
+}
+
+// Track is the aggregate that a DONELOG is recorded against (a book, an exam, a theme...).
+type Track struct {
+	id              TrackID
+	name            string
+	defaultCategory *CategoryID
+	sortOrder       int
+	active          bool
+	// team is set for a Track shared by a Team instead of owned by one user.
+	team *TeamID
+}
+
+// NewTrack constructs an active Track.
+func NewTrack(id TrackID, name string, defaultCategory *CategoryID, sortOrder int) (*Track, error) {
+	if id == (TrackID{}) {
+		return nil, errors.New("track id must not be empty")
+	}
+	validName, err := newName("track", name)
+	if err != nil {
+		return nil, err
+	}
+	return &Track{
+		id:              id,
+		name:            validName,
+		defaultCategory: defaultCategory,
+		sortOrder:       sortOrder,
+		active:          true,
+	}, nil
+}
+
+// NewTeamTrack constructs an active Track shared by team. Categories are personal,
+// so a team Track has no default Category.
+func NewTeamTrack(id TrackID, name string, team TeamID, sortOrder int) (*Track, error) {
+	if team == (TeamID{}) {
+		return nil, errors.New("team id must not be empty")
+	}
+	track, err := NewTrack(id, name, nil, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+	track.team = &team
+	return track, nil
+}
+
+// Rename changes the display name.
+func (t *Track) Rename(name string) error {
+	validName, err := newName("track", name)
+	if err != nil {
+		return err
+	}
+	t.name = validName
+	return nil
+}
+
+// Archive deactivates the Track. Archived Tracks accept no new DONELOGs.
+func (t *Track) Archive() {
+	t.active = false
+}
+
+// Activate re-enables an archived Track.
+func (t *Track) Activate() {
+	t.active = true
+}
+
+// ID returns the Track identifier.
+func (t *Track) ID() TrackID {
+	return t.id
+}
+
+// Name returns the display name.
+func (t *Track) Name() string {
+	return t.name
+}
+
+// DefaultCategory returns the Category suggested for new DONELOGs, if any.
+func (t *Track) DefaultCategory() *CategoryID {
+	return t.defaultCategory
+}
+
+// SortOrder returns the display order.
+func (t *Track) SortOrder() int {
+	return t.sortOrder
+}
+
+// Active reports whether new DONELOGs may reference the Track.
+func (t *Track) Active() bool {
+	return t.active
+}
+
+// Team returns the Team sharing the Track, or nil for a personal Track.
+func (t *Track) Team() *TeamID {
+	return t.team
+}
+
+// RawTrack represents persisted primitive values of a Track.
+type RawTrack struct {
+	ID                string
+	Name              string
+	DefaultCategoryID string
+	SortOrder         int
+	Active            bool
+	// Owner is stamped by the repository from the request context; empty in a single-user store.
+	Owner string
+	// Team is set for a team Track, which has no Owner.
+	Team string
+}
+
+// RehydrateTrack rebuilds a Track from persisted primitives.
+func RehydrateTrack(raw RawTrack) (*Track, error) {
+	id, err := NewTrackID(raw.ID)
+	if err != nil {
+		return nil, err
+	}
+	var defaultCategory *CategoryID
+	if raw.DefaultCategoryID != "" {
+		categoryID, err := NewCategoryID(raw.DefaultCategoryID)
+		if err != nil {
+			return nil, err
+		}
+		defaultCategory = &categoryID
+	}
+	track, err := NewTrack(id, raw.Name, defaultCategory, raw.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+	if raw.Team != "" {
+		team, err := NewTeamID(raw.Team)
+		if err != nil {
+			return nil, err
+		}
+		if defaultCategory != nil {
+			return nil, fmt.Errorf("team track %s must not have a default category", raw.ID)
+		}
+		track.team = &team
+	}
+	track.active = raw.Active
+	return track, nil
+}
+
+// Raw flattens the Track into persisted primitives.
+func (t *Track) Raw() RawTrack {
+	raw := RawTrack{
+		ID:        t.id.String(),
+		Name:      t.name,
+		SortOrder: t.sortOrder,
+		Active:    t.active,
+	}
+	if t.defaultCategory != nil {
+		raw.DefaultCategoryID = t.defaultCategory.String()
+	}
+	if t.team != nil {
+		raw.Team = t.team.String()
+	}
+	return raw
+}
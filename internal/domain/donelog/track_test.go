@@ -0,0 +1,85 @@
+package donelog
+
+import "testing"
+
+func TestRehydrateTrack(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         RawTrack
+		wantErr     bool
+		wantDefault string
+	}{
+		{
+			name:        "OK: archived track with default category",
+			raw:         RawTrack{ID: "track_book", Name: "  Clean Architecture ", DefaultCategoryID: "cat_reading", SortOrder: 100, Active: false},
+			wantDefault: "cat_reading",
+		},
+		{
+			name:    "NG: empty name",
+			raw:     RawTrack{ID: "track_book", Name: " "},
+			wantErr: true,
+		},
+		{
+			name:    "NG: invalid default category",
+			raw:     RawTrack{ID: "track_book", Name: "Book", DefaultCategoryID: "Reading"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track, err := RehydrateTrack(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if track.Name() != "Clean Architecture" {
+				t.Fatalf("expected trimmed name, got %q", track.Name())
+			}
+			if track.DefaultCategory().String() != tt.wantDefault {
+				t.Fatalf("expected default category %s, got %s", tt.wantDefault, track.DefaultCategory())
+			}
+			if track.Raw() != (RawTrack{ID: "track_book", Name: "Clean Architecture", DefaultCategoryID: "cat_reading", SortOrder: 100}) {
+				t.Fatalf("unexpected raw: %+v", track.Raw())
+			}
+		})
+	}
+}
+
+func TestNewCategory(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		catName string
+		wantErr bool
+	}{
+		{"OK: valid category", "cat_reading", "読書", false},
+		{"NG: line break in name", "cat_reading", "Read\ning", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, _ := NewCategoryID(tt.id)
+			category, err := NewCategory(id, tt.catName, 10)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !category.Active() {
+				t.Fatalf("expected new category to be active")
+			}
+			category.Archive()
+			restored, err := RehydrateCategory(category.Raw())
+			if err != nil {
+				t.Fatalf("rehydrate failed: %v", err)
+			}
+			if restored.Active() || restored.Name() != tt.catName {
+				t.Fatalf("unexpected rehydrated category: %+v", restored.Raw())
+			}
+		})
+	}
+}
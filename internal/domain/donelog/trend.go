@@ -0,0 +1,223 @@
+package donelog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxTrendDays keeps trend analysis to one year of daily points.
+const maxTrendDays = 366
+
+// Moving average windows, in days.
+const (
+	shortMovingAverageDays = 7
+	longMovingAverageDays  = 30
+)
+
+// TrendPoint is one day of a Trend with its derived values.
+type TrendPoint struct {
+	SummaryPoint
+	movingAverage7  float64
+	movingAverage30 float64
+	cumulative      int
+	index           int
+}
+
+// MovingAverage7 returns the trailing 7-day average, rounded to two decimals.
+// ok is false for the first six days of the series, where the window is not full yet.
+func (p TrendPoint) MovingAverage7() (value float64, ok bool) {
+	return p.movingAverage7, p.index >= shortMovingAverageDays-1
+}
+
+// MovingAverage30 returns the trailing 30-day average, rounded to two decimals.
+// ok is false until the series has 30 days.
+func (p TrendPoint) MovingAverage30() (value float64, ok bool) {
+	return p.movingAverage30, p.index >= longMovingAverageDays-1
+}
+
+// Cumulative returns the running total up to and including this day.
+func (p TrendPoint) Cumulative() int {
+	return p.cumulative
+}
+
+// BucketTotal is the total of one day or week, identified by its label.
+type BucketTotal struct {
+	label string
+	count int
+}
+
+// Label returns the day (2024-05-01) or ISO week (2024-W18) label.
+func (b BucketTotal) Label() string {
+	return b.label
+}
+
+// Count returns the bucket total.
+func (b BucketTotal) Count() int {
+	return b.count
+}
+
+// Trend is the read-only result of AnalyzeTrend: the daily series and statistics over it.
+type Trend struct {
+	summary   LogSummary
+	points    []TrendPoint
+	slope     float64
+	median    float64
+	bestDay   BucketTotal
+	worstDay  BucketTotal
+	bestWeek  *BucketTotal
+	worstWeek *BucketTotal
+}
+
+// Summary returns the underlying daily LogSummary.
+func (t Trend) Summary() LogSummary {
+	return t.summary
+}
+
+// Points returns the daily series with moving averages and cumulative totals.
+func (t Trend) Points() []TrendPoint {
+	return append([]TrendPoint(nil), t.points...)
+}
+
+// Slope is the least-squares regression slope of the daily counts, in Count per day, rounded to two decimals.
+func (t Trend) Slope() float64 {
+	return t.slope
+}
+
+// Median is the median daily Count, zero days included.
+func (t Trend) Median() float64 {
+	return t.median
+}
+
+// BestDay and WorstDay return the highest and lowest days; ties go to the earliest day.
+func (t Trend) BestDay() BucketTotal {
+	return t.bestDay
+}
+
+func (t Trend) WorstDay() BucketTotal {
+	return t.worstDay
+}
+
+// BestWeek and WorstWeek consider only weeks lying entirely inside the period,
+// so partial weeks at the edges do not count as the worst. They are nil when there is no full week.
+func (t Trend) BestWeek() *BucketTotal {
+	return t.bestWeek
+}
+
+func (t Trend) WorstWeek() *BucketTotal {
+	return t.worstWeek
+}
+
+// AnalyzeTrend builds the zero-filled daily series for period and derives trend statistics from it.
+// Weeks start on weekStart and are labeled like SummarizeByWeek.
+func (LogSummaryService) AnalyzeTrend(categoryID *CategoryID, period Period, logs []*DoneLog, weekStart WeekStart) (Trend, error) {
+	if _, err := ParseWeekStart(string(weekStart)); err != nil {
+		return Trend{}, err
+	}
+	if daysBetween(period.Start(), period.End())+1 > maxTrendDays {
+		return Trend{}, fmt.Errorf("trend period must be <= %d days", maxTrendDays)
+	}
+	var labels []string
+	for d := period.Start(); !d.After(period.End()); d = d.AddDate(0, 0, 1) {
+		labels = append(labels, dayLabel(d))
+	}
+	summary, err := summarize(categoryID, period, logs, labels, dayLabel)
+	if err != nil {
+		return Trend{}, err
+	}
+
+	points := summary.Points()
+	counts := make([]int, len(points))
+	for i, p := range points {
+		counts[i] = p.Count().Int()
+	}
+
+	trend := Trend{
+		summary: summary,
+		slope:   round2(regressionSlope(counts)),
+		median:  median(counts),
+	}
+	cumulative := 0
+	for i, p := range points {
+		cumulative += counts[i]
+		trend.points = append(trend.points, TrendPoint{
+			SummaryPoint:    p,
+			movingAverage7:  round2(trailingAverage(counts, i, shortMovingAverageDays)),
+			movingAverage30: round2(trailingAverage(counts, i, longMovingAverageDays)),
+			cumulative:      cumulative,
+			index:           i,
+		})
+		if i == 0 || counts[i] > trend.bestDay.count {
+			trend.bestDay = BucketTotal{label: p.Label(), count: counts[i]}
+		}
+		if i == 0 || counts[i] < trend.worstDay.count {
+			trend.worstDay = BucketTotal{label: p.Label(), count: counts[i]}
+		}
+	}
+
+	// Weekly totals over full weeks only.
+	first := weekStart.startOfWeek(period.Start())
+	if first.Before(period.Start()) {
+		first = first.AddDate(0, 0, 7)
+	}
+	for w := first; !w.AddDate(0, 0, 6).After(period.End()); w = w.AddDate(0, 0, 7) {
+		offset := daysBetween(period.Start(), w)
+		week := BucketTotal{label: weekStart.label(w)}
+		for _, c := range counts[offset : offset+7] {
+			week.count += c
+		}
+		if trend.bestWeek == nil || week.count > trend.bestWeek.count {
+			best := week
+			trend.bestWeek = &best
+		}
+		if trend.worstWeek == nil || week.count < trend.worstWeek.count {
+			worst := week
+			trend.worstWeek = &worst
+		}
+	}
+	return trend, nil
+}
+
+// trailingAverage averages up to window values ending at index i.
+func trailingAverage(counts []int, i, window int) float64 {
+	start := max(0, i-window+1)
+	sum := 0
+	for _, c := range counts[start : i+1] {
+		sum += c
+	}
+	return float64(sum) / float64(i-start+1)
+}
+
+// regressionSlope fits counts against their index by ordinary least squares.
+func regressionSlope(counts []int) float64 {
+	n := float64(len(counts))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, c := range counts {
+		x, y := float64(i), float64(c)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
+
+func median(counts []int) float64 {
+	if len(counts) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), counts...)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return float64(sorted[mid])
+	}
+	return float64(sorted[mid-1]+sorted[mid]) / 2
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -3,14 +3,22 @@ package donelog
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 var (
 	ulidPattern = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
 	slugPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	// ownerPattern admits login names and e-mail addresses but never "/" or whitespace.
+	ownerPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]{0,63}$`)
+	// htmlPattern matches raw HTML tags and comments, but not a lone "<" as in "a < b".
+	htmlPattern = regexp.MustCompile(`<!--[\s\S]*?-->|</?[A-Za-z][^<>]*>`)
 )
 
 // DoneLogID represents the identifier of a DONELOG entry.
@@ -103,6 +111,281 @@ func (id CategoryID) String() string {
 	return id.value
 }
 
+// Tag labels a DONELOG across Categories, e.g. "go" or "performance".
+type Tag struct {
+	value string
+}
+
+const (
+	maxTagLength = 32
+	// maxTagsPerDoneLog keeps tag sets short enough to show next to a title.
+	maxTagsPerDoneLog = 10
+)
+
+// NewTag validates and creates a Tag. Tags follow the same slug rules as TrackID.
+func NewTag(value string) (Tag, error) {
+	if value == "" {
+		return Tag{}, errors.New("tag must not be empty")
+	}
+	if len(value) > maxTagLength || !slugPattern.MatchString(value) {
+		return Tag{}, fmt.Errorf("invalid tag: %s", value)
+	}
+	return Tag{value: value}, nil
+}
+
+// String returns the tag value.
+func (t Tag) String() string {
+	return t.value
+}
+
+// TagSet is an immutable set of Tags ordered by value. The zero value is the empty set.
+type TagSet struct {
+	tags []Tag
+}
+
+// NewTagSet creates a TagSet, dropping duplicates. It holds at most 10 Tags.
+func NewTagSet(tags ...Tag) (TagSet, error) {
+	seen := map[Tag]bool{}
+	var unique []Tag
+	for _, tag := range tags {
+		if tag == (Tag{}) {
+			return TagSet{}, errors.New("tag must not be empty")
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	if len(unique) > maxTagsPerDoneLog {
+		return TagSet{}, fmt.Errorf("a DONELOG may have at most %d tags", maxTagsPerDoneLog)
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i].value < unique[j].value })
+	return TagSet{tags: unique}, nil
+}
+
+// ParseTagSet validates each value with NewTag and creates a TagSet.
+func ParseTagSet(values []string) (TagSet, error) {
+	tags := make([]Tag, 0, len(values))
+	for _, value := range values {
+		tag, err := NewTag(value)
+		if err != nil {
+			return TagSet{}, err
+		}
+		tags = append(tags, tag)
+	}
+	return NewTagSet(tags...)
+}
+
+// Tags returns the Tags ordered by value.
+func (s TagSet) Tags() []Tag {
+	return append([]Tag(nil), s.tags...)
+}
+
+// Strings returns the tag values ordered, or nil for the empty set.
+func (s TagSet) Strings() []string {
+	if len(s.tags) == 0 {
+		return nil
+	}
+	values := make([]string, len(s.tags))
+	for i, tag := range s.tags {
+		values[i] = tag.value
+	}
+	return values
+}
+
+// Len returns the number of Tags.
+func (s TagSet) Len() int {
+	return len(s.tags)
+}
+
+// Contains reports whether tag is in the set.
+func (s TagSet) Contains(tag Tag) bool {
+	for _, t := range s.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// With returns a new set that also holds tags.
+func (s TagSet) With(tags ...Tag) (TagSet, error) {
+	return NewTagSet(append(s.Tags(), tags...)...)
+}
+
+// Without returns a new set without tags. Tags not in the set are ignored.
+func (s TagSet) Without(tags ...Tag) TagSet {
+	var kept []Tag
+	for _, t := range s.tags {
+		drop := false
+		for _, tag := range tags {
+			drop = drop || t == tag
+		}
+		if !drop {
+			kept = append(kept, t)
+		}
+	}
+	return TagSet{tags: kept}
+}
+
+// Note is free-form Markdown about a DONELOG, such as what was learned. The zero value is the empty Note.
+type Note struct {
+	value string
+}
+
+const maxNoteLength = 2000
+
+// NewNote sanitizes and creates a Note. Line endings become "\n", control characters other than
+// tab and newline are dropped and raw HTML is stripped, so clients can render the Markdown as is.
+// A blank value gives the empty Note.
+func NewNote(value string) (Note, error) {
+	value = strings.ToValidUTF8(value, "")
+	value = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(value)
+	value = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, value)
+	// Stripping can join the pieces of a split tag such as "<<b>script>", so repeat until stable.
+	for {
+		stripped := htmlPattern.ReplaceAllString(value, "")
+		if stripped == value {
+			break
+		}
+		value = stripped
+	}
+	value = strings.TrimSpace(value)
+	if utf8.RuneCountInString(value) > maxNoteLength {
+		return Note{}, fmt.Errorf("note must be <= %d characters", maxNoteLength)
+	}
+	return Note{value: value}, nil
+}
+
+// String returns the Markdown source.
+func (n Note) String() string {
+	return n.value
+}
+
+// IsEmpty reports whether there is no note.
+func (n Note) IsEmpty() bool {
+	return n.value == ""
+}
+
+// Link is an absolute http or https URL attached to a DONELOG, e.g. the article it was about.
+type Link struct {
+	value string
+}
+
+const (
+	maxLinkLength      = 2048
+	maxLinksPerDoneLog = 10
+)
+
+// NewLink validates and creates a Link. URLs with credentials are rejected so they never end up in logs or exports.
+func NewLink(value string) (Link, error) {
+	if value == "" {
+		return Link{}, errors.New("link must not be empty")
+	}
+	if len(value) > maxLinkLength {
+		return Link{}, fmt.Errorf("link must be <= %d bytes", maxLinkLength)
+	}
+	u, err := url.Parse(value)
+	if err != nil || strings.ContainsFunc(value, unicode.IsSpace) || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Link{}, fmt.Errorf("invalid link: %s", value)
+	}
+	if u.User != nil {
+		return Link{}, fmt.Errorf("link must not contain credentials: %s", u.Redacted())
+	}
+	return Link{value: u.String()}, nil
+}
+
+// String returns the URL.
+func (l Link) String() string {
+	return l.value
+}
+
+// Links is an immutable list of Links in the order they were given. The zero value is the empty list.
+type Links struct {
+	links []Link
+}
+
+// NewLinks creates Links, dropping duplicates. It holds at most 10 Links.
+func NewLinks(links ...Link) (Links, error) {
+	seen := map[Link]bool{}
+	var unique []Link
+	for _, link := range links {
+		if link == (Link{}) {
+			return Links{}, errors.New("link must not be empty")
+		}
+		if !seen[link] {
+			seen[link] = true
+			unique = append(unique, link)
+		}
+	}
+	if len(unique) > maxLinksPerDoneLog {
+		return Links{}, fmt.Errorf("a DONELOG may have at most %d links", maxLinksPerDoneLog)
+	}
+	return Links{links: unique}, nil
+}
+
+// ParseLinks validates each value with NewLink and creates Links.
+func ParseLinks(values []string) (Links, error) {
+	links := make([]Link, 0, len(values))
+	for _, value := range values {
+		link, err := NewLink(value)
+		if err != nil {
+			return Links{}, err
+		}
+		links = append(links, link)
+	}
+	return NewLinks(links...)
+}
+
+// Links returns the Links in order.
+func (l Links) Links() []Link {
+	return append([]Link(nil), l.links...)
+}
+
+// Strings returns the URLs in order, or nil when there are none.
+func (l Links) Strings() []string {
+	if len(l.links) == 0 {
+		return nil
+	}
+	values := make([]string, len(l.links))
+	for i, link := range l.links {
+		values[i] = link.value
+	}
+	return values
+}
+
+// Len returns the number of Links.
+func (l Links) Len() int {
+	return len(l.links)
+}
+
+// OwnerID identifies the user who owns DONELOGs, Tracks, Categories and Goals.
+// The zero value is the owner of a single-user store; data written before owners existed belongs to it.
+type OwnerID struct {
+	value string
+}
+
+// NewOwnerID validates and creates an OwnerID.
+func NewOwnerID(value string) (OwnerID, error) {
+	if value == "" {
+		return OwnerID{}, errors.New("owner id must not be empty")
+	}
+	if !ownerPattern.MatchString(value) {
+		return OwnerID{}, fmt.Errorf("invalid owner id: %s", value)
+	}
+	return OwnerID{value: value}, nil
+}
+
+// String returns the identifier value, or "" for the single-user owner.
+func (id OwnerID) String() string {
+	return id.value
+}
+
 // Count expresses "how many things were done".
 type Count struct {
 	value int
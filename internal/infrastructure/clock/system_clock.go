@@ -0,0 +1,12 @@
+// Package clock provides time sources for the application layer.
+package clock
+
+import "time"
+
+// SystemClock reads the wall clock.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
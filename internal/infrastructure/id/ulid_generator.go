@@ -0,0 +1,109 @@
+// Package id provides identifier generators for the DONELOG application.
+package id
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator issues monotonic ULIDs: IDs created within the same millisecond
+// still sort in creation order, which keeps bulk imports ordered.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entropy io.Reader
+	lastMs  uint64
+	last    [10]byte
+}
+
+// NewULIDGenerator returns a generator backed by the system clock and crypto/rand.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now, entropy: rand.Reader}
+}
+
+// NewDoneLogID implements command.IDGenerator.
+func (g *ULIDGenerator) NewDoneLogID(ctx context.Context) (donelog.DoneLogID, error) {
+	value, err := g.next()
+	if err != nil {
+		return donelog.DoneLogID{}, err
+	}
+	return donelog.NewDoneLogID(value)
+}
+
+// NewGoalID implements command.GoalIDGenerator.
+func (g *ULIDGenerator) NewGoalID(ctx context.Context) (donelog.GoalID, error) {
+	value, err := g.next()
+	if err != nil {
+		return donelog.GoalID{}, err
+	}
+	return donelog.NewGoalID(value)
+}
+
+// NewString returns a fresh ULID for aggregates other than DONELOG.
+func (g *ULIDGenerator) NewString() (string, error) {
+	return g.next()
+}
+
+func (g *ULIDGenerator) next() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms == g.lastMs {
+		if !increment(&g.last) {
+			// Random part overflowed: move on to the next millisecond.
+			ms++
+			if _, err := io.ReadFull(g.entropy, g.last[:]); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		if _, err := io.ReadFull(g.entropy, g.last[:]); err != nil {
+			return "", err
+		}
+	}
+	g.lastMs = ms
+
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(raw[6:], g.last[:])
+	return encode(raw), nil
+}
+
+func increment(b *[10]byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode renders 128 bits as 26 Crockford base32 characters.
+func encode(raw [16]byte) string {
+	out := make([]byte, 26)
+	// 130 bits of output; the two leading bits are always zero.
+	var bitBuf uint32
+	bits := 2
+	pos := 0
+	for _, b := range raw {
+		bitBuf = bitBuf<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockford[(bitBuf>>uint(bits))&0x1F]
+			pos++
+		}
+	}
+	return string(out)
+}
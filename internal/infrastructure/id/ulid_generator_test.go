@@ -0,0 +1,32 @@
+package id
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestULIDGenerator(t *testing.T) {
+	fixed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	g := &ULIDGenerator{
+		now:     func() time.Time { return fixed },
+		entropy: bytes.NewReader(make([]byte, 100)),
+	}
+
+	first, err := g.NewDoneLogID(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := g.NewDoneLogID(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.String() != "01HWT0D7G00000000000000000" {
+		t.Fatalf("unexpected ulid encoding: %s", first.String())
+	}
+	if second.String() <= first.String() {
+		t.Fatalf("expected monotonic ids, got %s then %s", first, second)
+	}
+}
@@ -0,0 +1,171 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// clockSkew tolerates small clock differences between this server and the provider.
+const clockSkew = time.Minute
+
+// idTokenClaims are the ID token claims that are checked or used.
+type idTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	Expiry            int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	PreferredUsername string   `json:"preferred_username"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+}
+
+// audience accepts both forms of the aud claim: a single string or an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// verify checks the signature and the standard claims of an ID token (OpenID Connect Core 3.1.3.7).
+// The nonce is left to the caller, which knows the pending login.
+func (p *Provider) verify(ctx context.Context, token string) (idTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return idTokenClaims{}, errors.New("id_token is not a JWS compact serialization")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return idTokenClaims{}, fmt.Errorf("id_token header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return idTokenClaims{}, fmt.Errorf("id_token algorithm %q is not supported (want RS256)", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return idTokenClaims{}, errors.New("id_token signature is not base64url")
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return idTokenClaims{}, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return idTokenClaims{}, errors.New("id_token signature is invalid")
+	}
+
+	var claims idTokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return idTokenClaims{}, fmt.Errorf("id_token claims: %w", err)
+	}
+	now := p.now()
+	switch {
+	case claims.Issuer != p.metadata.Issuer:
+		return idTokenClaims{}, fmt.Errorf("id_token issuer %q is not %q", claims.Issuer, p.metadata.Issuer)
+	case !contains(claims.Audience, p.config.ClientID):
+		return idTokenClaims{}, fmt.Errorf("id_token audience %v does not include %q", []string(claims.Audience), p.config.ClientID)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID:
+		return idTokenClaims{}, fmt.Errorf("id_token azp %q is not %q", claims.AuthorizedParty, p.config.ClientID)
+	case claims.Expiry == 0 || !now.Before(time.Unix(claims.Expiry, 0).Add(clockSkew)):
+		return idTokenClaims{}, errors.New("id_token has expired")
+	case claims.IssuedAt > 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)):
+		return idTokenClaims{}, errors.New("id_token is issued in the future")
+	case claims.Subject == "":
+		return idTokenClaims{}, errors.New("id_token has no subject")
+	}
+	return claims, nil
+}
+
+// key returns the RSA key with kid, refreshing the JWKS once when it is unknown (the provider may have rotated keys).
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key := pick(p.keys, kid); key != nil {
+		return key, nil
+	}
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+	p.keys = keys
+	if key := pick(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("id_token key %q is not in the provider's JWKS", kid)
+}
+
+// pick returns the key with kid; an empty kid matches only when the set has exactly one key.
+func pick(keys map[string]*rsa.PublicKey, kid string) *rsa.PublicKey {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[kid]
+}
+
+// jsonWebKey is an RSA key of a JWKS document (RFC 7517). Other key types are skipped.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid modulus", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("key %q: invalid exponent", k.Kid)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("segment is not base64url")
+	}
+	return json.Unmarshal(b, v)
+}
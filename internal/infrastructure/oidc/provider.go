@@ -0,0 +1,194 @@
+// Package oidc is an OpenID Connect relying party for the authorization code flow with PKCE.
+// It discovers the provider from its issuer URL and verifies RS256-signed ID tokens against the provider's JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/auth"
+)
+
+// Config describes this application as a client of the provider.
+type Config struct {
+	// Issuer is the provider's issuer URL, e.g. "https://login.example.com/realms/acme".
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered at the provider, e.g. "https://donelog.example.com/api/auth/sso/callback".
+	RedirectURL string
+	// Scopes defaults to openid, profile and email.
+	Scopes []string
+	// HTTP defaults to a client with a 10 second timeout.
+	HTTP *http.Client
+}
+
+// metadata is the part of the discovery document the flow needs.
+type metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// Provider implements auth.IdentityProvider.
+type Provider struct {
+	config   Config
+	metadata metadata
+	now      func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+var _ auth.IdentityProvider = (*Provider)(nil)
+
+// Discover loads the provider's discovery document and checks that it supports PKCE with S256.
+func Discover(ctx context.Context, config Config) (*Provider, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("oidc: issuer, client id and redirect URL are required")
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.HTTP == nil {
+		config.HTTP = &http.Client{Timeout: 10 * time.Second}
+	}
+	p := &Provider{config: config, now: time.Now}
+
+	wellKnown := strings.TrimRight(config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &p.metadata); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if p.metadata.Issuer != config.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match the configured %q", p.metadata.Issuer, config.Issuer)
+	}
+	if p.metadata.AuthorizationEndpoint == "" || p.metadata.TokenEndpoint == "" || p.metadata.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: authorization, token and jwks endpoints are required")
+	}
+	if methods := p.metadata.CodeChallengeMethods; len(methods) > 0 && !contains(methods, "S256") {
+		return nil, errors.New("oidc discovery: provider does not support PKCE with S256")
+	}
+	return p, nil
+}
+
+// Issuer returns the provider's issuer identifier.
+func (p *Provider) Issuer() string {
+	return p.metadata.Issuer
+}
+
+// AuthCodeURL returns the authorization request URL for state, nonce and the S256 code challenge.
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// tokenResponse is the token endpoint's answer, successful or not.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems code at the token endpoint and verifies the returned ID token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (auth.IDClaims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return auth.IDClaims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	res, err := p.config.HTTP.Do(req)
+	if err != nil {
+		return auth.IDClaims{}, err
+	}
+	defer res.Body.Close()
+
+	var body tokenResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil {
+		return auth.IDClaims{}, fmt.Errorf("token endpoint: status %d: %w", res.StatusCode, err)
+	}
+	if res.StatusCode != http.StatusOK || body.Error != "" {
+		return auth.IDClaims{}, fmt.Errorf("token endpoint: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return auth.IDClaims{}, errors.New("token endpoint returned no id_token")
+	}
+
+	claims, err := p.verify(ctx, body.IDToken)
+	if err != nil {
+		return auth.IDClaims{}, err
+	}
+	result := auth.IDClaims{
+		Issuer:            claims.Issuer,
+		Subject:           claims.Subject,
+		Nonce:             claims.Nonce,
+		PreferredUsername: claims.PreferredUsername,
+	}
+	// An unverified address could name someone else's mailbox, so it is not used as a username.
+	if claims.EmailVerified {
+		result.Email = claims.Email
+	}
+	return result, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := p.config.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", target, res.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v)
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
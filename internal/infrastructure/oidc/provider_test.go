@@ -0,0 +1,92 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/infrastructure/oidc/oidctest"
+)
+
+const testVerifier = "0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestProviderExchange(t *testing.T) {
+	ctx := context.Background()
+	idp, err := oidctest.NewServer("donelog", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+	idp.SignIn(oidctest.User{Subject: "248289761001", PreferredUsername: "alice", Email: "alice@example.com"})
+
+	p, err := Discover(ctx, Config{Issuer: idp.URL, ClientID: "donelog", ClientSecret: "s3cret", RedirectURL: "http://donelog.test/callback"})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if _, err := Discover(ctx, Config{Issuer: idp.URL + "/", ClientID: "donelog", RedirectURL: "http://donelog.test/callback"}); err == nil {
+		t.Fatal("an issuer that differs from the discovery document should be rejected")
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(claims map[string]any)
+		verifier string
+		reuse    bool
+		wantErr  string
+	}{
+		{name: "OK: verified claims"},
+		{name: "NG: wrong PKCE verifier", verifier: strings.Repeat("x", 48), wantErr: "PKCE"},
+		{name: "NG: code used twice", reuse: true, wantErr: "unknown or used code"},
+		{name: "NG: other audience", mutate: func(c map[string]any) { c["aud"] = "someone-else" }, wantErr: "audience"},
+		{name: "NG: other issuer", mutate: func(c map[string]any) { c["iss"] = "https://evil.example" }, wantErr: "issuer"},
+		{name: "NG: expired", mutate: func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: "expired"},
+		{name: "NG: no subject", mutate: func(c map[string]any) { c["sub"] = "" }, wantErr: "subject"},
+		{
+			name:    "NG: several audiences without azp",
+			mutate:  func(c map[string]any) { c["aud"] = []string{"donelog", "other"} },
+			wantErr: "azp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.MutateClaims(tt.mutate)
+			defer idp.MutateClaims(nil)
+
+			code, state, err := oidctest.Authorize(p.AuthCodeURL("state-1", "nonce-1", challenge(testVerifier)))
+			if err != nil || state != "state-1" {
+				t.Fatalf("authorize: %q %v", state, err)
+			}
+			verifier := testVerifier
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.reuse {
+				if _, err := p.Exchange(ctx, code, verifier); err != nil {
+					t.Fatalf("first exchange: %v", err)
+				}
+			}
+			claims, err := p.Exchange(ctx, code, verifier)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if claims.Issuer != idp.URL || claims.Subject != "248289761001" || claims.Nonce != "nonce-1" ||
+				claims.PreferredUsername != "alice" || claims.Email != "alice@example.com" {
+				t.Fatalf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package filestore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Accounts, sessions, tokens and SSO identities are looked up before an owner is known, so unlike the DONELOG data
+// they are not scoped by the owner in ctx.
+
+// AccountRepository implements auth.AccountRepository.
+type AccountRepository struct {
+	store *Store
+}
+
+// Accounts returns the account repository backed by the store.
+func (s *Store) Accounts() AccountRepository {
+	return AccountRepository{store: s}
+}
+
+// Save inserts or replaces the account.
+func (r AccountRepository) Save(ctx context.Context, acct *account.Account) error {
+	raw := acct.Raw()
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Accounts[raw.Username] = raw
+		return nil
+	})
+}
+
+// FindByUsername returns nil when the account does not exist.
+func (r AccountRepository) FindByUsername(ctx context.Context, username donelog.OwnerID) (*account.RawAccount, error) {
+	var found *account.RawAccount
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Accounts[username.String()]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// SessionRepository implements auth.SessionRepository.
+type SessionRepository struct {
+	store *Store
+}
+
+// Sessions returns the web session repository backed by the store.
+func (s *Store) Sessions() SessionRepository {
+	return SessionRepository{store: s}
+}
+
+// Save inserts or replaces the session.
+func (r SessionRepository) Save(ctx context.Context, session *account.Session) error {
+	raw := session.Raw()
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Sessions[raw.Key] = raw
+		return nil
+	})
+}
+
+// FindByKey returns nil when the session does not exist.
+func (r SessionRepository) FindByKey(ctx context.Context, key string) (*account.RawSession, error) {
+	var found *account.RawSession
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Sessions[key]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Delete removes the session. Deleting a missing session is not an error.
+func (r SessionRepository) Delete(ctx context.Context, key string) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		delete(d.Sessions, key)
+		return nil
+	})
+}
+
+// DeleteExpired removes every session that has expired at now.
+func (r SessionRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		for key, raw := range d.Sessions {
+			if !now.Before(raw.ExpiresAt) {
+				delete(d.Sessions, key)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteByOwner removes every session of owner.
+func (r SessionRepository) DeleteByOwner(ctx context.Context, owner donelog.OwnerID) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		for key, raw := range d.Sessions {
+			if raw.Owner == owner.String() {
+				delete(d.Sessions, key)
+			}
+		}
+		return nil
+	})
+}
+
+// TokenRepository implements auth.TokenRepository.
+type TokenRepository struct {
+	store *Store
+}
+
+// Tokens returns the API token repository backed by the store.
+func (s *Store) Tokens() TokenRepository {
+	return TokenRepository{store: s}
+}
+
+// Save inserts or replaces the token.
+func (r TokenRepository) Save(ctx context.Context, token *account.Token) error {
+	raw := token.Raw()
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Tokens[raw.ID] = raw
+		return nil
+	})
+}
+
+// FindByID returns nil when the token does not exist.
+func (r TokenRepository) FindByID(ctx context.Context, id account.TokenID) (*account.RawToken, error) {
+	var found *account.RawToken
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Tokens[id.String()]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// ListByOwner returns the owner's tokens ordered by creation time, then ID.
+func (r TokenRepository) ListByOwner(ctx context.Context, owner donelog.OwnerID) ([]account.RawToken, error) {
+	var tokens []account.RawToken
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Tokens {
+			if raw.Owner == owner.String() {
+				tokens = append(tokens, raw)
+			}
+		}
+		return nil
+	})
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+	return tokens, err
+}
+
+// Delete removes the token. Deleting a missing token is not an error.
+func (r TokenRepository) Delete(ctx context.Context, id account.TokenID) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		delete(d.Tokens, id.String())
+		return nil
+	})
+}
+
+// IdentityRepository implements auth.IdentityRepository.
+type IdentityRepository struct {
+	store *Store
+}
+
+// Identities returns the SSO identity repository backed by the store.
+func (s *Store) Identities() IdentityRepository {
+	return IdentityRepository{store: s}
+}
+
+// Save inserts or replaces the identity.
+func (r IdentityRepository) Save(ctx context.Context, identity *account.Identity) error {
+	raw := identity.Raw()
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Identities[account.IdentityKey(raw.Issuer, raw.Subject)] = raw
+		return nil
+	})
+}
+
+// FindBySubject returns nil when the subject has not signed in before.
+func (r IdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*account.RawIdentity, error) {
+	var found *account.RawIdentity
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Identities[account.IdentityKey(issuer, subject)]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// UsernameTaken reports whether any identity maps to username.
+func (r IdentityRepository) UsernameTaken(ctx context.Context, username donelog.OwnerID) (bool, error) {
+	var taken bool
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Identities {
+			if raw.Username == username.String() {
+				taken = true
+				break
+			}
+		}
+		return nil
+	})
+	return taken, err
+}
+
+// OwnerDirectory implements auth.DataOwners.
+type OwnerDirectory struct {
+	store *Store
+}
+
+// Owners returns the directory of usernames that own data in the store.
+func (s *Store) Owners() OwnerDirectory {
+	return OwnerDirectory{store: s}
+}
+
+// HasData reports whether owner has any records, team memberships or journal entries.
+func (r OwnerDirectory) HasData(ctx context.Context, owner donelog.OwnerID) (bool, error) {
+	name := owner.String()
+	var found bool
+	err := r.store.read(ctx, func(d *dataset) error {
+		found = hasOwnedData(d, name)
+		return nil
+	})
+	return found, err
+}
+
+func hasOwnedData(d *dataset, owner string) bool {
+	for _, raw := range d.DoneLogs {
+		if raw.Owner == owner {
+			return true
+		}
+	}
+	for _, raw := range d.Tracks {
+		if raw.Owner == owner {
+			return true
+		}
+	}
+	for _, raw := range d.Categories {
+		if raw.Owner == owner {
+			return true
+		}
+	}
+	for _, raw := range d.Goals {
+		if raw.Owner == owner {
+			return true
+		}
+	}
+	if len(teamsOf(d, owner)) > 0 {
+		return true
+	}
+	for _, entry := range d.Audit {
+		if entry.Owner == owner {
+			return true
+		}
+	}
+	prefix := ownedKey(owner, "")
+	for key := range d.Settings {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	for key := range d.Undo {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	for key := range d.Idempotency {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingLoginRepository implements auth.PendingLoginRepository.
+type PendingLoginRepository struct {
+	store *Store
+}
+
+// PendingLogins returns the repository of SSO logins awaiting their callback.
+func (s *Store) PendingLogins() PendingLoginRepository {
+	return PendingLoginRepository{store: s}
+}
+
+// Save stores the pending login.
+func (r PendingLoginRepository) Save(ctx context.Context, login *account.PendingLogin) error {
+	raw := login.Raw()
+	return r.store.write(ctx, func(d *dataset) error {
+		d.SSOLogins[raw.Key] = raw
+		return nil
+	})
+}
+
+// Take removes and returns the pending login, so each one completes at most once.
+func (r PendingLoginRepository) Take(ctx context.Context, key string) (*account.RawPendingLogin, error) {
+	var found *account.RawPendingLogin
+	err := r.store.write(ctx, func(d *dataset) error {
+		if raw, ok := d.SSOLogins[key]; ok {
+			found = &raw
+			delete(d.SSOLogins, key)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// DeleteExpired removes every pending login that has expired at now.
+func (r PendingLoginRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		for key, raw := range d.SSOLogins {
+			if !now.Before(raw.ExpiresAt) {
+				delete(d.SSOLogins, key)
+			}
+		}
+		return nil
+	})
+}
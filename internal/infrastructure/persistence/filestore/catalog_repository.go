@@ -0,0 +1,195 @@
+package filestore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// TrackRepository stores Track aggregates.
+type TrackRepository struct {
+	store *Store
+}
+
+// Tracks returns the Track repository backed by the store.
+func (s *Store) Tracks() TrackRepository {
+	return TrackRepository{store: s}
+}
+
+// Save inserts or replaces the Track. A team Track is stored under its Team, any other Track is owned by the owner in ctx.
+func (r TrackRepository) Save(ctx context.Context, track *donelog.Track) error {
+	raw := track.Raw()
+	key := teamKey(raw.Team, raw.ID)
+	if raw.Team == "" {
+		raw.Owner = ownerOf(ctx)
+		key = ownedKey(raw.Owner, raw.ID)
+	}
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Tracks[key] = raw
+		return nil
+	})
+}
+
+// FindByID implements command.TrackStore. It returns the owner's own Track or a Track of one of their Teams,
+// and nil when neither exists.
+func (r TrackRepository) FindByID(ctx context.Context, id donelog.TrackID) (*donelog.RawTrack, error) {
+	var found *donelog.RawTrack
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, _, ok := resolveTrack(d, ownerOf(ctx), id.String()); ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// FindActiveByID implements command.TrackRepository. It returns archived Tracks with Active=false,
+// and team Tracks with ReadOnly=true when the owner's role does not allow recording DONELOGs.
+func (r TrackRepository) FindActiveByID(ctx context.Context, id donelog.TrackID) (*command.Track, error) {
+	var found *command.Track
+	err := r.store.read(ctx, func(d *dataset) error {
+		raw, canLog, ok := resolveTrack(d, ownerOf(ctx), id.String())
+		if !ok {
+			return nil
+		}
+		track, err := donelog.RehydrateTrack(raw)
+		if err != nil {
+			return err
+		}
+		found = &command.Track{ID: track.ID(), DefaultCategory: track.DefaultCategory(), Active: track.Active(), ReadOnly: !canLog, Team: track.Team()}
+		return nil
+	})
+	return found, err
+}
+
+// ListTracks implements query.TrackReader for the owner in ctx, ordered by SortOrder then ID.
+// It includes the Tracks of the owner's Teams that resolveTrack would find.
+func (r TrackRepository) ListTracks(ctx context.Context) ([]donelog.RawTrack, error) {
+	owner := ownerOf(ctx)
+	var tracks []donelog.RawTrack
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Tracks {
+			if raw.Team == "" && raw.Owner == owner {
+				tracks = append(tracks, raw)
+			}
+		}
+		for _, team := range teamsOf(d, owner) {
+			for _, raw := range d.Tracks {
+				if raw.Team != team.ID {
+					continue
+				}
+				if resolved, _, _ := resolveTrack(d, owner, raw.ID); resolved.Team == team.ID {
+					tracks = append(tracks, raw)
+				}
+			}
+		}
+		return nil
+	})
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].SortOrder != tracks[j].SortOrder {
+			return tracks[i].SortOrder < tracks[j].SortOrder
+		}
+		return tracks[i].ID < tracks[j].ID
+	})
+	return tracks, err
+}
+
+// ListTeamTracks implements command.TeamTrackStore, ordered by ID.
+func (r TrackRepository) ListTeamTracks(ctx context.Context, team donelog.TeamID) ([]donelog.RawTrack, error) {
+	var tracks []donelog.RawTrack
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Tracks {
+			if raw.Team == team.String() {
+				tracks = append(tracks, raw)
+			}
+		}
+		return nil
+	})
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].ID < tracks[j].ID })
+	return tracks, err
+}
+
+// FindMemberTeamTrack implements command.TeamTrackStore. Unlike FindByID it ignores member's own Tracks.
+func (r TrackRepository) FindMemberTeamTrack(ctx context.Context, member donelog.OwnerID, id donelog.TrackID) (*donelog.RawTrack, error) {
+	var found *donelog.RawTrack
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, team := range teamsOf(d, member.String()) {
+			if raw, ok := d.Tracks[teamKey(team.ID, id.String())]; ok {
+				found = &raw
+				return nil
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// CategoryRepository stores Category aggregates.
+type CategoryRepository struct {
+	store *Store
+}
+
+// Categories returns the Category repository backed by the store.
+func (s *Store) Categories() CategoryRepository {
+	return CategoryRepository{store: s}
+}
+
+// Save inserts or replaces the Category, owned by the owner in ctx.
+func (r CategoryRepository) Save(ctx context.Context, category *donelog.Category) error {
+	owner := ownerOf(ctx)
+	raw := category.Raw()
+	raw.Owner = owner
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Categories[ownedKey(owner, raw.ID)] = raw
+		return nil
+	})
+}
+
+// FindByID implements command.CategoryStore. It returns nil when the Category does not exist or belongs to another owner.
+func (r CategoryRepository) FindByID(ctx context.Context, id donelog.CategoryID) (*donelog.RawCategory, error) {
+	var found *donelog.RawCategory
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Categories[ownedKey(ownerOf(ctx), id.String())]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// FindActiveByID implements command.CategoryRepository. It returns archived Categories with Active=false.
+func (r CategoryRepository) FindActiveByID(ctx context.Context, id donelog.CategoryID) (*command.Category, error) {
+	var found *command.Category
+	err := r.store.read(ctx, func(d *dataset) error {
+		raw, ok := d.Categories[ownedKey(ownerOf(ctx), id.String())]
+		if !ok {
+			return nil
+		}
+		found = &command.Category{ID: id, Active: raw.Active}
+		return nil
+	})
+	return found, err
+}
+
+// ListCategories implements query.CategoryReader for the owner in ctx, ordered by SortOrder then ID.
+func (r CategoryRepository) ListCategories(ctx context.Context) ([]donelog.RawCategory, error) {
+	owner := ownerOf(ctx)
+	var categories []donelog.RawCategory
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Categories {
+			if raw.Owner == owner {
+				categories = append(categories, raw)
+			}
+		}
+		return nil
+	})
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, err
+}
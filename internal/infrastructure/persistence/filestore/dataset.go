@@ -0,0 +1,132 @@
+package filestore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/taketosaeki/donelog/internal/app/backup"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Snapshot implements backup.DatasetStore. It covers every owner.
+func (s *Store) Snapshot(ctx context.Context) (backup.Dataset, error) {
+	var data backup.Dataset
+	err := s.read(ctx, func(d *dataset) error {
+		for _, raw := range d.DoneLogs {
+			data.DoneLogs = append(data.DoneLogs, raw)
+		}
+		for _, raw := range d.Tracks {
+			data.Tracks = append(data.Tracks, raw)
+		}
+		for _, raw := range d.Categories {
+			data.Categories = append(data.Categories, raw)
+		}
+		for _, raw := range d.Goals {
+			data.Goals = append(data.Goals, raw)
+		}
+		for _, raw := range d.Teams {
+			data.Teams = append(data.Teams, raw)
+		}
+		for _, raw := range d.Accounts {
+			data.Accounts = append(data.Accounts, raw)
+		}
+		for _, raw := range d.Identities {
+			data.Identities = append(data.Identities, raw)
+		}
+		data.SignIns = true
+		data.Audit = append(data.Audit, d.Audit...)
+		data.Settings = make(map[string]string, len(d.Settings))
+		for k, v := range d.Settings {
+			data.Settings[k] = v
+		}
+		return nil
+	})
+	sortDoneLogs(data.DoneLogs)
+	sort.Slice(data.Tracks, func(i, j int) bool { return data.Tracks[i].ID < data.Tracks[j].ID })
+	sort.Slice(data.Categories, func(i, j int) bool { return data.Categories[i].ID < data.Categories[j].ID })
+	sortGoals(data.Goals)
+	sort.Slice(data.Teams, func(i, j int) bool { return data.Teams[i].ID < data.Teams[j].ID })
+	sort.Slice(data.Accounts, func(i, j int) bool { return data.Accounts[i].Username < data.Accounts[j].Username })
+	sort.Slice(data.Identities, func(i, j int) bool {
+		return account.IdentityKey(data.Identities[i].Issuer, data.Identities[i].Subject) < account.IdentityKey(data.Identities[j].Issuer, data.Identities[j].Subject)
+	})
+	return data, err
+}
+
+// Replace implements backup.DatasetStore for every owner. Undo journals and idempotency keys are cleared
+// because they describe the replaced data. Accounts and SSO identities come from the archive when it
+// carries them, so restored data keeps its users; sessions, tokens and pending SSO logins are kept.
+func (s *Store) Replace(ctx context.Context, data backup.Dataset) error {
+	next := newDataset()
+	for _, raw := range data.DoneLogs {
+		next.DoneLogs[ownedKey(raw.Owner, raw.ID)] = raw
+	}
+	for _, raw := range data.Tracks {
+		if raw.Team != "" {
+			next.Tracks[teamKey(raw.Team, raw.ID)] = raw
+			continue
+		}
+		next.Tracks[ownedKey(raw.Owner, raw.ID)] = raw
+	}
+	for _, raw := range data.Categories {
+		next.Categories[ownedKey(raw.Owner, raw.ID)] = raw
+	}
+	for _, raw := range data.Goals {
+		next.Goals[ownedKey(raw.Owner, raw.ID)] = raw
+	}
+	for _, raw := range data.Teams {
+		next.Teams[raw.ID] = raw
+	}
+	next.Audit = append([]donelog.AuditEntry(nil), data.Audit...)
+	for k, v := range data.Settings {
+		next.Settings[k] = v
+	}
+	for _, raw := range data.Accounts {
+		next.Accounts[raw.Username] = raw
+	}
+	for _, raw := range data.Identities {
+		next.Identities[account.IdentityKey(raw.Issuer, raw.Subject)] = raw
+	}
+	attributeTeamDoneLogs(next)
+
+	return s.write(ctx, func(d *dataset) error {
+		if !data.SignIns {
+			next.Accounts, next.Identities = d.Accounts, d.Identities
+		}
+		next.Sessions, next.Tokens, next.SSOLogins = d.Sessions, d.Tokens, d.SSOLogins
+		*d = *next
+		return nil
+	})
+}
+
+// SettingsRepository stores settings as key/value pairs. Keys are scoped by the owner in ctx.
+type SettingsRepository struct {
+	store *Store
+}
+
+// Settings returns the settings repository backed by the store.
+func (s *Store) Settings() SettingsRepository {
+	return SettingsRepository{store: s}
+}
+
+// Get returns the value and whether it was set.
+func (r SettingsRepository) Get(ctx context.Context, key string) (string, bool, error) {
+	var (
+		value string
+		ok    bool
+	)
+	err := r.store.read(ctx, func(d *dataset) error {
+		value, ok = d.Settings[ownedKey(ownerOf(ctx), key)]
+		return nil
+	})
+	return value, ok, err
+}
+
+// Set stores value under key.
+func (r SettingsRepository) Set(ctx context.Context, key, value string) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Settings[ownedKey(ownerOf(ctx), key)] = value
+		return nil
+	})
+}
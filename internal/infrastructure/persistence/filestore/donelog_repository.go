@@ -0,0 +1,113 @@
+package filestore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// DoneLogRepository implements the command and query side DONELOG contracts.
+type DoneLogRepository struct {
+	store *Store
+}
+
+// DoneLogs returns the DONELOG repository backed by the store.
+func (s *Store) DoneLogs() DoneLogRepository {
+	return DoneLogRepository{store: s}
+}
+
+// Save inserts or replaces the DONELOG, owned by the owner in ctx.
+func (r DoneLogRepository) Save(ctx context.Context, log *donelog.DoneLog) error {
+	owner := ownerOf(ctx)
+	raw := log.Raw()
+	raw.Owner = owner
+	return r.store.write(ctx, func(d *dataset) error {
+		d.DoneLogs[ownedKey(owner, raw.ID)] = raw
+		return nil
+	})
+}
+
+// FindByID returns nil when the DONELOG does not exist or belongs to another owner. Trashed DONELOGs are returned.
+func (r DoneLogRepository) FindByID(ctx context.Context, id donelog.DoneLogID) (*donelog.RawDoneLog, error) {
+	var found *donelog.RawDoneLog
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.DoneLogs[ownedKey(ownerOf(ctx), id.String())]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Delete removes the DONELOG permanently.
+func (r DoneLogRepository) Delete(ctx context.Context, id donelog.DoneLogID) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		delete(d.DoneLogs, ownedKey(ownerOf(ctx), id.String()))
+		return nil
+	})
+}
+
+// ListTrashedBefore implements command.TrashRepository for the owner in ctx.
+func (r DoneLogRepository) ListTrashedBefore(ctx context.Context, cutoff time.Time) ([]donelog.RawDoneLog, error) {
+	owner := ownerOf(ctx)
+	var logs []donelog.RawDoneLog
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.DoneLogs {
+			if raw.Owner == owner && raw.TrashedAt != nil && !raw.TrashedAt.After(cutoff) {
+				logs = append(logs, raw)
+			}
+		}
+		return nil
+	})
+	sortDoneLogs(logs)
+	return logs, err
+}
+
+// ListByTag implements command.TaggedDoneLogFinder for the owner in ctx. Trashed DONELOGs are included.
+func (r DoneLogRepository) ListByTag(ctx context.Context, tag donelog.Tag) ([]donelog.RawDoneLog, error) {
+	owner := ownerOf(ctx)
+	filter := query.DoneLogFilter{Tag: &tag}
+	var logs []donelog.RawDoneLog
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.DoneLogs {
+			if raw.Owner == owner && filter.Matches(raw) {
+				logs = append(logs, raw)
+			}
+		}
+		return nil
+	})
+	sortDoneLogs(logs)
+	return logs, err
+}
+
+// ListByPeriod implements query.DoneLogReader for the owner in ctx.
+func (r DoneLogRepository) ListByPeriod(ctx context.Context, period donelog.Period, filter query.DoneLogFilter) ([]donelog.RawDoneLog, error) {
+	owner := ownerOf(ctx)
+	var logs []donelog.RawDoneLog
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.DoneLogs {
+			if raw.Owner != owner || raw.TrashedAt != nil || !filter.Matches(raw) {
+				continue
+			}
+			if !period.Contains(donelog.OccurredOnFromTime(raw.OccurredOn)) {
+				continue
+			}
+			logs = append(logs, raw)
+		}
+		return nil
+	})
+	sortDoneLogs(logs)
+	return logs, err
+}
+
+func sortDoneLogs(logs []donelog.RawDoneLog) {
+	sort.Slice(logs, func(i, j int) bool {
+		if !logs[i].OccurredOn.Equal(logs[j].OccurredOn) {
+			return logs[i].OccurredOn.Before(logs[j].OccurredOn)
+		}
+		return logs[i].ID < logs[j].ID
+	})
+}
@@ -0,0 +1,74 @@
+package filestore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// GoalRepository implements command.GoalRepository and query.GoalReader.
+type GoalRepository struct {
+	store *Store
+}
+
+// Goals returns the Goal repository backed by the store.
+func (s *Store) Goals() GoalRepository {
+	return GoalRepository{store: s}
+}
+
+// Save inserts or replaces the Goal, owned by the owner in ctx.
+func (r GoalRepository) Save(ctx context.Context, goal *donelog.Goal) error {
+	owner := ownerOf(ctx)
+	raw := goal.Raw()
+	raw.Owner = owner
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Goals[ownedKey(owner, raw.ID)] = raw
+		return nil
+	})
+}
+
+// FindByID returns nil when the Goal does not exist or belongs to another owner.
+func (r GoalRepository) FindByID(ctx context.Context, id donelog.GoalID) (*donelog.RawGoal, error) {
+	var found *donelog.RawGoal
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Goals[ownedKey(ownerOf(ctx), id.String())]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Delete removes the Goal. Deleting a missing Goal is not an error.
+func (r GoalRepository) Delete(ctx context.Context, id donelog.GoalID) error {
+	return r.store.write(ctx, func(d *dataset) error {
+		delete(d.Goals, ownedKey(ownerOf(ctx), id.String()))
+		return nil
+	})
+}
+
+// ListGoals returns every Goal of the owner in ctx, ordered by start date, then ID.
+func (r GoalRepository) ListGoals(ctx context.Context) ([]donelog.RawGoal, error) {
+	owner := ownerOf(ctx)
+	var goals []donelog.RawGoal
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Goals {
+			if raw.Owner == owner {
+				goals = append(goals, raw)
+			}
+		}
+		return nil
+	})
+	sortGoals(goals)
+	return goals, err
+}
+
+func sortGoals(goals []donelog.RawGoal) {
+	sort.Slice(goals, func(i, j int) bool {
+		if !goals[i].StartDate.Equal(goals[j].StartDate) {
+			return goals[i].StartDate.Before(goals[j].StartDate)
+		}
+		return goals[i].ID < goals[j].ID
+	})
+}
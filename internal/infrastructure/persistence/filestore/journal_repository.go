@@ -0,0 +1,135 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// AuditLog implements command.AuditLog and query.AuditReader.
+type AuditLog struct {
+	store *Store
+}
+
+// Audit returns the audit log backed by the store.
+func (s *Store) Audit() AuditLog {
+	return AuditLog{store: s}
+}
+
+// Append adds an entry, owned by the owner in ctx, to the end of the log.
+func (a AuditLog) Append(ctx context.Context, entry donelog.AuditEntry) error {
+	entry.Owner = ownerOf(ctx)
+	return a.store.write(ctx, func(d *dataset) error {
+		d.Audit = append(d.Audit, entry)
+		return nil
+	})
+}
+
+// ListByDoneLogID returns the entries of the owner in ctx for one DONELOG in recording order.
+func (a AuditLog) ListByDoneLogID(ctx context.Context, id donelog.DoneLogID) ([]donelog.AuditEntry, error) {
+	owner := ownerOf(ctx)
+	var entries []donelog.AuditEntry
+	err := a.store.read(ctx, func(d *dataset) error {
+		for _, entry := range d.Audit {
+			if entry.Owner == owner && entry.DoneLogID == id.String() {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// UndoJournal implements command.UndoJournal.
+type UndoJournal struct {
+	store *Store
+	// limit caps the entries kept per owner and actor; older ones are dropped first.
+	limit int
+}
+
+const defaultUndoLimit = 20
+
+// Undo returns the undo journal backed by the store.
+func (s *Store) Undo() UndoJournal {
+	return UndoJournal{store: s, limit: defaultUndoLimit}
+}
+
+// Push records an undoable change of the owner in ctx.
+func (j UndoJournal) Push(ctx context.Context, entry command.UndoEntry) error {
+	key := ownedKey(ownerOf(ctx), entry.Actor)
+	return j.store.write(ctx, func(d *dataset) error {
+		entries := append(d.Undo[key], entry)
+		if len(entries) > j.limit {
+			entries = entries[len(entries)-j.limit:]
+		}
+		d.Undo[key] = entries
+		return nil
+	})
+}
+
+// Latest returns the most recent entry of actor, or nil.
+func (j UndoJournal) Latest(ctx context.Context, actor string) (*command.UndoEntry, error) {
+	var latest *command.UndoEntry
+	err := j.store.read(ctx, func(d *dataset) error {
+		entries := d.Undo[ownedKey(ownerOf(ctx), actor)]
+		if len(entries) > 0 {
+			entry := entries[len(entries)-1]
+			latest = &entry
+		}
+		return nil
+	})
+	return latest, err
+}
+
+// DropLatest forgets the most recent entry of actor.
+func (j UndoJournal) DropLatest(ctx context.Context, actor string) error {
+	key := ownedKey(ownerOf(ctx), actor)
+	return j.store.write(ctx, func(d *dataset) error {
+		entries := d.Undo[key]
+		if len(entries) > 0 {
+			d.Undo[key] = entries[:len(entries)-1]
+		}
+		return nil
+	})
+}
+
+// IdempotencyStore implements command.IdempotencyStore.
+type IdempotencyStore struct {
+	store *Store
+}
+
+// Idempotency returns the idempotency key store backed by the store.
+func (s *Store) Idempotency() IdempotencyStore {
+	return IdempotencyStore{store: s}
+}
+
+func idempotencyKey(ctx context.Context, actor, key string) string {
+	return ownedKey(ownerOf(ctx), actor+"\x00"+key)
+}
+
+// Find returns nil when the key is unused.
+func (i IdempotencyStore) Find(ctx context.Context, actor, key string) (*command.IdempotencyRecord, error) {
+	var found *command.IdempotencyRecord
+	err := i.store.read(ctx, func(d *dataset) error {
+		if record, ok := d.Idempotency[idempotencyKey(ctx, actor, key)]; ok {
+			found = &record
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Save fails with apperr.ErrConflict when the key already exists.
+func (i IdempotencyStore) Save(ctx context.Context, record command.IdempotencyRecord) error {
+	return i.store.write(ctx, func(d *dataset) error {
+		k := idempotencyKey(ctx, record.Actor, record.Key)
+		if _, ok := d.Idempotency[k]; ok {
+			return fmt.Errorf("idempotency key %q: %w", record.Key, apperr.ErrConflict)
+		}
+		d.Idempotency[k] = record
+		return nil
+	})
+}
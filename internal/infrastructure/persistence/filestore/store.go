@@ -0,0 +1,203 @@
+// Package filestore persists the DONELOG dataset as a single JSON file.
+// It is meant for the local CLI and small single-node deployments.
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// dataset is the on-disk document.
+type dataset struct {
+	DoneLogs    map[string]donelog.RawDoneLog        `json:"doneLogs"`
+	Tracks      map[string]donelog.RawTrack          `json:"tracks"`
+	Categories  map[string]donelog.RawCategory       `json:"categories"`
+	Goals       map[string]donelog.RawGoal           `json:"goals"`
+	Teams       map[string]donelog.RawTeam           `json:"teams"`
+	Audit       []donelog.AuditEntry                 `json:"audit"`
+	Undo        map[string][]command.UndoEntry       `json:"undo"`
+	Idempotency map[string]command.IdempotencyRecord `json:"idempotency"`
+	Settings    map[string]string                    `json:"settings"`
+	Accounts    map[string]account.RawAccount        `json:"accounts"`
+	Sessions    map[string]account.RawSession        `json:"sessions"`
+	Tokens      map[string]account.RawToken          `json:"tokens"`
+	Identities  map[string]account.RawIdentity       `json:"identities"`
+	SSOLogins   map[string]account.RawPendingLogin   `json:"ssoLogins"`
+}
+
+func newDataset() *dataset {
+	return &dataset{
+		DoneLogs:    map[string]donelog.RawDoneLog{},
+		Tracks:      map[string]donelog.RawTrack{},
+		Categories:  map[string]donelog.RawCategory{},
+		Goals:       map[string]donelog.RawGoal{},
+		Teams:       map[string]donelog.RawTeam{},
+		Undo:        map[string][]command.UndoEntry{},
+		Idempotency: map[string]command.IdempotencyRecord{},
+		Settings:    map[string]string{},
+		Accounts:    map[string]account.RawAccount{},
+		Sessions:    map[string]account.RawSession{},
+		Tokens:      map[string]account.RawToken{},
+		Identities:  map[string]account.RawIdentity{},
+		SSOLogins:   map[string]account.RawPendingLogin{},
+	}
+}
+
+// clone deep-copies the dataset through its JSON form.
+func (d *dataset) clone() (*dataset, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	c := newDataset()
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type txKey struct{}
+
+// tx is the working copy a transaction reads and writes until it commits.
+type tx struct {
+	data *dataset
+}
+
+// Store holds the dataset in memory and writes it back to disk after every committed change.
+type Store struct {
+	path string
+
+	// writeMu serializes writers so a transaction sees no interleaved changes.
+	writeMu sync.Mutex
+	mu      sync.RWMutex
+	data    *dataset
+}
+
+// Open loads the store at path, creating an empty one when the file does not exist.
+// An empty path keeps the data in memory only.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, data: newDataset()}
+	if path == "" {
+		return s, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s.data); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	attributeTeamDoneLogs(s.data)
+	return s, nil
+}
+
+// WithinTx implements command.Transactor. Changes made through ctx go to a private copy of the
+// dataset that replaces the shared one only once fn succeeds and the copy is on disk, so other
+// readers never see uncommitted rows.
+func (s *Store) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txOf(ctx) != nil {
+		return fn(ctx)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	work, err := s.data.clone()
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, &tx{data: work})); err != nil {
+		return err
+	}
+	if err := s.flush(work); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.data = work
+	s.mu.Unlock()
+	return nil
+}
+
+func txOf(ctx context.Context) *tx {
+	t, _ := ctx.Value(txKey{}).(*tx)
+	return t
+}
+
+// write applies fn under the write lock and persists the result unless ctx is inside a transaction,
+// in which case fn changes the transaction's copy.
+func (s *Store) write(ctx context.Context, fn func(d *dataset) error) error {
+	if t := txOf(ctx); t != nil {
+		return fn(t.data)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := fn(s.data); err != nil {
+		return err
+	}
+	return s.flush(s.data)
+}
+
+// ownerOf returns the owner that ctx reads and writes as.
+func ownerOf(ctx context.Context) string {
+	return appctx.Owner(ctx).String()
+}
+
+// ownedKey scopes a record key to owner. The single-user owner uses the bare key,
+// so stores written before owners existed load unchanged.
+func ownedKey(owner, key string) string {
+	if owner == "" {
+		return key
+	}
+	return owner + "/" + key
+}
+
+// read runs fn under the read lock, or on the transaction's copy when ctx is inside one.
+func (s *Store) read(ctx context.Context, fn func(d *dataset) error) error {
+	if t := txOf(ctx); t != nil {
+		return fn(t.data)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.data)
+}
+
+// flush writes d atomically. Callers hold writeMu, and mu when d is the shared dataset.
+func (s *Store) flush(d *dataset) error {
+	if s.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,404 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+func mustDoneLog(t *testing.T, id, track string, day int, trashed bool) *donelog.DoneLog {
+	t.Helper()
+	raw := donelog.RawDoneLog{
+		ID:         id,
+		Title:      "Chapter",
+		TrackID:    track,
+		CategoryID: "cat_reading",
+		Count:      day,
+		OccurredOn: time.Date(2024, 5, day, 0, 0, 0, 0, time.UTC),
+	}
+	if trashed {
+		at := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+		raw.TrashedAt = &at
+	}
+	log, err := donelog.RehydrateDoneLog(raw)
+	if err != nil {
+		t.Fatalf("rehydrate failed: %v", err)
+	}
+	return log
+}
+
+func TestStorePersistsAcrossOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "donelog.json")
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if err := store.DoneLogs().Save(ctx, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH1", "track_book", 1, false)); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	trackID, _ := donelog.NewTrackID("track_book")
+	track, _ := donelog.NewTrack(trackID, "Book", nil, 1)
+	if err := store.Tracks().Save(ctx, track); err != nil {
+		t.Fatalf("save track failed: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	id, _ := donelog.NewDoneLogID("01HYR1X5C9XM9P6H7K71M9QAH1")
+	found, err := reopened.DoneLogs().FindByID(ctx, id)
+	if err != nil || found == nil {
+		t.Fatalf("expected persisted log, got %v, %v", found, err)
+	}
+	active, err := reopened.Tracks().FindActiveByID(ctx, trackID)
+	if err != nil || active == nil || !active.Active {
+		t.Fatalf("expected persisted active track, got %+v, %v", active, err)
+	}
+}
+
+func TestStoreWithinTx(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      bool
+		wantFound bool
+	}{
+		{"commit keeps changes", false, true},
+		{"rollback discards changes", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, _ := Open(filepath.Join(t.TempDir(), "donelog.json"))
+			log := mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH1", "track_book", 1, false)
+
+			err := store.WithinTx(ctx, func(txCtx context.Context) error {
+				if err := store.DoneLogs().Save(txCtx, log); err != nil {
+					return err
+				}
+				if found, _ := store.DoneLogs().FindByID(txCtx, log.ID()); found == nil {
+					t.Fatal("the transaction should read its own writes")
+				}
+				if found, _ := store.DoneLogs().FindByID(ctx, log.ID()); found != nil {
+					t.Fatal("readers outside the transaction should not see uncommitted rows")
+				}
+				if tt.fail {
+					return errors.New("boom")
+				}
+				return nil
+			})
+			if (err != nil) != tt.fail {
+				t.Fatalf("error = %v, fail = %v", err, tt.fail)
+			}
+
+			found, _ := store.DoneLogs().FindByID(ctx, log.ID())
+			if (found != nil) != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found != nil, tt.wantFound)
+			}
+		})
+	}
+}
+
+func mustOwner(t *testing.T, value string) donelog.OwnerID {
+	t.Helper()
+	owner, err := donelog.NewOwnerID(value)
+	if err != nil {
+		t.Fatalf("owner: %v", err)
+	}
+	return owner
+}
+
+func TestListByPeriod(t *testing.T) {
+	ctx := context.Background()
+	store, _ := Open("")
+	repo := store.DoneLogs()
+	goTags, _ := donelog.ParseTagSet([]string{"go"})
+	for i, log := range []*donelog.DoneLog{
+		mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH3", "track_book", 3, false),
+		mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH1", "track_book", 1, false),
+		mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH2", "track_exam", 2, false),
+		mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH4", "track_book", 2, true),
+		mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH5", "track_book", 20, false),
+	} {
+		if i%2 == 1 {
+			log.SetTags(goTags)
+		}
+		if err := repo.Save(ctx, log); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+
+	start, _ := donelog.NewOccurredOn("2024-05-01")
+	end, _ := donelog.NewOccurredOn("2024-05-10")
+	period, _ := donelog.NewPeriod(start, end)
+	book, _ := donelog.NewTrackID("track_book")
+	tag, _ := donelog.NewTag("go")
+
+	tests := []struct {
+		name    string
+		filter  query.DoneLogFilter
+		wantIDs []string
+	}{
+		{"all tracks, trashed and out-of-period excluded", query.DoneLogFilter{}, []string{"01HYR1X5C9XM9P6H7K71M9QAH1", "01HYR1X5C9XM9P6H7K71M9QAH2", "01HYR1X5C9XM9P6H7K71M9QAH3"}},
+		{"track filter", query.DoneLogFilter{TrackID: &book}, []string{"01HYR1X5C9XM9P6H7K71M9QAH1", "01HYR1X5C9XM9P6H7K71M9QAH3"}},
+		{"tag filter", query.DoneLogFilter{Tag: &tag}, []string{"01HYR1X5C9XM9P6H7K71M9QAH1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := repo.ListByPeriod(ctx, period, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(logs) != len(tt.wantIDs) {
+				t.Fatalf("expected %d logs, got %d", len(tt.wantIDs), len(logs))
+			}
+			for i, want := range tt.wantIDs {
+				if logs[i].ID != want {
+					t.Fatalf("log %d = %s, want %s", i, logs[i].ID, want)
+				}
+			}
+		})
+	}
+
+	// ListByTag reaches trashed logs so renames keep them consistent.
+	tagged, err := repo.ListByTag(ctx, tag)
+	if err != nil || len(tagged) != 2 || tagged[1].TrashedAt == nil {
+		t.Fatalf("ListByTag = %+v, %v", tagged, err)
+	}
+}
+
+func TestSnapshotReplace(t *testing.T) {
+	ctx := context.Background()
+	source, _ := Open("")
+	_ = source.DoneLogs().Save(ctx, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH1", "track_book", 1, false))
+	_ = source.Settings().Set(ctx, "week_start", "sunday")
+	aliceCtx := appctx.WithOwner(ctx, mustOwner(t, "alice"))
+	_ = source.DoneLogs().Save(aliceCtx, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH2", "track_book", 2, false))
+	goal, _ := donelog.RehydrateGoal(donelog.RawGoal{ID: "01HYR1X5C9XM9P6H7K71M9QAG1", Name: "May", TrackID: "track_book", StartDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), Target: 10})
+	_ = source.Goals().Save(ctx, goal)
+	bob, _ := donelog.NewOwnerID("bob")
+	hash, _ := account.HashPassword("correct horse", []byte("0123456789abcdef"), 1)
+	bobAccount, _ := account.NewAccount(bob, hash, time.Now())
+	_ = source.Accounts().Save(ctx, bobAccount)
+	identity, _ := account.NewIdentity("https://idp.example.com", "sub-alice", mustOwner(t, "alice"), time.Now())
+	_ = source.Identities().Save(ctx, identity)
+
+	target, _ := Open(filepath.Join(t.TempDir(), "donelog.json"))
+	_ = target.DoneLogs().Save(ctx, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH9", "track_old", 2, false))
+	_ = target.Undo().Push(ctx, command.UndoEntry{Actor: "taketo", DoneLogID: "01HYR1X5C9XM9P6H7K71M9QAH9"})
+	alice, _ := donelog.NewOwnerID("alice")
+	acct, _ := account.NewAccount(alice, hash, time.Now())
+	_ = target.Accounts().Save(ctx, acct)
+
+	data, err := source.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	if err := target.Replace(ctx, data); err != nil {
+		t.Fatalf("replace failed: %v", err)
+	}
+
+	replaced, _ := target.Snapshot(ctx)
+	if len(replaced.DoneLogs) != 2 {
+		t.Fatalf("unexpected doneLogs after replace: %+v", replaced.DoneLogs)
+	}
+	// Restored records must land under the keys the repositories read, for the single user and for owners alike.
+	for _, tt := range []struct {
+		ctx context.Context
+		id  string
+	}{{ctx, "01HYR1X5C9XM9P6H7K71M9QAH1"}, {aliceCtx, "01HYR1X5C9XM9P6H7K71M9QAH2"}} {
+		id, _ := donelog.NewDoneLogID(tt.id)
+		if raw, err := target.DoneLogs().FindByID(tt.ctx, id); err != nil || raw == nil {
+			t.Fatalf("restored doneLog %s is not readable: %v", tt.id, err)
+		}
+	}
+	if goals, _ := target.Goals().ListGoals(ctx); len(goals) != 1 || goals[0].ID != "01HYR1X5C9XM9P6H7K71M9QAG1" {
+		t.Fatalf("unexpected goals after replace: %+v", goals)
+	}
+	if value, _, _ := target.Settings().Get(ctx, "week_start"); value != "sunday" {
+		t.Fatalf("expected settings to be replaced, got %q", value)
+	}
+	if latest, _ := target.Undo().Latest(ctx, "taketo"); latest != nil {
+		t.Fatalf("expected undo journal to be cleared")
+	}
+	// Sign-ins follow the archive, so alice's SSO subject still reaches the data she owned.
+	if raw, _ := target.Accounts().FindByUsername(ctx, alice); raw != nil {
+		t.Fatalf("expected accounts missing from the archive to be removed")
+	}
+	if raw, _ := target.Accounts().FindByUsername(ctx, bob); raw == nil {
+		t.Fatalf("expected the archived account to be restored")
+	}
+	if raw, _ := target.Identities().FindBySubject(ctx, "https://idp.example.com", "sub-alice"); raw == nil || raw.Username != "alice" {
+		t.Fatalf("expected the archived identity to be restored, got %+v", raw)
+	}
+
+	// Archives older than version 9 carry no sign-ins; the stored ones are kept.
+	data.Accounts, data.Identities, data.SignIns = nil, nil, false
+	if err := target.Replace(ctx, data); err != nil {
+		t.Fatalf("replace failed: %v", err)
+	}
+	if raw, _ := target.Accounts().FindByUsername(ctx, bob); raw == nil {
+		t.Fatalf("expected accounts to survive a restore without sign-ins")
+	}
+}
+
+func TestOwnerScoping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "donelog.json")
+	store, _ := Open(path)
+	aliceID, _ := donelog.NewOwnerID("alice")
+	bobID, _ := donelog.NewOwnerID("bob")
+	alice := appctx.WithOwner(context.Background(), aliceID)
+	bob := appctx.WithOwner(context.Background(), bobID)
+	single := context.Background()
+
+	trackID, _ := donelog.NewTrackID("track_book")
+	for _, ctx := range []context.Context{alice, bob} {
+		track, _ := donelog.NewTrack(trackID, "Book", nil, 1)
+		if err := store.Tracks().Save(ctx, track); err != nil {
+			t.Fatalf("save track: %v", err)
+		}
+	}
+	_ = store.DoneLogs().Save(alice, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH1", "track_book", 1, false))
+	_ = store.DoneLogs().Save(single, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH2", "track_book", 2, false))
+	_ = store.Audit().Append(alice, donelog.AuditEntry{DoneLogID: "01HYR1X5C9XM9P6H7K71M9QAH1", Action: donelog.AuditActionCreated})
+
+	reopened, _ := Open(path)
+	logID, _ := donelog.NewDoneLogID("01HYR1X5C9XM9P6H7K71M9QAH1")
+	if found, _ := reopened.DoneLogs().FindByID(alice, logID); found == nil || found.Owner != "alice" {
+		t.Fatalf("alice should see her DONELOG, got %+v", found)
+	}
+	if found, _ := reopened.DoneLogs().FindByID(bob, logID); found != nil {
+		t.Fatalf("bob must not see alice's DONELOG, got %+v", found)
+	}
+	if entries, _ := reopened.Audit().ListByDoneLogID(bob, logID); len(entries) != 0 {
+		t.Fatalf("bob must not see alice's history, got %+v", entries)
+	}
+	if track, _ := reopened.Tracks().FindActiveByID(bob, trackID); track == nil {
+		t.Fatal("bob should own his own track_book")
+	}
+	if tracks, _ := reopened.Tracks().ListTracks(single); len(tracks) != 0 {
+		t.Fatalf("the single-user owner has no tracks, got %+v", tracks)
+	}
+
+	start, _ := donelog.NewOccurredOn("2024-05-01")
+	end, _ := donelog.NewOccurredOn("2024-05-31")
+	may, _ := donelog.NewPeriod(start, end)
+	for _, tt := range []struct {
+		ctx  context.Context
+		want string
+	}{{alice, "01HYR1X5C9XM9P6H7K71M9QAH1"}, {single, "01HYR1X5C9XM9P6H7K71M9QAH2"}} {
+		logs, _ := reopened.DoneLogs().ListByPeriod(tt.ctx, may, query.DoneLogFilter{})
+		if len(logs) != 1 || logs[0].ID != tt.want {
+			t.Fatalf("%q: unexpected logs %+v", appctx.Owner(tt.ctx).String(), logs)
+		}
+	}
+
+	data, _ := reopened.Snapshot(context.Background())
+	if len(data.Tracks) != 2 || len(data.DoneLogs) != 2 {
+		t.Fatalf("snapshot must cover every owner: %+v", data)
+	}
+}
+
+func TestTeamTracks(t *testing.T) {
+	store, _ := Open(filepath.Join(t.TempDir(), "donelog.json"))
+	ctxOf := func(name string) context.Context {
+		owner, _ := donelog.NewOwnerID(name)
+		return appctx.WithOwner(context.Background(), owner)
+	}
+	alice, bob, carol := ctxOf("alice"), ctxOf("bob"), ctxOf("carol")
+	aliceID, bobID, carolID := appctx.Owner(alice), appctx.Owner(bob), appctx.Owner(carol)
+
+	teamID, _ := donelog.NewTeamID("club")
+	team, _ := donelog.NewTeam(teamID, "Club", aliceID)
+	for member, role := range map[donelog.OwnerID]donelog.Role{bobID: donelog.RoleViewer, carolID: donelog.RoleEditor} {
+		_ = team.Invite(member, role)
+		_ = team.Accept(member)
+	}
+	if err := store.Teams().Save(alice, team); err != nil {
+		t.Fatalf("save team: %v", err)
+	}
+	trackID, _ := donelog.NewTrackID("track_run")
+	shared, _ := donelog.NewTeamTrack(trackID, "Run", teamID, 1)
+	_ = store.Tracks().Save(alice, shared)
+	// carol's own track_run shadows the team Track for her.
+	own, _ := donelog.NewTrack(trackID, "My run", nil, 1)
+	_ = store.Tracks().Save(carol, own)
+
+	teamLog := mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH1", "track_run", 1, false)
+	teamLog.RecordFor(teamID)
+	_ = store.DoneLogs().Save(alice, teamLog)
+	_ = store.DoneLogs().Save(carol, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH2", "track_run", 2, false))
+	// Stored before DONELOGs recorded their Team; Replace and Open attribute it by resolving its Track.
+	_ = store.DoneLogs().Save(alice, mustDoneLog(t, "01HYR1X5C9XM9P6H7K71M9QAH3", "track_run", 4, false))
+
+	for _, tt := range []struct {
+		name     string
+		ctx      context.Context
+		wantName string
+		readOnly bool
+	}{
+		{name: "owner", ctx: alice, wantName: "Run"},
+		{name: "viewer", ctx: bob, wantName: "Run", readOnly: true},
+		{name: "shadowed by own track", ctx: carol, wantName: "My run"},
+	} {
+		raw, _ := store.Tracks().FindByID(tt.ctx, trackID)
+		if raw == nil || raw.Name != tt.wantName {
+			t.Fatalf("%s: unexpected track %+v", tt.name, raw)
+		}
+		track, err := store.Tracks().FindActiveByID(tt.ctx, trackID)
+		if err != nil || track == nil || track.ReadOnly != tt.readOnly {
+			t.Fatalf("%s: unexpected active track %+v (err %v)", tt.name, track, err)
+		}
+	}
+	if track, _ := store.Tracks().FindByID(context.Background(), trackID); track != nil {
+		t.Fatalf("the single-user owner is in no team, got %+v", track)
+	}
+	if tracks, _ := store.Tracks().ListTracks(bob); len(tracks) != 1 || tracks[0].Team != "club" {
+		t.Fatalf("bob should list the team track, got %+v", tracks)
+	}
+
+	start, _ := donelog.NewOccurredOn("2024-05-01")
+	end, _ := donelog.NewOccurredOn("2024-05-31")
+	may, _ := donelog.NewPeriod(start, end)
+	logs, _ := store.Teams().ListTeamDoneLogs(alice, teamID, may, query.DoneLogFilter{})
+	if len(logs) != 1 || logs[0].ID != teamLog.ID().String() {
+		t.Fatalf("only alice's DONELOG recorded for the team counts, got %+v", logs)
+	}
+
+	data, _ := store.Snapshot(context.Background())
+	if len(data.Teams) != 1 || len(data.Tracks) != 2 {
+		t.Fatalf("snapshot must include teams and team tracks: %+v", data)
+	}
+	if err := store.Replace(context.Background(), data); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if teams, _ := store.Teams().ListByMember(context.Background(), carolID); len(teams) != 1 {
+		t.Fatalf("teams must survive replace, got %+v", teams)
+	}
+	if track, _ := store.Tracks().FindActiveByID(bob, trackID); track == nil || !track.ReadOnly || track.Team == nil || *track.Team != teamID {
+		t.Fatalf("team track must survive replace, got %+v", track)
+	}
+	if logs, _ := store.Teams().ListTeamDoneLogs(alice, teamID, may, query.DoneLogFilter{}); len(logs) != 2 {
+		t.Fatalf("replace should attribute alice's older DONELOG to the team, got %+v", logs)
+	}
+
+	// carol's own Track does not hide the team Track when checking team Track IDs.
+	if raw, _ := store.Tracks().FindMemberTeamTrack(context.Background(), carolID, trackID); raw == nil || raw.Team != "club" {
+		t.Fatalf("carol's team track_run, got %+v", raw)
+	}
+	if tracks, _ := store.Tracks().ListTeamTracks(context.Background(), teamID); len(tracks) != 1 || tracks[0].ID != "track_run" {
+		t.Fatalf("club tracks = %+v", tracks)
+	}
+}
@@ -0,0 +1,144 @@
+package filestore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Teams are shared between their members, so like accounts they are not scoped by the owner in ctx.
+// Team Tracks are stored under teamKey instead of an owner.
+
+// TeamRepository stores Team aggregates.
+type TeamRepository struct {
+	store *Store
+}
+
+// Teams returns the Team repository backed by the store.
+func (s *Store) Teams() TeamRepository {
+	return TeamRepository{store: s}
+}
+
+// Save inserts or replaces the Team.
+func (r TeamRepository) Save(ctx context.Context, team *donelog.Team) error {
+	raw := team.Raw()
+	return r.store.write(ctx, func(d *dataset) error {
+		d.Teams[raw.ID] = raw
+		return nil
+	})
+}
+
+// FindByID returns nil when the Team does not exist. Callers check membership themselves.
+func (r TeamRepository) FindByID(ctx context.Context, id donelog.TeamID) (*donelog.RawTeam, error) {
+	var found *donelog.RawTeam
+	err := r.store.read(ctx, func(d *dataset) error {
+		if raw, ok := d.Teams[id.String()]; ok {
+			found = &raw
+		}
+		return nil
+	})
+	return found, err
+}
+
+// ListByMember returns the Teams member belongs to, ordered by ID.
+func (r TeamRepository) ListByMember(ctx context.Context, member donelog.OwnerID) ([]donelog.RawTeam, error) {
+	var teams []donelog.RawTeam
+	err := r.store.read(ctx, func(d *dataset) error {
+		teams = teamsOf(d, member.String())
+		return nil
+	})
+	return teams, err
+}
+
+// ListByInvitee returns the Teams with a pending invite for invitee, ordered by ID.
+func (r TeamRepository) ListByInvitee(ctx context.Context, invitee donelog.OwnerID) ([]donelog.RawTeam, error) {
+	var teams []donelog.RawTeam
+	err := r.store.read(ctx, func(d *dataset) error {
+		for _, raw := range d.Teams {
+			if _, ok := raw.Invites[invitee.String()]; ok {
+				teams = append(teams, raw)
+			}
+		}
+		return nil
+	})
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	return teams, err
+}
+
+// ListTeamDoneLogs implements query.TeamDoneLogReader. It returns the DONELOGs of every current member
+// that were recorded against the team's Tracks, ordered by OccurredOn, then ID. DONELOGs carry the Team
+// they were recorded for, so later Tracks with the same ID do not move them between Teams.
+func (r TeamRepository) ListTeamDoneLogs(ctx context.Context, team donelog.TeamID, period donelog.Period, filter query.DoneLogFilter) ([]donelog.RawDoneLog, error) {
+	var logs []donelog.RawDoneLog
+	err := r.store.read(ctx, func(d *dataset) error {
+		rawTeam, ok := d.Teams[team.String()]
+		if !ok {
+			return nil
+		}
+		for _, raw := range d.DoneLogs {
+			if _, member := rawTeam.Members[raw.Owner]; !member || raw.TrashedAt != nil || !filter.Matches(raw) {
+				continue
+			}
+			if !period.Contains(donelog.OccurredOnFromTime(raw.OccurredOn)) {
+				continue
+			}
+			if raw.Team == rawTeam.ID {
+				logs = append(logs, raw)
+			}
+		}
+		return nil
+	})
+	sortDoneLogs(logs)
+	return logs, err
+}
+
+// teamKey scopes a Track key to the Team sharing it. OwnerIDs cannot contain ":",
+// so team keys never collide with ownedKey.
+func teamKey(team, key string) string {
+	return "team:" + team + "/" + key
+}
+
+// teamsOf returns the Teams member belongs to, ordered by ID. The single-user owner belongs to none.
+func teamsOf(d *dataset, member string) []donelog.RawTeam {
+	if member == "" {
+		return nil
+	}
+	var teams []donelog.RawTeam
+	for _, raw := range d.Teams {
+		if _, ok := raw.Members[member]; ok {
+			teams = append(teams, raw)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	return teams
+}
+
+// attributeTeamDoneLogs stamps the Team on DONELOGs stored before DONELOGs recorded it,
+// using the Track their TrackID resolves to for their owner.
+func attributeTeamDoneLogs(d *dataset) {
+	for key, raw := range d.DoneLogs {
+		if raw.Team != "" {
+			continue
+		}
+		if track, _, ok := resolveTrack(d, raw.Owner, raw.TrackID); ok && track.Team != "" {
+			raw.Team = track.Team
+			d.DoneLogs[key] = raw
+		}
+	}
+}
+
+// resolveTrack finds the Track that id means for owner: their own Track first, then a Track of one of
+// their Teams in team ID order. canLog reports whether owner may record DONELOGs against it.
+func resolveTrack(d *dataset, owner, id string) (track donelog.RawTrack, canLog, ok bool) {
+	if raw, ok := d.Tracks[ownedKey(owner, id)]; ok {
+		return raw, true, true
+	}
+	for _, team := range teamsOf(d, owner) {
+		if raw, ok := d.Tracks[teamKey(team.ID, id)]; ok {
+			return raw, donelog.Role(team.Members[owner]).CanLog(), true
+		}
+	}
+	return donelog.RawTrack{}, false, false
+}
@@ -0,0 +1,211 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/app/auth"
+)
+
+func (h Handler) login(w http.ResponseWriter, r *http.Request) {
+	var body LoginRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	// The password hash is slow, so it is checked before the write transaction starts.
+	verified, err := h.Auth.VerifyLogin(r.Context(), body.Username, body.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var login auth.Login
+	err = h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		login, err = h.Auth.StartSession(ctx, verified)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	setSessionCookie(w, r, login)
+	writeJSON(w, http.StatusOK, login)
+}
+
+func (h Handler) logout(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err == nil && cookie.Value != "" {
+		if err := h.Auth.Logout(r.Context(), cookie.Value); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	clearSessionCookie(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h Handler) me(w http.ResponseWriter, r *http.Request) {
+	p, ok := appctx.Authenticated(r.Context())
+	if !ok {
+		writeUnauthorized(w, fmt.Errorf("not signed in: %w", apperr.ErrUnauthorized))
+		return
+	}
+	writeJSON(w, http.StatusOK, MeResponse{Username: p.Owner.String(), Scopes: p.Scopes.Strings(), Method: string(p.Method)})
+}
+
+// changePassword changes the caller's own password. Every session and token of the account is revoked,
+// the caller's included, so the session cookie is cleared and the caller signs in again.
+func (h Handler) changePassword(w http.ResponseWriter, r *http.Request) {
+	var body ChangePasswordRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	// Like login, the password hashes are computed before the write transaction starts.
+	change, err := h.Auth.VerifyPasswordChange(r.Context(), body.CurrentPassword, body.NewPassword)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	err = h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.Auth.ApplyPasswordChange(ctx, change)
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	clearSessionCookie(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h Handler) listTokens(w http.ResponseWriter, r *http.Request) {
+	items, err := h.Auth.ListTokens(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h Handler) createToken(w http.ResponseWriter, r *http.Request) {
+	var body CreateTokenRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	var issued auth.IssuedToken
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		issued, err = h.Auth.IssueToken(ctx, body.Name, body.Scopes)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+func (h Handler) revokeToken(w http.ResponseWriter, r *http.Request) {
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.Auth.RevokeToken(ctx, r.PathValue("id"))
+	})
+	writeNoContent(w, err)
+}
+
+// ssoLogin redirects the browser to the identity provider. The state is also kept in a short-lived
+// cookie so the callback only completes in the browser that started the sign-in.
+func (h Handler) ssoLogin(w http.ResponseWriter, r *http.Request) {
+	var redirect auth.SSORedirect
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		redirect, err = h.Auth.BeginSSO(ctx)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Value:    redirect.State,
+		Path:     "/api/auth/sso",
+		MaxAge:   int(auth.PendingLoginTTL / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Lax, because the provider sends the browser back with a cross-site top-level GET.
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, redirect.URL, http.StatusFound)
+}
+
+// ssoCallback completes the sign-in, sets the session cookie and redirects to the top page.
+func (h Handler) ssoCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		writeUnauthorized(w, fmt.Errorf("identity provider: %s %s: %w", e, q.Get("error_description"), apperr.ErrUnauthorized))
+		return
+	}
+	cookie, err := r.Cookie(ssoStateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != q.Get("state") {
+		writeUnauthorized(w, fmt.Errorf("sign-in was not started in this browser: %w", apperr.ErrUnauthorized))
+		return
+	}
+	// The code is redeemed at the provider before the write transaction, which only links and stores.
+	var login auth.Login
+	verified, err := h.Auth.VerifySSO(r.Context(), q.Get("state"), q.Get("code"))
+	if err == nil {
+		err = h.inTx(r.Context(), func(ctx context.Context) error {
+			var err error
+			login, err = h.Auth.FinishSSO(ctx, verified)
+			return err
+		})
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Value:    "",
+		Path:     "/api/auth/sso",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	setSessionCookie(w, r, login)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// setSessionCookie hands the session secret to the browser.
+func setSessionCookie(w http.ResponseWriter, r *http.Request, login auth.Login) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    login.Secret,
+		Path:     "/",
+		Expires:  login.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// clearSessionCookie tells the browser to drop the session cookie.
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func (h Handler) listTracks(w http.ResponseWriter, r *http.Request) {
+	items, err := h.ListTracks.Handle(r.Context(), query.ListTracksQuery{
+		IncludeArchived: r.URL.Query().Get("includeArchived") == "true",
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h Handler) createTrack(w http.ResponseWriter, r *http.Request) {
+	var body CreateTrackRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.CreateTrackCommand{
+		ID:                body.ID,
+		Name:              body.Name,
+		DefaultCategoryID: body.DefaultCategoryID,
+		SortOrder:         body.SortOrder,
+		TeamID:            body.TeamID,
+	}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.CreateTrack.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) archiveTrack(w http.ResponseWriter, r *http.Request) {
+	cmd := command.ArchiveTrackCommand{ID: r.PathValue("id")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.ArchiveTrack.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) listCategories(w http.ResponseWriter, r *http.Request) {
+	items, err := h.ListCategories.Handle(r.Context(), query.ListCategoriesQuery{
+		IncludeArchived: r.URL.Query().Get("includeArchived") == "true",
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h Handler) createCategory(w http.ResponseWriter, r *http.Request) {
+	var body CreateCategoryRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.CreateCategoryCommand{ID: body.ID, Name: body.Name, SortOrder: body.SortOrder}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.CreateCategory.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) archiveCategory(w http.ResponseWriter, r *http.Request) {
+	cmd := command.ArchiveCategoryCommand{ID: r.PathValue("id")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.ArchiveCategory.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
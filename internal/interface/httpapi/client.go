@@ -0,0 +1,452 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+// Client calls the REST API exposed by Handler. It is used by the CLI in remote mode.
+type Client struct {
+	// BaseURL is the server root, e.g. "http://localhost:8080".
+	BaseURL string
+	// Actor is sent as X-Actor on every request when set.
+	Actor string
+	// Token is a personal API token sent as a Bearer credential when set.
+	Token string
+	// HTTP defaults to http.DefaultClient.
+	HTTP *http.Client
+}
+
+// APIError is a non-2xx response. It unwraps to the apperr kind matching the status,
+// so callers can use errors.Is the same way in local and remote mode.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.Status {
+	case http.StatusBadRequest:
+		return apperr.ErrInvalid
+	case http.StatusNotFound:
+		return apperr.ErrNotFound
+	case http.StatusConflict:
+		return apperr.ErrConflict
+	case http.StatusUnauthorized:
+		return apperr.ErrUnauthorized
+	case http.StatusForbidden:
+		return apperr.ErrForbidden
+	default:
+		return nil
+	}
+}
+
+// CreateDoneLog creates a DONELOG and returns its ID. An empty idempotencyKey is not sent.
+func (c *Client) CreateDoneLog(ctx context.Context, body CreateDoneLogRequest, idempotencyKey string) (string, error) {
+	header := http.Header{}
+	if idempotencyKey != "" {
+		header.Set(headerIdempotencyKey, idempotencyKey)
+	}
+	var res CreateDoneLogResponse
+	err := c.do(ctx, http.MethodPost, "/api/donelogs", nil, header, body, &res)
+	return res.ID, err
+}
+
+func (c *Client) UpdateDoneLog(ctx context.Context, id string, body UpdateDoneLogRequest) error {
+	return c.do(ctx, http.MethodPut, "/api/donelogs/"+url.PathEscape(id), nil, nil, body, nil)
+}
+
+func (c *Client) DeleteDoneLog(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/donelogs/"+url.PathEscape(id), nil, nil, nil, nil)
+}
+
+func (c *Client) RestoreDoneLog(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/api/donelogs/"+url.PathEscape(id)+"/restore", nil, nil, nil, nil)
+}
+
+func (c *Client) TagDoneLog(ctx context.Context, id string, body TagDoneLogRequest) error {
+	return c.do(ctx, http.MethodPost, "/api/donelogs/"+url.PathEscape(id)+"/tags", nil, nil, body, nil)
+}
+
+func (c *Client) Undo(ctx context.Context) (UndoResponse, error) {
+	var res UndoResponse
+	err := c.do(ctx, http.MethodPost, "/api/donelogs/undo", nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) PurgeTrash(ctx context.Context) (PurgeResponse, error) {
+	var res PurgeResponse
+	err := c.do(ctx, http.MethodPost, "/api/donelogs/purge", nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) GetDoneLog(ctx context.Context, id string) (query.DoneLogItem, error) {
+	var res query.DoneLogItem
+	err := c.do(ctx, http.MethodGet, "/api/donelogs/"+url.PathEscape(id), nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) ListDoneLogs(ctx context.Context, q query.ListDoneLogsQuery) (query.DoneLogPage, error) {
+	params := url.Values{}
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "tag", q.Tag)
+	setParam(params, "startDate", q.StartDate)
+	setParam(params, "endDate", q.EndDate)
+	if q.Page > 0 {
+		params.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var res query.DoneLogPage
+	err := c.do(ctx, http.MethodGet, "/api/donelogs", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SummarizeByDay(ctx context.Context, q query.SummarizeByDayQuery) (query.Summary, error) {
+	params := url.Values{}
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "tag", q.Tag)
+	setParam(params, "startDate", q.StartDate)
+	setParam(params, "endDate", q.EndDate)
+	var res query.Summary
+	err := c.do(ctx, http.MethodGet, "/api/summaries/daily", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SummarizeByWeek(ctx context.Context, q query.SummarizeByWeekQuery) (query.Summary, error) {
+	params := url.Values{}
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "tag", q.Tag)
+	setParam(params, "startDate", q.StartDate)
+	setParam(params, "endDate", q.EndDate)
+	setParam(params, "weekStart", q.WeekStart)
+	var res query.Summary
+	err := c.do(ctx, http.MethodGet, "/api/summaries/weekly", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SummarizeByMonth(ctx context.Context, q query.SummarizeByMonthQuery) (query.Summary, error) {
+	params := url.Values{}
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "tag", q.Tag)
+	setParam(params, "startMonth", q.StartMonth)
+	setParam(params, "endMonth", q.EndMonth)
+	var res query.Summary
+	err := c.do(ctx, http.MethodGet, "/api/summaries/monthly", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SummarizeByQuarter(ctx context.Context, q query.SummarizeByQuarterQuery) (query.Summary, error) {
+	params := url.Values{}
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "tag", q.Tag)
+	setParam(params, "startQuarter", q.StartQuarter)
+	setParam(params, "endQuarter", q.EndQuarter)
+	var res query.Summary
+	err := c.do(ctx, http.MethodGet, "/api/summaries/quarterly", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SummarizeByYear(ctx context.Context, q query.SummarizeByYearQuery) (query.Summary, error) {
+	params := url.Values{}
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "tag", q.Tag)
+	setParam(params, "startYear", q.StartYear)
+	setParam(params, "endYear", q.EndYear)
+	var res query.Summary
+	err := c.do(ctx, http.MethodGet, "/api/summaries/yearly", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) YearOverYear(ctx context.Context, q query.CompareYearOverYearQuery) (query.YearOverYear, error) {
+	params := url.Values{}
+	if q.Year != 0 {
+		params.Set("year", strconv.Itoa(q.Year))
+	}
+	setParam(params, "unit", q.Unit)
+	setParam(params, "groupBy", q.GroupBy)
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "categoryId", q.CategoryID)
+	var res query.YearOverYear
+	err := c.do(ctx, http.MethodGet, "/api/summaries/yoy", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) Trend(ctx context.Context, q query.GetTrendQuery) (query.Trend, error) {
+	params := url.Values{}
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "categoryId", q.CategoryID)
+	setParam(params, "startDate", q.StartDate)
+	setParam(params, "endDate", q.EndDate)
+	setParam(params, "weekStart", q.WeekStart)
+	var res query.Trend
+	err := c.do(ctx, http.MethodGet, "/api/summaries/trend", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SummarizeByTag(ctx context.Context, q query.SummarizeByTagQuery) (query.TagSummary, error) {
+	params := url.Values{"startDate": {q.StartDate}, "endDate": {q.EndDate}}
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "categoryId", q.CategoryID)
+	var res query.TagSummary
+	err := c.do(ctx, http.MethodGet, "/api/tags/summary", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) RenameTag(ctx context.Context, from, to string) (int, error) {
+	var res TagChangeResponse
+	err := c.do(ctx, http.MethodPut, "/api/tags/"+url.PathEscape(from), nil, nil, RenameTagRequest{To: to}, &res)
+	return res.DoneLogs, err
+}
+
+func (c *Client) DeleteTag(ctx context.Context, tag string) (int, error) {
+	var res TagChangeResponse
+	err := c.do(ctx, http.MethodDelete, "/api/tags/"+url.PathEscape(tag), nil, nil, nil, &res)
+	return res.DoneLogs, err
+}
+
+func (c *Client) Streaks(ctx context.Context, q query.GetStreaksQuery) ([]query.TrackStreak, error) {
+	params := url.Values{}
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "unit", q.Unit)
+	if q.Freezes > 0 {
+		params.Set("freezes", strconv.Itoa(q.Freezes))
+	}
+	var res []query.TrackStreak
+	err := c.do(ctx, http.MethodGet, "/api/streaks", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) Heatmap(ctx context.Context, q query.GetHeatmapQuery) (query.Heatmap, error) {
+	params := url.Values{}
+	if q.Year != 0 {
+		params.Set("year", strconv.Itoa(q.Year))
+	}
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "categoryId", q.CategoryID)
+	var res query.Heatmap
+	err := c.do(ctx, http.MethodGet, "/api/heatmap", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) ListTracks(ctx context.Context, q query.ListTracksQuery) ([]query.TrackItem, error) {
+	var res []query.TrackItem
+	err := c.do(ctx, http.MethodGet, "/api/tracks", archivedParam(q.IncludeArchived), nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) CreateTrack(ctx context.Context, body CreateTrackRequest) error {
+	return c.do(ctx, http.MethodPost, "/api/tracks", nil, nil, body, nil)
+}
+
+func (c *Client) ArchiveTrack(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/api/tracks/"+url.PathEscape(id)+"/archive", nil, nil, nil, nil)
+}
+
+func (c *Client) ListCategories(ctx context.Context, q query.ListCategoriesQuery) ([]query.CategoryItem, error) {
+	var res []query.CategoryItem
+	err := c.do(ctx, http.MethodGet, "/api/categories", archivedParam(q.IncludeArchived), nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) CreateCategory(ctx context.Context, body CreateCategoryRequest) error {
+	return c.do(ctx, http.MethodPost, "/api/categories", nil, nil, body, nil)
+}
+
+func (c *Client) ArchiveCategory(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/api/categories/"+url.PathEscape(id)+"/archive", nil, nil, nil, nil)
+}
+
+func (c *Client) ListGoals(ctx context.Context) ([]query.GoalProgressItem, error) {
+	var res []query.GoalProgressItem
+	err := c.do(ctx, http.MethodGet, "/api/goals", nil, nil, nil, &res)
+	return res, err
+}
+
+// CreateGoal creates a Goal and returns its ID.
+func (c *Client) CreateGoal(ctx context.Context, body CreateGoalRequest) (string, error) {
+	var res CreateGoalResponse
+	err := c.do(ctx, http.MethodPost, "/api/goals", nil, nil, body, &res)
+	return res.ID, err
+}
+
+func (c *Client) GoalProgress(ctx context.Context, id string) (query.GoalProgressItem, error) {
+	var res query.GoalProgressItem
+	err := c.do(ctx, http.MethodGet, "/api/goals/"+url.PathEscape(id)+"/progress", nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) Forecast(ctx context.Context, q query.GetForecastQuery) (query.Forecast, error) {
+	params := url.Values{}
+	setParam(params, "goalId", q.GoalID)
+	setParam(params, "trackId", q.TrackID)
+	setParam(params, "deadline", q.Deadline)
+	if q.Target != 0 {
+		params.Set("target", strconv.Itoa(q.Target))
+	}
+	if q.WindowDays != 0 {
+		params.Set("window", strconv.Itoa(q.WindowDays))
+	}
+	var res query.Forecast
+	err := c.do(ctx, http.MethodGet, "/api/forecast", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) DeleteGoal(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/goals/"+url.PathEscape(id), nil, nil, nil, nil)
+}
+
+func (c *Client) ListTeams(ctx context.Context) ([]query.TeamItem, error) {
+	var res []query.TeamItem
+	err := c.do(ctx, http.MethodGet, "/api/teams", nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) CreateTeam(ctx context.Context, body CreateTeamRequest) error {
+	return c.do(ctx, http.MethodPost, "/api/teams", nil, nil, body, nil)
+}
+
+func (c *Client) SetTeamMember(ctx context.Context, teamID, member, role string) error {
+	path := "/api/teams/" + url.PathEscape(teamID) + "/members/" + url.PathEscape(member)
+	return c.do(ctx, http.MethodPut, path, nil, nil, SetTeamMemberRequest{Role: role}, nil)
+}
+
+func (c *Client) RemoveTeamMember(ctx context.Context, teamID, member string) error {
+	return c.do(ctx, http.MethodDelete, "/api/teams/"+url.PathEscape(teamID)+"/members/"+url.PathEscape(member), nil, nil, nil, nil)
+}
+
+func (c *Client) ListTeamInvites(ctx context.Context) ([]query.TeamInviteItem, error) {
+	var res []query.TeamInviteItem
+	err := c.do(ctx, http.MethodGet, "/api/teams/invites", nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) AcceptTeamInvite(ctx context.Context, teamID string) error {
+	return c.do(ctx, http.MethodPost, "/api/teams/"+url.PathEscape(teamID)+"/accept", nil, nil, nil, nil)
+}
+
+func (c *Client) TeamSummary(ctx context.Context, q query.GetTeamSummaryQuery) (query.TeamSummary, error) {
+	params := url.Values{"startDate": {q.StartDate}, "endDate": {q.EndDate}}
+	setParam(params, "trackId", q.TrackID)
+	var res query.TeamSummary
+	err := c.do(ctx, http.MethodGet, "/api/teams/"+url.PathEscape(q.TeamID)+"/summary", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) Leaderboard(ctx context.Context, q query.GetLeaderboardQuery) (query.Leaderboard, error) {
+	params := url.Values{"startDate": {q.StartDate}, "endDate": {q.EndDate}}
+	setParam(params, "trackId", q.TrackID)
+	var res query.Leaderboard
+	err := c.do(ctx, http.MethodGet, "/api/teams/"+url.PathEscape(q.TeamID)+"/leaderboard", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) CompareMembers(ctx context.Context, q query.CompareMembersQuery) (query.MemberComparison, error) {
+	params := url.Values{"member": {q.Member}, "startDate": {q.StartDate}, "endDate": {q.EndDate}}
+	setParam(params, "trackId", q.TrackID)
+	var res query.MemberComparison
+	err := c.do(ctx, http.MethodGet, "/api/teams/"+url.PathEscape(q.TeamID)+"/compare", params, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SetLeaderboardOptOut(ctx context.Context, teamID string, optOut bool) error {
+	return c.do(ctx, http.MethodPut, "/api/teams/"+url.PathEscape(teamID)+"/privacy", nil, nil, TeamPrivacyRequest{LeaderboardOptOut: optOut}, nil)
+}
+
+func (c *Client) GetSettings(ctx context.Context) (query.SettingsItem, error) {
+	var res query.SettingsItem
+	err := c.do(ctx, http.MethodGet, "/api/settings", nil, nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) UpdateSettings(ctx context.Context, body UpdateSettingsRequest) error {
+	return c.do(ctx, http.MethodPut, "/api/settings", nil, nil, body, nil)
+}
+
+// do sends one request. A nil in skips the body; a nil out discards the response body.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, header http.Header, in, out any) error {
+	target := strings.TrimRight(c.BaseURL, "/") + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Actor != "" {
+		req.Header.Set(headerActor, c.Actor)
+	}
+	if c.Token != "" {
+		req.Header.Set(headerAuthorization, "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.NewDecoder(res.Body).Decode(&e); err != nil || e.Error == "" {
+			e.Error = res.Status
+		}
+		return &APIError{Status: res.StatusCode, Message: e.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func setParam(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
+
+func archivedParam(include bool) url.Values {
+	if !include {
+		return nil
+	}
+	return url.Values{"includeArchived": {"true"}}
+}
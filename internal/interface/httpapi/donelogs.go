@@ -0,0 +1,267 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+const headerIdempotencyKey = "Idempotency-Key"
+
+func (h Handler) createDoneLog(w http.ResponseWriter, r *http.Request) {
+	var body CreateDoneLogRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.CreateDoneLogCommand{
+		Title:          body.Title,
+		TrackID:        body.TrackID,
+		CategoryID:     body.CategoryID,
+		Count:          body.Count,
+		OccurredOn:     body.OccurredOn,
+		Tags:           body.Tags,
+		IdempotencyKey: r.Header.Get(headerIdempotencyKey),
+	}
+
+	var id string
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		created, err := h.CreateDoneLog.Handle(ctx, cmd)
+		id = created.String()
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, CreateDoneLogResponse{ID: id})
+}
+
+func (h Handler) listDoneLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := intParam(q.Get("page"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("page: %w", err))
+		return
+	}
+	limit, err := intParam(q.Get("limit"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("limit: %w", err))
+		return
+	}
+
+	result, err := h.ListDoneLogs.Handle(r.Context(), query.ListDoneLogsQuery{
+		TrackID:    q.Get("trackId"),
+		CategoryID: q.Get("categoryId"),
+		Tag:        q.Get("tag"),
+		StartDate:  q.Get("startDate"),
+		EndDate:    q.Get("endDate"),
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h Handler) getDoneLog(w http.ResponseWriter, r *http.Request) {
+	item, err := h.GetDoneLog.Handle(r.Context(), query.GetDoneLogQuery{ID: r.PathValue("id")})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h Handler) updateDoneLog(w http.ResponseWriter, r *http.Request) {
+	var body UpdateDoneLogRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.UpdateDoneLogCommand{
+		ID:         r.PathValue("id"),
+		Title:      body.Title,
+		CategoryID: body.CategoryID,
+		Count:      body.Count,
+		OccurredOn: body.OccurredOn,
+		Tags:       body.Tags,
+		Note:       body.Note,
+		Links:      body.Links,
+	}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.UpdateDoneLog.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) deleteDoneLog(w http.ResponseWriter, r *http.Request) {
+	cmd := command.DeleteDoneLogCommand{ID: r.PathValue("id")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.DeleteDoneLog.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) restoreDoneLog(w http.ResponseWriter, r *http.Request) {
+	cmd := command.RestoreDoneLogCommand{ID: r.PathValue("id")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.RestoreDoneLog.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) history(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.History.Handle(r.Context(), query.GetDoneLogHistoryQuery{ID: r.PathValue("id")})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (h Handler) dailySummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.SummarizeByDay.Handle(r.Context(), query.SummarizeByDayQuery{
+		CategoryID: q.Get("categoryId"),
+		Tag:        q.Get("tag"),
+		StartDate:  q.Get("startDate"),
+		EndDate:    q.Get("endDate"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h Handler) weeklySummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.SummarizeByWeek.Handle(r.Context(), query.SummarizeByWeekQuery{
+		CategoryID: q.Get("categoryId"),
+		Tag:        q.Get("tag"),
+		StartDate:  q.Get("startDate"),
+		EndDate:    q.Get("endDate"),
+		WeekStart:  q.Get("weekStart"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h Handler) monthlySummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.SummarizeByMonth.Handle(r.Context(), query.SummarizeByMonthQuery{
+		CategoryID: q.Get("categoryId"),
+		Tag:        q.Get("tag"),
+		StartMonth: q.Get("startMonth"),
+		EndMonth:   q.Get("endMonth"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h Handler) quarterlySummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.SummarizeByQuarter.Handle(r.Context(), query.SummarizeByQuarterQuery{
+		CategoryID:   q.Get("categoryId"),
+		Tag:          q.Get("tag"),
+		StartQuarter: q.Get("startQuarter"),
+		EndQuarter:   q.Get("endQuarter"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h Handler) yearlySummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.SummarizeByYear.Handle(r.Context(), query.SummarizeByYearQuery{
+		CategoryID: q.Get("categoryId"),
+		Tag:        q.Get("tag"),
+		StartYear:  q.Get("startYear"),
+		EndYear:    q.Get("endYear"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h Handler) yearOverYear(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	year, err := intParam(q.Get("year"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("year: %w", err))
+		return
+	}
+	result, err := h.YearOverYear.Handle(r.Context(), query.CompareYearOverYearQuery{
+		Year:       year,
+		Unit:       q.Get("unit"),
+		GroupBy:    q.Get("groupBy"),
+		TrackID:    q.Get("trackId"),
+		CategoryID: q.Get("categoryId"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h Handler) trend(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	trend, err := h.Trend.Handle(r.Context(), query.GetTrendQuery{
+		TrackID:    q.Get("trackId"),
+		CategoryID: q.Get("categoryId"),
+		StartDate:  q.Get("startDate"),
+		EndDate:    q.Get("endDate"),
+		WeekStart:  q.Get("weekStart"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trend)
+}
+
+func (h Handler) streaks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	freezes, err := intParam(q.Get("freezes"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("freezes: %w", err))
+		return
+	}
+	streaks, err := h.Streaks.Handle(r.Context(), query.GetStreaksQuery{
+		TrackID: q.Get("trackId"),
+		Unit:    q.Get("unit"),
+		Freezes: freezes,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, streaks)
+}
+
+// intParam parses an optional integer query parameter; empty means zero.
+func intParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,138 @@
+package httpapi
+
+// Request and response bodies shared by the server routes and Client.
+
+// CreateDoneLogRequest is the body of POST /api/donelogs.
+// The Idempotency-Key header, when present, makes retries safe.
+type CreateDoneLogRequest struct {
+	Title      string   `json:"title"`
+	TrackID    string   `json:"trackId"`
+	CategoryID string   `json:"categoryId"`
+	Count      int      `json:"count"`
+	OccurredOn string   `json:"occurredOn"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// CreateDoneLogResponse returns the ID of the created DONELOG.
+type CreateDoneLogResponse struct {
+	ID string `json:"id"`
+}
+
+// UpdateDoneLogRequest is the body of PUT /api/donelogs/{id}.
+type UpdateDoneLogRequest struct {
+	Title      string `json:"title"`
+	CategoryID string `json:"categoryId"`
+	Count      int    `json:"count"`
+	OccurredOn string `json:"occurredOn"`
+	// Tags replaces the tag set; null or absent keeps the current tags and [] clears them.
+	Tags []string `json:"tags"`
+	// Note replaces the Markdown note; null or absent keeps it and "" clears it.
+	Note *string `json:"note"`
+	// Links replaces the attached URLs; null or absent keeps them and [] clears them.
+	Links []string `json:"links"`
+}
+
+// TagDoneLogRequest is the body of POST /api/donelogs/{id}/tags.
+type TagDoneLogRequest struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// RenameTagRequest is the body of PUT /api/tags/{tag}.
+type RenameTagRequest struct {
+	To string `json:"to"`
+}
+
+// TagChangeResponse reports how many DONELOGs a tag rename or deletion touched.
+type TagChangeResponse struct {
+	DoneLogs int `json:"doneLogs"`
+}
+
+// UndoResponse reports which change POST /api/donelogs/undo reverted.
+type UndoResponse struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+// PurgeResponse lists the trashed DONELOGs POST /api/donelogs/purge removed permanently.
+type PurgeResponse struct {
+	Purged []string `json:"purged"`
+}
+
+// UpdateSettingsRequest is the body of PUT /api/settings. Empty fields keep their current value.
+type UpdateSettingsRequest struct {
+	WeekStart string `json:"weekStart,omitempty"`
+}
+
+// CreateTrackRequest is the body of POST /api/tracks.
+type CreateTrackRequest struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	DefaultCategoryID string `json:"defaultCategoryId,omitempty"`
+	SortOrder         int    `json:"sortOrder"`
+	// TeamID shares the Track with a Team; it cannot be combined with DefaultCategoryID.
+	TeamID string `json:"teamId,omitempty"`
+}
+
+// CreateTeamRequest is the body of POST /api/teams.
+type CreateTeamRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// SetTeamMemberRequest is the body of PUT /api/teams/{id}/members/{member}.
+type SetTeamMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// TeamPrivacyRequest is the body of PUT /api/teams/{id}/privacy. It applies to the caller only.
+type TeamPrivacyRequest struct {
+	LeaderboardOptOut bool `json:"leaderboardOptOut"`
+}
+
+// CreateGoalRequest is the body of POST /api/goals. Set exactly one of TrackID and CategoryID.
+type CreateGoalRequest struct {
+	Name       string `json:"name"`
+	TrackID    string `json:"trackId,omitempty"`
+	CategoryID string `json:"categoryId,omitempty"`
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+	Target     int    `json:"target"`
+}
+
+// CreateGoalResponse returns the ID of the created Goal.
+type CreateGoalResponse struct {
+	ID string `json:"id"`
+}
+
+// CreateCategoryRequest is the body of POST /api/categories.
+type CreateCategoryRequest struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sortOrder"`
+}
+
+// LoginRequest is the body of POST /api/auth/login.
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ChangePasswordRequest is the body of POST /api/auth/password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// MeResponse describes the authenticated caller of GET /api/auth/me.
+type MeResponse struct {
+	Username string   `json:"username"`
+	Scopes   []string `json:"scopes"`
+	Method   string   `json:"method"`
+}
+
+// CreateTokenRequest is the body of POST /api/tokens.
+type CreateTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
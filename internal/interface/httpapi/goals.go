@@ -0,0 +1,95 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func (h Handler) listGoals(w http.ResponseWriter, r *http.Request) {
+	items, err := h.ListGoals.Handle(r.Context(), query.ListGoalProgressQuery{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h Handler) createGoal(w http.ResponseWriter, r *http.Request) {
+	var body CreateGoalRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.CreateGoalCommand{
+		Name:       body.Name,
+		TrackID:    body.TrackID,
+		CategoryID: body.CategoryID,
+		StartDate:  body.StartDate,
+		EndDate:    body.EndDate,
+		Target:     body.Target,
+	}
+
+	var id string
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		created, err := h.CreateGoal.Handle(ctx, cmd)
+		id = created.String()
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, CreateGoalResponse{ID: id})
+}
+
+func (h Handler) goalProgress(w http.ResponseWriter, r *http.Request) {
+	item, err := h.GoalProgress.Handle(r.Context(), query.GetGoalProgressQuery{ID: r.PathValue("id")})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// forecast serves both GET /api/forecast and GET /api/goals/{id}/forecast.
+func (h Handler) forecast(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	target, err := intParam(q.Get("target"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("target: %w", err))
+		return
+	}
+	window, err := intParam(q.Get("window"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("window: %w", err))
+		return
+	}
+	goalID := r.PathValue("id")
+	if goalID == "" {
+		goalID = q.Get("goalId")
+	}
+	forecast, err := h.Forecast.Handle(r.Context(), query.GetForecastQuery{
+		GoalID:     goalID,
+		TrackID:    q.Get("trackId"),
+		Target:     target,
+		Deadline:   q.Get("deadline"),
+		WindowDays: window,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, forecast)
+}
+
+func (h Handler) deleteGoal(w http.ResponseWriter, r *http.Request) {
+	cmd := command.DeleteGoalCommand{ID: r.PathValue("id")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.DeleteGoal.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
@@ -0,0 +1,220 @@
+// Package httpapi exposes the DONELOG application layer over REST.
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/taketosaeki/donelog/internal/app/auth"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/export"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+// Handler wires HTTP routes to application command and query handlers.
+type Handler struct {
+	// Tx, when set, runs each mutating request in a single transaction.
+	Tx command.Transactor
+
+	// Auth authenticates session cookies and API tokens, and signs in through the identity provider when one is configured.
+	Auth auth.Service
+	// RequireAuth rejects requests without a session or token with 401.
+	// When false, such requests fall back to the trusted X-Actor header.
+	RequireAuth bool
+
+	CreateDoneLog     command.CreateDoneLogHandler
+	UpdateDoneLog     command.UpdateDoneLogHandler
+	DeleteDoneLog     command.DeleteDoneLogHandler
+	RestoreDoneLog    command.RestoreDoneLogHandler
+	PurgeTrash        command.PurgeTrashedDoneLogsHandler
+	Undo              command.UndoLastChangeHandler
+	CreateTrack       command.CreateTrackHandler
+	ArchiveTrack      command.ArchiveTrackHandler
+	CreateCategory    command.CreateCategoryHandler
+	ArchiveCategory   command.ArchiveCategoryHandler
+	CreateGoal        command.CreateGoalHandler
+	DeleteGoal        command.DeleteGoalHandler
+	CreateTeam        command.CreateTeamHandler
+	SetTeamMember     command.SetTeamMemberHandler
+	RemoveTeamMember  command.RemoveTeamMemberHandler
+	AcceptTeamInvite  command.AcceptTeamInviteHandler
+	LeaderboardOptOut command.SetLeaderboardOptOutHandler
+	TagDoneLog        command.TagDoneLogHandler
+	RenameTag         command.RenameTagHandler
+	DeleteTag         command.DeleteTagHandler
+	UpdateSettings    command.UpdateSettingsHandler
+
+	ListDoneLogs       query.ListDoneLogsHandler
+	GetDoneLog         query.GetDoneLogHandler
+	History            query.GetDoneLogHistoryHandler
+	SummarizeByDay     query.SummarizeByDayHandler
+	SummarizeByWeek    query.SummarizeByWeekHandler
+	SummarizeByMonth   query.SummarizeByMonthHandler
+	SummarizeByQuarter query.SummarizeByQuarterHandler
+	SummarizeByYear    query.SummarizeByYearHandler
+	YearOverYear       query.CompareYearOverYearHandler
+	Trend              query.GetTrendHandler
+	ListTracks         query.ListTracksHandler
+	ListCategories     query.ListCategoriesHandler
+	Streaks            query.GetStreaksHandler
+	ListGoals          query.ListGoalProgressHandler
+	GoalProgress       query.GetGoalProgressHandler
+	Forecast           query.GetForecastHandler
+	Heatmap            query.GetHeatmapHandler
+	ListTeams          query.ListTeamsHandler
+	ListTeamInvites    query.ListTeamInvitesHandler
+	TeamSummary        query.GetTeamSummaryHandler
+	Leaderboard        query.GetLeaderboardHandler
+	CompareMembers     query.CompareMembersHandler
+	SummarizeByTag     query.SummarizeByTagHandler
+	GetSettings        query.GetSettingsHandler
+
+	Export export.Exporter
+}
+
+// Routes returns the HTTP routing table wrapped with the request-context and authentication middleware.
+func (h Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/auth/login", h.login)
+	mux.HandleFunc("POST /api/auth/logout", h.logout)
+	mux.HandleFunc("GET /api/auth/me", h.me)
+	mux.HandleFunc("POST /api/auth/password", h.changePassword)
+	mux.HandleFunc("GET /api/auth/sso/login", h.ssoLogin)
+	mux.HandleFunc("GET /api/auth/sso/callback", h.ssoCallback)
+	mux.HandleFunc("GET /api/tokens", h.listTokens)
+	mux.HandleFunc("POST /api/tokens", h.createToken)
+	mux.HandleFunc("DELETE /api/tokens/{id}", h.revokeToken)
+	mux.HandleFunc("POST /api/donelogs", h.createDoneLog)
+	mux.HandleFunc("GET /api/donelogs", h.listDoneLogs)
+	mux.HandleFunc("GET /api/donelogs/{id}", h.getDoneLog)
+	mux.HandleFunc("PUT /api/donelogs/{id}", h.updateDoneLog)
+	mux.HandleFunc("DELETE /api/donelogs/{id}", h.deleteDoneLog)
+	mux.HandleFunc("POST /api/donelogs/{id}/restore", h.restoreDoneLog)
+	mux.HandleFunc("GET /api/donelogs/{id}/history", h.history)
+	mux.HandleFunc("POST /api/donelogs/{id}/tags", h.tagDoneLog)
+	mux.HandleFunc("POST /api/donelogs/undo", h.undo)
+	mux.HandleFunc("POST /api/donelogs/purge", h.purgeTrash)
+	mux.HandleFunc("GET /api/donelogs/export", h.export)
+	mux.HandleFunc("GET /api/summaries/daily", h.dailySummary)
+	mux.HandleFunc("GET /api/summaries/weekly", h.weeklySummary)
+	mux.HandleFunc("GET /api/summaries/monthly", h.monthlySummary)
+	mux.HandleFunc("GET /api/summaries/quarterly", h.quarterlySummary)
+	mux.HandleFunc("GET /api/summaries/yearly", h.yearlySummary)
+	mux.HandleFunc("GET /api/summaries/yoy", h.yearOverYear)
+	mux.HandleFunc("GET /api/summaries/trend", h.trend)
+	mux.HandleFunc("GET /api/tags/summary", h.tagSummary)
+	mux.HandleFunc("PUT /api/tags/{tag}", h.renameTag)
+	mux.HandleFunc("DELETE /api/tags/{tag}", h.deleteTag)
+	mux.HandleFunc("GET /api/settings", h.getSettings)
+	mux.HandleFunc("PUT /api/settings", h.updateSettings)
+	mux.HandleFunc("GET /api/streaks", h.streaks)
+	mux.HandleFunc("GET /api/heatmap", h.heatmap)
+	mux.HandleFunc("GET /api/heatmap.svg", h.heatmapSVG)
+	mux.HandleFunc("GET /api/tracks", h.listTracks)
+	mux.HandleFunc("POST /api/tracks", h.createTrack)
+	mux.HandleFunc("POST /api/tracks/{id}/archive", h.archiveTrack)
+	mux.HandleFunc("GET /api/categories", h.listCategories)
+	mux.HandleFunc("POST /api/categories", h.createCategory)
+	mux.HandleFunc("POST /api/categories/{id}/archive", h.archiveCategory)
+	mux.HandleFunc("GET /api/goals", h.listGoals)
+	mux.HandleFunc("POST /api/goals", h.createGoal)
+	mux.HandleFunc("GET /api/goals/{id}/progress", h.goalProgress)
+	mux.HandleFunc("GET /api/goals/{id}/forecast", h.forecast)
+	mux.HandleFunc("GET /api/forecast", h.forecast)
+	mux.HandleFunc("DELETE /api/goals/{id}", h.deleteGoal)
+	mux.HandleFunc("GET /api/teams", h.listTeams)
+	mux.HandleFunc("POST /api/teams", h.createTeam)
+	mux.HandleFunc("PUT /api/teams/{id}/members/{member}", h.setTeamMember)
+	mux.HandleFunc("DELETE /api/teams/{id}/members/{member}", h.removeTeamMember)
+	mux.HandleFunc("GET /api/teams/invites", h.listTeamInvites)
+	mux.HandleFunc("POST /api/teams/{id}/accept", h.acceptTeamInvite)
+	mux.HandleFunc("GET /api/teams/{id}/summary", h.teamSummary)
+	mux.HandleFunc("GET /api/teams/{id}/leaderboard", h.leaderboard)
+	mux.HandleFunc("GET /api/teams/{id}/compare", h.compareMembers)
+	mux.HandleFunc("PUT /api/teams/{id}/privacy", h.setLeaderboardOptOut)
+	return WithRequestContext(h.authenticate(mux))
+}
+
+// inTx runs fn inside h.Tx when one is configured.
+func (h Handler) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if h.Tx == nil {
+		return fn(ctx)
+	}
+	return h.Tx.WithinTx(ctx, fn)
+}
+
+func (h Handler) undo(w http.ResponseWriter, r *http.Request) {
+	var result command.UndoResult
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		result, err = h.Undo.Handle(ctx, command.UndoLastChangeCommand{})
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, UndoResponse{
+		ID:     result.DoneLogID.String(),
+		Action: string(result.Action),
+	})
+}
+
+func (h Handler) purgeTrash(w http.ResponseWriter, r *http.Request) {
+	var ids []donelog.DoneLogID
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		ids, err = h.PurgeTrash.Handle(ctx, command.PurgeTrashedDoneLogsCommand{})
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := PurgeResponse{Purged: make([]string, 0, len(ids))}
+	for _, id := range ids {
+		res.Purged = append(res.Purged, id.String())
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h Handler) export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = string(export.FormatCSV)
+	}
+	req, err := export.ParseRequest(q.Get("startDate"), q.Get("endDate"), q.Get("trackId"), q.Get("categoryId"), format)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("donelogs_%s_%s.%s", q.Get("startDate"), q.Get("endDate"), req.Format)
+	w.Header().Set("Content-Type", req.Format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	out := &trackingWriter{ResponseWriter: w}
+	if err := h.Export.Export(r.Context(), req, out); err != nil {
+		if !out.wrote {
+			w.Header().Del("Content-Disposition")
+			writeError(w, err)
+			return
+		}
+		// The download has already started; abort it so the client sees a truncated transfer.
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// trackingWriter remembers whether any body bytes were sent.
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (t *trackingWriter) Write(b []byte) (int, error) {
+	t.wrote = true
+	return t.ResponseWriter.Write(b)
+}
@@ -0,0 +1,277 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/export"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+	"github.com/taketosaeki/donelog/internal/infrastructure/persistence/filestore"
+)
+
+type stubDoneLogRepo struct {
+	current *donelog.RawDoneLog
+}
+
+func (s *stubDoneLogRepo) Save(ctx context.Context, log *donelog.DoneLog) error {
+	raw := log.Raw()
+	s.current = &raw
+	return nil
+}
+func (s *stubDoneLogRepo) FindByID(ctx context.Context, id donelog.DoneLogID) (*donelog.RawDoneLog, error) {
+	return s.current, nil
+}
+func (s *stubDoneLogRepo) Delete(ctx context.Context, id donelog.DoneLogID) error {
+	s.current = nil
+	return nil
+}
+
+type stubUndoJournal struct {
+	entries map[string]command.UndoEntry
+}
+
+func (s *stubUndoJournal) Push(ctx context.Context, entry command.UndoEntry) error {
+	s.entries[entry.Actor] = entry
+	return nil
+}
+func (s *stubUndoJournal) Latest(ctx context.Context, actor string) (*command.UndoEntry, error) {
+	entry, ok := s.entries[actor]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+func (s *stubUndoJournal) DropLatest(ctx context.Context, actor string) error {
+	delete(s.entries, actor)
+	return nil
+}
+
+type stubAuditLog struct{}
+
+func (stubAuditLog) Append(ctx context.Context, entry donelog.AuditEntry) error { return nil }
+
+type stubTime struct{ now time.Time }
+
+func (s stubTime) Now() time.Time { return s.now }
+
+func TestUndoEndpoint(t *testing.T) {
+	now := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	created := donelog.RawDoneLog{
+		ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Title:      "Oops",
+		TrackID:    "track_sample",
+		CategoryID: "cat_sample",
+		Count:      1,
+		OccurredOn: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name       string
+		actor      string
+		wantStatus int
+		wantAction string
+	}{
+		{"OK: undoes own change", "taketo", http.StatusOK, "created"},
+		{"NG: nothing to undo for another actor", "guest", http.StatusNotFound, ""},
+		{"NG: anonymous callers have no undo", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubDoneLogRepo{current: &created}
+			journal := &stubUndoJournal{entries: map[string]command.UndoEntry{
+				"actor:taketo": {Actor: "actor:taketo", Action: donelog.AuditActionCreated, DoneLogID: created.ID, After: &created, RecordedAt: now},
+			}}
+			h := Handler{Undo: command.UndoLastChangeHandler{
+				DoneLogs: repo,
+				Undo:     journal,
+				Audit:    stubAuditLog{},
+				Time:     stubTime{now: now.Add(time.Minute)},
+				Window:   5 * time.Minute,
+			}}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/donelogs/undo", nil)
+			req.Header.Set("X-Actor", tt.actor)
+			req.Header.Set("X-Request-ID", "req-1")
+			rec := httptest.NewRecorder()
+			h.Routes().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Header().Get("X-Request-ID") != "req-1" {
+				t.Fatalf("expected request id to be echoed")
+			}
+			if tt.wantAction == "" {
+				return
+			}
+			var body UndoResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			if body.Action != tt.wantAction || body.ID != created.ID {
+				t.Fatalf("unexpected body: %+v", body)
+			}
+			if repo.current != nil {
+				t.Fatalf("expected created log to be removed")
+			}
+		})
+	}
+}
+
+func TestWithRequestContext(t *testing.T) {
+	var gotActor, gotRequestID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotActor = appctx.Actor(r.Context())
+		gotRequestID = appctx.RequestID(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Actor", "taketo")
+	rec := httptest.NewRecorder()
+	WithRequestContext(next).ServeHTTP(rec, req)
+
+	if gotActor != "taketo" {
+		t.Fatalf("expected actor taketo, got %q", gotActor)
+	}
+	if gotRequestID == "" || rec.Header().Get("X-Request-ID") != gotRequestID {
+		t.Fatalf("expected generated request id to be echoed, got %q", gotRequestID)
+	}
+}
+
+func TestExportEndpoint(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	log, _ := donelog.RehydrateDoneLog(donelog.RawDoneLog{
+		ID:         "01HYR1X5C9XM9P6H7K71M9QAHX",
+		Title:      "ch.1",
+		TrackID:    "track_book",
+		CategoryID: "cat_reading",
+		Count:      3,
+		OccurredOn: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	})
+	_ = store.DoneLogs().Save(ctx, log)
+
+	h := Handler{Export: export.Exporter{DoneLogs: store.DoneLogs(), Tracks: store.Tracks(), Categories: store.Categories()}}
+
+	tests := []struct {
+		name            string
+		url             string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "OK: csv download",
+			url:             "/api/donelogs/export?startDate=2024-05-01&endDate=2024-05-31",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "01HYR1X5C9XM9P6H7K71M9QAHX,ch.1,track_book,,cat_reading,,3,2024-05-01",
+		},
+		{
+			name:       "NG: missing dates",
+			url:        "/api/donelogs/export?format=jsonl",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.Routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantContentType != "" && rec.Header().Get("Content-Type") != tt.wantContentType {
+				t.Fatalf("unexpected content type: %s", rec.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Fatalf("unexpected body: %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHeatmapEndpoint(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	for i, day := range []int{1, 2, 2} {
+		log, _ := donelog.RehydrateDoneLog(donelog.RawDoneLog{
+			ID:         []string{"01HYR1X5C9XM9P6H7K71M9QAH1", "01HYR1X5C9XM9P6H7K71M9QAH2", "01HYR1X5C9XM9P6H7K71M9QAH3"}[i],
+			Title:      "ch",
+			TrackID:    "track_book",
+			CategoryID: "cat_reading",
+			Count:      4,
+			OccurredOn: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+		})
+		_ = store.DoneLogs().Save(ctx, log)
+	}
+	h := Handler{Heatmap: query.GetHeatmapHandler{DoneLogs: store.DoneLogs(), Clock: stubTime{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}}}
+
+	tests := []struct {
+		name            string
+		url             string
+		wantStatus      int
+		wantContentType string
+		wantBody        []string
+	}{
+		{
+			name:            "OK: svg with one cell per day, Monday first",
+			url:             "/api/heatmap.svg?trackId=track_book",
+			wantStatus:      http.StatusOK,
+			wantContentType: "image/svg+xml",
+			wantBody: []string{
+				"<svg ",
+				`<rect x="30" y="20" width="11" height="11" rx="2" fill="#9be9a8" data-date="2024-01-01" data-count="4" data-level="1">`,
+				`data-date="2024-01-02" data-count="8" data-level="4"`,
+				`data-date="2024-12-31" data-count="0" data-level="0"`,
+				"12 done in 2024 (track track_book)",
+			},
+		},
+		{
+			name:            "OK: json",
+			url:             "/api/heatmap?year=2024&categoryId=cat_reading",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        []string{`"totalCount":12`, `"activeDays":2`},
+		},
+		{
+			name:       "NG: year is not a number",
+			url:        "/api/heatmap.svg?year=last",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "NG: invalid track id",
+			url:        "/api/heatmap.svg?trackId=Bad%20Id",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.Routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantContentType != "" && rec.Header().Get("Content-Type") != tt.wantContentType {
+				t.Fatalf("unexpected content type: %s", rec.Header().Get("Content-Type"))
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Fatalf("expected %q in body", want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package httpapi
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func (h Handler) heatmap(w http.ResponseWriter, r *http.Request) {
+	heatmap, ok := h.loadHeatmap(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, heatmap)
+}
+
+func (h Handler) heatmapSVG(w http.ResponseWriter, r *http.Request) {
+	heatmap, ok := h.loadHeatmap(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(renderHeatmapSVG(heatmap))
+}
+
+// loadHeatmap runs the query from the URL parameters, writing the error response on failure.
+func (h Handler) loadHeatmap(w http.ResponseWriter, r *http.Request) (query.Heatmap, bool) {
+	q := r.URL.Query()
+	year, err := intParam(q.Get("year"))
+	if err != nil {
+		writeBadRequest(w, fmt.Errorf("year: %w", err))
+		return query.Heatmap{}, false
+	}
+	heatmap, err := h.Heatmap.Handle(r.Context(), query.GetHeatmapQuery{
+		Year:       year,
+		TrackID:    q.Get("trackId"),
+		CategoryID: q.Get("categoryId"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return query.Heatmap{}, false
+	}
+	return heatmap, true
+}
+
+// Heatmap geometry in SVG user units.
+const (
+	heatmapCell   = 11
+	heatmapStep   = 13
+	heatmapLeft   = 30
+	heatmapTop    = 20
+	heatmapBottom = 30
+)
+
+// heatmapColors are indexed by level, from no activity to the busiest quartile.
+var heatmapColors = [...]string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+
+// renderHeatmapSVG draws one column per week and one row per weekday, Monday first.
+func renderHeatmapSVG(hm query.Heatmap) []byte {
+	first := time.Date(hm.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(first.Weekday()) + 6) % 7
+	weeks := (offset + len(hm.Days) + 6) / 7
+	width := heatmapLeft + weeks*heatmapStep + 10
+	height := heatmapTop + 7*heatmapStep + heatmapBottom
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="9" fill="#57606a">`,
+		width, height, width, height)
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(heatmapCaption(hm)))
+
+	for m := time.January; m <= time.December; m++ {
+		day := time.Date(hm.Year, m, 1, 0, 0, 0, 0, time.UTC).YearDay() - 1
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", heatmapLeft+(offset+day)/7*heatmapStep, heatmapTop-6, m.String()[:3])
+	}
+	for row, label := range []string{"Mon", "", "Wed", "", "Fri", "", ""} {
+		if label != "" {
+			fmt.Fprintf(&b, `<text x="0" y="%d">%s</text>`+"\n", heatmapTop+row*heatmapStep+heatmapCell-2, label)
+		}
+	}
+
+	for i, day := range hm.Days {
+		slot := offset + i
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s" data-date="%s" data-count="%d" data-level="%d"><title>%s: %d</title></rect>`+"\n",
+			heatmapLeft+slot/7*heatmapStep, heatmapTop+slot%7*heatmapStep, heatmapCell, heatmapCell,
+			heatmapColors[day.Level], day.Date, day.Count, day.Level, day.Date, day.Count)
+	}
+
+	legendY := heatmapTop + 7*heatmapStep + 12
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", heatmapLeft, legendY+9, html.EscapeString(heatmapCaption(hm)))
+	legendX := width - 10 - len(heatmapColors)*heatmapStep - 30
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">Less</text>`+"\n", legendX-4, legendY+9)
+	for level, color := range heatmapColors {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/>`+"\n", legendX+level*heatmapStep, legendY, heatmapCell, heatmapCell, color)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d">More</text>`+"\n", legendX+len(heatmapColors)*heatmapStep+2, legendY+9)
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
+
+func heatmapCaption(hm query.Heatmap) string {
+	caption := fmt.Sprintf("%d done in %d", hm.TotalCount, hm.Year)
+	switch {
+	case hm.TrackID != "":
+		caption += " (track " + hm.TrackID + ")"
+	case hm.CategoryID != "":
+		caption += " (category " + hm.CategoryID + ")"
+	}
+	return caption
+}
@@ -0,0 +1,125 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/taketosaeki/donelog/internal/app/appctx"
+	"github.com/taketosaeki/donelog/internal/app/apperr"
+	"github.com/taketosaeki/donelog/internal/domain/account"
+	"github.com/taketosaeki/donelog/internal/domain/donelog"
+)
+
+const (
+	headerRequestID     = "X-Request-ID"
+	headerActor         = "X-Actor"
+	headerAuthorization = "Authorization"
+	sessionCookie       = "donelog_session"
+	ssoStateCookie      = "donelog_sso_state"
+)
+
+// publicRoutes are reachable without credentials because they are how credentials are obtained.
+var publicRoutes = map[string]bool{
+	"POST /api/auth/login":       true,
+	"GET /api/auth/sso/login":    true,
+	"GET /api/auth/sso/callback": true,
+}
+
+// WithRequestContext stores the request ID and acting user in the request context.
+// The request ID is taken from X-Request-ID or generated, and echoed back to the client.
+// The actor also becomes the data owner, so every repository call is scoped to their data;
+// requests without X-Actor work on the single-user owner's data.
+func WithRequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(headerRequestID)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(headerRequestID, requestID)
+
+		ctx := appctx.WithRequestID(r.Context(), requestID)
+		if actor := r.Header.Get(headerActor); actor != "" {
+			owner, err := donelog.NewOwnerID(actor)
+			if err != nil {
+				writeBadRequest(w, fmt.Errorf("%s: %w", headerActor, err))
+				return
+			}
+			ctx = appctx.WithOwner(appctx.WithActor(ctx, actor), owner)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate resolves a Bearer API token or the session cookie into the principal of the request.
+// The principal replaces any X-Actor, and its scopes must cover the method: GET and HEAD need read,
+// everything else needs write. Requests without credentials pass through unless RequireAuth is set.
+// The sign-in routes in publicRoutes are always public.
+func (h Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicRoutes[r.Method+" "+r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		p, ok, err := h.principal(r)
+		if err != nil {
+			if r.Header.Get(headerAuthorization) == "" {
+				clearSessionCookie(w, r)
+			}
+			writeUnauthorized(w, err)
+			return
+		}
+		if !ok {
+			if h.RequireAuth {
+				writeUnauthorized(w, fmt.Errorf("sign in or send an API token: %w", apperr.ErrUnauthorized))
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if scope := requiredScope(r.Method); !p.Scopes.Has(scope) {
+			writeError(w, fmt.Errorf("%s requests need the %s scope: %w", r.Method, scope, apperr.ErrForbidden))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(appctx.WithPrincipal(r.Context(), p)))
+	})
+}
+
+// principal authenticates the request; ok is false when it carries no credentials.
+func (h Handler) principal(r *http.Request) (p appctx.Principal, ok bool, err error) {
+	if header := r.Header.Get(headerAuthorization); header != "" {
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			return appctx.Principal{}, false, fmt.Errorf("%s must be a Bearer token: %w", headerAuthorization, apperr.ErrUnauthorized)
+		}
+		p, err = h.Auth.AuthenticateToken(r.Context(), strings.TrimSpace(token))
+		return p, err == nil, err
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil && cookie.Value != "" {
+		p, err = h.Auth.AuthenticateSession(r.Context(), cookie.Value)
+		return p, err == nil, err
+	}
+	return appctx.Principal{}, false, nil
+}
+
+func requiredScope(method string) account.Scope {
+	if method == http.MethodGet || method == http.MethodHead {
+		return account.ScopeRead
+	}
+	return account.ScopeWrite
+}
+
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="donelog"`)
+	writeError(w, err)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,31 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func (h Handler) getSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.GetSettings.Handle(r.Context(), query.GetSettingsQuery{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (h Handler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	var body UpdateSettingsRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.UpdateSettingsCommand{WeekStart: body.WeekStart}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.UpdateSettings.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
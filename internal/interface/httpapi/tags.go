@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func (h Handler) tagDoneLog(w http.ResponseWriter, r *http.Request) {
+	var body TagDoneLogRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.TagDoneLogCommand{ID: r.PathValue("id"), Add: body.Add, Remove: body.Remove}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.TagDoneLog.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) renameTag(w http.ResponseWriter, r *http.Request) {
+	var body RenameTagRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.RenameTagCommand{From: r.PathValue("tag"), To: body.To}
+	var changed int
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		changed, err = h.RenameTag.Handle(ctx, cmd)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, TagChangeResponse{DoneLogs: changed})
+}
+
+func (h Handler) deleteTag(w http.ResponseWriter, r *http.Request) {
+	cmd := command.DeleteTagCommand{Tag: r.PathValue("tag")}
+	var changed int
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		var err error
+		changed, err = h.DeleteTag.Handle(ctx, cmd)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, TagChangeResponse{DoneLogs: changed})
+}
+
+func (h Handler) tagSummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.SummarizeByTag.Handle(r.Context(), query.SummarizeByTagQuery{
+		TrackID:    q.Get("trackId"),
+		CategoryID: q.Get("categoryId"),
+		StartDate:  q.Get("startDate"),
+		EndDate:    q.Get("endDate"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,128 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/command"
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+func (h Handler) listTeams(w http.ResponseWriter, r *http.Request) {
+	items, err := h.ListTeams.Handle(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h Handler) createTeam(w http.ResponseWriter, r *http.Request) {
+	var body CreateTeamRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.CreateTeamCommand{ID: body.ID, Name: body.Name}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.CreateTeam.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) setTeamMember(w http.ResponseWriter, r *http.Request) {
+	var body SetTeamMemberRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.SetTeamMemberCommand{TeamID: r.PathValue("id"), Member: r.PathValue("member"), Role: body.Role}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.SetTeamMember.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) removeTeamMember(w http.ResponseWriter, r *http.Request) {
+	cmd := command.RemoveTeamMemberCommand{TeamID: r.PathValue("id"), Member: r.PathValue("member")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.RemoveTeamMember.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) listTeamInvites(w http.ResponseWriter, r *http.Request) {
+	items, err := h.ListTeamInvites.Handle(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h Handler) acceptTeamInvite(w http.ResponseWriter, r *http.Request) {
+	cmd := command.AcceptTeamInviteCommand{TeamID: r.PathValue("id")}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.AcceptTeamInvite.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
+
+func (h Handler) teamSummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	summary, err := h.TeamSummary.Handle(r.Context(), query.GetTeamSummaryQuery{
+		TeamID:    r.PathValue("id"),
+		TrackID:   q.Get("trackId"),
+		StartDate: q.Get("startDate"),
+		EndDate:   q.Get("endDate"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h Handler) leaderboard(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	board, err := h.Leaderboard.Handle(r.Context(), query.GetLeaderboardQuery{
+		TeamID:    r.PathValue("id"),
+		TrackID:   q.Get("trackId"),
+		StartDate: q.Get("startDate"),
+		EndDate:   q.Get("endDate"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, board)
+}
+
+func (h Handler) compareMembers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	cmp, err := h.CompareMembers.Handle(r.Context(), query.CompareMembersQuery{
+		TeamID:    r.PathValue("id"),
+		Member:    q.Get("member"),
+		TrackID:   q.Get("trackId"),
+		StartDate: q.Get("startDate"),
+		EndDate:   q.Get("endDate"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cmp)
+}
+
+func (h Handler) setLeaderboardOptOut(w http.ResponseWriter, r *http.Request) {
+	var body TeamPrivacyRequest
+	if err := decodeJSON(r, &body); err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	cmd := command.SetLeaderboardOptOutCommand{TeamID: r.PathValue("id"), OptOut: body.LeaderboardOptOut}
+	err := h.inTx(r.Context(), func(ctx context.Context) error {
+		return h.LeaderboardOptOut.Handle(ctx, cmd)
+	})
+	writeNoContent(w, err)
+}
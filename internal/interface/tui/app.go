@@ -0,0 +1,272 @@
+// Package tui is a full-screen terminal UI for quick capture and a glance at recent activity.
+// It talks to the application through Backend, so it works against the local store or a server.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// chartDays is the length of the daily bar chart, today included.
+	chartDays = 30
+	// maxTodayItems caps the "Today" list; it is a capture screen, not a browser.
+	maxTodayItems = 100
+)
+
+// Backend is the subset of DONELOG operations the TUI needs.
+// *httpapi.Client satisfies it, as does the CLI's local backend.
+type Backend interface {
+	CreateDoneLog(ctx context.Context, body httpapi.CreateDoneLogRequest, idempotencyKey string) (string, error)
+	UpdateDoneLog(ctx context.Context, id string, body httpapi.UpdateDoneLogRequest) error
+	DeleteDoneLog(ctx context.Context, id string) error
+	Undo(ctx context.Context) (httpapi.UndoResponse, error)
+	ListDoneLogs(ctx context.Context, q query.ListDoneLogsQuery) (query.DoneLogPage, error)
+	SummarizeByDay(ctx context.Context, q query.SummarizeByDayQuery) (query.Summary, error)
+	ListTracks(ctx context.Context, q query.ListTracksQuery) ([]query.TrackItem, error)
+	ListCategories(ctx context.Context, q query.ListCategoriesQuery) ([]query.CategoryItem, error)
+}
+
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeForm
+	modeConfirmDelete
+)
+
+// categoryTotal is one row of the per-category panel.
+type categoryTotal struct {
+	name  string
+	count int
+}
+
+// App holds the screen state. HandleKey mutates it and View renders it;
+// neither touches the terminal, so both are easy to drive from tests.
+type App struct {
+	backend Backend
+	now     func() time.Time
+
+	rows, cols int
+
+	today      []query.DoneLogItem
+	daily      query.Summary
+	categories []categoryTotal
+	tracks     []query.TrackItem
+
+	cursor    int
+	mode      mode
+	form      *form
+	status    string
+	lastTrack string
+}
+
+// NewApp returns an App reading the clock from now.
+func NewApp(backend Backend, now func() time.Time) *App {
+	return &App{backend: backend, now: now, rows: 24, cols: 80}
+}
+
+// Resize records the terminal size used by View.
+func (a *App) Resize(rows, cols int) {
+	a.rows, a.cols = rows, cols
+}
+
+// Refresh reloads today's DoneLogs, the 30-day daily summary and per-category totals.
+// Failures are shown in the status line rather than returned.
+func (a *App) Refresh(ctx context.Context) {
+	if err := a.load(ctx); err != nil {
+		a.status = "error: " + err.Error()
+	}
+	if a.cursor >= len(a.today) {
+		a.cursor = max(0, len(a.today)-1)
+	}
+}
+
+func (a *App) load(ctx context.Context) error {
+	today := a.now().Format(dateLayout)
+	from := a.now().AddDate(0, 0, 1-chartDays).Format(dateLayout)
+
+	page, err := a.backend.ListDoneLogs(ctx, query.ListDoneLogsQuery{StartDate: today, EndDate: today, Limit: maxTodayItems})
+	if err != nil {
+		return err
+	}
+	a.today = page.Items
+
+	if a.daily, err = a.backend.SummarizeByDay(ctx, query.SummarizeByDayQuery{StartDate: from, EndDate: today}); err != nil {
+		return err
+	}
+	if a.tracks, err = a.backend.ListTracks(ctx, query.ListTracksQuery{}); err != nil {
+		return err
+	}
+
+	categories, err := a.backend.ListCategories(ctx, query.ListCategoriesQuery{})
+	if err != nil {
+		return err
+	}
+	a.categories = a.categories[:0]
+	for _, c := range categories {
+		summary, err := a.backend.SummarizeByDay(ctx, query.SummarizeByDayQuery{CategoryID: c.ID, StartDate: from, EndDate: today})
+		if err != nil {
+			return err
+		}
+		a.categories = append(a.categories, categoryTotal{name: c.Name, count: summary.TotalCount})
+	}
+	return nil
+}
+
+// HandleKey applies one keystroke and reports whether the TUI should exit.
+func (a *App) HandleKey(ctx context.Context, k Key) bool {
+	if k.Kind == KeyCtrlC {
+		return true
+	}
+	switch a.mode {
+	case modeForm:
+		a.handleFormKey(ctx, k)
+	case modeConfirmDelete:
+		a.handleConfirmKey(ctx, k)
+	default:
+		return a.handleBrowseKey(ctx, k)
+	}
+	return false
+}
+
+func (a *App) handleBrowseKey(ctx context.Context, k Key) bool {
+	switch {
+	case k.Kind == KeyUp || k == RuneKey('k'):
+		a.cursor = max(0, a.cursor-1)
+	case k.Kind == KeyDown || k == RuneKey('j'):
+		a.cursor = max(0, min(len(a.today)-1, a.cursor+1))
+	case k == RuneKey('a'):
+		a.form = newAddForm(a.lastTrack, a.now().Format(dateLayout))
+		a.mode = modeForm
+	case k == RuneKey('e'):
+		if item, ok := a.selected(); ok {
+			a.form = newEditForm(item)
+			a.mode = modeForm
+		}
+	case k == RuneKey('d'):
+		if _, ok := a.selected(); ok {
+			a.mode = modeConfirmDelete
+		}
+	case k == RuneKey('u'):
+		result, err := a.backend.Undo(ctx)
+		a.report(err, fmt.Sprintf("reverted %s", result.Action))
+		a.Refresh(ctx)
+	case k == RuneKey('r'):
+		a.status = ""
+		a.Refresh(ctx)
+	case k == RuneKey('q'):
+		return true
+	}
+	return false
+}
+
+func (a *App) handleConfirmKey(ctx context.Context, k Key) {
+	a.mode = modeBrowse
+	item, ok := a.selected()
+	if k != RuneKey('y') || !ok {
+		a.status = "delete cancelled"
+		return
+	}
+	err := a.backend.DeleteDoneLog(ctx, item.ID)
+	a.report(err, fmt.Sprintf("deleted %q (u to undo)", item.Title))
+	a.Refresh(ctx)
+}
+
+func (a *App) handleFormKey(ctx context.Context, k Key) {
+	f := a.form
+	switch k.Kind {
+	case KeyEsc:
+		a.mode, a.form = modeBrowse, nil
+	case KeyTab, KeyDown:
+		f.focus = (f.focus + 1) % len(f.fields)
+	case KeyUp:
+		f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+	case KeyBackspace:
+		value := []rune(f.fields[f.focus].value)
+		if len(value) > 0 {
+			f.fields[f.focus].value = string(value[:len(value)-1])
+		}
+	case KeyRune:
+		f.fields[f.focus].value += string(k.Rune)
+	case KeyEnter:
+		if err := a.submit(ctx); err != nil {
+			// Keep the form open so the input can be fixed.
+			a.status = "error: " + err.Error()
+			return
+		}
+		a.mode, a.form = modeBrowse, nil
+		a.Refresh(ctx)
+	}
+}
+
+// submit sends the form as a create or update.
+func (a *App) submit(ctx context.Context) error {
+	f := a.form
+	count, err := strconv.Atoi(f.value(fieldCount))
+	if err != nil {
+		return fmt.Errorf("count must be a number")
+	}
+
+	if f.editID != "" {
+		err := a.backend.UpdateDoneLog(ctx, f.editID, httpapi.UpdateDoneLogRequest{
+			Title:      f.value(fieldTitle),
+			CategoryID: f.value(fieldCategory),
+			Count:      count,
+			OccurredOn: f.value(fieldDate),
+		})
+		if err == nil {
+			a.status = fmt.Sprintf("updated %q", f.value(fieldTitle))
+		}
+		return err
+	}
+
+	track := f.value(fieldTrack)
+	category := f.value(fieldCategory)
+	if category == "" {
+		category = a.defaultCategory(track)
+	}
+	_, err = a.backend.CreateDoneLog(ctx, httpapi.CreateDoneLogRequest{
+		Title:      f.value(fieldTitle),
+		TrackID:    track,
+		CategoryID: category,
+		Count:      count,
+		OccurredOn: f.value(fieldDate),
+	}, "")
+	if err != nil {
+		return err
+	}
+	a.lastTrack = track
+	a.status = fmt.Sprintf("added %q", f.value(fieldTitle))
+	return nil
+}
+
+func (a *App) defaultCategory(trackID string) string {
+	for _, t := range a.tracks {
+		if t.ID == trackID {
+			return t.DefaultCategoryID
+		}
+	}
+	return ""
+}
+
+func (a *App) selected() (query.DoneLogItem, bool) {
+	if a.cursor < 0 || a.cursor >= len(a.today) {
+		return query.DoneLogItem{}, false
+	}
+	return a.today[a.cursor], true
+}
+
+func (a *App) report(err error, success string) {
+	if err != nil {
+		a.status = "error: " + err.Error()
+		return
+	}
+	a.status = success
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strconv"
+
+	"github.com/taketosaeki/donelog/internal/app/donelog/query"
+)
+
+const (
+	fieldTitle    = "Title"
+	fieldTrack    = "Track"
+	fieldCategory = "Category"
+	fieldCount    = "Count"
+	fieldDate     = "Date"
+)
+
+type formField struct {
+	label string
+	value string
+}
+
+// form is the add/edit panel. An empty editID means "add".
+type form struct {
+	editID string
+	fields []formField
+	focus  int
+}
+
+// newAddForm starts on the title, with the last used Track and today prefilled for quick capture.
+func newAddForm(track, today string) *form {
+	return &form{fields: []formField{
+		{label: fieldTitle},
+		{label: fieldTrack, value: track},
+		{label: fieldCategory},
+		{label: fieldCount, value: "1"},
+		{label: fieldDate, value: today},
+	}}
+}
+
+// newEditForm omits the Track, which an update cannot change.
+func newEditForm(item query.DoneLogItem) *form {
+	return &form{editID: item.ID, fields: []formField{
+		{label: fieldTitle, value: item.Title},
+		{label: fieldCategory, value: item.CategoryID},
+		{label: fieldCount, value: strconv.Itoa(item.Count)},
+		{label: fieldDate, value: item.OccurredOn},
+	}}
+}
+
+func (f *form) value(label string) string {
+	for _, field := range f.fields {
+		if field.label == label {
+			return field.value
+		}
+	}
+	return ""
+}
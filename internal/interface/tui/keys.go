@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+// KeyKind distinguishes printable runes from editing and navigation keys.
+type KeyKind int
+
+const (
+	KeyRune KeyKind = iota
+	KeyUp
+	KeyDown
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyCtrlC
+	KeyUnknown
+)
+
+// Key is one decoded keystroke. Rune is set only for KeyRune.
+type Key struct {
+	Kind KeyKind
+	Rune rune
+}
+
+// RuneKey is a shorthand for a printable key.
+func RuneKey(r rune) Key {
+	return Key{Kind: KeyRune, Rune: r}
+}
+
+// ReadKey decodes one keystroke from a terminal in non-canonical mode.
+// A lone ESC is reported as KeyEsc; ESC [ A/B as the arrow keys.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	switch b {
+	case 3:
+		return Key{Kind: KeyCtrlC}, nil
+	case '\t':
+		return Key{Kind: KeyTab}, nil
+	case '\r', '\n':
+		return Key{Kind: KeyEnter}, nil
+	case 8, 127:
+		return Key{Kind: KeyBackspace}, nil
+	case 27:
+		if r.Buffered() == 0 {
+			return Key{Kind: KeyEsc}, nil
+		}
+		next, _ := r.ReadByte()
+		if next != '[' && next != 'O' {
+			return Key{Kind: KeyEsc}, nil
+		}
+		final, err := r.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		switch final {
+		case 'A':
+			return Key{Kind: KeyUp}, nil
+		case 'B':
+			return Key{Kind: KeyDown}, nil
+		}
+		return Key{Kind: KeyUnknown}, nil
+	}
+	if b < utf8.RuneSelf {
+		if b < ' ' {
+			return Key{Kind: KeyUnknown}, nil
+		}
+		return RuneKey(rune(b)), nil
+	}
+
+	if err := r.UnreadByte(); err != nil {
+		return Key{}, err
+	}
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	return RuneKey(ch), nil
+}
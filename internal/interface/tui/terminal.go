@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// Run takes over the terminal attached to in until the user quits.
+// It switches to the alternate screen and puts the tty into non-canonical, no-echo mode via stty.
+func Run(ctx context.Context, app *App, in *os.File, out io.Writer) error {
+	restore, err := makeRaw(in)
+	if err != nil {
+		return fmt.Errorf("terminal: %w", err)
+	}
+	defer restore()
+
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	app.Resize(terminalSize(in))
+	app.Refresh(ctx)
+	keys := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, clearScreen+app.View())
+		key, err := ReadKey(keys)
+		if err != nil {
+			return err
+		}
+		app.Resize(terminalSize(in))
+		if app.HandleKey(ctx, key) {
+			return nil
+		}
+	}
+}
+
+// makeRaw disables line buffering, echo and signal keys so Ctrl-C reaches the TUI.
+// Output processing is left on, so "\n" still starts a new line.
+func makeRaw(tty *os.File) (func(), error) {
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() { _, _ = stty(tty, strings.TrimSpace(saved)) }, nil
+}
+
+// terminalSize reports rows and columns, falling back to 24x80.
+func terminalSize(tty *os.File) (int, int) {
+	out, err := stty(tty, "size")
+	if err != nil {
+		return 24, 80
+	}
+	var rows, cols int
+	if _, err := fmt.Sscan(out, &rows, &cols); err != nil || rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taketosaeki/donelog/internal/bootstrap"
+	"github.com/taketosaeki/donelog/internal/infrastructure/persistence/filestore"
+	"github.com/taketosaeki/donelog/internal/interface/httpapi"
+)
+
+func TestReadKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Key
+	}{
+		{"OK: runes and enter", "ab\r", []Key{RuneKey('a'), RuneKey('b'), {Kind: KeyEnter}}},
+		{"OK: arrows", "\x1b[A\x1b[B", []Key{{Kind: KeyUp}, {Kind: KeyDown}}},
+		{"OK: lone escape", "\x1b", []Key{{Kind: KeyEsc}}},
+		{"OK: multibyte rune and backspace", "読\x7f", []Key{RuneKey('読'), {Kind: KeyBackspace}}},
+		{"OK: ctrl-c and tab", "\x03\t", []Key{{Kind: KeyCtrlC}, {Kind: KeyTab}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			for i, want := range tt.want {
+				got, err := ReadKey(r)
+				if err != nil {
+					t.Fatalf("key %d: %v", i, err)
+				}
+				if got != want {
+					t.Fatalf("key %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func typeKeys(ctx context.Context, app *App, keys ...any) {
+	for _, k := range keys {
+		switch k := k.(type) {
+		case string:
+			for _, r := range k {
+				app.HandleKey(ctx, RuneKey(r))
+			}
+		case KeyKind:
+			app.HandleKey(ctx, Key{Kind: k})
+		}
+	}
+}
+
+func TestApp(t *testing.T) {
+	ctx := context.Background()
+	store, _ := filestore.Open("")
+	server := httptest.NewServer(bootstrap.New(store).HTTPHandler().Routes())
+	defer server.Close()
+	client := &httpapi.Client{BaseURL: server.URL, Actor: "taketo"}
+	_ = client.CreateCategory(ctx, httpapi.CreateCategoryRequest{ID: "pages", Name: "Pages"})
+	_ = client.CreateTrack(ctx, httpapi.CreateTrackRequest{ID: "reading", Name: "Reading", DefaultCategoryID: "pages"})
+
+	today := time.Now()
+	app := NewApp(client, func() time.Time { return today })
+	app.Refresh(ctx)
+	if !strings.Contains(app.View(), "nothing yet") {
+		t.Fatalf("expected empty today list:\n%s", app.View())
+	}
+
+	// Quick capture: title, track, (blank category), count.
+	typeKeys(ctx, app, "a", "ch.1", KeyTab, "reading", KeyTab, KeyTab, KeyBackspace, "12", KeyEnter)
+	view := app.View()
+	if app.mode != modeBrowse || !strings.Contains(view, "12  ch.1  · Reading / Pages") {
+		t.Fatalf("expected added entry, status %q:\n%s", app.status, view)
+	}
+	if !strings.Contains(view, "Last 30 days · 12 done") || !strings.Contains(view, "Pages    12") {
+		t.Fatalf("expected chart and category totals:\n%s", view)
+	}
+
+	// The next add remembers the Track.
+	typeKeys(ctx, app, "a")
+	if app.form.value(fieldTrack) != "reading" {
+		t.Fatalf("expected last track to be prefilled, got %q", app.form.value(fieldTrack))
+	}
+	typeKeys(ctx, app, KeyEsc)
+
+	// Edit the count.
+	typeKeys(ctx, app, "e", KeyTab, KeyTab, KeyBackspace, KeyBackspace, "30", KeyEnter)
+	if app.today[0].Count != 30 {
+		t.Fatalf("expected edited count, got %+v (status %q)", app.today[0], app.status)
+	}
+
+	// Invalid input keeps the form open with an error.
+	typeKeys(ctx, app, "e", KeyTab, KeyTab, "x", KeyEnter)
+	if app.mode != modeForm || !strings.HasPrefix(app.status, "error:") {
+		t.Fatalf("expected form to stay open with an error, status %q", app.status)
+	}
+	typeKeys(ctx, app, KeyEsc)
+
+	// Delete asks for confirmation, then undo brings the entry back.
+	typeKeys(ctx, app, "d", "n")
+	if len(app.today) != 1 {
+		t.Fatal("expected delete to be cancelled")
+	}
+	typeKeys(ctx, app, "d", "y")
+	if len(app.today) != 0 {
+		t.Fatalf("expected entry to be deleted, status %q", app.status)
+	}
+	typeKeys(ctx, app, "u")
+	if len(app.today) != 1 {
+		t.Fatalf("expected undo to restore the entry, status %q", app.status)
+	}
+
+	if !app.HandleKey(ctx, RuneKey('q')) {
+		t.Fatal("expected q to quit")
+	}
+}
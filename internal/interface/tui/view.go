@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	chartHeight = 6
+	// categoryBarWidth is the width of the longest per-category bar.
+	categoryBarWidth = 30
+)
+
+// eighths are partial block characters, from empty to full.
+var eighths = []rune(" ▁▂▃▄▅▆▇█")
+
+// View renders the whole screen as text with "\n" line breaks.
+func (a *App) View() string {
+	var b strings.Builder
+	width := max(a.cols, 40)
+
+	fmt.Fprintf(&b, "DONELOG  %s\n", a.now().Format("2006-01-02 (Mon)"))
+	a.viewToday(&b, width)
+	if a.mode == modeForm {
+		a.viewForm(&b, width)
+	} else {
+		a.viewChart(&b, width)
+		a.viewCategories(&b, width)
+	}
+
+	b.WriteString(rule("", width))
+	switch a.mode {
+	case modeForm:
+		b.WriteString("Enter save · Tab/↓ next field · ↑ previous · Esc cancel\n")
+	case modeConfirmDelete:
+		item, _ := a.selected()
+		fmt.Fprintf(&b, "Delete %q? (y/N)\n", item.Title)
+	default:
+		b.WriteString("[a]dd  [e]dit  [d]elete  [u]ndo  [r]efresh  [q]uit  ↑↓/jk move\n")
+	}
+	if a.status != "" {
+		b.WriteString(truncate(a.status, width) + "\n")
+	}
+	return b.String()
+}
+
+func (a *App) viewToday(b *strings.Builder, width int) {
+	total := 0
+	for _, item := range a.today {
+		total += item.Count
+	}
+	b.WriteString(rule(fmt.Sprintf("Today · %d entries · %d done", len(a.today), total), width))
+	if len(a.today) == 0 {
+		b.WriteString("  nothing yet — press a to add\n")
+		return
+	}
+
+	// Keep the cursor visible when the list is taller than its share of the screen.
+	visible := max(3, a.rows-chartHeight-len(a.categories)-12)
+	start := max(0, min(a.cursor-visible+1, len(a.today)-visible))
+	for i := start; i < len(a.today) && i < start+visible; i++ {
+		item := a.today[i]
+		marker := " "
+		if i == a.cursor && a.mode != modeForm {
+			marker = ">"
+		}
+		line := fmt.Sprintf("%s %4d  %s  · %s / %s", marker, item.Count, item.Title, nameOr(item.TrackName, item.TrackID), nameOr(item.CategoryName, item.CategoryID))
+		b.WriteString(truncate(line, width) + "\n")
+	}
+}
+
+func (a *App) viewForm(b *strings.Builder, width int) {
+	title := "Add DoneLog"
+	if a.form.editID != "" {
+		title = "Edit DoneLog"
+	}
+	b.WriteString(rule(title, width))
+	for i, field := range a.form.fields {
+		marker, cursor := " ", ""
+		if i == a.form.focus {
+			marker, cursor = ">", "_"
+		}
+		hint := ""
+		if field.label == fieldCategory && a.form.editID == "" && field.value == "" {
+			hint = "  (blank: track default)"
+		}
+		b.WriteString(truncate(fmt.Sprintf("%s %-9s %s%s%s", marker, field.label+":", field.value, cursor, hint), width) + "\n")
+	}
+}
+
+// viewChart draws one column per day, scaled to the busiest day, using eighth blocks.
+func (a *App) viewChart(b *strings.Builder, width int) {
+	points := a.daily.Points
+	b.WriteString(rule(fmt.Sprintf("Last %d days · %d done", chartDays, a.daily.TotalCount), width))
+	if len(points) == 0 {
+		return
+	}
+	peak := 0
+	for _, p := range points {
+		peak = max(peak, p.Count)
+	}
+
+	for row := chartHeight - 1; row >= 0; row-- {
+		line := []rune("  ")
+		for _, p := range points {
+			line = append(line, barCell(p.Count, peak, row), ' ')
+		}
+		if row == chartHeight-1 {
+			line = append(line, []rune(fmt.Sprintf(" %d", peak))...)
+		}
+		b.WriteString(strings.TrimRight(string(line), " ") + "\n")
+	}
+
+	first, last := points[0].Label, points[len(points)-1].Label
+	gap := max(1, len(points)*2-len(first[5:])-len(last[5:]))
+	fmt.Fprintf(b, "  %s%s%s\n", first[5:], strings.Repeat(" ", gap), last[5:])
+}
+
+// barCell returns the block for one row of a column whose full height is chartHeight rows.
+func barCell(count, peak, row int) rune {
+	if peak == 0 || count == 0 {
+		return ' '
+	}
+	filled := max(1, count*chartHeight*8/peak) - row*8
+	return eighths[max(0, min(8, filled))]
+}
+
+func (a *App) viewCategories(b *strings.Builder, width int) {
+	b.WriteString(rule(fmt.Sprintf("By category · last %d days", chartDays), width))
+	peak, nameWidth := 0, 0
+	for _, c := range a.categories {
+		peak = max(peak, c.count)
+		nameWidth = max(nameWidth, utf8.RuneCountInString(c.name))
+	}
+	for _, c := range a.categories {
+		bar := ""
+		if peak > 0 && c.count > 0 {
+			bar = strings.Repeat("█", max(1, c.count*categoryBarWidth/peak))
+		}
+		pad := strings.Repeat(" ", nameWidth-utf8.RuneCountInString(c.name))
+		b.WriteString(truncate(fmt.Sprintf("  %s%s %5d %s", c.name, pad, c.count, bar), width) + "\n")
+	}
+}
+
+// rule is a section heading padded with a horizontal line.
+func rule(title string, width int) string {
+	if title != "" {
+		title = "── " + title + " "
+	}
+	return title + strings.Repeat("─", max(0, width-utf8.RuneCountInString(title))) + "\n"
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func nameOr(name, id string) string {
+	if name == "" {
+		return id
+	}
+	return name
+}